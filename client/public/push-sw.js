@@ -0,0 +1,34 @@
+// Service worker showing Pow Hunter's Web Push alerts. Payloads are the JSON
+// WebPushPayload built by the server: title, body, url, tag and unsubscribe_url.
+
+self.addEventListener('push', (event) => {
+	if (!event.data) {
+		return
+	}
+
+	const payload = event.data.json()
+	const actions = payload.unsubscribe_url
+		? [{ action: 'unsubscribe', title: 'Unsubscribe' }]
+		: []
+
+	event.waitUntil(
+		self.registration.showNotification(payload.title, {
+			body: payload.body,
+			tag: payload.tag,
+			renotify: true,
+			icon: '/favicon.ico',
+			data: { url: payload.url, unsubscribeUrl: payload.unsubscribe_url },
+			actions,
+		})
+	)
+})
+
+self.addEventListener('notificationclick', (event) => {
+	event.notification.close()
+
+	const { url, unsubscribeUrl } = event.notification.data || {}
+	const target = event.action === 'unsubscribe' ? unsubscribeUrl : url
+	if (target) {
+		event.waitUntil(self.clients.openWindow(target))
+	}
+})
@@ -44,7 +44,7 @@ export default function ContactUs() {
 		setSuccess(false)
 
 		try {
-			const response = await fetch(`${BASE_SERVER_URL}/api/contact`, {
+			const response = await fetch(`${BASE_SERVER_URL}/api/v1/contact`, {
 				method: 'POST',
 				headers: {
 					'Content-Type': 'application/json',
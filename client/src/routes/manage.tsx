@@ -176,7 +176,7 @@ export default function ManageSubscriptionsPage() {
 
 						<Box sx={{ display: 'flex', flexDirection: 'column', gap: 2 }}>
 							{alerts.map((alert) => (
-								<Card key={alert.id} variant="outlined">
+								<Card key={alert.resort_uuid} variant="outlined">
 									<CardContent>
 										<Box
 											sx={{
@@ -201,8 +201,10 @@ export default function ManageSubscriptionsPage() {
 													/>
 												</Box>
 												<Typography variant="body2" color="text.secondary">
-													Created: {console.log(alert)}
-													{new Date(alert.created_at.Time).toLocaleDateString()}
+													Created:{' '}
+													{alert.created_at
+														? new Date(alert.created_at).toLocaleDateString()
+														: 'Unknown'}
 												</Typography>
 											</Box>
 											<IconButton
@@ -1,28 +1,35 @@
 export const BASE_SERVER_URL =
 	import.meta.env.VITE_BASE_SERVER_URL || 'http://localhost:8080'
 
-export type NullableString = {
-	String: string
-	Valid: boolean
-}
-
-export type NullableFloat = {
-	Float64: number
-	Valid: boolean
-}
+// Response bodies of version 1 of the API, matching server/internal/apiv1.
 
 export type ResortApiResponse = {
-	id: number
 	uuid: string
 	name: string
-	url_host: NullableString
-	url_pathname: NullableString
-	latitude: NullableFloat
-	longitude: NullableFloat
+	url: {
+		host: string
+		pathname: string
+	} | null
+	lat: number | null
+	lon: number | null
+}
+
+export type ForecastDay = {
+	date: string
+	snow_amount: number
+	min_temperature: number
+	max_temperature: number
+	avg_temperature: number
+}
+
+export type ResortDetail = ResortApiResponse & {
+	subscriber_count: number
+	units: 'imperial' | 'metric'
+	forecast_updated_at: string | null
+	forecast: ForecastDay[]
 }
 
 export type Resort = {
-	id: number
 	uuid: string
 	name: string
 	urlHost: string | null
@@ -32,15 +39,15 @@ export type Resort = {
 }
 
 export type UserAlert = {
-	id: number
-	user_uuid: string
 	resort_uuid: string
 	resort_name: string
 	min_snow_amount: number
 	notification_days: number
 	active: boolean
-	created_at: {
-		Time: string
-		Valid: boolean
-	}
+	created_at: string | null
+}
+
+export type StatusResponse = {
+	status: string
+	message: string
 }
@@ -11,11 +11,27 @@ type AlertData = {
 	resortsUuids: string[]
 }
 
+type FieldError = {
+	field: string
+	rule: string
+	message: string
+}
+
 type ErrorResponse = {
 	error: string
 	message: string
+	fields?: FieldError[]
+}
+
+const FIELD_ERROR_MESSAGES: Record<string, string> = {
+	email: 'Please enter a valid email address.',
+	notificationDays: 'Please choose between 1 and 10 days of notice.',
+	minSnowAmount: 'Please choose a snow amount between 0 and 100 inches.',
 }
 
+const getFieldErrorMessage = (fields: FieldError[] = []): string | undefined =>
+	fields.map((field) => FIELD_ERROR_MESSAGES[field.field]).find(Boolean)
+
 const getErrorMessage = (errorResponse: ErrorResponse): string => {
 	switch (errorResponse.error) {
 		case 'DUPLICATE_ALERT':
@@ -24,10 +40,17 @@ const getErrorMessage = (errorResponse: ErrorResponse): string => {
 			return 'Please enter a valid email address.'
 		case 'MISSING_PHONE':
 			return 'Please enter a phone number to receive SMS alerts.'
+		case 'INVALID_PHONE':
+			return 'Please enter a valid mobile phone number, including the country code if outside the US.'
 		case 'MISSING_RESORTS':
 			return 'Please select at least one resort to receive alerts for.'
 		case 'VALIDATION_ERROR':
-			return 'Please check your information and try again.'
+			return (
+				getFieldErrorMessage(errorResponse.fields) ||
+				'Please check your information and try again.'
+			)
+		case 'RATE_LIMITED':
+			return 'Too many sign-up attempts. Please wait a while and try again.'
 		case 'METHOD_NOT_ALLOWED':
 			return 'Something went wrong. Please refresh the page and try again.'
 		case 'INVALID_REQUEST':
@@ -43,7 +66,7 @@ const getErrorMessage = (errorResponse: ErrorResponse): string => {
 }
 
 const createAlert = async (data: AlertData): Promise<void> => {
-	const response = await fetch(`${BASE_SERVER_URL}/api/alerts`, {
+	const response = await fetch(`${BASE_SERVER_URL}/api/v1/alerts`, {
 		method: 'POST',
 		mode: 'cors',
 		headers: {
@@ -3,7 +3,7 @@ import { BASE_SERVER_URL, UserAlert } from './types.ts'
 
 const fetchUserAlerts = async (email: string): Promise<UserAlert[]> => {
 	const response = await fetch(
-		`${BASE_SERVER_URL}/api/user/alerts?email=${encodeURIComponent(email)}`,
+		`${BASE_SERVER_URL}/api/v1/me/alerts?email=${encodeURIComponent(email)}`,
 		{
 			method: 'GET',
 			headers: {
@@ -31,9 +31,9 @@ const deleteAlert = async ({
 	resortUuid: string
 }): Promise<void> => {
 	const response = await fetch(
-		`${BASE_SERVER_URL}/api/user/alerts/delete?email=${encodeURIComponent(
-			email
-		)}&resort_uuid=${encodeURIComponent(resortUuid)}`,
+		`${BASE_SERVER_URL}/api/v1/me/alerts/${encodeURIComponent(
+			resortUuid
+		)}?email=${encodeURIComponent(email)}`,
 		{
 			method: 'DELETE',
 			headers: {
@@ -50,9 +50,7 @@ const deleteAlert = async ({
 
 const deleteAllAlerts = async (email: string): Promise<void> => {
 	const response = await fetch(
-		`${BASE_SERVER_URL}/api/user/alerts/delete-all?email=${encodeURIComponent(
-			email
-		)}`,
+		`${BASE_SERVER_URL}/api/v1/me/alerts?email=${encodeURIComponent(email)}`,
 		{
 			method: 'DELETE',
 			headers: {
@@ -0,0 +1,126 @@
+import { useMutation, useQuery } from '@tanstack/react-query'
+import { BASE_SERVER_URL } from './types.ts'
+
+const SERVICE_WORKER_URL = '/push-sw.js'
+
+export const pushSupported = (): boolean =>
+	typeof window !== 'undefined' &&
+	'serviceWorker' in navigator &&
+	'PushManager' in window &&
+	'Notification' in window
+
+// The VAPID public key is base64url encoded; subscribe() wants the raw bytes.
+const urlBase64ToUint8Array = (base64: string): Uint8Array => {
+	const padded = (base64 + '='.repeat((4 - (base64.length % 4)) % 4))
+		.replace(/-/g, '+')
+		.replace(/_/g, '/')
+	const raw = window.atob(padded)
+	return Uint8Array.from(raw, (char) => char.charCodeAt(0))
+}
+
+const fetchPublicKey = async (): Promise<string | null> => {
+	const response = await fetch(`${BASE_SERVER_URL}/api/v1/push/vapid-public-key`, {
+		method: 'GET',
+		headers: {
+			'Content-Type': 'application/json',
+		},
+	})
+
+	if (!response.ok) {
+		// Push isn't configured on this server.
+		if (response.status === 404) {
+			return null
+		}
+		throw new Error(`Failed to fetch push key: ${response.status}`)
+	}
+
+	const { public_key } = await response.json()
+	return public_key
+}
+
+const subscribe = async ({
+	email,
+	publicKey,
+}: {
+	email: string
+	publicKey: string
+}): Promise<void> => {
+	const permission = await Notification.requestPermission()
+	if (permission !== 'granted') {
+		throw new Error('Notifications are blocked for this site')
+	}
+
+	const registration =
+		await navigator.serviceWorker.register(SERVICE_WORKER_URL)
+	await navigator.serviceWorker.ready
+
+	const subscription = await registration.pushManager.subscribe({
+		userVisibleOnly: true,
+		applicationServerKey: urlBase64ToUint8Array(publicKey),
+	})
+
+	const response = await fetch(
+		`${BASE_SERVER_URL}/api/v1/me/push-subscriptions`,
+		{
+			method: 'POST',
+			headers: {
+				'Content-Type': 'application/json',
+			},
+			credentials: 'include',
+			body: JSON.stringify({ email, subscription: subscription.toJSON() }),
+		}
+	)
+
+	if (!response.ok) {
+		throw new Error(`Failed to save push subscription: ${response.status}`)
+	}
+}
+
+const unsubscribe = async (email: string): Promise<void> => {
+	const registration =
+		await navigator.serviceWorker.getRegistration(SERVICE_WORKER_URL)
+	const subscription = await registration?.pushManager.getSubscription()
+	if (!subscription) {
+		return
+	}
+
+	const response = await fetch(
+		`${BASE_SERVER_URL}/api/v1/me/push-subscriptions?email=${encodeURIComponent(
+			email
+		)}&endpoint=${encodeURIComponent(subscription.endpoint)}`,
+		{
+			method: 'DELETE',
+			headers: {
+				'Content-Type': 'application/json',
+			},
+			credentials: 'include',
+		}
+	)
+
+	if (!response.ok && response.status !== 404) {
+		throw new Error(`Failed to remove push subscription: ${response.status}`)
+	}
+
+	await subscription.unsubscribe()
+}
+
+export function usePushPublicKey() {
+	return useQuery<string | null>({
+		queryKey: ['pushPublicKey'],
+		queryFn: fetchPublicKey,
+		enabled: pushSupported(),
+		staleTime: Infinity,
+	})
+}
+
+export function useSubscribeToPush() {
+	return useMutation<void, Error, { email: string; publicKey: string }>({
+		mutationFn: subscribe,
+	})
+}
+
+export function useUnsubscribeFromPush() {
+	return useMutation<void, Error, string>({
+		mutationFn: unsubscribe,
+	})
+}
@@ -3,7 +3,7 @@ import { BASE_SERVER_URL, Resort, ResortApiResponse } from './types.ts'
 
 const fetchResorts = async (): Promise<ResortApiResponse[]> => {
 	try {
-		const response = await fetch(`${BASE_SERVER_URL}/api/resorts`, {
+		const response = await fetch(`${BASE_SERVER_URL}/api/v1/resorts`, {
 			method: 'GET',
 			mode: 'cors',
 			cache: 'no-store',
@@ -26,15 +26,12 @@ const fetchResorts = async (): Promise<ResortApiResponse[]> => {
 const transformResortData = (data?: ResortApiResponse[]): Resort[] => {
 	return (
 		data?.map((resort) => ({
-			id: resort.id,
 			uuid: resort.uuid,
 			name: resort.name,
-			urlHost: resort.url_host.Valid ? resort.url_host.String : null,
-			urlPathname: resort.url_pathname.Valid
-				? resort.url_pathname.String
-				: null,
-			latitude: resort.latitude.Valid ? resort.latitude.Float64 : null,
-			longitude: resort.longitude.Valid ? resort.longitude.Float64 : null,
+			urlHost: resort.url?.host ?? null,
+			urlPathname: resort.url?.pathname ?? null,
+			latitude: resort.lat,
+			longitude: resort.lon,
 		})) || []
 	) // Default to empty array if data is undefined
 }
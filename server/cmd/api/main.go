@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,27 +11,28 @@ import (
 	"time"
 
 	"github.com/MattSilvaa/powhunter/internal/handlers"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
 )
 
 func main() {
+	logging.Setup("api")
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "api")
+	if err != nil {
+		logging.Fatal("Failed to configure tracing", "error", err)
+	}
+	defer shutdownTracing()
+
 	h, err := handlers.NewHandlers()
 	if err != nil {
-		log.Fatalf("Failed to initialize handlers: %v", err)
+		logging.Fatal("Failed to initialize handlers", "error", err)
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-	mux.HandleFunc("/api/resorts", h.Resort.ListAllResorts)
-	mux.HandleFunc("/api/alerts", h.Alert.CreateAlert)
-	mux.HandleFunc("/api/user/alerts", h.Alert.GetUserAlerts)
-	mux.HandleFunc("/api/user/alerts/delete", h.Alert.DeleteUserAlert)
-	mux.HandleFunc("/api/user/alerts/delete-all", h.Alert.DeleteAllUserAlerts)
-	mux.HandleFunc("/api/contact", h.Contact.HandleContact)
+	metrics.RegisterStore(h.Store())
 
-	handler := corsMiddleware(mux)
+	handler := corsMiddleware(h.Routes())
 
 	server := &http.Server{
 		Addr:         ":8080",
@@ -39,31 +40,32 @@ func main() {
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
 	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		log.Printf("Server starting on %s", server.Addr)
+		slog.Info("Server starting", "addr", server.Addr)
 
 		serverErr := server.ListenAndServe()
 		if serverErr != nil && !errors.Is(serverErr, http.ErrServerClosed) {
-			log.Fatalf("Server failed to start: %v", serverErr)
+			logging.Fatal("Server failed to start", "error", serverErr)
 		}
 	}()
 
 	<-stop
-	log.Println("Shutting down API server...")
+	slog.Info("Shutting down API server")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logging.Fatal("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited gracefully")
+	slog.Info("Server exited gracefully")
 }
 
 func corsMiddleware(h http.Handler) http.Handler {
@@ -85,7 +87,9 @@ func corsMiddleware(h http.Handler) http.Handler {
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().
-			Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
+			Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, "+
+				handlers.RequestIDHeader+", traceparent, tracestate")
+		w.Header().Set("Access-Control-Expose-Headers", handlers.RequestIDHeader)
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
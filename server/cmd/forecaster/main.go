@@ -2,24 +2,49 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
 	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
 	"github.com/MattSilvaa/powhunter/internal/weather"
 
 	_ "github.com/lib/pq"
 )
 
+// resortForecastRetentionDays is how long stored forecasts are kept after their day has passed. It must
+// cover the history shown in the Atom feeds.
+const resortForecastRetentionDays = 30
+
+// passTimeout bounds a forecast check.
+const passTimeout = 5 * time.Minute
+
 func main() {
+	logging.Setup("forecaster")
+
 	dbConn, err := db.New()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 
-	store := db.NewStore(dbConn)
+	// The pass calls the store through spans, so its trace shows the queries it ran.
+	dbStore := db.NewStore(dbConn)
+	store := db.Traced(dbStore)
 	weatherClient := weather.NewOpenMeteoClient()
 
 	var twilioClient notify.NotificationService
@@ -28,80 +53,254 @@ func main() {
 	twilioFromNumber := os.Getenv("TWILIO_FROM_NUMBER")
 
 	if twilioAccountSID == "" || twilioAuthToken == "" || twilioFromNumber == "" {
-		log.Fatalf(
+		logging.Fatal(
 			"Twilio credentials not found. Set TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, and TWILIO_FROM_NUMBER environment variables.",
 		)
 	}
+	publicBaseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	statusCallbackURL := ""
+	if publicBaseURL != "" {
+		statusCallbackURL = publicBaseURL + "/api/v1/sms/status"
+	}
 	twilioClient = notify.NewTwilioClient(
 		twilioFromNumber,
+		statusCallbackURL,
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	templates, err := notify.TemplatesFromEnv()
+	if err != nil {
+		logging.Fatal("Failed to load notification templates", "error", err)
+	}
+
+	unsubscribeSigner, err := unsubscribe.SignerFromEnv()
+	if err != nil {
+		logging.Fatal("Failed to configure unsubscribe links", "error", err)
+	}
+
+	webPushNotifier, err := notify.WebPushNotifierFromEnv(store)
+	if err != nil {
+		logging.Fatal("Failed to configure web push", "error", err)
+	}
+
+	// Send the alerts each pass queues along with any earlier retries that are now due. Failed sends stay in
+	// the outbox and are retried by the next pass or by the outbox worker.
+	worker := notify.NewOutboxWorker(store, twilioClient, notify.LimitsFromEnv(), templates).
+		WithNotifier(db.ChannelWebhook, notify.WebhookNotifierFromEnv(store)).
+		WithNotifier(db.ChannelSlack, notify.SlackNotifierFromEnv(store)).
+		WithNotifier(db.ChannelDiscord, notify.DiscordNotifierFromEnv(store)).
+		WithNotifier(db.ChannelPush, notify.NtfyNotifierFromEnv())
+	if webPushNotifier != nil {
+		worker.WithNotifier(db.ChannelWebPush, webPushNotifier)
+	}
+	if unsubscribeSigner != nil && publicBaseURL != "" {
+		worker.WithUnsubscribeLinks(unsubscribeSigner, publicBaseURL)
+	}
+
+	var interval time.Duration
+	if value := os.Getenv("FORECAST_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			logging.Fatal("Invalid FORECAST_INTERVAL", "value", value)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "forecaster")
+	if err != nil {
+		logging.Fatal("Failed to configure tracing", "error", err)
+	}
+	defer shutdownTracing()
+
+	// As a daemon, the forecaster is ready while the database is and passes keep finishing. A pass is late
+	// once it hasn't finished within an interval and the time a pass may take.
+	var mux *http.ServeMux
+	heartbeat := health.NewHeartbeat()
+	if interval > 0 {
+		checks := append(
+			health.DatabaseChecks(dbStore, db.LatestMigration()),
+			heartbeat.Check("last_run", interval+passTimeout),
+		)
+		if health.CheckProvidersFromEnv() {
+			checks = append(checks, health.ProviderCheck(weather.ProviderOpenMeteo, weatherClient.Ping))
+		}
+		mux = http.NewServeMux()
+		health.NewChecker(checks...).Routes(mux)
+	}
+
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		metrics.Serve(ctx, addr, mux)
+	}
+
+	if interval == 0 {
+		if err := checkForecasts(ctx, store, weatherClient, worker); err != nil {
+			shutdownTracing()
+			logging.Fatal("Forecast check failed", "error", err)
+		}
+		return
+	}
+
+	slog.Info("Checking forecasts periodically", "interval", interval.String())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := checkForecasts(ctx, store, weatherClient, worker); err != nil {
+			slog.Error("Forecast check failed", "error", err)
+		} else {
+			heartbeat.Beat()
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Forecaster stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkForecasts runs one pass: it fetches and stores the forecast of every resort, updates calendar events,
+// queues the alerts the forecast matches, including bluebird alerts for clear days after snow, and sends
+// what's in the outbox. The pass is traced as a whole, with the store calls, forecast fetches and sends it
+// makes as children of its span.
+func checkForecasts(
+	ctx context.Context,
+	store db.StoreService,
+	weatherClient weather.WeatherService,
+	worker *notify.OutboxWorker,
+) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, passTimeout)
 	defer cancel()
 
+	// Everything logged during the pass, including the store's queries and the sends of the outbox, carries
+	// the run's ID.
+	runID := uuid.NewString()
+	ctx = logging.WithAttrs(ctx, slog.String(logging.RunIDKey, runID))
+	ctx, span := tracing.Start(ctx, "checkForecasts", trace.WithAttributes(attribute.String("run_id", runID)))
+	defer func() { tracing.End(span, err) }()
+	slog.InfoContext(ctx, "Forecast check starting")
+
 	resorts, err := store.ListAllResorts(ctx)
 	if err != nil {
-		log.Fatalf("Failed to list resorts: %v", err)
+		return fmt.Errorf("failed to list resorts: %w", err)
 	}
 
 	for _, resort := range resorts {
+		ctx := logging.WithAttrs(ctx,
+			slog.String("resort", resort.Name),
+			slog.String("resort_uuid", resort.Uuid.String()),
+		)
+
 		if !resort.Latitude.Valid || !resort.Longitude.Valid {
-			log.Printf("Skipping resort %s: missing coordinates", resort.Name)
+			slog.WarnContext(ctx, "Skipping resort: missing coordinates")
 			continue
 		}
 
-		log.Printf(
-			"Checking forecast for %s (%.4f, %.4f)",
-			resort.Name,
-			resort.Latitude.Float64,
-			resort.Longitude.Float64,
+		slog.InfoContext(ctx, "Checking forecast",
+			"latitude", resort.Latitude.Float64,
+			"longitude", resort.Longitude.Float64,
 		)
 
-		predictions, err := weatherClient.GetSnowForecast(ctx, resort.Latitude.Float64, resort.Longitude.Float64)
+		daily, err := weatherClient.GetDailyForecast(ctx, resort.Latitude.Float64, resort.Longitude.Float64)
 		if err != nil {
-			log.Printf("Error getting forecast for %s: %v", resort.Name, err)
+			slog.ErrorContext(ctx, "Error getting forecast", "error", err)
 			continue
 		}
+		metrics.ResortsProcessed.Inc()
 
-		if len(predictions) == 0 {
-			log.Printf("No snow predicted for %s", resort.Name)
-			continue
+		resortForecasts := make([]db.ResortForecast, 0, len(daily))
+		for _, pred := range daily {
+			resortForecasts = append(resortForecasts, db.ResortForecast{
+				Date:           pred.Date,
+				SnowAmount:     pred.SnowAmount,
+				MinTemperature: pred.MinTemperature,
+				MaxTemperature: pred.MaxTemperature,
+				AvgTemperature: pred.AvgTemperature,
+			})
+		}
+		if err := store.SaveResortForecasts(ctx, resort.Uuid, resortForecasts); err != nil {
+			slog.ErrorContext(ctx, "Error saving forecast", "error", err)
 		}
 
-		log.Printf("Found %d snow predictions for %s:", len(predictions), resort.Name)
+		predictions := weather.SnowDays(daily)
+		today := time.Now().Truncate(24 * time.Hour)
+		forecasts := make([]db.CalendarForecast, 0, len(predictions))
 		for _, pred := range predictions {
-			log.Printf("  %s: %.1f inches", pred.Date.Format("2006-01-02"), pred.SnowAmount)
+			forecasts = append(forecasts, db.CalendarForecast{
+				Date:       pred.Date,
+				SnowAmount: pred.SnowAmount,
+				DaysAhead:  max(int32(pred.Date.Sub(today).Hours()/24), 0),
+			})
+		}
+		// Synced before the check below, so that events are cancelled when the snow disappears from the
+		// forecast altogether.
+		calendarSync, err := store.SyncCalendarEvents(ctx, resort.Uuid, today, forecasts)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error updating calendar events", "error", err)
+		} else if calendarSync.Cancelled > 0 {
+			slog.InfoContext(ctx, "Cancelled calendar events", "cancelled", calendarSync.Cancelled)
+		}
 
-			daysAhead := int32(pred.Date.Sub(time.Now().Truncate(24*time.Hour)).Hours() / 24)
-			if daysAhead < 0 {
-				daysAhead = 0
+		for _, bluebird := range weather.BluebirdDays(daily) {
+			ctx := logging.WithAttrs(ctx, slog.String("forecast_date", bluebird.Date.Format("2006-01-02")))
+			alerts, err := store.QueueBluebirdAlerts(
+				ctx,
+				resort.Uuid.String(),
+				bluebird.Date,
+				bluebird.SnowAmount,
+				max(int32(bluebird.Date.Sub(today).Hours()/24), 0),
+			)
+			if err != nil {
+				slog.ErrorContext(ctx, "Error queueing bluebird alerts", "error", err)
+				continue
 			}
+			if len(alerts) > 0 {
+				metrics.AlertMatches.Add(float64(len(alerts)))
+				slog.InfoContext(ctx, "Queued bluebird alerts", "alerts", len(alerts), "snow_inches", bluebird.SnowAmount)
+			}
+		}
+
+		if len(predictions) == 0 {
+			slog.InfoContext(ctx, "No snow predicted")
+			continue
+		}
 
-			alerts, err := store.GetAlertMatches(ctx, resort.Uuid.String(), pred.Date, pred.SnowAmount, daysAhead)
+		slog.InfoContext(ctx, "Found snow predictions", "predictions", len(predictions))
+		for _, forecast := range forecasts {
+			ctx := logging.WithAttrs(ctx, slog.String("forecast_date", forecast.Date.Format("2006-01-02")))
+			slog.InfoContext(ctx, "Snow predicted", "snow_inches", forecast.SnowAmount)
+
+			alerts, err := store.QueueAlertMatches(
+				ctx,
+				resort.Uuid.String(),
+				forecast.Date,
+				forecast.SnowAmount,
+				forecast.DaysAhead,
+			)
 			if err != nil {
-				log.Printf("Error finding matching alerts: %v", err)
+				slog.ErrorContext(ctx, "Error queueing matching alerts", "error", err)
 				continue
 			}
 
-			log.Printf("Found %d matching alerts", len(alerts))
-			for _, alert := range alerts {
-				log.Printf("Alert for %s (Phone: %s)", alert.ResortName, alert.UserPhone)
-
-				if alert.UserPhone != "" {
-					message := notify.FormatSnowAlertMessage(alert)
-					if err := twilioClient.SendSMS(alert.UserPhone, message); err != nil {
-						log.Printf("Error sending SMS to %s: %v", alert.UserPhone, err)
-					} else {
-						log.Printf("Sent SMS alert to %s for %s", alert.UserPhone, alert.ResortName)
-					}
-				}
-
-				if err := store.RecordAlertSent(ctx, alert); err != nil {
-					log.Printf("Error recording alert history: %v", err)
-				}
-			}
+			metrics.AlertMatches.Add(float64(len(alerts)))
+			slog.InfoContext(ctx, "Queued matching alerts", "alerts", len(alerts))
 		}
 	}
 
-	log.Println("Forecast check complete")
+	pruneBefore := time.Now().Truncate(24*time.Hour).AddDate(0, 0, -resortForecastRetentionDays)
+	pruned, err := store.PruneResortForecasts(ctx, pruneBefore)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error pruning old forecasts", "error", err)
+	} else if pruned > 0 {
+		slog.InfoContext(ctx, "Pruned old forecasts", "pruned", pruned)
+	}
+
+	if err := worker.Drain(ctx); err != nil {
+		slog.ErrorContext(ctx, "Error sending queued alerts", "error", err)
+	}
+
+	metrics.ForecasterLastRun.SetToCurrentTime()
+	slog.InfoContext(ctx, "Forecast check complete")
+	return nil
 }
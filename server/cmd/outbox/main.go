@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: outbox <command> [arguments]
+
+Commands:
+  run                   Send queued notifications, retrying failures, until interrupted
+  list [status] [limit] List outbox messages (default status "dead", limit 50)
+  requeue <uuid>        Requeue a dead-lettered message
+  requeue --all         Requeue every dead-lettered message
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	logging.Setup("outbox")
+
+	dbConn, err := db.New()
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+	defer dbConn.Close()
+
+	store := db.NewStore(dbConn)
+
+	switch os.Args[1] {
+	case "run":
+		run(store)
+	case "list":
+		list(store, os.Args[2:])
+	case "requeue":
+		requeue(store, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func run(store *db.Store) {
+	twilioFromNumber := os.Getenv("TWILIO_FROM_NUMBER")
+	if os.Getenv("TWILIO_ACCOUNT_SID") == "" || os.Getenv("TWILIO_AUTH_TOKEN") == "" || twilioFromNumber == "" {
+		logging.Fatal(
+			"Twilio credentials not found. Set TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN, and TWILIO_FROM_NUMBER environment variables.",
+		)
+	}
+
+	publicBaseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	statusCallbackURL := ""
+	if publicBaseURL != "" {
+		statusCallbackURL = publicBaseURL + "/api/v1/sms/status"
+	}
+
+	pollInterval := 30 * time.Second
+	if value := os.Getenv("OUTBOX_POLL_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			logging.Fatal("Invalid OUTBOX_POLL_INTERVAL", "value", value, "error", err)
+		}
+		pollInterval = parsed
+	}
+
+	templates, err := notify.TemplatesFromEnv()
+	if err != nil {
+		logging.Fatal("Failed to load notification templates", "error", err)
+	}
+
+	unsubscribeSigner, err := unsubscribe.SignerFromEnv()
+	if err != nil {
+		logging.Fatal("Failed to configure unsubscribe links", "error", err)
+	}
+
+	// The worker calls the store through spans, so each batch's trace shows the queries it ran.
+	traced := db.Traced(store)
+
+	webPushNotifier, err := notify.WebPushNotifierFromEnv(traced)
+	if err != nil {
+		logging.Fatal("Failed to configure web push", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, "outbox")
+	if err != nil {
+		logging.Fatal("Failed to configure tracing", "error", err)
+	}
+	defer shutdownTracing()
+
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		metrics.Serve(ctx, addr, nil)
+	}
+
+	worker := notify.NewOutboxWorker(
+		traced,
+		notify.NewTwilioClient(twilioFromNumber, statusCallbackURL),
+		notify.LimitsFromEnv(),
+		templates,
+	).
+		WithNotifier(db.ChannelWebhook, notify.WebhookNotifierFromEnv(traced)).
+		WithNotifier(db.ChannelSlack, notify.SlackNotifierFromEnv(traced)).
+		WithNotifier(db.ChannelDiscord, notify.DiscordNotifierFromEnv(traced)).
+		WithNotifier(db.ChannelPush, notify.NtfyNotifierFromEnv())
+	if webPushNotifier != nil {
+		worker.WithNotifier(db.ChannelWebPush, webPushNotifier)
+	}
+	if unsubscribeSigner != nil && publicBaseURL != "" {
+		worker.WithUnsubscribeLinks(unsubscribeSigner, publicBaseURL)
+	}
+	slog.Info("Sending queued notifications", "poll_interval", pollInterval.String())
+	if err := worker.Run(ctx, pollInterval); err != nil && ctx.Err() == nil {
+		logging.Fatal("Outbox worker stopped", "error", err)
+	}
+	slog.Info("Outbox worker stopped")
+}
+
+func list(store *db.Store, args []string) {
+	status := db.OutboxStatusDead
+	if len(args) > 0 {
+		status = args[0]
+	}
+
+	limit := 50
+	if len(args) > 1 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed <= 0 {
+			logging.Fatal("Invalid limit", "value", args[1])
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	messages, err := store.ListOutboxMessages(ctx, status, int32(limit))
+	if err != nil {
+		logging.Fatal("Failed to list outbox messages", "error", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "UUID\tCHANNEL\tRECIPIENT\tFORECAST\tSNOW\tATTEMPTS\tUPDATED\tLAST ERROR")
+	for _, m := range messages {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\t%d/%d\t%s\t%s\n",
+			m.Uuid,
+			m.Channel,
+			m.Recipient,
+			m.ForecastDate.Format("2006-01-02"),
+			m.SnowAmount,
+			m.Attempts,
+			m.MaxAttempts,
+			m.UpdatedAt.Format(time.RFC3339),
+			m.LastError.String,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		logging.Fatal("Failed to write output", "error", err)
+	}
+}
+
+func requeue(store *db.Store, args []string) {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if args[0] == "--all" {
+		count, err := store.RequeueDeadOutboxMessages(ctx)
+		if err != nil {
+			logging.Fatal("Failed to requeue outbox messages", "error", err)
+		}
+		slog.Info("Requeued dead-lettered messages", "count", count)
+		return
+	}
+
+	messageUUID, err := uuid.Parse(args[0])
+	if err != nil {
+		logging.Fatal("Invalid message UUID", "value", args[0], "error", err)
+	}
+
+	requeued, err := store.RequeueOutboxMessage(ctx, messageUUID)
+	if err != nil {
+		logging.Fatal("Failed to requeue outbox message", "error", err)
+	}
+	if !requeued {
+		logging.Fatal("No dead-lettered message with UUID", "message_uuid", messageUUID)
+	}
+	slog.Info("Requeued message", "message_uuid", messageUUID)
+}
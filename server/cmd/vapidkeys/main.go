@@ -0,0 +1,20 @@
+// Command vapidkeys generates a VAPID key pair for Web Push. Put the private key in VAPID_PRIVATE_KEY; the
+// public key is served to browsers from /api/v1/push/vapid-public-key.
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MattSilvaa/powhunter/internal/webpush"
+)
+
+func main() {
+	keys, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", keys.PrivateKey())
+	fmt.Printf("# Public key: %s\n", keys.PublicKey())
+}
@@ -0,0 +1,180 @@
+// Package apierror defines the errors the API returns. Every error has a Code from a fixed catalogue,
+// which clients branch on, and a message for people. Each code is always returned with the same HTTP
+// status, so the status is part of the catalogue rather than chosen by handlers.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MattSilvaa/powhunter/internal/validate"
+)
+
+// Code identifies an error. Clients branch on it, so a code's meaning and status never change once added.
+type Code string
+
+const (
+	InternalError    Code = "INTERNAL_ERROR"
+	NotFound         Code = "NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+
+	InvalidRequest       Code = "INVALID_REQUEST"
+	ValidationError      Code = "VALIDATION_ERROR"
+	MissingRequiredField Code = "MISSING_REQUIRED_FIELD"
+
+	MissingEmail      Code = "MISSING_EMAIL"
+	MissingEndpoint   Code = "MISSING_ENDPOINT"
+	MissingMessage    Code = "MISSING_MESSAGE"
+	MissingMessageSID Code = "MISSING_MESSAGE_SID"
+	MissingName       Code = "MISSING_NAME"
+	MissingPhone      Code = "MISSING_PHONE"
+	MissingResort     Code = "MISSING_RESORT"
+	MissingResorts    Code = "MISSING_RESORTS"
+	MissingURL        Code = "MISSING_URL"
+
+	InvalidChannel      Code = "INVALID_CHANNEL"
+	InvalidDestination  Code = "INVALID_DESTINATION"
+	InvalidEmail        Code = "INVALID_EMAIL"
+	InvalidLimit        Code = "INVALID_LIMIT"
+	InvalidLocale       Code = "INVALID_LOCALE"
+	InvalidPhone        Code = "INVALID_PHONE"
+	InvalidSubscription Code = "INVALID_SUBSCRIPTION"
+	InvalidTopic        Code = "INVALID_TOPIC"
+	InvalidUnits        Code = "INVALID_UNITS"
+	InvalidURL          Code = "INVALID_URL"
+	InvalidWebhook      Code = "INVALID_WEBHOOK"
+
+	InvalidSignature Code = "INVALID_SIGNATURE"
+
+	AlertNotFound        Code = "ALERT_NOT_FOUND"
+	CalendarNotFound     Code = "CALENDAR_NOT_FOUND"
+	DestinationNotFound  Code = "DESTINATION_NOT_FOUND"
+	FeedNotFound         Code = "FEED_NOT_FOUND"
+	PushNotConfigured    Code = "PUSH_NOT_CONFIGURED"
+	SMSNotConfigured     Code = "SMS_NOT_CONFIGURED"
+	ResortNotFound       Code = "RESORT_NOT_FOUND"
+	SubscriptionNotFound Code = "SUBSCRIPTION_NOT_FOUND"
+	UserNotFound         Code = "USER_NOT_FOUND"
+	WebhookNotFound      Code = "WEBHOOK_NOT_FOUND"
+
+	DuplicateAlert    Code = "DUPLICATE_ALERT"
+	DuplicateEntry    Code = "DUPLICATE_ENTRY"
+	DestinationLimit  Code = "DESTINATION_LIMIT"
+	SubscriptionLimit Code = "SUBSCRIPTION_LIMIT"
+	WebhookLimit      Code = "WEBHOOK_LIMIT"
+
+	RateLimited Code = "RATE_LIMITED"
+
+	WebhookFailed Code = "WEBHOOK_FAILED"
+)
+
+// definition is a code's entry in the catalogue.
+type definition struct {
+	status int
+	// title summarizes the problem the code identifies. It's the message of errors mapped from the store,
+	// and the title of problem details.
+	title string
+}
+
+var catalogue = map[Code]definition{
+	InternalError:    {http.StatusInternalServerError, "Internal error"},
+	NotFound:         {http.StatusNotFound, "Not found"},
+	MethodNotAllowed: {http.StatusMethodNotAllowed, "Method not allowed"},
+
+	InvalidRequest:       {http.StatusBadRequest, "Invalid request body"},
+	ValidationError:      {http.StatusBadRequest, "Data validation failed"},
+	MissingRequiredField: {http.StatusBadRequest, "Required field is missing"},
+
+	MissingEmail:      {http.StatusBadRequest, "Email is required"},
+	MissingEndpoint:   {http.StatusBadRequest, "Endpoint is required"},
+	MissingMessage:    {http.StatusBadRequest, "Message is required"},
+	MissingMessageSID: {http.StatusBadRequest, "MessageSid is required"},
+	MissingName:       {http.StatusBadRequest, "Name is required"},
+	MissingPhone:      {http.StatusBadRequest, "Phone number is required"},
+	MissingResort:     {http.StatusBadRequest, "Resort UUID is required"},
+	MissingResorts:    {http.StatusBadRequest, "At least one resort is required"},
+	MissingURL:        {http.StatusBadRequest, "Webhook URL is required"},
+
+	InvalidChannel:      {http.StatusBadRequest, "Channel is not supported"},
+	InvalidDestination:  {http.StatusBadRequest, "Destination UUID is not valid"},
+	InvalidEmail:        {http.StatusBadRequest, "Invalid email address"},
+	InvalidLimit:        {http.StatusBadRequest, "Limit is out of range"},
+	InvalidLocale:       {http.StatusBadRequest, "Locale is not supported"},
+	InvalidPhone:        {http.StatusBadRequest, "Phone number is not valid"},
+	InvalidSubscription: {http.StatusBadRequest, "Push subscription is not valid"},
+	InvalidTopic:        {http.StatusBadRequest, "Push topic is not valid"},
+	InvalidUnits:        {http.StatusBadRequest, "Units must be imperial or metric"},
+	InvalidURL:          {http.StatusBadRequest, "Webhook URL is not valid"},
+	InvalidWebhook:      {http.StatusBadRequest, "Webhook UUID is not valid"},
+
+	InvalidSignature: {http.StatusForbidden, "Request signature is not valid"},
+
+	AlertNotFound:        {http.StatusNotFound, "You don't have an alert for this resort"},
+	CalendarNotFound:     {http.StatusNotFound, "Calendar not found"},
+	DestinationNotFound:  {http.StatusNotFound, "No destination with that UUID"},
+	FeedNotFound:         {http.StatusNotFound, "Feed not found"},
+	PushNotConfigured:    {http.StatusNotFound, "Push notifications are not available"},
+	SMSNotConfigured:     {http.StatusNotFound, "Text messages are not available"},
+	ResortNotFound:       {http.StatusNotFound, "Resort not found"},
+	SubscriptionNotFound: {http.StatusNotFound, "No subscription for that endpoint"},
+	UserNotFound:         {http.StatusNotFound, "No user with that email"},
+	WebhookNotFound:      {http.StatusNotFound, "No webhook with that UUID"},
+
+	DuplicateAlert:    {http.StatusConflict, "You already have an alert for this resort"},
+	DuplicateEntry:    {http.StatusConflict, "This entry already exists"},
+	DestinationLimit:  {http.StatusConflict, "The alert has as many destinations as it can"},
+	SubscriptionLimit: {http.StatusConflict, "Push alerts are going to as many browsers as they can"},
+	WebhookLimit:      {http.StatusConflict, "You have as many webhooks as you can register"},
+
+	RateLimited: {http.StatusTooManyRequests, "Too many requests"},
+
+	WebhookFailed: {http.StatusBadGateway, "Webhook test failed"},
+}
+
+// Status returns the HTTP status the code is returned with. Codes missing from the catalogue are internal
+// errors.
+func (c Code) Status() int {
+	if d, ok := catalogue[c]; ok {
+		return d.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title returns a short summary of the problem the code identifies.
+func (c Code) Title() string {
+	if d, ok := catalogue[c]; ok {
+		return d.title
+	}
+	return catalogue[InternalError].title
+}
+
+// Error is an error returned by the API.
+type Error struct {
+	Code    Code
+	Message string
+	// Fields are the request fields the error is about, if it's about any.
+	Fields validate.Errors
+}
+
+// New returns an error with a code and a message saying what went wrong with the request.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Invalid returns an error with a code and a message, listing the fields err is about if it's
+// validate.Errors.
+func Invalid(code Code, message string, err error) *Error {
+	apiErr := New(code, message)
+	errors.As(err, &apiErr.Fields)
+	return apiErr
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// Status returns the HTTP status the error is returned with.
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
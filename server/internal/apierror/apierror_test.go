@@ -0,0 +1,148 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, DuplicateAlert.Status())
+	assert.Equal(t, "You already have an alert for this resort", DuplicateAlert.Title())
+
+	unknown := Code("UNKNOWN")
+	assert.Equal(t, http.StatusInternalServerError, unknown.Status(), "codes missing from the catalogue are internal")
+	assert.Equal(t, "Internal error", unknown.Title())
+}
+
+func TestFromStore(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedError *Error
+	}{
+		{
+			name:          "Not found",
+			err:           fmt.Errorf("deleting webhook: %w", db.ErrWebhookNotFound),
+			expectedError: New(WebhookNotFound, "No webhook with that UUID"),
+		},
+		{
+			name:          "Limit",
+			err:           db.ErrDestinationLimit,
+			expectedError: New(DestinationLimit, "An alert can have up to 3 destinations"),
+		},
+		{
+			name:          "Duplicate alert",
+			err:           &pq.Error{Code: "23505", Constraint: "user_alerts_user_uuid_resort_uuid_key"},
+			expectedError: New(DuplicateAlert, "You already have an alert for this resort"),
+		},
+		{
+			name:          "Duplicate",
+			err:           &pq.Error{Code: "23505", Constraint: "users_email_key"},
+			expectedError: New(DuplicateEntry, "This entry already exists"),
+		},
+		{
+			name:          "Not null violation",
+			err:           &pq.Error{Code: "23502"},
+			expectedError: New(MissingRequiredField, "Required field is missing"),
+		},
+		{
+			name:          "Foreign key violation",
+			err:           fmt.Errorf("creating alerts: %w", &pq.Error{Code: "23503"}),
+			expectedError: New(ValidationError, "Data validation failed"),
+		},
+		{
+			name:          "Other Postgres error",
+			err:           &pq.Error{Code: "57014"},
+			expectedError: New(InternalError, "Failed to create alert"),
+		},
+		{
+			name:          "Other error",
+			err:           errors.New("connection refused"),
+			expectedError: New(InternalError, "Failed to create alert"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedError, FromStore(tt.err, "Failed to create alert"))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name                string
+		accept              []string
+		err                 error
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Error response",
+			err:                 New(MissingEmail, "Email parameter is required"),
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"MISSING_EMAIL","message":"Email parameter is required"}`,
+		},
+		{
+			name:                "Problem details",
+			accept:              []string{"application/json", "application/problem+json"},
+			err:                 New(MissingEmail, "Email parameter is required"),
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: ProblemMediaType,
+			expectedBody: `{"type":"https://powhunter.app/problems/missing-email","title":"Email is required",` +
+				`"status":400,"detail":"Email parameter is required","code":"MISSING_EMAIL"}`,
+		},
+		{
+			name:                "Problem details refused",
+			accept:              []string{"application/problem+json;q=0, application/json"},
+			err:                 New(MissingEmail, "Email parameter is required"),
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"MISSING_EMAIL","message":"Email parameter is required"}`,
+		},
+		{
+			name:                "Wrapped error",
+			err:                 fmt.Errorf("validating: %w", New(InvalidUnits, "Units must be imperial or metric")),
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"INVALID_UNITS","message":"Units must be imperial or metric"}`,
+		},
+		{
+			name:                "Other error",
+			err:                 errors.New("pq: password authentication failed"),
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"error":"INTERNAL_ERROR","message":"Internal error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/me/alerts", nil)
+			for _, accept := range tt.accept {
+				req.Header.Add("Accept", accept)
+			}
+			rr := httptest.NewRecorder()
+
+			Write(rr, req, tt.err)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedContentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+			require.True(t, json.Valid(rr.Body.Bytes()))
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+// Postgres error codes the store can fail with because of what a request asked for.
+const (
+	pqInvalidTextRepresentation = "22P02"
+	pqNotNullViolation          = "23502"
+	pqForeignKeyViolation       = "23503"
+	pqUniqueViolation           = "23505"
+	pqCheckViolation            = "23514"
+)
+
+// alertPerResortConstraint keeps users to one alert per resort.
+const alertPerResortConstraint = "user_alerts_user_uuid_resort_uuid_key"
+
+// storeErrors maps the store's errors to the API's.
+var storeErrors = []struct {
+	err    error
+	apiErr *Error
+}{
+	{db.ErrUserNotFound, New(UserNotFound, UserNotFound.Title())},
+	{db.ErrAlertNotFound, New(AlertNotFound, AlertNotFound.Title())},
+	{db.ErrResortNotFound, New(ResortNotFound, ResortNotFound.Title())},
+	{db.ErrCalendarNotFound, New(CalendarNotFound, CalendarNotFound.Title())},
+	{db.ErrDestinationNotFound, New(DestinationNotFound, DestinationNotFound.Title())},
+	{db.ErrWebhookNotFound, New(WebhookNotFound, WebhookNotFound.Title())},
+	{db.ErrPushSubscriptionNotFound, New(SubscriptionNotFound, SubscriptionNotFound.Title())},
+	{db.ErrDestinationLimit, New(DestinationLimit,
+		fmt.Sprintf("An alert can have up to %d destinations", db.MaxDestinationsPerAlert))},
+	{db.ErrWebhookLimit, New(WebhookLimit,
+		fmt.Sprintf("You can register up to %d webhooks", db.MaxWebhooksPerUser))},
+	{db.ErrPushSubscriptionLimit, New(SubscriptionLimit,
+		fmt.Sprintf("Push alerts can go to up to %d browsers", db.MaxPushSubscriptionsPerUser))},
+}
+
+// FromStore maps an error from the store to the error the API returns: missing records are not found,
+// limits and unique violations are conflicts, and constraint violations are invalid requests. Any other
+// error is an internal error with message, which says what the handler failed to do.
+func FromStore(err error, message string) *Error {
+	for _, m := range storeErrors {
+		if errors.Is(err, m.err) {
+			apiErr := *m.apiErr
+			return &apiErr
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqUniqueViolation:
+			if pqErr.Constraint == alertPerResortConstraint {
+				return New(DuplicateAlert, DuplicateAlert.Title())
+			}
+			return New(DuplicateEntry, DuplicateEntry.Title())
+		case pqNotNullViolation:
+			return New(MissingRequiredField, MissingRequiredField.Title())
+		case pqCheckViolation, pqForeignKeyViolation, pqInvalidTextRepresentation:
+			return New(ValidationError, ValidationError.Title())
+		}
+	}
+
+	return New(InternalError, message)
+}
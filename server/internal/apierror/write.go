@@ -0,0 +1,89 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/MattSilvaa/powhunter/internal/validate"
+)
+
+// ProblemMediaType is the media type of RFC 7807 problem details.
+const ProblemMediaType = "application/problem+json"
+
+// ProblemTypeBase prefixes the type URI of problem details, which is followed by the code in lowercase
+// with dashes. Type URIs identify problems; they aren't guaranteed to resolve.
+const ProblemTypeBase = "https://powhunter.app/problems/"
+
+// ErrorResponse is the body of an error response, unless the request accepts problem details.
+type ErrorResponse struct {
+	Error   Code   `json:"error"`
+	Message string `json:"message"`
+	// Fields are the request fields that aren't valid, when the error is about some.
+	Fields validate.Errors `json:"fields,omitempty"`
+}
+
+// Problem is the body of an error response as RFC 7807 problem details, for requests that accept them. Code
+// and Fields are extension members carrying the same as in an ErrorResponse.
+type Problem struct {
+	Type   string          `json:"type"`
+	Title  string          `json:"title"`
+	Status int             `json:"status"`
+	Detail string          `json:"detail"`
+	Code   Code            `json:"code"`
+	Fields validate.Errors `json:"fields,omitempty"`
+}
+
+// NewProblem returns the problem details of an error.
+func NewProblem(e *Error) Problem {
+	return Problem{
+		Type:   ProblemTypeBase + strings.ReplaceAll(strings.ToLower(string(e.Code)), "_", "-"),
+		Title:  e.Code.Title(),
+		Status: e.Status(),
+		Detail: e.Message,
+		Code:   e.Code,
+		Fields: e.Fields,
+	}
+}
+
+// Write writes err as the response to r: as problem details if r accepts them, and as an ErrorResponse
+// otherwise. Errors that aren't an *Error are written as internal errors, without their message.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = New(InternalError, InternalError.Title())
+	}
+
+	var body any = ErrorResponse{Error: apiErr.Code, Message: apiErr.Message, Fields: apiErr.Fields}
+	contentType := "application/json"
+	if AcceptsProblem(r) {
+		body = NewProblem(apiErr)
+		contentType = ProblemMediaType
+	}
+
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(apiErr.Status())
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode error response", "error", err)
+	}
+}
+
+// AcceptsProblem reports whether r's Accept header lists problem details.
+func AcceptsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && mediaType == ProblemMediaType && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package apiv1
+
+import (
+	"time"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// Alert is a user's alert on one resort. A user has at most one alert per resort, so alerts are identified
+// by their resort.
+type Alert struct {
+	ResortUUID       string     `json:"resort_uuid"`
+	ResortName       string     `json:"resort_name"`
+	MinSnowAmount    float64    `json:"min_snow_amount"`
+	NotificationDays int32      `json:"notification_days"`
+	Active           bool       `json:"active"`
+	CreatedAt        *time.Time `json:"created_at"`
+}
+
+// NewAlert maps a stored alert.
+func NewAlert(alert dbgen.GetUserAlertsByEmailRow) Alert {
+	response := Alert{
+		ResortName:       alert.ResortName,
+		MinSnowAmount:    alert.MinSnowAmount,
+		NotificationDays: alert.NotificationDays,
+		// Alerts without an active flag aren't matched by the forecaster.
+		Active: alert.Active.Valid && alert.Active.Bool,
+	}
+	if alert.ResortUuid.Valid {
+		response.ResortUUID = alert.ResortUuid.UUID.String()
+	}
+	if alert.CreatedAt.Valid {
+		response.CreatedAt = &alert.CreatedAt.Time
+	}
+	return response
+}
+
+// NewAlerts maps stored alerts.
+func NewAlerts(alerts []dbgen.GetUserAlertsByEmailRow) []Alert {
+	response := make([]Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		response = append(response, NewAlert(alert))
+	}
+	return response
+}
@@ -0,0 +1,36 @@
+// Package apiv1 defines the JSON bodies returned by version 1 of the API, and maps store models onto them.
+//
+// These types are a contract with the web client, so handlers never encode store or sqlc models directly:
+// nullable columns become JSON nulls or omitted fields, and serial IDs stay internal. Fields may be added
+// freely, but renaming or removing one, or changing its type, needs a new version of the API.
+package apiv1
+
+import (
+	"math"
+	"time"
+)
+
+// Status acknowledges a request that has nothing else to return.
+type Status struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewStatus returns a successful status with a message for the user.
+func NewStatus(message string) Status {
+	return Status{Status: "success", Message: message}
+}
+
+// timePtr returns a pointer to t, or nil if t is zero, so that unset times encode as null.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// roundTo rounds a value to the given number of decimal places.
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
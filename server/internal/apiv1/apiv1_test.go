@@ -0,0 +1,134 @@
+package apiv1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+func TestNewResort(t *testing.T) {
+	tests := []struct {
+		name     string
+		resort   dbgen.Resort
+		expected string
+	}{
+		{
+			name: "All columns set",
+			resort: dbgen.Resort{
+				ID:          7,
+				Uuid:        uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"),
+				Name:        "Crystal Mountain",
+				UrlHost:     sql.NullString{String: "www.crystalmountainresort.com", Valid: true},
+				UrlPathname: sql.NullString{String: "/snow-report", Valid: true},
+				Latitude:    sql.NullFloat64{Float64: 46.9282, Valid: true},
+				Longitude:   sql.NullFloat64{Float64: -121.5045, Valid: true},
+			},
+			expected: `{
+				"uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"name": "Crystal Mountain",
+				"url": {"host": "www.crystalmountainresort.com", "pathname": "/snow-report"},
+				"lat": 46.9282,
+				"lon": -121.5045
+			}`,
+		},
+		{
+			name: "Null columns",
+			resort: dbgen.Resort{
+				ID:   8,
+				Uuid: uuid.MustParse("1d8ddb3d-3839-473d-a8b7-89015d961d25"),
+				Name: "Stevens Pass",
+			},
+			expected: `{
+				"uuid": "1d8ddb3d-3839-473d-a8b7-89015d961d25",
+				"name": "Stevens Pass",
+				"url": null,
+				"lat": null,
+				"lon": null
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(NewResort(tt.resort))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestNewAlert(t *testing.T) {
+	created := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	resortUUID := uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d")
+
+	tests := []struct {
+		name     string
+		alert    dbgen.GetUserAlertsByEmailRow
+		expected string
+	}{
+		{
+			name: "Active alert",
+			alert: dbgen.GetUserAlertsByEmailRow{
+				ID:               12,
+				UserUuid:         uuid.NullUUID{UUID: uuid.New(), Valid: true},
+				ResortUuid:       uuid.NullUUID{UUID: resortUUID, Valid: true},
+				ResortName:       "Crystal Mountain",
+				MinSnowAmount:    6,
+				NotificationDays: 3,
+				Active:           sql.NullBool{Bool: true, Valid: true},
+				CreatedAt:        sql.NullTime{Time: created, Valid: true},
+			},
+			expected: `{
+				"resort_uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"resort_name": "Crystal Mountain",
+				"min_snow_amount": 6,
+				"notification_days": 3,
+				"active": true,
+				"created_at": "2025-12-18T06:00:00Z"
+			}`,
+		},
+		{
+			name: "Null columns",
+			alert: dbgen.GetUserAlertsByEmailRow{
+				ID:               13,
+				ResortUuid:       uuid.NullUUID{UUID: resortUUID, Valid: true},
+				ResortName:       "Crystal Mountain",
+				MinSnowAmount:    6,
+				NotificationDays: 3,
+			},
+			expected: `{
+				"resort_uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"resort_name": "Crystal Mountain",
+				"min_snow_amount": 6,
+				"notification_days": 3,
+				"active": false,
+				"created_at": null
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(NewAlert(tt.alert))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestNewResorts_EncodesEmptyListAsArray(t *testing.T) {
+	body, err := json.Marshal(NewResorts(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(body))
+
+	body, err = json.Marshal(NewAlerts(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(body))
+}
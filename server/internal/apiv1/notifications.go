@@ -0,0 +1,146 @@
+package apiv1
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/phone"
+)
+
+// Delivery is a single outbound notification and its delivery status.
+type Delivery struct {
+	UUID       string `json:"uuid"`
+	ResortUUID string `json:"resort_uuid,omitempty"`
+	Channel    string `json:"channel"`
+	// Recipient is the number an SMS was sent to, masked to its country code and last four digits. Other
+	// channels leave it out, since their recipients are topic URLs or internal IDs.
+	Recipient    string    `json:"recipient,omitempty"`
+	Status       string    `json:"status"`
+	ErrorCode    string    `json:"error_code,omitempty"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Destination is a Slack or Discord channel an alert is also sent to. The webhook URL is a credential, so
+// it isn't returned.
+type Destination struct {
+	UUID       string    `json:"uuid"`
+	ResortUUID string    `json:"resort_uuid"`
+	Channel    string    `json:"channel"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Webhook is a registered webhook. Like the URLs of destinations, webhook URLs often carry a token, so only
+// the host is returned. The secret is only included when the webhook is created.
+type Webhook struct {
+	UUID      string    `json:"uuid"`
+	Host      string    `json:"host"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookTest reports a test event the webhook accepted.
+type WebhookTest struct {
+	Status     string `json:"status"`
+	DeliveryID string `json:"delivery_id"`
+}
+
+// PushSubscription is a browser subscribed to Web Push alerts.
+type PushSubscription struct {
+	UUID      string    `json:"uuid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VAPIDPublicKey is the applicationServerKey browsers subscribe to Web Push with.
+type VAPIDPublicKey struct {
+	PublicKey string `json:"public_key"`
+}
+
+// NewDelivery maps a stored delivery. Deliveries are listed by email alone, so the recipient is masked.
+func NewDelivery(d dbgen.NotificationDelivery) Delivery {
+	delivery := Delivery{
+		UUID:         d.Uuid.String(),
+		Channel:      d.Channel,
+		Status:       d.Status,
+		ErrorCode:    d.ErrorCode.String,
+		ErrorMessage: d.ErrorMessage.String,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+	}
+	if d.ResortUuid.Valid {
+		delivery.ResortUUID = d.ResortUuid.UUID.String()
+	}
+	if d.Channel == db.ChannelSMS {
+		delivery.Recipient = phone.Mask(d.Recipient)
+	}
+	return delivery
+}
+
+// NewDeliveries maps stored deliveries.
+func NewDeliveries(deliveries []dbgen.NotificationDelivery) []Delivery {
+	response := make([]Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, NewDelivery(delivery))
+	}
+	return response
+}
+
+// NewDestination maps a stored alert destination.
+func NewDestination(destination dbgen.AlertDestination) Destination {
+	return Destination{
+		UUID:       destination.Uuid.String(),
+		ResortUUID: destination.ResortUuid.String(),
+		Channel:    destination.Channel,
+		CreatedAt:  destination.CreatedAt,
+	}
+}
+
+// NewDestinations maps stored alert destinations.
+func NewDestinations(destinations []dbgen.AlertDestination) []Destination {
+	response := make([]Destination, 0, len(destinations))
+	for _, destination := range destinations {
+		response = append(response, NewDestination(destination))
+	}
+	return response
+}
+
+// NewWebhook maps a stored webhook, leaving out its secret and all of its URL but the host.
+func NewWebhook(webhook dbgen.Webhook) Webhook {
+	response := Webhook{
+		UUID:      webhook.Uuid.String(),
+		CreatedAt: webhook.CreatedAt,
+	}
+	if u, err := url.Parse(webhook.Url); err == nil {
+		response.Host = u.Host
+	}
+	return response
+}
+
+// NewWebhooks maps stored webhooks, leaving out their secrets.
+func NewWebhooks(webhooks []dbgen.Webhook) []Webhook {
+	response := make([]Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, NewWebhook(webhook))
+	}
+	return response
+}
+
+// NewWebhookTest maps the receipt of a test event.
+func NewWebhookTest(receipt notify.Receipt) WebhookTest {
+	return WebhookTest{
+		Status:     receipt.Status,
+		DeliveryID: receipt.MessageID,
+	}
+}
+
+// NewPushSubscription maps a stored push subscription, leaving out its keys.
+func NewPushSubscription(subscription dbgen.PushSubscription) PushSubscription {
+	return PushSubscription{
+		UUID:      subscription.Uuid.String(),
+		CreatedAt: subscription.CreatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package apiv1
+
+import (
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// Resort is a ski resort users can set alerts on.
+type Resort struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// URL is the resort's snow report, or null if it isn't known.
+	URL *ResortURL `json:"url"`
+	Lat *float64   `json:"lat"`
+	Lon *float64   `json:"lon"`
+}
+
+// ResortURL is the address of a resort's snow report, split as the client links to it.
+type ResortURL struct {
+	Host     string `json:"host"`
+	PathName string `json:"pathname"`
+}
+
+// ResortDetail is a resort with its latest stored forecast.
+type ResortDetail struct {
+	Resort
+	// SubscriberCount is the number of users with an active alert on the resort.
+	SubscriberCount int64 `json:"subscriber_count"`
+	// Units are the units of the forecast: inches and °F, or centimeters and °C.
+	Units units.System `json:"units"`
+	// ForecastUpdatedAt is when the forecast was last fetched, or null if it hasn't been yet.
+	ForecastUpdatedAt *time.Time    `json:"forecast_updated_at"`
+	Forecast          []ForecastDay `json:"forecast"`
+}
+
+// ForecastDay is one day of a resort's forecast.
+type ForecastDay struct {
+	Date           string  `json:"date"`
+	SnowAmount     float64 `json:"snow_amount"`
+	MinTemperature float64 `json:"min_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	AvgTemperature float64 `json:"avg_temperature"`
+}
+
+// NewResort maps a stored resort.
+func NewResort(resort dbgen.Resort) Resort {
+	response := Resort{
+		UUID: resort.Uuid.String(),
+		Name: resort.Name,
+	}
+	if resort.UrlHost.Valid && resort.UrlHost.String != "" {
+		response.URL = &ResortURL{
+			Host:     resort.UrlHost.String,
+			PathName: resort.UrlPathname.String,
+		}
+	}
+	if resort.Latitude.Valid {
+		response.Lat = &resort.Latitude.Float64
+	}
+	if resort.Longitude.Valid {
+		response.Lon = &resort.Longitude.Float64
+	}
+	return response
+}
+
+// NewResorts maps stored resorts.
+func NewResorts(resorts []dbgen.Resort) []Resort {
+	response := make([]Resort, 0, len(resorts))
+	for _, resort := range resorts {
+		response = append(response, NewResort(resort))
+	}
+	return response
+}
+
+// NewResortDetail maps a resort and its forecast, converting the forecast to system.
+func NewResortDetail(detail db.ResortDetail, system units.System) ResortDetail {
+	response := ResortDetail{
+		Resort:            NewResort(detail.Resort),
+		SubscriberCount:   detail.SubscriberCount,
+		Units:             system,
+		ForecastUpdatedAt: timePtr(detail.RefreshedAt),
+		Forecast:          make([]ForecastDay, 0, len(detail.Forecasts)),
+	}
+
+	for _, forecast := range detail.Forecasts {
+		response.Forecast = append(response.Forecast, ForecastDay{
+			Date:           forecast.Date.Format(time.DateOnly),
+			SnowAmount:     roundTo(system.Snow(forecast.SnowAmount), 1),
+			MinTemperature: roundTo(system.Temperature(forecast.MinTemperature), 1),
+			MaxTemperature: roundTo(system.Temperature(forecast.MaxTemperature), 1),
+			AvgTemperature: roundTo(system.Temperature(forecast.AvgTemperature), 1),
+		})
+	}
+
+	return response
+}
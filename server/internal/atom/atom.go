@@ -0,0 +1,135 @@
+// Package atom writes Atom (RFC 4287) feeds.
+//
+// Feed readers identify entries by their ID and use Updated to tell a changed entry from one they've seen,
+// so callers give each entry an ID that never changes and only move Updated when its content does.
+package atom
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ContentType is the media type of a feed.
+const ContentType = "application/atom+xml; charset=utf-8"
+
+const namespace = "http://www.w3.org/2005/Atom"
+
+// Feed is an Atom feed.
+type Feed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	// Updated is when the feed last changed, normally the most recent entry's Updated.
+	Updated time.Time
+	Author  string
+	// SelfURL is where the feed is served, and AlternateURL the web page it describes.
+	SelfURL      string
+	AlternateURL string
+	Entries      []Entry
+}
+
+// Entry is an entry in a feed.
+type Entry struct {
+	ID    string
+	Title string
+	// Summary is plain text.
+	Summary   string
+	URL       string
+	Published time.Time
+	Updated   time.Time
+}
+
+type xmlFeed struct {
+	XMLName  xml.Name   `xml:"feed"`
+	Xmlns    string     `xml:"xmlns,attr"`
+	ID       string     `xml:"id"`
+	Title    string     `xml:"title"`
+	Subtitle string     `xml:"subtitle,omitempty"`
+	Updated  string     `xml:"updated"`
+	Author   *xmlAuthor `xml:"author,omitempty"`
+	Links    []xmlLink  `xml:"link"`
+	Entries  []xmlEntry `xml:"entry"`
+}
+
+type xmlAuthor struct {
+	Name string `xml:"name"`
+}
+
+type xmlLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type xmlEntry struct {
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Published string    `xml:"published,omitempty"`
+	Updated   string    `xml:"updated"`
+	Summary   *xmlText  `xml:"summary,omitempty"`
+	Links     []xmlLink `xml:"link"`
+}
+
+type xmlText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Encode writes the feed to w.
+func (f Feed) Encode(w io.Writer) error {
+	feed := xmlFeed{
+		Xmlns:    namespace,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  formatTime(f.Updated),
+		Entries:  make([]xmlEntry, 0, len(f.Entries)),
+	}
+	if f.Author != "" {
+		feed.Author = &xmlAuthor{Name: f.Author}
+	}
+	if f.SelfURL != "" {
+		feed.Links = append(feed.Links, xmlLink{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL})
+	}
+	if f.AlternateURL != "" {
+		feed.Links = append(feed.Links, xmlLink{Rel: "alternate", Type: "text/html", Href: f.AlternateURL})
+	}
+
+	for _, entry := range f.Entries {
+		e := xmlEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Updated: formatTime(entry.Updated),
+		}
+		if !entry.Published.IsZero() {
+			e.Published = formatTime(entry.Published)
+		}
+		if entry.Summary != "" {
+			e.Summary = &xmlText{Type: "text", Text: entry.Summary}
+		}
+		if entry.URL != "" {
+			e.Links = append(e.Links, xmlLink{Rel: "alternate", Href: entry.URL})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return fmt.Errorf("error encoding feed: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// formatTime formats t as an RFC 3339 timestamp in UTC.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
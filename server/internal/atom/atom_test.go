@@ -0,0 +1,69 @@
+package atom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed_Encode(t *testing.T) {
+	feed := Feed{
+		ID:           "tag:powhunter.app,2025:resorts/feed",
+		Title:        "Pow Hunter powder forecasts",
+		Updated:      time.Date(2025, 12, 18, 6, 30, 0, 0, time.FixedZone("PST", -8*60*60)),
+		Author:       "Pow Hunter",
+		SelfURL:      "https://api.powhunter.app/api/feeds/resorts.atom",
+		AlternateURL: "https://powhunter.app",
+		Entries: []Entry{
+			{
+				ID:        "tag:powhunter.app,2025:resorts/9a8b7c6d/forecasts/2025-12-20",
+				Title:     "Crystal Mountain: 8.5 inches on Sat, Dec 20",
+				Summary:   "8.5 inches of snow forecast at Crystal Mountain & nearby <peaks>.",
+				URL:       "https://www.crystalmountainresort.com",
+				Published: time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+				Updated:   time.Date(2025, 12, 18, 14, 30, 0, 0, time.UTC),
+			},
+			{
+				ID:      "tag:powhunter.app,2025:resorts/1d8ddb3d/forecasts/2025-12-21",
+				Title:   "Stevens Pass: 6.0 inches on Sun, Dec 21",
+				Updated: time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, feed.Encode(&buf))
+	out := buf.String()
+
+	assert.Contains(t, out, `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, out, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, out, `<updated>2025-12-18T14:30:00Z</updated>`, "timestamps are in UTC")
+	assert.Contains(t, out, `<link rel="self" type="application/atom+xml" href="https://api.powhunter.app/api/feeds/resorts.atom"></link>`)
+	assert.Contains(t, out, `<summary type="text">8.5 inches of snow forecast at Crystal Mountain &amp; nearby &lt;peaks&gt;.</summary>`)
+	assert.Contains(t, out, `<link rel="alternate" href="https://www.crystalmountainresort.com"></link>`)
+
+	var decoded struct {
+		ID      string `xml:"id"`
+		Author  string `xml:"author>name"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Title     string `xml:"title"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+			Summary   string `xml:"summary"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, feed.ID, decoded.ID)
+	assert.Equal(t, "Pow Hunter", decoded.Author)
+	require.Len(t, decoded.Entries, 2)
+	assert.Equal(t, feed.Entries[0].ID, decoded.Entries[0].ID)
+	assert.Equal(t, "2025-12-17T06:00:00Z", decoded.Entries[0].Published)
+	assert.Equal(t, "2025-12-18T14:30:00Z", decoded.Entries[0].Updated)
+	assert.Empty(t, decoded.Entries[1].Published, "published is optional")
+	assert.Empty(t, decoded.Entries[1].Summary)
+}
@@ -0,0 +1,162 @@
+// Package calendar writes iCalendar (RFC 5545) feeds of forecast powder days, and issues the tokens that
+// keep each user's feed URL private.
+//
+// Events are all-day and identified by a UID that stays the same for a resort and date, so calendar clients
+// replace an event when the forecast changes instead of adding another. Cancelled events stay in the feed
+// with STATUS:CANCELLED until they age out, so clients remove them rather than keeping a stale copy.
+package calendar
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of a feed.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	productID = "-//Pow Hunter//Powder Days//EN"
+
+	// refreshInterval is how often clients are asked to fetch the feed again. The forecaster runs every 12
+	// hours.
+	refreshInterval = "PT6H"
+
+	// maxLineOctets is the longest a content line may be before it is folded.
+	maxLineOctets = 75
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+
+	tokenBytes = 32
+)
+
+// Feed is a calendar of events.
+type Feed struct {
+	Name   string
+	Events []Event
+}
+
+// Event is an all-day calendar event.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	URL         string
+	// Sequence is the event's revision, which clients compare to tell which copy is newer.
+	Sequence  int32
+	Cancelled bool
+	// Modified is when the event last changed.
+	Modified time.Time
+}
+
+// Encode writes the feed as an iCalendar object.
+func (f Feed) Encode(w io.Writer) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", productID)
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	e.line("X-WR-CALNAME", text(f.Name))
+	e.line("REFRESH-INTERVAL;VALUE=DURATION", refreshInterval)
+	e.line("X-PUBLISHED-TTL", refreshInterval)
+
+	for _, event := range f.Events {
+		status := "CONFIRMED"
+		if event.Cancelled {
+			status = "CANCELLED"
+		}
+		modified := event.Modified.UTC().Format(dateTimeLayout)
+
+		e.line("BEGIN", "VEVENT")
+		e.line("UID", text(event.UID))
+		e.line("DTSTAMP", modified)
+		e.line("LAST-MODIFIED", modified)
+		e.line("SEQUENCE", strconv.Itoa(int(event.Sequence)))
+		e.line("DTSTART;VALUE=DATE", event.Date.Format(dateLayout))
+		e.line("DTEND;VALUE=DATE", event.Date.AddDate(0, 0, 1).Format(dateLayout))
+		e.line("SUMMARY", text(event.Summary))
+		if event.Description != "" {
+			e.line("DESCRIPTION", text(event.Description))
+		}
+		if event.URL != "" {
+			e.line("URL", event.URL)
+		}
+		e.line("STATUS", status)
+		e.line("TRANSP", "TRANSPARENT")
+		e.line("END", "VEVENT")
+	}
+
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// encoder writes content lines, keeping the first write error.
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, folded into lines of at most maxLineOctets octets. Folds never split a UTF-8
+// sequence.
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	rest := name + ":" + value
+	limit := maxLineOctets
+	for len(rest) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(rest[cut]) {
+			cut--
+		}
+		b.WriteString(rest[:cut])
+		b.WriteString("\r\n ")
+		rest = rest[cut:]
+		// Continuation lines start with the space, which counts towards their length.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(rest)
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// text escapes a TEXT property value.
+func text(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// NewToken returns a random token for a feed URL.
+func NewToken() (string, error) {
+	token := make([]byte, tokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("error generating calendar token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashToken returns the hash of a token that is stored in place of the token itself.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed_Encode(t *testing.T) {
+	modified := time.Date(2025, 12, 18, 6, 30, 0, 0, time.UTC)
+	feed := Feed{
+		Name: "Pow Hunter powder days",
+		Events: []Event{
+			{
+				UID:         "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d@powhunter.app",
+				Date:        time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+				Summary:     "Powder day: Crystal Mountain, 8.5 inches",
+				Description: "8.5 inches of snow forecast.\nSnow report: https://www.crystalmountainresort.com",
+				URL:         "https://www.crystalmountainresort.com",
+				Sequence:    2,
+				Modified:    modified,
+			},
+			{
+				UID:       "1d8ddb3d-3839-473d-a8b7-89015d961d25@powhunter.app",
+				Date:      time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+				Summary:   "Powder day: Stevens Pass; 6.0 inches",
+				Sequence:  1,
+				Cancelled: true,
+				Modified:  modified,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, feed.Encode(&buf))
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Pow Hunter//Powder Days//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Pow Hunter powder days",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT6H",
+		"X-PUBLISHED-TTL:PT6H",
+		"BEGIN:VEVENT",
+		"UID:9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d@powhunter.app",
+		"DTSTAMP:20251218T063000Z",
+		"LAST-MODIFIED:20251218T063000Z",
+		"SEQUENCE:2",
+		"DTSTART;VALUE=DATE:20251220",
+		"DTEND;VALUE=DATE:20251221",
+		`SUMMARY:Powder day: Crystal Mountain\, 8.5 inches`,
+		`DESCRIPTION:8.5 inches of snow forecast.\nSnow report: https://www.crystalm`,
+		" ountainresort.com",
+		"URL:https://www.crystalmountainresort.com",
+		"STATUS:CONFIRMED",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:1d8ddb3d-3839-473d-a8b7-89015d961d25@powhunter.app",
+		"DTSTAMP:20251218T063000Z",
+		"LAST-MODIFIED:20251218T063000Z",
+		"SEQUENCE:1",
+		"DTSTART;VALUE=DATE:20251231",
+		"DTEND;VALUE=DATE:20260101",
+		`SUMMARY:Powder day: Stevens Pass\; 6.0 inches`,
+		"STATUS:CANCELLED",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestEncoder_FoldsWithoutSplittingCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	summary := strings.Repeat("❄", 60)
+	require.NoError(t, Feed{Events: []Event{{Summary: summary}}}.Encode(&buf))
+
+	var unfolded string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, line)
+		assert.True(t, strings.ToValidUTF8(line, "") == line, "folds split a character: %q", line)
+
+		if strings.HasPrefix(line, " ") {
+			unfolded += line[1:]
+		} else {
+			unfolded += "\n" + line
+		}
+	}
+	assert.Contains(t, unfolded, "\nSUMMARY:"+summary+"\n")
+}
+
+func TestToken(t *testing.T) {
+	first, err := NewToken()
+	require.NoError(t, err)
+	second, err := NewToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, HashToken(first), HashToken(first))
+	assert.NotEqual(t, HashToken(first), HashToken(second))
+	assert.Len(t, HashToken(first), 64)
+}
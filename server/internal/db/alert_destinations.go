@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// Chat channels an alert can be posted to through an incoming webhook. Their outbox recipient is the
+// destination's UUID.
+const (
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+)
+
+// MaxDestinationsPerAlert is how many extra destinations one alert can have.
+const MaxDestinationsPerAlert = 3
+
+var (
+	// ErrAlertNotFound is returned when the user has no alert for the given resort.
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrDestinationNotFound is returned when no destination matches the given UUID, or it belongs to
+	// another user.
+	ErrDestinationNotFound = errors.New("alert destination not found")
+	// ErrDestinationLimit is returned when an alert already has MaxDestinationsPerAlert destinations.
+	ErrDestinationLimit = errors.New("alert destination limit reached")
+)
+
+// CreateAlertDestination adds a chat channel to post a user's alert for a resort to.
+func (s *Store) CreateAlertDestination(
+	ctx context.Context,
+	email string,
+	resortUUID uuid.UUID,
+	channel, url string,
+) (dbgen.AlertDestination, error) {
+	var destination dbgen.AlertDestination
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		_, err = q.GetUserAlert(ctx, dbgen.GetUserAlertParams{
+			UserUuid:   uuid.NullUUID{UUID: user.Uuid, Valid: true},
+			ResortUuid: uuid.NullUUID{UUID: resortUUID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlertNotFound
+			}
+			return fmt.Errorf("error getting alert: %w", err)
+		}
+
+		count, err := q.CountAlertDestinations(ctx, dbgen.CountAlertDestinationsParams{
+			UserUuid:   user.Uuid,
+			ResortUuid: resortUUID,
+		})
+		if err != nil {
+			return fmt.Errorf("error counting alert destinations: %w", err)
+		}
+		if count >= MaxDestinationsPerAlert {
+			return ErrDestinationLimit
+		}
+
+		destination, err = q.CreateAlertDestination(ctx, dbgen.CreateAlertDestinationParams{
+			UserUuid:   user.Uuid,
+			ResortUuid: resortUUID,
+			Channel:    channel,
+			Url:        url,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating alert destination: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dbgen.AlertDestination{}, err
+	}
+
+	return destination, nil
+}
+
+// ListAlertDestinations returns the destinations of every alert belonging to the user with the given email.
+func (s *Store) ListAlertDestinations(ctx context.Context, email string) ([]dbgen.AlertDestination, error) {
+	destinations, err := s.queries.ListAlertDestinationsByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("error listing alert destinations: %w", err)
+	}
+	return destinations, nil
+}
+
+// GetAlertDestination returns an alert destination by UUID.
+func (s *Store) GetAlertDestination(ctx context.Context, destinationUUID uuid.UUID) (dbgen.AlertDestination, error) {
+	destination, err := s.queries.GetAlertDestination(ctx, destinationUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbgen.AlertDestination{}, ErrDestinationNotFound
+		}
+		return dbgen.AlertDestination{}, fmt.Errorf("error getting alert destination: %w", err)
+	}
+	return destination, nil
+}
+
+// DeleteAlertDestination removes a destination belonging to the user with the given email, along with any
+// alerts still queued for it.
+func (s *Store) DeleteAlertDestination(ctx context.Context, email string, destinationUUID uuid.UUID) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		channel, err := q.DeleteAlertDestination(ctx, dbgen.DeleteAlertDestinationParams{
+			Uuid:  destinationUUID,
+			Email: email,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrDestinationNotFound
+			}
+			return fmt.Errorf("error deleting alert destination: %w", err)
+		}
+
+		_, err = q.CancelPendingOutboxMessages(ctx, dbgen.CancelPendingOutboxMessagesParams{
+			Channel:   channel,
+			Recipient: destinationUUID.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("error cancelling queued alerts: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// ErrCalendarNotFound is returned when no calendar feed matches a token, or the user has none.
+var ErrCalendarNotFound = errors.New("calendar feed not found")
+
+// CalendarForecast is one day of a resort's snow forecast.
+type CalendarForecast struct {
+	Date       time.Time
+	SnowAmount float64
+	DaysAhead  int32
+}
+
+// CalendarSync counts the events a forecast run matched and cancelled for one resort.
+type CalendarSync struct {
+	Matched   int64
+	Cancelled int64
+}
+
+// CalendarFeed is a user's calendar of powder days.
+type CalendarFeed struct {
+	UserUUID uuid.UUID
+	// Units and Locale are the user's presentation preferences. Event snow amounts are always in inches.
+	Units  units.System
+	Locale string
+	Events []CalendarEvent
+}
+
+// CalendarEvent is a forecast powder day at one of the user's resorts. Its UUID stays the same as the
+// forecast changes, and Sequence increases with every change.
+type CalendarEvent struct {
+	UUID         uuid.UUID
+	ResortName   string
+	ResortURL    string
+	ForecastDate time.Time
+	SnowAmount   float64
+	Sequence     int32
+	// Cancelled is set once the forecast no longer meets the user's alert.
+	Cancelled bool
+	UpdatedAt time.Time
+}
+
+// CreateCalendarFeed gives the user with the given email a calendar feed, protected by the token hashing
+// to tokenHash. A user has at most one feed, so this replaces the token of an existing one.
+func (s *Store) CreateCalendarFeed(ctx context.Context, email, tokenHash string) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		_, err = q.UpsertCalendarFeed(ctx, dbgen.UpsertCalendarFeedParams{
+			UserUuid:  user.Uuid,
+			TokenHash: tokenHash,
+		})
+		if err != nil {
+			return fmt.Errorf("error saving calendar feed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteCalendarFeed removes the calendar feed whose token hashes to tokenHash, so its URL stops working.
+func (s *Store) DeleteCalendarFeed(ctx context.Context, tokenHash string) error {
+	deleted, err := s.queries.DeleteCalendarFeedByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("error deleting calendar feed: %w", err)
+	}
+	if deleted == 0 {
+		return ErrCalendarNotFound
+	}
+	return nil
+}
+
+// GetCalendarFeed returns the feed whose token hashes to tokenHash, with its events forecast for since or
+// later.
+func (s *Store) GetCalendarFeed(ctx context.Context, tokenHash string, since time.Time) (CalendarFeed, error) {
+	user, err := s.queries.GetCalendarFeedUser(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return CalendarFeed{}, ErrCalendarNotFound
+		}
+		return CalendarFeed{}, fmt.Errorf("error getting calendar feed: %w", err)
+	}
+
+	rows, err := s.queries.ListCalendarEventsForUser(ctx, dbgen.ListCalendarEventsForUserParams{
+		UserUuid:     user.Uuid,
+		ForecastDate: since,
+	})
+	if err != nil {
+		return CalendarFeed{}, fmt.Errorf("error listing calendar events: %w", err)
+	}
+
+	feed := CalendarFeed{
+		UserUUID: user.Uuid,
+		Units:    units.System(user.Units),
+		Locale:   user.Locale,
+		Events:   make([]CalendarEvent, 0, len(rows)),
+	}
+	for _, row := range rows {
+		feed.Events = append(feed.Events, CalendarEvent{
+			UUID:         row.Uuid,
+			ResortName:   row.ResortName,
+			ResortURL:    ResortURL(row.UrlHost, row.UrlPathname),
+			ForecastDate: row.ForecastDate,
+			SnowAmount:   row.SnowAmount,
+			Sequence:     row.Sequence,
+			Cancelled:    row.CancelledAt.Valid,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+
+	return feed, nil
+}
+
+// SyncCalendarEvents brings the calendar events for a resort in line with its latest forecast, which holds
+// the days with snow from the date from onwards. Days meeting an alert get an event, or have theirs updated;
+// events on or after from that no forecast day meets any more are cancelled.
+func (s *Store) SyncCalendarEvents(
+	ctx context.Context,
+	resortUUID uuid.UUID,
+	from time.Time,
+	forecasts []CalendarForecast,
+) (CalendarSync, error) {
+	var sync CalendarSync
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		for _, forecast := range forecasts {
+			matched, err := q.UpsertCalendarEvents(ctx, dbgen.UpsertCalendarEventsParams{
+				ForecastDate: forecast.Date,
+				SnowAmount:   forecast.SnowAmount,
+				ResortUuid:   resortUUID,
+				DaysAhead:    forecast.DaysAhead,
+			})
+			if err != nil {
+				return fmt.Errorf("error updating calendar events for resort %s: %w", resortUUID, err)
+			}
+			sync.Matched += matched
+		}
+
+		cancelled, err := q.CancelUncheckedCalendarEvents(ctx, dbgen.CancelUncheckedCalendarEventsParams{
+			ResortUuid:   resortUUID,
+			ForecastDate: from,
+		})
+		if err != nil {
+			return fmt.Errorf("error cancelling calendar events for resort %s: %w", resortUUID, err)
+		}
+		sync.Cancelled = cancelled
+
+		return nil
+	})
+	if err != nil {
+		return CalendarSync{}, err
+	}
+
+	return sync, nil
+}
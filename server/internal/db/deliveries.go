@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// Notification channels.
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Delivery statuses, in the order a message normally moves through them.
+const (
+	DeliveryStatusQueued      = "queued"
+	DeliveryStatusSent        = "sent"
+	DeliveryStatusDelivered   = "delivered"
+	DeliveryStatusFailed      = "failed"
+	DeliveryStatusUndelivered = "undelivered"
+)
+
+// deliveryStatusPredecessors lists the statuses a delivery may move to each status from.
+// Provider callbacks can arrive out of order, so a late "sent" must not overwrite "delivered".
+var deliveryStatusPredecessors = map[string][]string{
+	DeliveryStatusQueued:      {DeliveryStatusQueued},
+	DeliveryStatusSent:        {DeliveryStatusQueued, DeliveryStatusSent},
+	DeliveryStatusDelivered:   {DeliveryStatusQueued, DeliveryStatusSent, DeliveryStatusDelivered},
+	DeliveryStatusFailed:      {DeliveryStatusQueued, DeliveryStatusSent, DeliveryStatusFailed},
+	DeliveryStatusUndelivered: {DeliveryStatusQueued, DeliveryStatusSent, DeliveryStatusUndelivered},
+}
+
+// ErrUserNotFound is returned when no user matches the given email.
+var ErrUserNotFound = errors.New("user not found")
+
+// Delivery describes one outbound message handed to a notification provider.
+type Delivery struct {
+	UserUUID          uuid.UUID
+	ResortUUID        uuid.UUID
+	Channel           string
+	Provider          string
+	ProviderMessageID string
+	Recipient         string
+	Status            string
+	ErrorCode         string
+	ErrorMessage      string
+}
+
+// RecordDelivery persists an outbound message and its initial status.
+func (s *Store) RecordDelivery(ctx context.Context, delivery Delivery) error {
+	_, err := s.queries.InsertDelivery(ctx, dbgen.InsertDeliveryParams{
+		UserUuid:          uuid.NullUUID{UUID: delivery.UserUUID, Valid: delivery.UserUUID != uuid.Nil},
+		ResortUuid:        uuid.NullUUID{UUID: delivery.ResortUUID, Valid: delivery.ResortUUID != uuid.Nil},
+		Channel:           delivery.Channel,
+		Provider:          delivery.Provider,
+		ProviderMessageID: sql.NullString{String: delivery.ProviderMessageID, Valid: delivery.ProviderMessageID != ""},
+		Recipient:         delivery.Recipient,
+		Status:            delivery.Status,
+		ErrorCode:         sql.NullString{String: delivery.ErrorCode, Valid: delivery.ErrorCode != ""},
+		ErrorMessage:      sql.NullString{String: delivery.ErrorMessage, Valid: delivery.ErrorMessage != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("error recording delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateDeliveryStatus applies a provider status callback to a recorded delivery. It reports whether a
+// delivery was updated; callbacks for unknown messages or that would move a delivery backwards are ignored.
+func (s *Store) UpdateDeliveryStatus(
+	ctx context.Context,
+	provider, providerMessageID, status, errorCode, errorMessage string,
+) (bool, error) {
+	previous, ok := deliveryStatusPredecessors[status]
+	if !ok {
+		return false, fmt.Errorf("unknown delivery status %q", status)
+	}
+
+	rows, err := s.queries.UpdateDeliveryStatus(ctx, dbgen.UpdateDeliveryStatusParams{
+		Status:            status,
+		ErrorCode:         sql.NullString{String: errorCode, Valid: errorCode != ""},
+		ErrorMessage:      sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+		Provider:          provider,
+		ProviderMessageID: sql.NullString{String: providerMessageID, Valid: true},
+		PreviousStatuses:  previous,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error updating delivery status: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetRecentDeliveriesByEmail returns the most recent deliveries for a user, newest first.
+func (s *Store) GetRecentDeliveriesByEmail(
+	ctx context.Context,
+	email string,
+	limit int32,
+) ([]dbgen.NotificationDelivery, error) {
+	deliveries, err := s.queries.ListUserDeliveriesByEmail(ctx, dbgen.ListUserDeliveriesByEmailParams{
+		Email: email,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting user deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// NotificationLimits are a user's overrides of the default notification limits. A nil limit means the
+// default applies.
+type NotificationLimits struct {
+	MaxPerDay         *int32
+	MinSpacingMinutes *int32
+}
+
+// NotificationBudget is a user's notification limits together with the messages sent to them recently.
+type NotificationBudget struct {
+	Limits NotificationLimits
+	// SentCount is the number of messages sent since the time the budget was requested for.
+	SentCount    int
+	OldestSentAt time.Time
+	LastSentAt   time.Time
+}
+
+// GetNotificationBudget returns a user's notification limits and the messages that count against them,
+// i.e. deliveries on the channel since the given time that have not failed. Limits apply to each channel
+// separately, so an SMS doesn't hold back the push notification for the same alert.
+func (s *Store) GetNotificationBudget(
+	ctx context.Context,
+	userUUID uuid.UUID,
+	channel string,
+	since time.Time,
+) (NotificationBudget, error) {
+	row, err := s.queries.GetUserNotificationBudget(ctx, dbgen.GetUserNotificationBudgetParams{
+		Channel:  channel,
+		Since:    since,
+		UserUuid: userUUID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NotificationBudget{}, ErrUserNotFound
+		}
+		return NotificationBudget{}, fmt.Errorf("error getting notification budget: %w", err)
+	}
+
+	budget := NotificationBudget{
+		SentCount:    int(row.SentCount),
+		OldestSentAt: row.OldestSentAt.Time,
+		LastSentAt:   row.LastSentAt.Time,
+	}
+	if row.MaxAlertsPerDay.Valid {
+		budget.Limits.MaxPerDay = &row.MaxAlertsPerDay.Int32
+	}
+	if row.MinAlertSpacingMinutes.Valid {
+		budget.Limits.MinSpacingMinutes = &row.MinAlertSpacingMinutes.Int32
+	}
+
+	return budget, nil
+}
+
+// Preferences are the notification settings a user controls.
+type Preferences struct {
+	Limits NotificationLimits
+	// Units is the measurement system alerts are presented in. Snow amounts are stored in inches regardless.
+	Units units.System
+	// Locale is the BCP 47 tag alerts are written and formatted for.
+	Locale string
+	// NtfyTopicURL is the ntfy topic push alerts are published to. Empty turns push alerts off.
+	NtfyTopicURL string
+}
+
+// SetPreferences replaces a user's notification preferences. Push alerts still queued for a topic the user
+// has dropped are cancelled.
+func (s *Store) SetPreferences(ctx context.Context, email string, prefs Preferences) error {
+	params := dbgen.SetUserPreferencesParams{
+		Email:        email,
+		Units:        string(prefs.Units),
+		Locale:       prefs.Locale,
+		NtfyTopicUrl: sql.NullString{String: prefs.NtfyTopicURL, Valid: prefs.NtfyTopicURL != ""},
+	}
+	if prefs.Limits.MaxPerDay != nil {
+		params.MaxAlertsPerDay = sql.NullInt32{Int32: *prefs.Limits.MaxPerDay, Valid: true}
+	}
+	if prefs.Limits.MinSpacingMinutes != nil {
+		params.MinAlertSpacingMinutes = sql.NullInt32{Int32: *prefs.Limits.MinSpacingMinutes, Valid: true}
+	}
+
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		rows, err := q.SetUserPreferences(ctx, params)
+		if err != nil {
+			return fmt.Errorf("error setting preferences: %w", err)
+		}
+		if rows == 0 {
+			return ErrUserNotFound
+		}
+
+		if user.NtfyTopicUrl.Valid && user.NtfyTopicUrl.String != prefs.NtfyTopicURL {
+			_, err := q.CancelPendingOutboxMessages(ctx, dbgen.CancelPendingOutboxMessagesParams{
+				Channel:   ChannelPush,
+				Recipient: user.NtfyTopicUrl.String,
+			})
+			if err != nil {
+				return fmt.Errorf("error cancelling push alerts for the old topic: %w", err)
+			}
+		}
+		return nil
+	})
+}
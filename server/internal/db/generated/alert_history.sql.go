@@ -33,13 +33,52 @@ func (q *Queries) CheckAlertSent(ctx context.Context, arg CheckAlertSentParams)
 	return alert_sent, err
 }
 
+const checkBluebirdAlertSent = `-- name: CheckBluebirdAlertSent :one
+SELECT EXISTS(SELECT 1
+              FROM alert_history ah
+              WHERE ah.user_uuid = $1
+                AND ah.resort_uuid = $2
+                AND ah.forecast_date = $3
+                AND ah.alert_kind = 'bluebird'
+              UNION ALL
+              SELECT 1
+              FROM notification_outbox o
+              WHERE o.user_uuid = $1
+                AND o.resort_uuid = $2
+                AND o.forecast_date = $3
+                AND o.alert_kind = 'bluebird') as alert_sent
+`
+
+type CheckBluebirdAlertSentParams struct {
+	UserUuid     uuid.NullUUID `json:"user_uuid"`
+	ResortUuid   uuid.NullUUID `json:"resort_uuid"`
+	ForecastDate time.Time     `json:"forecast_date"`
+}
+
+func (q *Queries) CheckBluebirdAlertSent(ctx context.Context, arg CheckBluebirdAlertSentParams) (bool, error) {
+	row := q.queryRow(ctx, q.checkBluebirdAlertSentStmt, checkBluebirdAlertSent, arg.UserUuid, arg.ResortUuid, arg.ForecastDate)
+	var alert_sent bool
+	err := row.Scan(&alert_sent)
+	return alert_sent, err
+}
+
 const getLastAlertSnowAmount = `-- name: GetLastAlertSnowAmount :one
 SELECT snow_amount
-FROM alert_history
-WHERE user_uuid = $1
-  AND resort_uuid = $2
-  AND forecast_date = $3
-ORDER BY sent_at DESC LIMIT 1
+FROM (SELECT ah.snow_amount, ah.sent_at AS alerted_at
+      FROM alert_history ah
+      WHERE ah.user_uuid = $1
+        AND ah.resort_uuid = $2
+        AND ah.forecast_date = $3
+        AND ah.alert_kind <> 'bluebird'
+      UNION ALL
+      SELECT o.snow_amount, o.created_at AS alerted_at
+      FROM notification_outbox o
+      WHERE o.user_uuid = $1
+        AND o.resort_uuid = $2
+        AND o.forecast_date = $3
+        AND o.alert_kind <> 'bluebird'
+        AND o.status <> 'sent') alerts
+ORDER BY alerted_at DESC LIMIT 1
 `
 
 type GetLastAlertSnowAmountParams struct {
@@ -56,8 +95,8 @@ func (q *Queries) GetLastAlertSnowAmount(ctx context.Context, arg GetLastAlertSn
 }
 
 const insertAlertHistory = `-- name: InsertAlertHistory :exec
-INSERT INTO alert_history (user_uuid, resort_uuid, forecast_date, snow_amount, sent_at)
-VALUES ($1, $2, $3, $4, NOW())
+INSERT INTO alert_history (user_uuid, resort_uuid, forecast_date, snow_amount, alert_kind, sent_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
 `
 
 type InsertAlertHistoryParams struct {
@@ -65,6 +104,7 @@ type InsertAlertHistoryParams struct {
 	ResortUuid   uuid.NullUUID `json:"resort_uuid"`
 	ForecastDate time.Time     `json:"forecast_date"`
 	SnowAmount   float64       `json:"snow_amount"`
+	AlertKind    string        `json:"alert_kind"`
 }
 
 func (q *Queries) InsertAlertHistory(ctx context.Context, arg InsertAlertHistoryParams) error {
@@ -73,6 +113,7 @@ func (q *Queries) InsertAlertHistory(ctx context.Context, arg InsertAlertHistory
 		arg.ResortUuid,
 		arg.ForecastDate,
 		arg.SnowAmount,
+		arg.AlertKind,
 	)
 	return err
 }
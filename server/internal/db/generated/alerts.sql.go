@@ -12,6 +12,19 @@ import (
 	"github.com/google/uuid"
 )
 
+const countActiveAlerts = `-- name: CountActiveAlerts :one
+SELECT COUNT(*)
+FROM user_alerts
+WHERE active = true
+`
+
+func (q *Queries) CountActiveAlerts(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countActiveAlertsStmt, countActiveAlerts)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUserAlert = `-- name: CreateUserAlert :one
 INSERT INTO user_alerts (user_uuid, resort_uuid, min_snow_amount, notification_days)
 VALUES ($1, $2, $3, $4) RETURNING id, user_uuid, resort_uuid, min_snow_amount, notification_days, active, created_at
@@ -44,6 +57,19 @@ func (q *Queries) CreateUserAlert(ctx context.Context, arg CreateUserAlertParams
 	return i, err
 }
 
+const deleteAllAlertsForUser = `-- name: DeleteAllAlertsForUser :execrows
+DELETE FROM user_alerts
+WHERE user_uuid = $1
+`
+
+func (q *Queries) DeleteAllAlertsForUser(ctx context.Context, userUuid uuid.NullUUID) (int64, error) {
+	result, err := q.exec(ctx, q.deleteAllAlertsForUserStmt, deleteAllAlertsForUser, userUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteAllUserAlerts = `-- name: DeleteAllUserAlerts :exec
 DELETE FROM user_alerts
 WHERE user_uuid = (SELECT uuid FROM users WHERE email = $1)
@@ -70,11 +96,32 @@ func (q *Queries) DeleteUserAlert(ctx context.Context, arg DeleteUserAlertParams
 	return err
 }
 
+const deleteUserAlertForUser = `-- name: DeleteUserAlertForUser :execrows
+DELETE FROM user_alerts
+WHERE user_uuid = $1
+  AND resort_uuid = $2
+`
+
+type DeleteUserAlertForUserParams struct {
+	UserUuid   uuid.NullUUID `json:"user_uuid"`
+	ResortUuid uuid.NullUUID `json:"resort_uuid"`
+}
+
+func (q *Queries) DeleteUserAlertForUser(ctx context.Context, arg DeleteUserAlertForUserParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteUserAlertForUserStmt, deleteUserAlertForUser, arg.UserUuid, arg.ResortUuid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getResortAlerts = `-- name: GetResortAlerts :many
-SELECT id, user_uuid, resort_uuid, min_snow_amount, notification_days, active, created_at
-FROM user_alerts
-WHERE resort_uuid = $1
-  and active = true
+SELECT ua.id, ua.user_uuid, ua.resort_uuid, ua.min_snow_amount, ua.notification_days, ua.active, ua.created_at
+FROM user_alerts ua
+         JOIN users u ON ua.user_uuid = u.uuid
+WHERE ua.resort_uuid = $1
+  AND ua.active = true
+  AND (u.alerts_paused_until IS NULL OR u.alerts_paused_until <= NOW())
 `
 
 func (q *Queries) GetResortAlerts(ctx context.Context, resortUuid uuid.NullUUID) ([]UserAlert, error) {
@@ -24,27 +24,120 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.cancelPendingOutboxMessagesStmt, err = db.PrepareContext(ctx, cancelPendingOutboxMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelPendingOutboxMessages: %w", err)
+	}
+	if q.cancelPendingOutboxMessagesForUserStmt, err = db.PrepareContext(ctx, cancelPendingOutboxMessagesForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelPendingOutboxMessagesForUser: %w", err)
+	}
+	if q.cancelUncheckedCalendarEventsStmt, err = db.PrepareContext(ctx, cancelUncheckedCalendarEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelUncheckedCalendarEvents: %w", err)
+	}
 	if q.checkAlertSentStmt, err = db.PrepareContext(ctx, checkAlertSent); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckAlertSent: %w", err)
 	}
+	if q.checkBluebirdAlertSentStmt, err = db.PrepareContext(ctx, checkBluebirdAlertSent); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckBluebirdAlertSent: %w", err)
+	}
+	if q.claimOutboxMessagesStmt, err = db.PrepareContext(ctx, claimOutboxMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimOutboxMessages: %w", err)
+	}
 	if q.clearResortsStmt, err = db.PrepareContext(ctx, clearResorts); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearResorts: %w", err)
 	}
+	if q.clearUserSMSOptOutStmt, err = db.PrepareContext(ctx, clearUserSMSOptOut); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearUserSMSOptOut: %w", err)
+	}
+	if q.countActiveAlertsStmt, err = db.PrepareContext(ctx, countActiveAlerts); err != nil {
+		return nil, fmt.Errorf("error preparing query CountActiveAlerts: %w", err)
+	}
+	if q.countAlertDestinationsStmt, err = db.PrepareContext(ctx, countAlertDestinations); err != nil {
+		return nil, fmt.Errorf("error preparing query CountAlertDestinations: %w", err)
+	}
+	if q.countOtherPushSubscriptionsForUserStmt, err = db.PrepareContext(ctx, countOtherPushSubscriptionsForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CountOtherPushSubscriptionsForUser: %w", err)
+	}
+	if q.countResortSubscribersStmt, err = db.PrepareContext(ctx, countResortSubscribers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountResortSubscribers: %w", err)
+	}
+	if q.countUsersStmt, err = db.PrepareContext(ctx, countUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsers: %w", err)
+	}
+	if q.countWebhooksForUserStmt, err = db.PrepareContext(ctx, countWebhooksForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CountWebhooksForUser: %w", err)
+	}
+	if q.createAlertDestinationStmt, err = db.PrepareContext(ctx, createAlertDestination); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAlertDestination: %w", err)
+	}
+	if q.createRateLimitBucketStmt, err = db.PrepareContext(ctx, createRateLimitBucket); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRateLimitBucket: %w", err)
+	}
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
 	if q.createUserAlertStmt, err = db.PrepareContext(ctx, createUserAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUserAlert: %w", err)
 	}
+	if q.createWebhookStmt, err = db.PrepareContext(ctx, createWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhook: %w", err)
+	}
+	if q.deferOutboxMessagesStmt, err = db.PrepareContext(ctx, deferOutboxMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query DeferOutboxMessages: %w", err)
+	}
+	if q.deleteAlertDestinationStmt, err = db.PrepareContext(ctx, deleteAlertDestination); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAlertDestination: %w", err)
+	}
+	if q.deleteAllAlertsForUserStmt, err = db.PrepareContext(ctx, deleteAllAlertsForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAllAlertsForUser: %w", err)
+	}
 	if q.deleteAllUserAlertsStmt, err = db.PrepareContext(ctx, deleteAllUserAlerts); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAllUserAlerts: %w", err)
 	}
+	if q.deleteCalendarFeedByTokenHashStmt, err = db.PrepareContext(ctx, deleteCalendarFeedByTokenHash); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCalendarFeedByTokenHash: %w", err)
+	}
+	if q.deleteExpiredRateLimitBucketsStmt, err = db.PrepareContext(ctx, deleteExpiredRateLimitBuckets); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredRateLimitBuckets: %w", err)
+	}
+	if q.deletePushSubscriptionStmt, err = db.PrepareContext(ctx, deletePushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePushSubscription: %w", err)
+	}
+	if q.deletePushSubscriptionForEmailStmt, err = db.PrepareContext(ctx, deletePushSubscriptionForEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePushSubscriptionForEmail: %w", err)
+	}
+	if q.deleteResortForecastsBeforeStmt, err = db.PrepareContext(ctx, deleteResortForecastsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteResortForecastsBefore: %w", err)
+	}
 	if q.deleteUserAlertStmt, err = db.PrepareContext(ctx, deleteUserAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUserAlert: %w", err)
 	}
+	if q.deleteUserAlertForUserStmt, err = db.PrepareContext(ctx, deleteUserAlertForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserAlertForUser: %w", err)
+	}
+	if q.deleteWebhookStmt, err = db.PrepareContext(ctx, deleteWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhook: %w", err)
+	}
+	if q.enqueueOutboxMessageStmt, err = db.PrepareContext(ctx, enqueueOutboxMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query EnqueueOutboxMessage: %w", err)
+	}
+	if q.failOutboxMessageStmt, err = db.PrepareContext(ctx, failOutboxMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query FailOutboxMessage: %w", err)
+	}
+	if q.getAlertDestinationStmt, err = db.PrepareContext(ctx, getAlertDestination); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlertDestination: %w", err)
+	}
+	if q.getCalendarFeedUserStmt, err = db.PrepareContext(ctx, getCalendarFeedUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCalendarFeedUser: %w", err)
+	}
 	if q.getLastAlertSnowAmountStmt, err = db.PrepareContext(ctx, getLastAlertSnowAmount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLastAlertSnowAmount: %w", err)
 	}
+	if q.getPushSubscriptionStmt, err = db.PrepareContext(ctx, getPushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPushSubscription: %w", err)
+	}
+	if q.getRateLimitBucketForUpdateStmt, err = db.PrepareContext(ctx, getRateLimitBucketForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRateLimitBucketForUpdate: %w", err)
+	}
 	if q.getResortAlertsStmt, err = db.PrepareContext(ctx, getResortAlerts); err != nil {
 		return nil, fmt.Errorf("error preparing query GetResortAlerts: %w", err)
 	}
@@ -63,36 +156,187 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getUserByUUIDStmt, err = db.PrepareContext(ctx, getUserByUUID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByUUID: %w", err)
 	}
+	if q.getUserNotificationBudgetStmt, err = db.PrepareContext(ctx, getUserNotificationBudget); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserNotificationBudget: %w", err)
+	}
+	if q.getWebhookStmt, err = db.PrepareContext(ctx, getWebhook); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhook: %w", err)
+	}
+	if q.getWebhookForEmailStmt, err = db.PrepareContext(ctx, getWebhookForEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhookForEmail: %w", err)
+	}
 	if q.insertAlertHistoryStmt, err = db.PrepareContext(ctx, insertAlertHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertAlertHistory: %w", err)
 	}
+	if q.insertDeliveryStmt, err = db.PrepareContext(ctx, insertDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertDelivery: %w", err)
+	}
 	if q.insertResortStmt, err = db.PrepareContext(ctx, insertResort); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertResort: %w", err)
 	}
+	if q.insertUnsubscribeEventStmt, err = db.PrepareContext(ctx, insertUnsubscribeEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertUnsubscribeEvent: %w", err)
+	}
 	if q.listActiveAlertsStmt, err = db.PrepareContext(ctx, listActiveAlerts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListActiveAlerts: %w", err)
 	}
+	if q.listAlertDestinationsByEmailStmt, err = db.PrepareContext(ctx, listAlertDestinationsByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAlertDestinationsByEmail: %w", err)
+	}
+	if q.listAlertDestinationsForAlertStmt, err = db.PrepareContext(ctx, listAlertDestinationsForAlert); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAlertDestinationsForAlert: %w", err)
+	}
+	if q.listCalendarEventsForUserStmt, err = db.PrepareContext(ctx, listCalendarEventsForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCalendarEventsForUser: %w", err)
+	}
+	if q.listForecastFeedStmt, err = db.PrepareContext(ctx, listForecastFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query ListForecastFeed: %w", err)
+	}
+	if q.listOutboxMessagesByStatusStmt, err = db.PrepareContext(ctx, listOutboxMessagesByStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOutboxMessagesByStatus: %w", err)
+	}
+	if q.listPushSubscriptionsForUserStmt, err = db.PrepareContext(ctx, listPushSubscriptionsForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPushSubscriptionsForUser: %w", err)
+	}
+	if q.listResortForecastsStmt, err = db.PrepareContext(ctx, listResortForecasts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListResortForecasts: %w", err)
+	}
 	if q.listResortsStmt, err = db.PrepareContext(ctx, listResorts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListResorts: %w", err)
 	}
+	if q.listUserDeliveriesByEmailStmt, err = db.PrepareContext(ctx, listUserDeliveriesByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserDeliveriesByEmail: %w", err)
+	}
+	if q.listWebhooksByEmailStmt, err = db.PrepareContext(ctx, listWebhooksByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhooksByEmail: %w", err)
+	}
+	if q.listWebhooksForUserStmt, err = db.PrepareContext(ctx, listWebhooksForUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhooksForUser: %w", err)
+	}
+	if q.markOutboxMessageSentStmt, err = db.PrepareContext(ctx, markOutboxMessageSent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxMessageSent: %w", err)
+	}
+	if q.pauseUserAlertsStmt, err = db.PrepareContext(ctx, pauseUserAlerts); err != nil {
+		return nil, fmt.Errorf("error preparing query PauseUserAlerts: %w", err)
+	}
+	if q.requeueDeadOutboxMessagesStmt, err = db.PrepareContext(ctx, requeueDeadOutboxMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query RequeueDeadOutboxMessages: %w", err)
+	}
+	if q.requeueOutboxMessageStmt, err = db.PrepareContext(ctx, requeueOutboxMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query RequeueOutboxMessage: %w", err)
+	}
+	if q.setUserPreferencesStmt, err = db.PrepareContext(ctx, setUserPreferences); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserPreferences: %w", err)
+	}
+	if q.setUserSMSOptOutStmt, err = db.PrepareContext(ctx, setUserSMSOptOut); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserSMSOptOut: %w", err)
+	}
+	if q.updateDeliveryStatusStmt, err = db.PrepareContext(ctx, updateDeliveryStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateDeliveryStatus: %w", err)
+	}
+	if q.updateRateLimitBucketStmt, err = db.PrepareContext(ctx, updateRateLimitBucket); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateRateLimitBucket: %w", err)
+	}
 	if q.updateUserAlertStmt, err = db.PrepareContext(ctx, updateUserAlert); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUserAlert: %w", err)
 	}
+	if q.upsertCalendarEventsStmt, err = db.PrepareContext(ctx, upsertCalendarEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertCalendarEvents: %w", err)
+	}
+	if q.upsertCalendarFeedStmt, err = db.PrepareContext(ctx, upsertCalendarFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertCalendarFeed: %w", err)
+	}
+	if q.upsertPushSubscriptionStmt, err = db.PrepareContext(ctx, upsertPushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPushSubscription: %w", err)
+	}
+	if q.upsertResortForecastStmt, err = db.PrepareContext(ctx, upsertResortForecast); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertResortForecast: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.cancelPendingOutboxMessagesStmt != nil {
+		if cerr := q.cancelPendingOutboxMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelPendingOutboxMessagesStmt: %w", cerr)
+		}
+	}
+	if q.cancelPendingOutboxMessagesForUserStmt != nil {
+		if cerr := q.cancelPendingOutboxMessagesForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelPendingOutboxMessagesForUserStmt: %w", cerr)
+		}
+	}
+	if q.cancelUncheckedCalendarEventsStmt != nil {
+		if cerr := q.cancelUncheckedCalendarEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelUncheckedCalendarEventsStmt: %w", cerr)
+		}
+	}
 	if q.checkAlertSentStmt != nil {
 		if cerr := q.checkAlertSentStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing checkAlertSentStmt: %w", cerr)
 		}
 	}
+	if q.checkBluebirdAlertSentStmt != nil {
+		if cerr := q.checkBluebirdAlertSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkBluebirdAlertSentStmt: %w", cerr)
+		}
+	}
+	if q.claimOutboxMessagesStmt != nil {
+		if cerr := q.claimOutboxMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimOutboxMessagesStmt: %w", cerr)
+		}
+	}
 	if q.clearResortsStmt != nil {
 		if cerr := q.clearResortsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing clearResortsStmt: %w", cerr)
 		}
 	}
+	if q.clearUserSMSOptOutStmt != nil {
+		if cerr := q.clearUserSMSOptOutStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearUserSMSOptOutStmt: %w", cerr)
+		}
+	}
+	if q.countActiveAlertsStmt != nil {
+		if cerr := q.countActiveAlertsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countActiveAlertsStmt: %w", cerr)
+		}
+	}
+	if q.countAlertDestinationsStmt != nil {
+		if cerr := q.countAlertDestinationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countAlertDestinationsStmt: %w", cerr)
+		}
+	}
+	if q.countOtherPushSubscriptionsForUserStmt != nil {
+		if cerr := q.countOtherPushSubscriptionsForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countOtherPushSubscriptionsForUserStmt: %w", cerr)
+		}
+	}
+	if q.countResortSubscribersStmt != nil {
+		if cerr := q.countResortSubscribersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countResortSubscribersStmt: %w", cerr)
+		}
+	}
+	if q.countUsersStmt != nil {
+		if cerr := q.countUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersStmt: %w", cerr)
+		}
+	}
+	if q.countWebhooksForUserStmt != nil {
+		if cerr := q.countWebhooksForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countWebhooksForUserStmt: %w", cerr)
+		}
+	}
+	if q.createAlertDestinationStmt != nil {
+		if cerr := q.createAlertDestinationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAlertDestinationStmt: %w", cerr)
+		}
+	}
+	if q.createRateLimitBucketStmt != nil {
+		if cerr := q.createRateLimitBucketStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRateLimitBucketStmt: %w", cerr)
+		}
+	}
 	if q.createUserStmt != nil {
 		if cerr := q.createUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
@@ -103,21 +347,106 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createUserAlertStmt: %w", cerr)
 		}
 	}
+	if q.createWebhookStmt != nil {
+		if cerr := q.createWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookStmt: %w", cerr)
+		}
+	}
+	if q.deferOutboxMessagesStmt != nil {
+		if cerr := q.deferOutboxMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deferOutboxMessagesStmt: %w", cerr)
+		}
+	}
+	if q.deleteAlertDestinationStmt != nil {
+		if cerr := q.deleteAlertDestinationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAlertDestinationStmt: %w", cerr)
+		}
+	}
+	if q.deleteAllAlertsForUserStmt != nil {
+		if cerr := q.deleteAllAlertsForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAllAlertsForUserStmt: %w", cerr)
+		}
+	}
 	if q.deleteAllUserAlertsStmt != nil {
 		if cerr := q.deleteAllUserAlertsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAllUserAlertsStmt: %w", cerr)
 		}
 	}
+	if q.deleteCalendarFeedByTokenHashStmt != nil {
+		if cerr := q.deleteCalendarFeedByTokenHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCalendarFeedByTokenHashStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredRateLimitBucketsStmt != nil {
+		if cerr := q.deleteExpiredRateLimitBucketsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredRateLimitBucketsStmt: %w", cerr)
+		}
+	}
+	if q.deletePushSubscriptionStmt != nil {
+		if cerr := q.deletePushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePushSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.deletePushSubscriptionForEmailStmt != nil {
+		if cerr := q.deletePushSubscriptionForEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePushSubscriptionForEmailStmt: %w", cerr)
+		}
+	}
+	if q.deleteResortForecastsBeforeStmt != nil {
+		if cerr := q.deleteResortForecastsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteResortForecastsBeforeStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserAlertStmt != nil {
 		if cerr := q.deleteUserAlertStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserAlertStmt: %w", cerr)
 		}
 	}
+	if q.deleteUserAlertForUserStmt != nil {
+		if cerr := q.deleteUserAlertForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserAlertForUserStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebhookStmt != nil {
+		if cerr := q.deleteWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookStmt: %w", cerr)
+		}
+	}
+	if q.enqueueOutboxMessageStmt != nil {
+		if cerr := q.enqueueOutboxMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enqueueOutboxMessageStmt: %w", cerr)
+		}
+	}
+	if q.failOutboxMessageStmt != nil {
+		if cerr := q.failOutboxMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failOutboxMessageStmt: %w", cerr)
+		}
+	}
+	if q.getAlertDestinationStmt != nil {
+		if cerr := q.getAlertDestinationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlertDestinationStmt: %w", cerr)
+		}
+	}
+	if q.getCalendarFeedUserStmt != nil {
+		if cerr := q.getCalendarFeedUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCalendarFeedUserStmt: %w", cerr)
+		}
+	}
 	if q.getLastAlertSnowAmountStmt != nil {
 		if cerr := q.getLastAlertSnowAmountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getLastAlertSnowAmountStmt: %w", cerr)
 		}
 	}
+	if q.getPushSubscriptionStmt != nil {
+		if cerr := q.getPushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPushSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.getRateLimitBucketForUpdateStmt != nil {
+		if cerr := q.getRateLimitBucketForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRateLimitBucketForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getResortAlertsStmt != nil {
 		if cerr := q.getResortAlertsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getResortAlertsStmt: %w", cerr)
@@ -148,31 +477,166 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserByUUIDStmt: %w", cerr)
 		}
 	}
+	if q.getUserNotificationBudgetStmt != nil {
+		if cerr := q.getUserNotificationBudgetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserNotificationBudgetStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookStmt != nil {
+		if cerr := q.getWebhookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookStmt: %w", cerr)
+		}
+	}
+	if q.getWebhookForEmailStmt != nil {
+		if cerr := q.getWebhookForEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookForEmailStmt: %w", cerr)
+		}
+	}
 	if q.insertAlertHistoryStmt != nil {
 		if cerr := q.insertAlertHistoryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertAlertHistoryStmt: %w", cerr)
 		}
 	}
+	if q.insertDeliveryStmt != nil {
+		if cerr := q.insertDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertDeliveryStmt: %w", cerr)
+		}
+	}
 	if q.insertResortStmt != nil {
 		if cerr := q.insertResortStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertResortStmt: %w", cerr)
 		}
 	}
+	if q.insertUnsubscribeEventStmt != nil {
+		if cerr := q.insertUnsubscribeEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertUnsubscribeEventStmt: %w", cerr)
+		}
+	}
 	if q.listActiveAlertsStmt != nil {
 		if cerr := q.listActiveAlertsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listActiveAlertsStmt: %w", cerr)
 		}
 	}
+	if q.listAlertDestinationsByEmailStmt != nil {
+		if cerr := q.listAlertDestinationsByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAlertDestinationsByEmailStmt: %w", cerr)
+		}
+	}
+	if q.listAlertDestinationsForAlertStmt != nil {
+		if cerr := q.listAlertDestinationsForAlertStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAlertDestinationsForAlertStmt: %w", cerr)
+		}
+	}
+	if q.listCalendarEventsForUserStmt != nil {
+		if cerr := q.listCalendarEventsForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCalendarEventsForUserStmt: %w", cerr)
+		}
+	}
+	if q.listForecastFeedStmt != nil {
+		if cerr := q.listForecastFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listForecastFeedStmt: %w", cerr)
+		}
+	}
+	if q.listOutboxMessagesByStatusStmt != nil {
+		if cerr := q.listOutboxMessagesByStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOutboxMessagesByStatusStmt: %w", cerr)
+		}
+	}
+	if q.listPushSubscriptionsForUserStmt != nil {
+		if cerr := q.listPushSubscriptionsForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPushSubscriptionsForUserStmt: %w", cerr)
+		}
+	}
+	if q.listResortForecastsStmt != nil {
+		if cerr := q.listResortForecastsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listResortForecastsStmt: %w", cerr)
+		}
+	}
 	if q.listResortsStmt != nil {
 		if cerr := q.listResortsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listResortsStmt: %w", cerr)
 		}
 	}
+	if q.listUserDeliveriesByEmailStmt != nil {
+		if cerr := q.listUserDeliveriesByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserDeliveriesByEmailStmt: %w", cerr)
+		}
+	}
+	if q.listWebhooksByEmailStmt != nil {
+		if cerr := q.listWebhooksByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhooksByEmailStmt: %w", cerr)
+		}
+	}
+	if q.listWebhooksForUserStmt != nil {
+		if cerr := q.listWebhooksForUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhooksForUserStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxMessageSentStmt != nil {
+		if cerr := q.markOutboxMessageSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxMessageSentStmt: %w", cerr)
+		}
+	}
+	if q.pauseUserAlertsStmt != nil {
+		if cerr := q.pauseUserAlertsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pauseUserAlertsStmt: %w", cerr)
+		}
+	}
+	if q.requeueDeadOutboxMessagesStmt != nil {
+		if cerr := q.requeueDeadOutboxMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requeueDeadOutboxMessagesStmt: %w", cerr)
+		}
+	}
+	if q.requeueOutboxMessageStmt != nil {
+		if cerr := q.requeueOutboxMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requeueOutboxMessageStmt: %w", cerr)
+		}
+	}
+	if q.setUserPreferencesStmt != nil {
+		if cerr := q.setUserPreferencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserPreferencesStmt: %w", cerr)
+		}
+	}
+	if q.setUserSMSOptOutStmt != nil {
+		if cerr := q.setUserSMSOptOutStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserSMSOptOutStmt: %w", cerr)
+		}
+	}
+	if q.updateDeliveryStatusStmt != nil {
+		if cerr := q.updateDeliveryStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateDeliveryStatusStmt: %w", cerr)
+		}
+	}
+	if q.updateRateLimitBucketStmt != nil {
+		if cerr := q.updateRateLimitBucketStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateRateLimitBucketStmt: %w", cerr)
+		}
+	}
 	if q.updateUserAlertStmt != nil {
 		if cerr := q.updateUserAlertStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateUserAlertStmt: %w", cerr)
 		}
 	}
+	if q.upsertCalendarEventsStmt != nil {
+		if cerr := q.upsertCalendarEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertCalendarEventsStmt: %w", cerr)
+		}
+	}
+	if q.upsertCalendarFeedStmt != nil {
+		if cerr := q.upsertCalendarFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertCalendarFeedStmt: %w", cerr)
+		}
+	}
+	if q.upsertPushSubscriptionStmt != nil {
+		if cerr := q.upsertPushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPushSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.upsertResortForecastStmt != nil {
+		if cerr := q.upsertResortForecastStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertResortForecastStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -210,49 +674,165 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                         DBTX
-	tx                         *sql.Tx
-	checkAlertSentStmt         *sql.Stmt
-	clearResortsStmt           *sql.Stmt
-	createUserStmt             *sql.Stmt
-	createUserAlertStmt        *sql.Stmt
-	deleteAllUserAlertsStmt    *sql.Stmt
-	deleteUserAlertStmt        *sql.Stmt
-	getLastAlertSnowAmountStmt *sql.Stmt
-	getResortAlertsStmt        *sql.Stmt
-	getResortByUUIDStmt        *sql.Stmt
-	getUserAlertStmt           *sql.Stmt
-	getUserAlertsByEmailStmt   *sql.Stmt
-	getUserByEmailStmt         *sql.Stmt
-	getUserByUUIDStmt          *sql.Stmt
-	insertAlertHistoryStmt     *sql.Stmt
-	insertResortStmt           *sql.Stmt
-	listActiveAlertsStmt       *sql.Stmt
-	listResortsStmt            *sql.Stmt
-	updateUserAlertStmt        *sql.Stmt
+	db                                     DBTX
+	tx                                     *sql.Tx
+	cancelPendingOutboxMessagesStmt        *sql.Stmt
+	cancelPendingOutboxMessagesForUserStmt *sql.Stmt
+	cancelUncheckedCalendarEventsStmt      *sql.Stmt
+	checkAlertSentStmt                     *sql.Stmt
+	checkBluebirdAlertSentStmt             *sql.Stmt
+	claimOutboxMessagesStmt                *sql.Stmt
+	clearResortsStmt                       *sql.Stmt
+	clearUserSMSOptOutStmt                 *sql.Stmt
+	countActiveAlertsStmt                  *sql.Stmt
+	countAlertDestinationsStmt             *sql.Stmt
+	countOtherPushSubscriptionsForUserStmt *sql.Stmt
+	countResortSubscribersStmt             *sql.Stmt
+	countUsersStmt                         *sql.Stmt
+	countWebhooksForUserStmt               *sql.Stmt
+	createAlertDestinationStmt             *sql.Stmt
+	createRateLimitBucketStmt              *sql.Stmt
+	createUserStmt                         *sql.Stmt
+	createUserAlertStmt                    *sql.Stmt
+	createWebhookStmt                      *sql.Stmt
+	deferOutboxMessagesStmt                *sql.Stmt
+	deleteAlertDestinationStmt             *sql.Stmt
+	deleteAllAlertsForUserStmt             *sql.Stmt
+	deleteAllUserAlertsStmt                *sql.Stmt
+	deleteCalendarFeedByTokenHashStmt      *sql.Stmt
+	deleteExpiredRateLimitBucketsStmt      *sql.Stmt
+	deletePushSubscriptionStmt             *sql.Stmt
+	deletePushSubscriptionForEmailStmt     *sql.Stmt
+	deleteResortForecastsBeforeStmt        *sql.Stmt
+	deleteUserAlertStmt                    *sql.Stmt
+	deleteUserAlertForUserStmt             *sql.Stmt
+	deleteWebhookStmt                      *sql.Stmt
+	enqueueOutboxMessageStmt               *sql.Stmt
+	failOutboxMessageStmt                  *sql.Stmt
+	getAlertDestinationStmt                *sql.Stmt
+	getCalendarFeedUserStmt                *sql.Stmt
+	getLastAlertSnowAmountStmt             *sql.Stmt
+	getPushSubscriptionStmt                *sql.Stmt
+	getRateLimitBucketForUpdateStmt        *sql.Stmt
+	getResortAlertsStmt                    *sql.Stmt
+	getResortByUUIDStmt                    *sql.Stmt
+	getUserAlertStmt                       *sql.Stmt
+	getUserAlertsByEmailStmt               *sql.Stmt
+	getUserByEmailStmt                     *sql.Stmt
+	getUserByUUIDStmt                      *sql.Stmt
+	getUserNotificationBudgetStmt          *sql.Stmt
+	getWebhookStmt                         *sql.Stmt
+	getWebhookForEmailStmt                 *sql.Stmt
+	insertAlertHistoryStmt                 *sql.Stmt
+	insertDeliveryStmt                     *sql.Stmt
+	insertResortStmt                       *sql.Stmt
+	insertUnsubscribeEventStmt             *sql.Stmt
+	listActiveAlertsStmt                   *sql.Stmt
+	listAlertDestinationsByEmailStmt       *sql.Stmt
+	listAlertDestinationsForAlertStmt      *sql.Stmt
+	listCalendarEventsForUserStmt          *sql.Stmt
+	listForecastFeedStmt                   *sql.Stmt
+	listOutboxMessagesByStatusStmt         *sql.Stmt
+	listPushSubscriptionsForUserStmt       *sql.Stmt
+	listResortForecastsStmt                *sql.Stmt
+	listResortsStmt                        *sql.Stmt
+	listUserDeliveriesByEmailStmt          *sql.Stmt
+	listWebhooksByEmailStmt                *sql.Stmt
+	listWebhooksForUserStmt                *sql.Stmt
+	markOutboxMessageSentStmt              *sql.Stmt
+	pauseUserAlertsStmt                    *sql.Stmt
+	requeueDeadOutboxMessagesStmt          *sql.Stmt
+	requeueOutboxMessageStmt               *sql.Stmt
+	setUserPreferencesStmt                 *sql.Stmt
+	setUserSMSOptOutStmt                   *sql.Stmt
+	updateDeliveryStatusStmt               *sql.Stmt
+	updateRateLimitBucketStmt              *sql.Stmt
+	updateUserAlertStmt                    *sql.Stmt
+	upsertCalendarEventsStmt               *sql.Stmt
+	upsertCalendarFeedStmt                 *sql.Stmt
+	upsertPushSubscriptionStmt             *sql.Stmt
+	upsertResortForecastStmt               *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                         tx,
-		tx:                         tx,
-		checkAlertSentStmt:         q.checkAlertSentStmt,
-		clearResortsStmt:           q.clearResortsStmt,
-		createUserStmt:             q.createUserStmt,
-		createUserAlertStmt:        q.createUserAlertStmt,
-		deleteAllUserAlertsStmt:    q.deleteAllUserAlertsStmt,
-		deleteUserAlertStmt:        q.deleteUserAlertStmt,
-		getLastAlertSnowAmountStmt: q.getLastAlertSnowAmountStmt,
-		getResortAlertsStmt:        q.getResortAlertsStmt,
-		getResortByUUIDStmt:        q.getResortByUUIDStmt,
-		getUserAlertStmt:           q.getUserAlertStmt,
-		getUserAlertsByEmailStmt:   q.getUserAlertsByEmailStmt,
-		getUserByEmailStmt:         q.getUserByEmailStmt,
-		getUserByUUIDStmt:          q.getUserByUUIDStmt,
-		insertAlertHistoryStmt:     q.insertAlertHistoryStmt,
-		insertResortStmt:           q.insertResortStmt,
-		listActiveAlertsStmt:       q.listActiveAlertsStmt,
-		listResortsStmt:            q.listResortsStmt,
-		updateUserAlertStmt:        q.updateUserAlertStmt,
+		db:                                     tx,
+		tx:                                     tx,
+		cancelPendingOutboxMessagesStmt:        q.cancelPendingOutboxMessagesStmt,
+		cancelPendingOutboxMessagesForUserStmt: q.cancelPendingOutboxMessagesForUserStmt,
+		cancelUncheckedCalendarEventsStmt:      q.cancelUncheckedCalendarEventsStmt,
+		checkAlertSentStmt:                     q.checkAlertSentStmt,
+		checkBluebirdAlertSentStmt:             q.checkBluebirdAlertSentStmt,
+		claimOutboxMessagesStmt:                q.claimOutboxMessagesStmt,
+		clearResortsStmt:                       q.clearResortsStmt,
+		clearUserSMSOptOutStmt:                 q.clearUserSMSOptOutStmt,
+		countActiveAlertsStmt:                  q.countActiveAlertsStmt,
+		countAlertDestinationsStmt:             q.countAlertDestinationsStmt,
+		countOtherPushSubscriptionsForUserStmt: q.countOtherPushSubscriptionsForUserStmt,
+		countResortSubscribersStmt:             q.countResortSubscribersStmt,
+		countUsersStmt:                         q.countUsersStmt,
+		countWebhooksForUserStmt:               q.countWebhooksForUserStmt,
+		createAlertDestinationStmt:             q.createAlertDestinationStmt,
+		createRateLimitBucketStmt:              q.createRateLimitBucketStmt,
+		createUserStmt:                         q.createUserStmt,
+		createUserAlertStmt:                    q.createUserAlertStmt,
+		createWebhookStmt:                      q.createWebhookStmt,
+		deferOutboxMessagesStmt:                q.deferOutboxMessagesStmt,
+		deleteAlertDestinationStmt:             q.deleteAlertDestinationStmt,
+		deleteAllAlertsForUserStmt:             q.deleteAllAlertsForUserStmt,
+		deleteAllUserAlertsStmt:                q.deleteAllUserAlertsStmt,
+		deleteCalendarFeedByTokenHashStmt:      q.deleteCalendarFeedByTokenHashStmt,
+		deleteExpiredRateLimitBucketsStmt:      q.deleteExpiredRateLimitBucketsStmt,
+		deletePushSubscriptionStmt:             q.deletePushSubscriptionStmt,
+		deletePushSubscriptionForEmailStmt:     q.deletePushSubscriptionForEmailStmt,
+		deleteResortForecastsBeforeStmt:        q.deleteResortForecastsBeforeStmt,
+		deleteUserAlertStmt:                    q.deleteUserAlertStmt,
+		deleteUserAlertForUserStmt:             q.deleteUserAlertForUserStmt,
+		deleteWebhookStmt:                      q.deleteWebhookStmt,
+		enqueueOutboxMessageStmt:               q.enqueueOutboxMessageStmt,
+		failOutboxMessageStmt:                  q.failOutboxMessageStmt,
+		getAlertDestinationStmt:                q.getAlertDestinationStmt,
+		getCalendarFeedUserStmt:                q.getCalendarFeedUserStmt,
+		getLastAlertSnowAmountStmt:             q.getLastAlertSnowAmountStmt,
+		getPushSubscriptionStmt:                q.getPushSubscriptionStmt,
+		getRateLimitBucketForUpdateStmt:        q.getRateLimitBucketForUpdateStmt,
+		getResortAlertsStmt:                    q.getResortAlertsStmt,
+		getResortByUUIDStmt:                    q.getResortByUUIDStmt,
+		getUserAlertStmt:                       q.getUserAlertStmt,
+		getUserAlertsByEmailStmt:               q.getUserAlertsByEmailStmt,
+		getUserByEmailStmt:                     q.getUserByEmailStmt,
+		getUserByUUIDStmt:                      q.getUserByUUIDStmt,
+		getUserNotificationBudgetStmt:          q.getUserNotificationBudgetStmt,
+		getWebhookStmt:                         q.getWebhookStmt,
+		getWebhookForEmailStmt:                 q.getWebhookForEmailStmt,
+		insertAlertHistoryStmt:                 q.insertAlertHistoryStmt,
+		insertDeliveryStmt:                     q.insertDeliveryStmt,
+		insertResortStmt:                       q.insertResortStmt,
+		insertUnsubscribeEventStmt:             q.insertUnsubscribeEventStmt,
+		listActiveAlertsStmt:                   q.listActiveAlertsStmt,
+		listAlertDestinationsByEmailStmt:       q.listAlertDestinationsByEmailStmt,
+		listAlertDestinationsForAlertStmt:      q.listAlertDestinationsForAlertStmt,
+		listCalendarEventsForUserStmt:          q.listCalendarEventsForUserStmt,
+		listForecastFeedStmt:                   q.listForecastFeedStmt,
+		listOutboxMessagesByStatusStmt:         q.listOutboxMessagesByStatusStmt,
+		listPushSubscriptionsForUserStmt:       q.listPushSubscriptionsForUserStmt,
+		listResortForecastsStmt:                q.listResortForecastsStmt,
+		listResortsStmt:                        q.listResortsStmt,
+		listUserDeliveriesByEmailStmt:          q.listUserDeliveriesByEmailStmt,
+		listWebhooksByEmailStmt:                q.listWebhooksByEmailStmt,
+		listWebhooksForUserStmt:                q.listWebhooksForUserStmt,
+		markOutboxMessageSentStmt:              q.markOutboxMessageSentStmt,
+		pauseUserAlertsStmt:                    q.pauseUserAlertsStmt,
+		requeueDeadOutboxMessagesStmt:          q.requeueDeadOutboxMessagesStmt,
+		requeueOutboxMessageStmt:               q.requeueOutboxMessageStmt,
+		setUserPreferencesStmt:                 q.setUserPreferencesStmt,
+		setUserSMSOptOutStmt:                   q.setUserSMSOptOutStmt,
+		updateDeliveryStatusStmt:               q.updateDeliveryStatusStmt,
+		updateRateLimitBucketStmt:              q.updateRateLimitBucketStmt,
+		updateUserAlertStmt:                    q.updateUserAlertStmt,
+		upsertCalendarEventsStmt:               q.upsertCalendarEventsStmt,
+		upsertCalendarFeedStmt:                 q.upsertCalendarFeedStmt,
+		upsertPushSubscriptionStmt:             q.upsertPushSubscriptionStmt,
+		upsertResortForecastStmt:               q.upsertResortForecastStmt,
 	}
 }
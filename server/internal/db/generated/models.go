@@ -11,6 +11,16 @@ import (
 	"github.com/google/uuid"
 )
 
+type AlertDestination struct {
+	ID         int32     `json:"id"`
+	Uuid       uuid.UUID `json:"uuid"`
+	UserUuid   uuid.UUID `json:"user_uuid"`
+	ResortUuid uuid.UUID `json:"resort_uuid"`
+	Channel    string    `json:"channel"`
+	Url        string    `json:"url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type AlertHistory struct {
 	ID           int32         `json:"id"`
 	UserUuid     uuid.NullUUID `json:"user_uuid"`
@@ -18,6 +28,83 @@ type AlertHistory struct {
 	SentAt       sql.NullTime  `json:"sent_at"`
 	ForecastDate time.Time     `json:"forecast_date"`
 	SnowAmount   float64       `json:"snow_amount"`
+	AlertKind    string        `json:"alert_kind"`
+}
+
+type CalendarEvent struct {
+	ID           int32        `json:"id"`
+	Uuid         uuid.UUID    `json:"uuid"`
+	UserUuid     uuid.UUID    `json:"user_uuid"`
+	ResortUuid   uuid.UUID    `json:"resort_uuid"`
+	ForecastDate time.Time    `json:"forecast_date"`
+	SnowAmount   float64      `json:"snow_amount"`
+	Sequence     int32        `json:"sequence"`
+	CancelledAt  sql.NullTime `json:"cancelled_at"`
+	CheckedAt    time.Time    `json:"checked_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type CalendarFeed struct {
+	ID        int32     `json:"id"`
+	UserUuid  uuid.UUID `json:"user_uuid"`
+	TokenHash string    `json:"token_hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationDelivery struct {
+	ID                int32          `json:"id"`
+	Uuid              uuid.UUID      `json:"uuid"`
+	UserUuid          uuid.NullUUID  `json:"user_uuid"`
+	ResortUuid        uuid.NullUUID  `json:"resort_uuid"`
+	Channel           string         `json:"channel"`
+	Provider          string         `json:"provider"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	Recipient         string         `json:"recipient"`
+	Status            string         `json:"status"`
+	ErrorCode         sql.NullString `json:"error_code"`
+	ErrorMessage      sql.NullString `json:"error_message"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+type NotificationOutbox struct {
+	ID            int32          `json:"id"`
+	Uuid          uuid.UUID      `json:"uuid"`
+	UserUuid      uuid.UUID      `json:"user_uuid"`
+	ResortUuid    uuid.UUID      `json:"resort_uuid"`
+	Channel       string         `json:"channel"`
+	Recipient     string         `json:"recipient"`
+	ForecastDate  time.Time      `json:"forecast_date"`
+	SnowAmount    float64        `json:"snow_amount"`
+	IsUpdate      bool           `json:"is_update"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	MaxAttempts   int32          `json:"max_attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LockedUntil   sql.NullTime   `json:"locked_until"`
+	LastError     sql.NullString `json:"last_error"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	AlertKind     string         `json:"alert_kind"`
+}
+
+type PushSubscription struct {
+	ID        int32     `json:"id"`
+	Uuid      uuid.UUID `json:"uuid"`
+	UserUuid  uuid.UUID `json:"user_uuid"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type Resort struct {
@@ -30,12 +117,43 @@ type Resort struct {
 	Longitude   sql.NullFloat64 `json:"longitude"`
 }
 
+type ResortForecast struct {
+	ID           int32     `json:"id"`
+	ResortUuid   uuid.UUID `json:"resort_uuid"`
+	ForecastDate time.Time `json:"forecast_date"`
+	SnowAmount   float64   `json:"snow_amount"`
+	TempMin      float64   `json:"temp_min"`
+	TempMax      float64   `json:"temp_max"`
+	TempAvg      float64   `json:"temp_avg"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type UnsubscribeEvent struct {
+	ID            int32          `json:"id"`
+	Uuid          uuid.UUID      `json:"uuid"`
+	UserUuid      uuid.UUID      `json:"user_uuid"`
+	ResortUuid    uuid.NullUUID  `json:"resort_uuid"`
+	Channel       sql.NullString `json:"channel"`
+	Method        string         `json:"method"`
+	AlertsRemoved int32          `json:"alerts_removed"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
 type User struct {
-	ID        int32          `json:"id"`
-	Uuid      uuid.UUID      `json:"uuid"`
-	Email     string         `json:"email"`
-	Phone     sql.NullString `json:"phone"`
-	CreatedAt sql.NullTime   `json:"created_at"`
+	ID                     int32          `json:"id"`
+	Uuid                   uuid.UUID      `json:"uuid"`
+	Email                  string         `json:"email"`
+	Phone                  sql.NullString `json:"phone"`
+	CreatedAt              sql.NullTime   `json:"created_at"`
+	SmsOptedOutAt          sql.NullTime   `json:"sms_opted_out_at"`
+	AlertsPausedUntil      sql.NullTime   `json:"alerts_paused_until"`
+	MaxAlertsPerDay        sql.NullInt32  `json:"max_alerts_per_day"`
+	MinAlertSpacingMinutes sql.NullInt32  `json:"min_alert_spacing_minutes"`
+	Units                  string         `json:"units"`
+	Locale                 string         `json:"locale"`
+	NtfyTopicUrl           sql.NullString `json:"ntfy_topic_url"`
 }
 
 type UserAlert struct {
@@ -47,3 +165,12 @@ type UserAlert struct {
 	Active           sql.NullBool  `json:"active"`
 	CreatedAt        sql.NullTime  `json:"created_at"`
 }
+
+type Webhook struct {
+	ID        int32     `json:"id"`
+	Uuid      uuid.UUID `json:"uuid"`
+	UserUuid  uuid.UUID `json:"user_uuid"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -6,29 +6,95 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	CancelPendingOutboxMessages(ctx context.Context, arg CancelPendingOutboxMessagesParams) (int64, error)
+	CancelPendingOutboxMessagesForUser(ctx context.Context, arg CancelPendingOutboxMessagesForUserParams) (int64, error)
+	// Cancels the resort's upcoming events that UpsertCalendarEvents didn't touch in this transaction: the
+	// forecast dropped below the alert's minimum, no snow is forecast any more, or the alert was removed.
+	CancelUncheckedCalendarEvents(ctx context.Context, arg CancelUncheckedCalendarEventsParams) (int64, error)
 	CheckAlertSent(ctx context.Context, arg CheckAlertSentParams) (bool, error)
+	CheckBluebirdAlertSent(ctx context.Context, arg CheckBluebirdAlertSentParams) (bool, error)
+	ClaimOutboxMessages(ctx context.Context, arg ClaimOutboxMessagesParams) ([]ClaimOutboxMessagesRow, error)
 	ClearResorts(ctx context.Context) error
+	ClearUserSMSOptOut(ctx context.Context, phone sql.NullString) error
+	CountActiveAlerts(ctx context.Context) (int64, error)
+	CountAlertDestinations(ctx context.Context, arg CountAlertDestinationsParams) (int64, error)
+	CountOtherPushSubscriptionsForUser(ctx context.Context, arg CountOtherPushSubscriptionsForUserParams) (int64, error)
+	CountResortSubscribers(ctx context.Context, resortUuid uuid.NullUUID) (int64, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CountWebhooksForUser(ctx context.Context, userUuid uuid.UUID) (int64, error)
+	CreateAlertDestination(ctx context.Context, arg CreateAlertDestinationParams) (AlertDestination, error)
+	CreateRateLimitBucket(ctx context.Context, arg CreateRateLimitBucketParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserAlert(ctx context.Context, arg CreateUserAlertParams) (UserAlert, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	DeferOutboxMessages(ctx context.Context, arg DeferOutboxMessagesParams) error
+	DeleteAlertDestination(ctx context.Context, arg DeleteAlertDestinationParams) (string, error)
+	DeleteAllAlertsForUser(ctx context.Context, userUuid uuid.NullUUID) (int64, error)
 	DeleteAllUserAlerts(ctx context.Context, email string) error
+	DeleteCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (int64, error)
+	DeleteExpiredRateLimitBuckets(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeletePushSubscription(ctx context.Context, argUuid uuid.UUID) (int64, error)
+	DeletePushSubscriptionForEmail(ctx context.Context, arg DeletePushSubscriptionForEmailParams) (uuid.UUID, error)
+	DeleteResortForecastsBefore(ctx context.Context, forecastDate time.Time) (int64, error)
 	DeleteUserAlert(ctx context.Context, arg DeleteUserAlertParams) error
+	DeleteUserAlertForUser(ctx context.Context, arg DeleteUserAlertForUserParams) (int64, error)
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	EnqueueOutboxMessage(ctx context.Context, arg EnqueueOutboxMessageParams) error
+	FailOutboxMessage(ctx context.Context, arg FailOutboxMessageParams) (string, error)
+	GetAlertDestination(ctx context.Context, argUuid uuid.UUID) (AlertDestination, error)
+	GetCalendarFeedUser(ctx context.Context, tokenHash string) (GetCalendarFeedUserRow, error)
 	GetLastAlertSnowAmount(ctx context.Context, arg GetLastAlertSnowAmountParams) (float64, error)
+	GetPushSubscription(ctx context.Context, argUuid uuid.UUID) (PushSubscription, error)
+	GetRateLimitBucketForUpdate(ctx context.Context, key string) (RateLimitBucket, error)
 	GetResortAlerts(ctx context.Context, resortUuid uuid.NullUUID) ([]UserAlert, error)
 	GetResortByUUID(ctx context.Context, argUuid uuid.UUID) (Resort, error)
 	GetUserAlert(ctx context.Context, arg GetUserAlertParams) (UserAlert, error)
 	GetUserAlertsByEmail(ctx context.Context, email string) ([]GetUserAlertsByEmailRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByUUID(ctx context.Context, argUuid uuid.UUID) (User, error)
+	GetUserNotificationBudget(ctx context.Context, arg GetUserNotificationBudgetParams) (GetUserNotificationBudgetRow, error)
+	GetWebhook(ctx context.Context, argUuid uuid.UUID) (Webhook, error)
+	GetWebhookForEmail(ctx context.Context, arg GetWebhookForEmailParams) (Webhook, error)
 	InsertAlertHistory(ctx context.Context, arg InsertAlertHistoryParams) error
+	InsertDelivery(ctx context.Context, arg InsertDeliveryParams) (NotificationDelivery, error)
 	InsertResort(ctx context.Context, arg InsertResortParams) (Resort, error)
+	InsertUnsubscribeEvent(ctx context.Context, arg InsertUnsubscribeEventParams) error
 	ListActiveAlerts(ctx context.Context) ([]ListActiveAlertsRow, error)
+	ListAlertDestinationsByEmail(ctx context.Context, email string) ([]AlertDestination, error)
+	ListAlertDestinationsForAlert(ctx context.Context, arg ListAlertDestinationsForAlertParams) ([]AlertDestination, error)
+	ListCalendarEventsForUser(ctx context.Context, arg ListCalendarEventsForUserParams) ([]ListCalendarEventsForUserRow, error)
+	// Lists forecasts of at least min_snow from since onwards, most recently changed first, for one resort or,
+	// when resort_uuid is null, all of them.
+	ListForecastFeed(ctx context.Context, arg ListForecastFeedParams) ([]ListForecastFeedRow, error)
+	ListOutboxMessagesByStatus(ctx context.Context, arg ListOutboxMessagesByStatusParams) ([]NotificationOutbox, error)
+	ListPushSubscriptionsForUser(ctx context.Context, userUuid uuid.UUID) ([]PushSubscription, error)
+	ListResortForecasts(ctx context.Context, arg ListResortForecastsParams) ([]ResortForecast, error)
 	ListResorts(ctx context.Context) ([]Resort, error)
+	ListUserDeliveriesByEmail(ctx context.Context, arg ListUserDeliveriesByEmailParams) ([]NotificationDelivery, error)
+	ListWebhooksByEmail(ctx context.Context, email string) ([]Webhook, error)
+	ListWebhooksForUser(ctx context.Context, userUuid uuid.UUID) ([]Webhook, error)
+	MarkOutboxMessageSent(ctx context.Context, argUuid uuid.UUID) (MarkOutboxMessageSentRow, error)
+	PauseUserAlerts(ctx context.Context, arg PauseUserAlertsParams) error
+	RequeueDeadOutboxMessages(ctx context.Context) (int64, error)
+	RequeueOutboxMessage(ctx context.Context, argUuid uuid.UUID) (int64, error)
+	SetUserPreferences(ctx context.Context, arg SetUserPreferencesParams) (int64, error)
+	SetUserSMSOptOut(ctx context.Context, phone sql.NullString) error
+	UpdateDeliveryStatus(ctx context.Context, arg UpdateDeliveryStatusParams) (int64, error)
+	UpdateRateLimitBucket(ctx context.Context, arg UpdateRateLimitBucketParams) error
 	UpdateUserAlert(ctx context.Context, arg UpdateUserAlertParams) (UserAlert, error)
+	// Records the forecast for every active alert on the resort it meets. checked_at is set to the transaction's
+	// start time, which CancelUncheckedCalendarEvents compares against.
+	UpsertCalendarEvents(ctx context.Context, arg UpsertCalendarEventsParams) (int64, error)
+	UpsertCalendarFeed(ctx context.Context, arg UpsertCalendarFeedParams) (CalendarFeed, error)
+	UpsertPushSubscription(ctx context.Context, arg UpsertPushSubscriptionParams) (PushSubscription, error)
+	UpsertResortForecast(ctx context.Context, arg UpsertResortForecastParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,6 +11,20 @@ import (
 	"github.com/google/uuid"
 )
 
+const countResortSubscribers = `-- name: CountResortSubscribers :one
+SELECT COUNT(DISTINCT user_uuid)
+FROM user_alerts
+WHERE resort_uuid = $1
+  AND active = true
+`
+
+func (q *Queries) CountResortSubscribers(ctx context.Context, resortUuid uuid.NullUUID) (int64, error) {
+	row := q.queryRow(ctx, q.countResortSubscribersStmt, countResortSubscribers, resortUuid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getResortByUUID = `-- name: GetResortByUUID :one
 SELECT id, uuid, name, url_host, url_pathname, latitude, longitude FROM resorts
 WHERE uuid = $1 LIMIT 1
@@ -12,13 +12,37 @@ import (
 	"github.com/google/uuid"
 )
 
+const clearUserSMSOptOut = `-- name: ClearUserSMSOptOut :exec
+UPDATE users
+SET sms_opted_out_at    = NULL,
+    alerts_paused_until = NULL
+WHERE phone = $1
+`
+
+func (q *Queries) ClearUserSMSOptOut(ctx context.Context, phone sql.NullString) error {
+	_, err := q.exec(ctx, q.clearUserSMSOptOutStmt, clearUserSMSOptOut, phone)
+	return err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersStmt, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   email, phone
 ) VALUES (
   $1, $2
 )
-RETURNING id, uuid, email, phone, created_at
+RETURNING id, uuid, email, phone, created_at, sms_opted_out_at, alerts_paused_until, max_alerts_per_day, min_alert_spacing_minutes, units, locale, ntfy_topic_url
 `
 
 type CreateUserParams struct {
@@ -35,12 +59,19 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.SmsOptedOutAt,
+		&i.AlertsPausedUntil,
+		&i.MaxAlertsPerDay,
+		&i.MinAlertSpacingMinutes,
+		&i.Units,
+		&i.Locale,
+		&i.NtfyTopicUrl,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, uuid, email, phone, created_at FROM users
+SELECT id, uuid, email, phone, created_at, sms_opted_out_at, alerts_paused_until, max_alerts_per_day, min_alert_spacing_minutes, units, locale, ntfy_topic_url FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -53,12 +84,19 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.SmsOptedOutAt,
+		&i.AlertsPausedUntil,
+		&i.MaxAlertsPerDay,
+		&i.MinAlertSpacingMinutes,
+		&i.Units,
+		&i.Locale,
+		&i.NtfyTopicUrl,
 	)
 	return i, err
 }
 
 const getUserByUUID = `-- name: GetUserByUUID :one
-SELECT id, uuid, email, phone, created_at FROM users
+SELECT id, uuid, email, phone, created_at, sms_opted_out_at, alerts_paused_until, max_alerts_per_day, min_alert_spacing_minutes, units, locale, ntfy_topic_url FROM users
 WHERE uuid = $1 LIMIT 1
 `
 
@@ -71,6 +109,74 @@ func (q *Queries) GetUserByUUID(ctx context.Context, argUuid uuid.UUID) (User, e
 		&i.Email,
 		&i.Phone,
 		&i.CreatedAt,
+		&i.SmsOptedOutAt,
+		&i.AlertsPausedUntil,
+		&i.MaxAlertsPerDay,
+		&i.MinAlertSpacingMinutes,
+		&i.Units,
+		&i.Locale,
+		&i.NtfyTopicUrl,
 	)
 	return i, err
 }
+
+const pauseUserAlerts = `-- name: PauseUserAlerts :exec
+UPDATE users
+SET alerts_paused_until = $2
+WHERE phone = $1
+`
+
+type PauseUserAlertsParams struct {
+	Phone             sql.NullString `json:"phone"`
+	AlertsPausedUntil sql.NullTime   `json:"alerts_paused_until"`
+}
+
+func (q *Queries) PauseUserAlerts(ctx context.Context, arg PauseUserAlertsParams) error {
+	_, err := q.exec(ctx, q.pauseUserAlertsStmt, pauseUserAlerts, arg.Phone, arg.AlertsPausedUntil)
+	return err
+}
+
+const setUserPreferences = `-- name: SetUserPreferences :execrows
+UPDATE users
+SET max_alerts_per_day        = $2,
+    min_alert_spacing_minutes = $3,
+    units                     = $4,
+    locale                    = $5,
+    ntfy_topic_url            = $6
+WHERE email = $1
+`
+
+type SetUserPreferencesParams struct {
+	Email                  string         `json:"email"`
+	MaxAlertsPerDay        sql.NullInt32  `json:"max_alerts_per_day"`
+	MinAlertSpacingMinutes sql.NullInt32  `json:"min_alert_spacing_minutes"`
+	Units                  string         `json:"units"`
+	Locale                 string         `json:"locale"`
+	NtfyTopicUrl           sql.NullString `json:"ntfy_topic_url"`
+}
+
+func (q *Queries) SetUserPreferences(ctx context.Context, arg SetUserPreferencesParams) (int64, error) {
+	result, err := q.exec(ctx, q.setUserPreferencesStmt, setUserPreferences,
+		arg.Email,
+		arg.MaxAlertsPerDay,
+		arg.MinAlertSpacingMinutes,
+		arg.Units,
+		arg.Locale,
+		arg.NtfyTopicUrl,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserSMSOptOut = `-- name: SetUserSMSOptOut :exec
+UPDATE users
+SET sms_opted_out_at = NOW()
+WHERE phone = $1
+`
+
+func (q *Queries) SetUserSMSOptOut(ctx context.Context, phone sql.NullString) error {
+	_, err := q.exec(ctx, q.setUserSMSOptOutStmt, setUserSMSOptOut, phone)
+	return err
+}
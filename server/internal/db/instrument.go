@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+)
+
+// instrumentedDB times the queries run through it and logs them at debug level. Records are logged in the
+// context the query runs in, so they carry the ID of the request or forecaster run the query is for.
+type instrumentedDB struct {
+	dbgen.DBTX
+}
+
+func (i instrumentedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := i.DBTX.ExecContext(ctx, query, args...)
+	observeQuery(ctx, query, start, err)
+	return result, err
+}
+
+func (i instrumentedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := i.DBTX.QueryContext(ctx, query, args...)
+	observeQuery(ctx, query, start, err)
+	return rows, err
+}
+
+func (i instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := i.DBTX.QueryRowContext(ctx, query, args...)
+	observeQuery(ctx, query, start, row.Err())
+	return row
+}
+
+// observeQuery records a query that started at start, with the error it failed with if it did. Its
+// arguments aren't logged, since they hold personal data.
+func observeQuery(ctx context.Context, query string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	name := queryName(query)
+	metrics.DBQueryDuration.WithLabelValues(name).Observe(elapsed.Seconds())
+
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{
+		"query", name,
+		"duration_ms", float64(elapsed) / float64(time.Millisecond),
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.DebugContext(ctx, "Database query", attrs...)
+}
+
+// queryName returns the name sqlc gives a query in the comment it starts with, or "query" for queries
+// without one.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "query"
+	}
+	if name, _, ok := strings.Cut(rest, " "); ok {
+		return name
+	}
+	return "query"
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// migrationFiles are the goose migrations the service is built with, embedded so that readiness can check
+// the database has been migrated to the latest of them.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// LatestMigration returns the version of the latest migration the service is built with.
+var LatestMigration = sync.OnceValue(func() int64 {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	var latest int64
+	for _, entry := range entries {
+		version, err := migrationVersion(entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		latest = max(latest, version)
+	}
+	return latest
+})
+
+// migrationVersion returns the version of a migration from the number its file name starts with, as goose
+// numbers them.
+func migrationVersion(name string) (int64, error) {
+	prefix, _, ok := strings.Cut(name, "_")
+	if !ok {
+		return 0, fmt.Errorf("migration %q is not named <version>_<description>.sql", name)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("migration %q is not named <version>_<description>.sql", name)
+	}
+	return version, nil
+}
+
+// Ping checks the database can be reached.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version of the latest migration goose has applied to the database, or 0 if
+// it has applied none. The query isn't generated by sqlc, since goose creates its table rather than the
+// migrations.
+func (s *Store) MigrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied",
+	).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,29 @@
+-- migrations/002_normalize_user_phones.sql
+-- +goose Up
+-- Strip formatting characters (spaces, dashes, dots, parentheses) from stored numbers.
+UPDATE users
+SET phone = regexp_replace(phone, '[^0-9+]', '', 'g')
+WHERE phone IS NOT NULL;
+
+UPDATE users
+SET phone = NULL
+WHERE phone = '';
+
+-- Numbers without a country calling code were entered by North American users,
+-- which is the default region used by the phone package.
+UPDATE users
+SET phone = '+1' || phone
+WHERE phone ~ '^[2-9][0-9]{2}[2-9][0-9]{6}$';
+
+UPDATE users
+SET phone = '+' || phone
+WHERE phone ~ '^1[2-9][0-9]{2}[2-9][0-9]{6}$';
+
+-- New rows must be stored in E.164 format. Existing rows that could not be
+-- normalized above are left in place and are re-validated before every send.
+ALTER TABLE users
+    ADD CONSTRAINT users_phone_e164 CHECK (phone ~ '^\+[1-9][0-9]{1,14}$') NOT VALID;
+
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_e164;
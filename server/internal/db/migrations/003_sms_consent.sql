@@ -0,0 +1,15 @@
+-- migrations/003_sms_consent.sql
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN sms_opted_out_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN alerts_paused_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_phone ON users(phone);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_phone;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS alerts_paused_until,
+    DROP COLUMN IF EXISTS sms_opted_out_at;
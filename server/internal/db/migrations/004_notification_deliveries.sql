@@ -0,0 +1,28 @@
+-- migrations/004_notification_deliveries.sql
+-- +goose Up
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID REFERENCES users(uuid) ON DELETE CASCADE,
+    resort_uuid UUID REFERENCES resorts(uuid) ON DELETE SET NULL,
+    channel VARCHAR(20) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_message_id VARCHAR(64),
+    recipient VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_code VARCHAR(20),
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_deliveries_status_check
+        CHECK (status IN ('queued', 'sent', 'delivered', 'failed', 'undelivered'))
+);
+
+CREATE UNIQUE INDEX idx_notification_deliveries_provider_message
+    ON notification_deliveries(provider, provider_message_id)
+    WHERE provider_message_id IS NOT NULL;
+CREATE INDEX idx_notification_deliveries_user_created ON notification_deliveries(user_uuid, created_at DESC);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_deliveries;
@@ -0,0 +1,32 @@
+-- migrations/005_notification_outbox.sql
+-- +goose Up
+CREATE TABLE notification_outbox (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    resort_uuid UUID NOT NULL REFERENCES resorts(uuid) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    forecast_date DATE NOT NULL,
+    snow_amount DOUBLE PRECISION NOT NULL,
+    is_update BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_outbox_status_check
+        CHECK (status IN ('pending', 'sending', 'sent', 'dead'))
+);
+
+CREATE INDEX idx_notification_outbox_due ON notification_outbox(next_attempt_at)
+    WHERE status IN ('pending', 'sending');
+CREATE INDEX idx_notification_outbox_alert ON notification_outbox(user_uuid, resort_uuid, forecast_date);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_outbox;
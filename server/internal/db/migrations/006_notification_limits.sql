@@ -0,0 +1,16 @@
+-- migrations/006_notification_limits.sql
+-- +goose Up
+-- NULL means the user gets the deployment-wide default limit.
+ALTER TABLE users
+    ADD COLUMN max_alerts_per_day INTEGER,
+    ADD COLUMN min_alert_spacing_minutes INTEGER,
+    ADD CONSTRAINT users_max_alerts_per_day_check CHECK (max_alerts_per_day > 0),
+    ADD CONSTRAINT users_min_alert_spacing_minutes_check CHECK (min_alert_spacing_minutes >= 0);
+
+
+-- +goose Down
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_min_alert_spacing_minutes_check,
+    DROP CONSTRAINT IF EXISTS users_max_alerts_per_day_check,
+    DROP COLUMN IF EXISTS min_alert_spacing_minutes,
+    DROP COLUMN IF EXISTS max_alerts_per_day;
@@ -0,0 +1,14 @@
+-- migrations/007_user_units_locale.sql
+-- +goose Up
+-- Snow amounts stay stored in inches; units only controls how alerts present them.
+ALTER TABLE users
+    ADD COLUMN units VARCHAR(10) NOT NULL DEFAULT 'imperial',
+    ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en-US',
+    ADD CONSTRAINT users_units_check CHECK (units IN ('imperial', 'metric'));
+
+
+-- +goose Down
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_units_check,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS units;
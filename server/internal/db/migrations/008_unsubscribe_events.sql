@@ -0,0 +1,20 @@
+-- migrations/008_unsubscribe_events.sql
+-- +goose Up
+-- One row per unsubscribe link used. resort_uuid is NULL when every alert was removed.
+CREATE TABLE unsubscribe_events (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    resort_uuid UUID REFERENCES resorts(uuid) ON DELETE SET NULL,
+    channel VARCHAR(20),
+    method VARCHAR(20) NOT NULL,
+    alerts_removed INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT unsubscribe_events_method_check CHECK (method IN ('link', 'one_click'))
+);
+
+CREATE INDEX idx_unsubscribe_events_user_created ON unsubscribe_events(user_uuid, created_at DESC);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS unsubscribe_events;
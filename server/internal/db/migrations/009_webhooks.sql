@@ -0,0 +1,18 @@
+-- migrations/009_webhooks.sql
+-- +goose Up
+-- Outbound webhooks users register to receive alerts in their own automations. The secret signs each
+-- payload, so it is stored as issued.
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks(user_uuid);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,20 @@
+-- migrations/010_alert_destinations.sql
+-- +goose Up
+-- Extra places an alert is posted besides the user's own channels, such as a Slack or Discord channel shared
+-- with friends. Destinations belong to one alert and are removed with it.
+CREATE TABLE alert_destinations (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL,
+    resort_uuid UUID NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('slack', 'discord')),
+    url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_uuid, resort_uuid) REFERENCES user_alerts(user_uuid, resort_uuid) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_alert_destinations_alert ON alert_destinations(user_uuid, resort_uuid);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS alert_destinations;
@@ -0,0 +1,10 @@
+-- migrations/011_user_push_topics.sql
+-- +goose Up
+-- An ntfy-compatible topic URL alerts are pushed to, e.g. https://ntfy.sh/my-powder-alerts.
+ALTER TABLE users
+    ADD COLUMN ntfy_topic_url TEXT;
+
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN IF EXISTS ntfy_topic_url;
@@ -0,0 +1,19 @@
+-- migrations/012_push_subscriptions.sql
+-- +goose Up
+-- Browser PushSubscriptions for Web Push. The endpoint identifies a subscription: a browser that subscribes
+-- again, or for another user, replaces its earlier row.
+CREATE TABLE push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_push_subscriptions_user ON push_subscriptions(user_uuid);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS push_subscriptions;
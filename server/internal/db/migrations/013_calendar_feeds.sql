@@ -0,0 +1,34 @@
+-- migrations/013_calendar_feeds.sql
+-- +goose Up
+-- A user's private iCalendar feed. Only a SHA-256 hash of the token in the feed URL is stored.
+CREATE TABLE calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    user_uuid UUID NOT NULL UNIQUE REFERENCES users(uuid) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Powder days forecast for a user's alerts, one per resort and date. The forecaster keeps them in step with
+-- each run: changed amounts bump the sequence so calendar clients replace the event, and forecasts that no
+-- longer meet the alert are cancelled rather than deleted.
+CREATE TABLE calendar_events (
+    id SERIAL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    resort_uuid UUID NOT NULL REFERENCES resorts(uuid) ON DELETE CASCADE,
+    forecast_date DATE NOT NULL,
+    snow_amount DOUBLE PRECISION NOT NULL,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_uuid, resort_uuid, forecast_date)
+);
+
+CREATE INDEX idx_calendar_events_resort_date ON calendar_events(resort_uuid, forecast_date);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS calendar_events;
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,23 @@
+-- migrations/014_resort_forecasts.sql
+-- +goose Up
+-- The latest forecast for each day at a resort, saved by every forecaster run. Amounts are in inches and
+-- temperatures in °F. updated_at only moves when the snow amount changes, so feeds can tell readers about it.
+CREATE TABLE resort_forecasts (
+    id SERIAL PRIMARY KEY,
+    resort_uuid UUID NOT NULL REFERENCES resorts(uuid) ON DELETE CASCADE,
+    forecast_date DATE NOT NULL,
+    snow_amount DOUBLE PRECISION NOT NULL,
+    temp_min DOUBLE PRECISION NOT NULL,
+    temp_max DOUBLE PRECISION NOT NULL,
+    temp_avg DOUBLE PRECISION NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (resort_uuid, forecast_date)
+);
+
+CREATE INDEX idx_resort_forecasts_updated_at ON resort_forecasts(updated_at);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS resort_forecasts;
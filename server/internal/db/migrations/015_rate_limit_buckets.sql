@@ -0,0 +1,16 @@
+-- migrations/015_rate_limit_buckets.sql
+-- +goose Up
+-- Token buckets of the API's rate limiter, shared by every instance. A bucket refills with time, and once
+-- full at expires_at it's no different from having none, so it can be deleted.
+CREATE TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS rate_limit_buckets;
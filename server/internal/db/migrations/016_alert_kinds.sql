@@ -0,0 +1,22 @@
+-- migrations/016_alert_kinds.sql
+-- +goose Up
+-- Powder alerts are sent when a forecast meets an alert and again when it grows, downgrades when it shrinks
+-- after an alert and bluebird alerts for a clear day after a powder day. Only powder alerts and downgrades
+-- are the last alert a new forecast is compared with.
+ALTER TABLE notification_outbox
+    ADD COLUMN alert_kind VARCHAR(20) NOT NULL DEFAULT 'powder',
+    ADD CONSTRAINT notification_outbox_alert_kind_check CHECK (alert_kind IN ('powder', 'downgrade', 'bluebird'));
+
+ALTER TABLE alert_history
+    ADD COLUMN alert_kind VARCHAR(20) NOT NULL DEFAULT 'powder',
+    ADD CONSTRAINT alert_history_alert_kind_check CHECK (alert_kind IN ('powder', 'downgrade', 'bluebird'));
+
+
+-- +goose Down
+ALTER TABLE alert_history
+    DROP CONSTRAINT IF EXISTS alert_history_alert_kind_check,
+    DROP COLUMN IF EXISTS alert_kind;
+
+ALTER TABLE notification_outbox
+    DROP CONSTRAINT IF EXISTS notification_outbox_alert_kind_check,
+    DROP COLUMN IF EXISTS alert_kind;
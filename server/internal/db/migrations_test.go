@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationVersion(t *testing.T) {
+	version, err := migrationVersion("015_rate_limit_buckets.sql")
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), version)
+
+	_, err = migrationVersion("rate_limit_buckets.sql")
+	assert.Error(t, err)
+	_, err = migrationVersion("v15_rate_limit_buckets.sql")
+	assert.Error(t, err)
+}
+
+func TestLatestMigration(t *testing.T) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	// Migrations are numbered in order, so the latest is the last file.
+	latest, err := migrationVersion(entries[len(entries)-1].Name())
+	require.NoError(t, err)
+	assert.Equal(t, latest, LatestMigration())
+}
@@ -16,6 +16,7 @@ import (
 
 	db "github.com/MattSilvaa/powhunter/internal/db"
 	db0 "github.com/MattSilvaa/powhunter/internal/db/generated"
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -43,6 +44,50 @@ func (m *MockStoreService) EXPECT() *MockStoreServiceMockRecorder {
 	return m.recorder
 }
 
+// ClaimOutboxMessages mocks base method.
+func (m *MockStoreService) ClaimOutboxMessages(ctx context.Context, batchSize int32, lease time.Duration) ([]db.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxMessages", ctx, batchSize, lease)
+	ret0, _ := ret[0].([]db.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxMessages indicates an expected call of ClaimOutboxMessages.
+func (mr *MockStoreServiceMockRecorder) ClaimOutboxMessages(ctx, batchSize, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxMessages", reflect.TypeOf((*MockStoreService)(nil).ClaimOutboxMessages), ctx, batchSize, lease)
+}
+
+// CreateAlertDestination mocks base method.
+func (m *MockStoreService) CreateAlertDestination(ctx context.Context, email string, resortUUID uuid.UUID, channel, url string) (db0.AlertDestination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlertDestination", ctx, email, resortUUID, channel, url)
+	ret0, _ := ret[0].(db0.AlertDestination)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlertDestination indicates an expected call of CreateAlertDestination.
+func (mr *MockStoreServiceMockRecorder) CreateAlertDestination(ctx, email, resortUUID, channel, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlertDestination", reflect.TypeOf((*MockStoreService)(nil).CreateAlertDestination), ctx, email, resortUUID, channel, url)
+}
+
+// CreateCalendarFeed mocks base method.
+func (m *MockStoreService) CreateCalendarFeed(ctx context.Context, email, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCalendarFeed", ctx, email, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCalendarFeed indicates an expected call of CreateCalendarFeed.
+func (mr *MockStoreServiceMockRecorder) CreateCalendarFeed(ctx, email, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCalendarFeed", reflect.TypeOf((*MockStoreService)(nil).CreateCalendarFeed), ctx, email, tokenHash)
+}
+
 // CreateUserWithAlerts mocks base method.
 func (m *MockStoreService) CreateUserWithAlerts(ctx context.Context, email, phone string, minSnowAmount float64, notificationDays int32, resortUUIDs []string) error {
 	m.ctrl.T.Helper()
@@ -57,6 +102,49 @@ func (mr *MockStoreServiceMockRecorder) CreateUserWithAlerts(ctx, email, phone,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithAlerts", reflect.TypeOf((*MockStoreService)(nil).CreateUserWithAlerts), ctx, email, phone, minSnowAmount, notificationDays, resortUUIDs)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockStoreService) CreateWebhook(ctx context.Context, email, url, secret string) (db0.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, email, url, secret)
+	ret0, _ := ret[0].(db0.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreServiceMockRecorder) CreateWebhook(ctx, email, url, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStoreService)(nil).CreateWebhook), ctx, email, url, secret)
+}
+
+// DeferOutboxMessages mocks base method.
+func (m *MockStoreService) DeferOutboxMessages(ctx context.Context, messageUUIDs []uuid.UUID, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferOutboxMessages", ctx, messageUUIDs, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferOutboxMessages indicates an expected call of DeferOutboxMessages.
+func (mr *MockStoreServiceMockRecorder) DeferOutboxMessages(ctx, messageUUIDs, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferOutboxMessages", reflect.TypeOf((*MockStoreService)(nil).DeferOutboxMessages), ctx, messageUUIDs, nextAttemptAt)
+}
+
+// DeleteAlertDestination mocks base method.
+func (m *MockStoreService) DeleteAlertDestination(ctx context.Context, email string, destinationUUID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlertDestination", ctx, email, destinationUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlertDestination indicates an expected call of DeleteAlertDestination.
+func (mr *MockStoreServiceMockRecorder) DeleteAlertDestination(ctx, email, destinationUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlertDestination", reflect.TypeOf((*MockStoreService)(nil).DeleteAlertDestination), ctx, email, destinationUUID)
+}
+
 // DeleteAllUserAlerts mocks base method.
 func (m *MockStoreService) DeleteAllUserAlerts(ctx context.Context, email string) error {
 	m.ctrl.T.Helper()
@@ -71,6 +159,34 @@ func (mr *MockStoreServiceMockRecorder) DeleteAllUserAlerts(ctx, email any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllUserAlerts", reflect.TypeOf((*MockStoreService)(nil).DeleteAllUserAlerts), ctx, email)
 }
 
+// DeleteCalendarFeed mocks base method.
+func (m *MockStoreService) DeleteCalendarFeed(ctx context.Context, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendarFeed", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCalendarFeed indicates an expected call of DeleteCalendarFeed.
+func (mr *MockStoreServiceMockRecorder) DeleteCalendarFeed(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendarFeed", reflect.TypeOf((*MockStoreService)(nil).DeleteCalendarFeed), ctx, tokenHash)
+}
+
+// DeletePushSubscription mocks base method.
+func (m *MockStoreService) DeletePushSubscription(ctx context.Context, email, endpoint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePushSubscription", ctx, email, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePushSubscription indicates an expected call of DeletePushSubscription.
+func (mr *MockStoreServiceMockRecorder) DeletePushSubscription(ctx, email, endpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePushSubscription", reflect.TypeOf((*MockStoreService)(nil).DeletePushSubscription), ctx, email, endpoint)
+}
+
 // DeleteUserAlert mocks base method.
 func (m *MockStoreService) DeleteUserAlert(ctx context.Context, email, resortUuid string) error {
 	m.ctrl.T.Helper()
@@ -85,6 +201,64 @@ func (mr *MockStoreServiceMockRecorder) DeleteUserAlert(ctx, email, resortUuid a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAlert", reflect.TypeOf((*MockStoreService)(nil).DeleteUserAlert), ctx, email, resortUuid)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockStoreService) DeleteWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, email, webhookUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoreServiceMockRecorder) DeleteWebhook(ctx, email, webhookUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStoreService)(nil).DeleteWebhook), ctx, email, webhookUUID)
+}
+
+// ExpirePushSubscription mocks base method.
+func (m *MockStoreService) ExpirePushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePushSubscription", ctx, subscriptionUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePushSubscription indicates an expected call of ExpirePushSubscription.
+func (mr *MockStoreServiceMockRecorder) ExpirePushSubscription(ctx, subscriptionUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePushSubscription", reflect.TypeOf((*MockStoreService)(nil).ExpirePushSubscription), ctx, subscriptionUUID)
+}
+
+// FailOutboxMessage mocks base method.
+func (m *MockStoreService) FailOutboxMessage(ctx context.Context, messageUUID uuid.UUID, lastError string, nextAttemptAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailOutboxMessage", ctx, messageUUID, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailOutboxMessage indicates an expected call of FailOutboxMessage.
+func (mr *MockStoreServiceMockRecorder) FailOutboxMessage(ctx, messageUUID, lastError, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOutboxMessage", reflect.TypeOf((*MockStoreService)(nil).FailOutboxMessage), ctx, messageUUID, lastError, nextAttemptAt)
+}
+
+// GetAlertDestination mocks base method.
+func (m *MockStoreService) GetAlertDestination(ctx context.Context, destinationUUID uuid.UUID) (db0.AlertDestination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertDestination", ctx, destinationUUID)
+	ret0, _ := ret[0].(db0.AlertDestination)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertDestination indicates an expected call of GetAlertDestination.
+func (mr *MockStoreServiceMockRecorder) GetAlertDestination(ctx, destinationUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertDestination", reflect.TypeOf((*MockStoreService)(nil).GetAlertDestination), ctx, destinationUUID)
+}
+
 // GetAlertMatches mocks base method.
 func (m *MockStoreService) GetAlertMatches(ctx context.Context, resortUUID string, forecastDate time.Time, predictedSnowAmount float64, daysAhead int32) ([]db.AlertToSend, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +274,111 @@ func (mr *MockStoreServiceMockRecorder) GetAlertMatches(ctx, resortUUID, forecas
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertMatches", reflect.TypeOf((*MockStoreService)(nil).GetAlertMatches), ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
 }
 
+// GetCalendarFeed mocks base method.
+func (m *MockStoreService) GetCalendarFeed(ctx context.Context, tokenHash string, since time.Time) (db.CalendarFeed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendarFeed", ctx, tokenHash, since)
+	ret0, _ := ret[0].(db.CalendarFeed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendarFeed indicates an expected call of GetCalendarFeed.
+func (mr *MockStoreServiceMockRecorder) GetCalendarFeed(ctx, tokenHash, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendarFeed", reflect.TypeOf((*MockStoreService)(nil).GetCalendarFeed), ctx, tokenHash, since)
+}
+
+// GetNotificationBudget mocks base method.
+func (m *MockStoreService) GetNotificationBudget(ctx context.Context, userUUID uuid.UUID, channel string, since time.Time) (db.NotificationBudget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationBudget", ctx, userUUID, channel, since)
+	ret0, _ := ret[0].(db.NotificationBudget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationBudget indicates an expected call of GetNotificationBudget.
+func (mr *MockStoreServiceMockRecorder) GetNotificationBudget(ctx, userUUID, channel, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationBudget", reflect.TypeOf((*MockStoreService)(nil).GetNotificationBudget), ctx, userUUID, channel, since)
+}
+
+// GetPushSubscription mocks base method.
+func (m *MockStoreService) GetPushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) (db0.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPushSubscription", ctx, subscriptionUUID)
+	ret0, _ := ret[0].(db0.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPushSubscription indicates an expected call of GetPushSubscription.
+func (mr *MockStoreServiceMockRecorder) GetPushSubscription(ctx, subscriptionUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPushSubscription", reflect.TypeOf((*MockStoreService)(nil).GetPushSubscription), ctx, subscriptionUUID)
+}
+
+// GetRecentDeliveriesByEmail mocks base method.
+func (m *MockStoreService) GetRecentDeliveriesByEmail(ctx context.Context, email string, limit int32) ([]db0.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentDeliveriesByEmail", ctx, email, limit)
+	ret0, _ := ret[0].([]db0.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentDeliveriesByEmail indicates an expected call of GetRecentDeliveriesByEmail.
+func (mr *MockStoreServiceMockRecorder) GetRecentDeliveriesByEmail(ctx, email, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentDeliveriesByEmail", reflect.TypeOf((*MockStoreService)(nil).GetRecentDeliveriesByEmail), ctx, email, limit)
+}
+
+// GetResort mocks base method.
+func (m *MockStoreService) GetResort(ctx context.Context, resortUUID uuid.UUID) (db0.Resort, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResort", ctx, resortUUID)
+	ret0, _ := ret[0].(db0.Resort)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResort indicates an expected call of GetResort.
+func (mr *MockStoreServiceMockRecorder) GetResort(ctx, resortUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResort", reflect.TypeOf((*MockStoreService)(nil).GetResort), ctx, resortUUID)
+}
+
+// GetResortDetail mocks base method.
+func (m *MockStoreService) GetResortDetail(ctx context.Context, resortUUID uuid.UUID, from time.Time) (db.ResortDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResortDetail", ctx, resortUUID, from)
+	ret0, _ := ret[0].(db.ResortDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResortDetail indicates an expected call of GetResortDetail.
+func (mr *MockStoreServiceMockRecorder) GetResortDetail(ctx, resortUUID, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResortDetail", reflect.TypeOf((*MockStoreService)(nil).GetResortDetail), ctx, resortUUID, from)
+}
+
+// GetSMSNumber mocks base method.
+func (m *MockStoreService) GetSMSNumber(ctx context.Context, email string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSMSNumber", ctx, email)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSMSNumber indicates an expected call of GetSMSNumber.
+func (mr *MockStoreServiceMockRecorder) GetSMSNumber(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSMSNumber", reflect.TypeOf((*MockStoreService)(nil).GetSMSNumber), ctx, email)
+}
+
 // GetUserAlertsByEmail mocks base method.
 func (m *MockStoreService) GetUserAlertsByEmail(ctx context.Context, email string) ([]db0.GetUserAlertsByEmailRow, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +394,51 @@ func (mr *MockStoreServiceMockRecorder) GetUserAlertsByEmail(ctx, email any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAlertsByEmail", reflect.TypeOf((*MockStoreService)(nil).GetUserAlertsByEmail), ctx, email)
 }
 
+// GetUserWebhook mocks base method.
+func (m *MockStoreService) GetUserWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) (db0.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserWebhook", ctx, email, webhookUUID)
+	ret0, _ := ret[0].(db0.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserWebhook indicates an expected call of GetUserWebhook.
+func (mr *MockStoreServiceMockRecorder) GetUserWebhook(ctx, email, webhookUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWebhook", reflect.TypeOf((*MockStoreService)(nil).GetUserWebhook), ctx, email, webhookUUID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockStoreService) GetWebhook(ctx context.Context, webhookUUID uuid.UUID) (db0.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, webhookUUID)
+	ret0, _ := ret[0].(db0.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockStoreServiceMockRecorder) GetWebhook(ctx, webhookUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockStoreService)(nil).GetWebhook), ctx, webhookUUID)
+}
+
+// ListAlertDestinations mocks base method.
+func (m *MockStoreService) ListAlertDestinations(ctx context.Context, email string) ([]db0.AlertDestination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertDestinations", ctx, email)
+	ret0, _ := ret[0].([]db0.AlertDestination)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertDestinations indicates an expected call of ListAlertDestinations.
+func (mr *MockStoreServiceMockRecorder) ListAlertDestinations(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertDestinations", reflect.TypeOf((*MockStoreService)(nil).ListAlertDestinations), ctx, email)
+}
+
 // ListAllResorts mocks base method.
 func (m *MockStoreService) ListAllResorts(ctx context.Context) ([]db0.Resort, error) {
 	m.ctrl.T.Helper()
@@ -130,6 +454,124 @@ func (mr *MockStoreServiceMockRecorder) ListAllResorts(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllResorts", reflect.TypeOf((*MockStoreService)(nil).ListAllResorts), ctx)
 }
 
+// ListForecastFeed mocks base method.
+func (m *MockStoreService) ListForecastFeed(ctx context.Context, query db.ForecastFeedQuery) ([]db.ForecastFeedEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForecastFeed", ctx, query)
+	ret0, _ := ret[0].([]db.ForecastFeedEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForecastFeed indicates an expected call of ListForecastFeed.
+func (mr *MockStoreServiceMockRecorder) ListForecastFeed(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForecastFeed", reflect.TypeOf((*MockStoreService)(nil).ListForecastFeed), ctx, query)
+}
+
+// ListOutboxMessages mocks base method.
+func (m *MockStoreService) ListOutboxMessages(ctx context.Context, status string, limit int32) ([]db0.NotificationOutbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxMessages", ctx, status, limit)
+	ret0, _ := ret[0].([]db0.NotificationOutbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxMessages indicates an expected call of ListOutboxMessages.
+func (mr *MockStoreServiceMockRecorder) ListOutboxMessages(ctx, status, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxMessages", reflect.TypeOf((*MockStoreService)(nil).ListOutboxMessages), ctx, status, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockStoreService) ListWebhooks(ctx context.Context, email string) ([]db0.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, email)
+	ret0, _ := ret[0].([]db0.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockStoreServiceMockRecorder) ListWebhooks(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockStoreService)(nil).ListWebhooks), ctx, email)
+}
+
+// MarkOutboxMessagesSent mocks base method.
+func (m *MockStoreService) MarkOutboxMessagesSent(ctx context.Context, messageUUIDs []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxMessagesSent", ctx, messageUUIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxMessagesSent indicates an expected call of MarkOutboxMessagesSent.
+func (mr *MockStoreServiceMockRecorder) MarkOutboxMessagesSent(ctx, messageUUIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxMessagesSent", reflect.TypeOf((*MockStoreService)(nil).MarkOutboxMessagesSent), ctx, messageUUIDs)
+}
+
+// PauseAlerts mocks base method.
+func (m *MockStoreService) PauseAlerts(ctx context.Context, phone string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseAlerts", ctx, phone, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PauseAlerts indicates an expected call of PauseAlerts.
+func (mr *MockStoreServiceMockRecorder) PauseAlerts(ctx, phone, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseAlerts", reflect.TypeOf((*MockStoreService)(nil).PauseAlerts), ctx, phone, until)
+}
+
+// PruneResortForecasts mocks base method.
+func (m *MockStoreService) PruneResortForecasts(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneResortForecasts", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneResortForecasts indicates an expected call of PruneResortForecasts.
+func (mr *MockStoreServiceMockRecorder) PruneResortForecasts(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneResortForecasts", reflect.TypeOf((*MockStoreService)(nil).PruneResortForecasts), ctx, before)
+}
+
+// QueueAlertMatches mocks base method.
+func (m *MockStoreService) QueueAlertMatches(ctx context.Context, resortUUID string, forecastDate time.Time, predictedSnowAmount float64, daysAhead int32) ([]db.AlertToSend, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueAlertMatches", ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+	ret0, _ := ret[0].([]db.AlertToSend)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueueAlertMatches indicates an expected call of QueueAlertMatches.
+func (mr *MockStoreServiceMockRecorder) QueueAlertMatches(ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueAlertMatches", reflect.TypeOf((*MockStoreService)(nil).QueueAlertMatches), ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+}
+
+// QueueBluebirdAlerts mocks base method.
+func (m *MockStoreService) QueueBluebirdAlerts(ctx context.Context, resortUUID string, forecastDate time.Time, snowAmount float64, daysAhead int32) ([]db.AlertToSend, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueBluebirdAlerts", ctx, resortUUID, forecastDate, snowAmount, daysAhead)
+	ret0, _ := ret[0].([]db.AlertToSend)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueueBluebirdAlerts indicates an expected call of QueueBluebirdAlerts.
+func (mr *MockStoreServiceMockRecorder) QueueBluebirdAlerts(ctx, resortUUID, forecastDate, snowAmount, daysAhead any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueBluebirdAlerts", reflect.TypeOf((*MockStoreService)(nil).QueueBluebirdAlerts), ctx, resortUUID, forecastDate, snowAmount, daysAhead)
+}
+
 // RecordAlertSent mocks base method.
 func (m *MockStoreService) RecordAlertSent(ctx context.Context, alert db.AlertToSend) error {
 	m.ctrl.T.Helper()
@@ -143,3 +585,149 @@ func (mr *MockStoreServiceMockRecorder) RecordAlertSent(ctx, alert any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAlertSent", reflect.TypeOf((*MockStoreService)(nil).RecordAlertSent), ctx, alert)
 }
+
+// RecordDelivery mocks base method.
+func (m *MockStoreService) RecordDelivery(ctx context.Context, delivery db.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDelivery indicates an expected call of RecordDelivery.
+func (mr *MockStoreServiceMockRecorder) RecordDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDelivery", reflect.TypeOf((*MockStoreService)(nil).RecordDelivery), ctx, delivery)
+}
+
+// RequeueDeadOutboxMessages mocks base method.
+func (m *MockStoreService) RequeueDeadOutboxMessages(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadOutboxMessages", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeadOutboxMessages indicates an expected call of RequeueDeadOutboxMessages.
+func (mr *MockStoreServiceMockRecorder) RequeueDeadOutboxMessages(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadOutboxMessages", reflect.TypeOf((*MockStoreService)(nil).RequeueDeadOutboxMessages), ctx)
+}
+
+// RequeueOutboxMessage mocks base method.
+func (m *MockStoreService) RequeueOutboxMessage(ctx context.Context, messageUUID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueOutboxMessage", ctx, messageUUID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueOutboxMessage indicates an expected call of RequeueOutboxMessage.
+func (mr *MockStoreServiceMockRecorder) RequeueOutboxMessage(ctx, messageUUID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueOutboxMessage", reflect.TypeOf((*MockStoreService)(nil).RequeueOutboxMessage), ctx, messageUUID)
+}
+
+// SavePushSubscription mocks base method.
+func (m *MockStoreService) SavePushSubscription(ctx context.Context, email, endpoint, p256dh, auth string) (db0.PushSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePushSubscription", ctx, email, endpoint, p256dh, auth)
+	ret0, _ := ret[0].(db0.PushSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePushSubscription indicates an expected call of SavePushSubscription.
+func (mr *MockStoreServiceMockRecorder) SavePushSubscription(ctx, email, endpoint, p256dh, auth any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePushSubscription", reflect.TypeOf((*MockStoreService)(nil).SavePushSubscription), ctx, email, endpoint, p256dh, auth)
+}
+
+// SaveResortForecasts mocks base method.
+func (m *MockStoreService) SaveResortForecasts(ctx context.Context, resortUUID uuid.UUID, forecasts []db.ResortForecast) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveResortForecasts", ctx, resortUUID, forecasts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveResortForecasts indicates an expected call of SaveResortForecasts.
+func (mr *MockStoreServiceMockRecorder) SaveResortForecasts(ctx, resortUUID, forecasts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResortForecasts", reflect.TypeOf((*MockStoreService)(nil).SaveResortForecasts), ctx, resortUUID, forecasts)
+}
+
+// SetPreferences mocks base method.
+func (m *MockStoreService) SetPreferences(ctx context.Context, email string, prefs db.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferences", ctx, email, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferences indicates an expected call of SetPreferences.
+func (mr *MockStoreServiceMockRecorder) SetPreferences(ctx, email, prefs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferences", reflect.TypeOf((*MockStoreService)(nil).SetPreferences), ctx, email, prefs)
+}
+
+// SetSMSOptOut mocks base method.
+func (m *MockStoreService) SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSMSOptOut", ctx, phone, optedOut)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSMSOptOut indicates an expected call of SetSMSOptOut.
+func (mr *MockStoreServiceMockRecorder) SetSMSOptOut(ctx, phone, optedOut any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSMSOptOut", reflect.TypeOf((*MockStoreService)(nil).SetSMSOptOut), ctx, phone, optedOut)
+}
+
+// SyncCalendarEvents mocks base method.
+func (m *MockStoreService) SyncCalendarEvents(ctx context.Context, resortUUID uuid.UUID, from time.Time, forecasts []db.CalendarForecast) (db.CalendarSync, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncCalendarEvents", ctx, resortUUID, from, forecasts)
+	ret0, _ := ret[0].(db.CalendarSync)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncCalendarEvents indicates an expected call of SyncCalendarEvents.
+func (mr *MockStoreServiceMockRecorder) SyncCalendarEvents(ctx, resortUUID, from, forecasts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncCalendarEvents", reflect.TypeOf((*MockStoreService)(nil).SyncCalendarEvents), ctx, resortUUID, from, forecasts)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockStoreService) Unsubscribe(ctx context.Context, unsubscribe db.Unsubscribe) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, unsubscribe)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockStoreServiceMockRecorder) Unsubscribe(ctx, unsubscribe any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockStoreService)(nil).Unsubscribe), ctx, unsubscribe)
+}
+
+// UpdateDeliveryStatus mocks base method.
+func (m *MockStoreService) UpdateDeliveryStatus(ctx context.Context, provider, providerMessageID, status, errorCode, errorMessage string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeliveryStatus", ctx, provider, providerMessageID, status, errorCode, errorMessage)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDeliveryStatus indicates an expected call of UpdateDeliveryStatus.
+func (mr *MockStoreServiceMockRecorder) UpdateDeliveryStatus(ctx, provider, providerMessageID, status, errorCode, errorMessage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeliveryStatus", reflect.TypeOf((*MockStoreService)(nil).UpdateDeliveryStatus), ctx, provider, providerMessageID, status, errorCode, errorMessage)
+}
@@ -0,0 +1,324 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// Outbox statuses. Messages are claimed from pending into sending, and end up either sent or, once
+// their attempts are exhausted, dead until an operator requeues them.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage is a queued notification claimed for sending.
+type OutboxMessage struct {
+	UUID        uuid.UUID
+	Channel     string
+	Recipient   string
+	Attempts    int32
+	MaxAttempts int32
+	Alert       AlertToSend
+}
+
+// QueueAlertMatches finds alerts that match a forecast and writes them to the notification outbox in the
+// same transaction, so a match is never lost between being found and being sent. Each match is queued once
+// for every destination the user has; users with none are not queued.
+func (s *Store) QueueAlertMatches(
+	ctx context.Context,
+	resortUUID string,
+	forecastDate time.Time,
+	predictedSnowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	var queued []AlertToSend
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		matches, err := findAlertMatches(ctx, q, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+		if err != nil {
+			return err
+		}
+
+		queued, err = enqueueAlerts(ctx, q, matches)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued, nil
+}
+
+// QueueBluebirdAlerts finds the users to tell about a clear day at a resort after snowAmount inches of snow
+// and writes their bluebird alerts to the notification outbox, like QueueAlertMatches. Each user is sent one
+// bluebird alert per resort and day.
+func (s *Store) QueueBluebirdAlerts(
+	ctx context.Context,
+	resortUUID string,
+	forecastDate time.Time,
+	snowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	var queued []AlertToSend
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		matches, err := findBluebirdMatches(ctx, q, resortUUID, forecastDate, snowAmount, daysAhead)
+		if err != nil {
+			return err
+		}
+
+		queued, err = enqueueAlerts(ctx, q, matches)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queued, nil
+}
+
+// powderOnlyChannels are the channels only powder alerts are sent on. Webhook payloads and chat messages
+// describe a forecast of fresh snow, so they can't tell a downgrade or a bluebird day from one.
+var powderOnlyChannels = map[string]bool{
+	ChannelWebhook: true,
+	ChannelSlack:   true,
+	ChannelDiscord: true,
+}
+
+// enqueueAlerts queues each match once for every destination the user has that carries its kind of alert,
+// and returns the matches that were queued.
+func enqueueAlerts(ctx context.Context, q *dbgen.Queries, matches []AlertToSend) ([]AlertToSend, error) {
+	var queued []AlertToSend
+	for _, match := range matches {
+		destinations, err := alertDestinations(ctx, q, match)
+		if err != nil {
+			return nil, err
+		}
+
+		enqueued := false
+		for _, destination := range destinations {
+			if match.kind() != AlertKindPowder && powderOnlyChannels[destination.channel] {
+				continue
+			}
+
+			err := q.EnqueueOutboxMessage(ctx, dbgen.EnqueueOutboxMessageParams{
+				UserUuid:     match.UserUuid,
+				ResortUuid:   match.ResortUUID,
+				Channel:      destination.channel,
+				Recipient:    destination.recipient,
+				ForecastDate: match.ForecastDate,
+				SnowAmount:   match.SnowAmount,
+				IsUpdate:     match.IsUpdate,
+				AlertKind:    match.kind(),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error queueing alert for user %s: %w", match.UserUuid.String(), err)
+			}
+			enqueued = true
+		}
+		if enqueued {
+			queued = append(queued, match)
+		}
+	}
+
+	return queued, nil
+}
+
+// destination is a channel and recipient an alert is sent to.
+type destination struct {
+	channel   string
+	recipient string
+}
+
+// alertDestinations returns where a matched alert is sent: the user's phone unless they've opted out of
+// SMS, their push topic if they have one, each browser they subscribed to Web Push on, each of their
+// webhooks and the chat channels attached to the alert.
+func alertDestinations(ctx context.Context, q *dbgen.Queries, match AlertToSend) ([]destination, error) {
+	var destinations []destination
+	if match.UserPhone != "" && !match.UserSMSOptedOut {
+		destinations = append(destinations, destination{channel: ChannelSMS, recipient: match.UserPhone})
+	}
+	if match.UserNtfyTopic != "" {
+		destinations = append(destinations, destination{channel: ChannelPush, recipient: match.UserNtfyTopic})
+	}
+
+	subscriptions, err := q.ListPushSubscriptionsForUser(ctx, match.UserUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error listing push subscriptions for user %s: %w", match.UserUuid.String(), err)
+	}
+	for _, subscription := range subscriptions {
+		destinations = append(destinations, destination{channel: ChannelWebPush, recipient: subscription.Uuid.String()})
+	}
+
+	webhooks, err := q.ListWebhooksForUser(ctx, match.UserUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks for user %s: %w", match.UserUuid.String(), err)
+	}
+	for _, webhook := range webhooks {
+		destinations = append(destinations, destination{channel: ChannelWebhook, recipient: webhook.Uuid.String()})
+	}
+
+	extra, err := q.ListAlertDestinationsForAlert(ctx, dbgen.ListAlertDestinationsForAlertParams{
+		UserUuid:   match.UserUuid,
+		ResortUuid: match.ResortUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing alert destinations for user %s: %w", match.UserUuid.String(), err)
+	}
+	for _, d := range extra {
+		destinations = append(destinations, destination{channel: d.Channel, recipient: d.Uuid.String()})
+	}
+
+	return destinations, nil
+}
+
+// ClaimOutboxMessages claims up to batchSize due messages. Rows locked by another worker are skipped, and
+// a claimed message that isn't marked sent or failed within lease becomes due again.
+func (s *Store) ClaimOutboxMessages(ctx context.Context, batchSize int32, lease time.Duration) ([]OutboxMessage, error) {
+	rows, err := s.queries.ClaimOutboxMessages(ctx, dbgen.ClaimOutboxMessagesParams{
+		BatchSize:    batchSize,
+		LeaseSeconds: int32(lease / time.Second),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+
+	messages := make([]OutboxMessage, 0, len(rows))
+	for _, row := range rows {
+		var userPhone string
+		if row.Channel == ChannelSMS {
+			userPhone = row.Recipient
+		}
+
+		messages = append(messages, OutboxMessage{
+			UUID:        row.Uuid,
+			Channel:     row.Channel,
+			Recipient:   row.Recipient,
+			Attempts:    row.Attempts,
+			MaxAttempts: row.MaxAttempts,
+			Alert: AlertToSend{
+				UserUuid:     row.UserUuid,
+				UserEmail:    row.Email,
+				UserPhone:    userPhone,
+				ResortName:   row.ResortName,
+				ResortUUID:   row.ResortUuid,
+				ResortURL:    ResortURL(row.UrlHost, row.UrlPathname),
+				SnowAmount:   row.SnowAmount,
+				ForecastDate: row.ForecastDate,
+				IsUpdate:     row.IsUpdate,
+				Kind:         row.AlertKind,
+				Units:        units.System(row.Units),
+				Locale:       row.Locale,
+			},
+		})
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxMessagesSent marks claimed messages as sent and records them in the alert history. Messages merged
+// into one summary are marked sent together.
+func (s *Store) MarkOutboxMessagesSent(ctx context.Context, messageUUIDs []uuid.UUID) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		for _, messageUUID := range messageUUIDs {
+			sent, err := q.MarkOutboxMessageSent(ctx, messageUUID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("outbox message %s is not being sent", messageUUID)
+				}
+				return fmt.Errorf("error marking outbox message sent: %w", err)
+			}
+
+			err = q.InsertAlertHistory(ctx, dbgen.InsertAlertHistoryParams{
+				UserUuid:     uuid.NullUUID{UUID: sent.UserUuid, Valid: true},
+				ResortUuid:   uuid.NullUUID{UUID: sent.ResortUuid, Valid: true},
+				ForecastDate: sent.ForecastDate,
+				SnowAmount:   sent.SnowAmount,
+				AlertKind:    sent.AlertKind,
+			})
+			if err != nil {
+				return fmt.Errorf("error recording alert history: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeferOutboxMessages releases claimed messages without sending them, to be claimed again at nextAttemptAt.
+// Deferring doesn't use up an attempt.
+func (s *Store) DeferOutboxMessages(ctx context.Context, messageUUIDs []uuid.UUID, nextAttemptAt time.Time) error {
+	err := s.queries.DeferOutboxMessages(ctx, dbgen.DeferOutboxMessagesParams{
+		NextAttemptAt: nextAttemptAt,
+		Uuids:         messageUUIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("error deferring outbox messages: %w", err)
+	}
+	return nil
+}
+
+// FailOutboxMessage records a failed send. The message is retried at nextAttemptAt unless it has used all
+// of its attempts, in which case it is dead-lettered and FailOutboxMessage reports true.
+func (s *Store) FailOutboxMessage(
+	ctx context.Context,
+	messageUUID uuid.UUID,
+	lastError string,
+	nextAttemptAt time.Time,
+) (bool, error) {
+	status, err := s.queries.FailOutboxMessage(ctx, dbgen.FailOutboxMessageParams{
+		Uuid:          messageUUID,
+		NextAttemptAt: nextAttemptAt,
+		LastError:     sql.NullString{String: lastError, Valid: lastError != ""},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("outbox message %s is not being sent", messageUUID)
+		}
+		return false, fmt.Errorf("error failing outbox message: %w", err)
+	}
+
+	return status == OutboxStatusDead, nil
+}
+
+// ListOutboxMessages returns outbox messages with the given status, most recently updated first.
+func (s *Store) ListOutboxMessages(ctx context.Context, status string, limit int32) ([]dbgen.NotificationOutbox, error) {
+	messages, err := s.queries.ListOutboxMessagesByStatus(ctx, dbgen.ListOutboxMessagesByStatusParams{
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// RequeueOutboxMessage resets a dead-lettered message so it is sent again with a fresh set of attempts.
+// It reports false if no dead message has the given UUID.
+func (s *Store) RequeueOutboxMessage(ctx context.Context, messageUUID uuid.UUID) (bool, error) {
+	rows, err := s.queries.RequeueOutboxMessage(ctx, messageUUID)
+	if err != nil {
+		return false, fmt.Errorf("error requeueing outbox message: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RequeueDeadOutboxMessages requeues every dead-lettered message and returns how many were requeued.
+func (s *Store) RequeueDeadOutboxMessages(ctx context.Context) (int64, error) {
+	rows, err := s.queries.RequeueDeadOutboxMessages(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error requeueing dead outbox messages: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// ChannelWebPush delivers alerts as encrypted Web Push messages to browsers users subscribed in the web
+// client. Its outbox recipient is the subscription's UUID.
+const ChannelWebPush = "webpush"
+
+// MaxPushSubscriptionsPerUser is how many browsers one user can receive Web Push alerts on.
+const MaxPushSubscriptionsPerUser = 10
+
+var (
+	// ErrPushSubscriptionNotFound is returned when no push subscription matches, or it belongs to another user.
+	ErrPushSubscriptionNotFound = errors.New("push subscription not found")
+	// ErrPushSubscriptionLimit is returned when a user already has MaxPushSubscriptionsPerUser subscriptions.
+	ErrPushSubscriptionLimit = errors.New("push subscription limit reached")
+)
+
+// SavePushSubscription stores a browser's push subscription for the user with the given email. A browser
+// subscribing again replaces its keys, and moves the subscription over if it belonged to another user.
+func (s *Store) SavePushSubscription(
+	ctx context.Context,
+	email string,
+	endpoint, p256dh, auth string,
+) (dbgen.PushSubscription, error) {
+	var subscription dbgen.PushSubscription
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		count, err := q.CountOtherPushSubscriptionsForUser(ctx, dbgen.CountOtherPushSubscriptionsForUserParams{
+			UserUuid: user.Uuid,
+			Endpoint: endpoint,
+		})
+		if err != nil {
+			return fmt.Errorf("error counting push subscriptions: %w", err)
+		}
+		if count >= MaxPushSubscriptionsPerUser {
+			return ErrPushSubscriptionLimit
+		}
+
+		subscription, err = q.UpsertPushSubscription(ctx, dbgen.UpsertPushSubscriptionParams{
+			UserUuid: user.Uuid,
+			Endpoint: endpoint,
+			P256dh:   p256dh,
+			Auth:     auth,
+		})
+		if err != nil {
+			return fmt.Errorf("error saving push subscription: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dbgen.PushSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// GetPushSubscription returns a push subscription by UUID.
+func (s *Store) GetPushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) (dbgen.PushSubscription, error) {
+	subscription, err := s.queries.GetPushSubscription(ctx, subscriptionUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbgen.PushSubscription{}, ErrPushSubscriptionNotFound
+		}
+		return dbgen.PushSubscription{}, fmt.Errorf("error getting push subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// DeletePushSubscription removes the subscription with the given endpoint from the user with the given
+// email, along with any alerts still queued for it.
+func (s *Store) DeletePushSubscription(ctx context.Context, email, endpoint string) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		subscriptionUUID, err := q.DeletePushSubscriptionForEmail(ctx, dbgen.DeletePushSubscriptionForEmailParams{
+			Endpoint: endpoint,
+			Email:    email,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPushSubscriptionNotFound
+			}
+			return fmt.Errorf("error deleting push subscription: %w", err)
+		}
+
+		return cancelPushAlerts(ctx, q, subscriptionUUID)
+	})
+}
+
+// ExpirePushSubscription removes a subscription the push service reports as gone, along with any alerts
+// still queued for it. Expiring a subscription that was already removed is not an error.
+func (s *Store) ExpirePushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		if _, err := q.DeletePushSubscription(ctx, subscriptionUUID); err != nil {
+			return fmt.Errorf("error deleting push subscription: %w", err)
+		}
+
+		return cancelPushAlerts(ctx, q, subscriptionUUID)
+	})
+}
+
+func cancelPushAlerts(ctx context.Context, q *dbgen.Queries, subscriptionUUID uuid.UUID) error {
+	_, err := q.CancelPendingOutboxMessages(ctx, dbgen.CancelPendingOutboxMessagesParams{
+		Channel:   ChannelWebPush,
+		Recipient: subscriptionUUID.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error cancelling queued push alerts: %w", err)
+	}
+	return nil
+}
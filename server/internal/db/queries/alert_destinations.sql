@@ -0,0 +1,38 @@
+-- name: CreateAlertDestination :one
+INSERT INTO alert_destinations (
+  user_uuid, resort_uuid, channel, url
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: CountAlertDestinations :one
+SELECT COUNT(*)
+FROM alert_destinations
+WHERE user_uuid = $1
+  AND resort_uuid = $2;
+
+-- name: ListAlertDestinationsForAlert :many
+SELECT *
+FROM alert_destinations
+WHERE user_uuid = $1
+  AND resort_uuid = $2
+ORDER BY created_at;
+
+-- name: ListAlertDestinationsByEmail :many
+SELECT d.*
+FROM alert_destinations d
+         JOIN users u ON d.user_uuid = u.uuid
+WHERE u.email = $1
+ORDER BY d.created_at;
+
+-- name: GetAlertDestination :one
+SELECT *
+FROM alert_destinations
+WHERE uuid = $1;
+
+-- name: DeleteAlertDestination :one
+DELETE FROM alert_destinations
+WHERE uuid = $1
+  AND user_uuid = (SELECT uuid FROM users WHERE email = $2)
+RETURNING channel;
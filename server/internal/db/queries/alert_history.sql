@@ -5,14 +5,39 @@ SELECT EXISTS(SELECT 1
                 AND resort_uuid = $2
                 AND forecast_date = $3) as alert_sent;
 
+-- name: CheckBluebirdAlertSent :one
+SELECT EXISTS(SELECT 1
+              FROM alert_history ah
+              WHERE ah.user_uuid = $1
+                AND ah.resort_uuid = $2
+                AND ah.forecast_date = $3
+                AND ah.alert_kind = 'bluebird'
+              UNION ALL
+              SELECT 1
+              FROM notification_outbox o
+              WHERE o.user_uuid = $1
+                AND o.resort_uuid = $2
+                AND o.forecast_date = $3
+                AND o.alert_kind = 'bluebird') as alert_sent;
+
 -- name: GetLastAlertSnowAmount :one
 SELECT snow_amount
-FROM alert_history
-WHERE user_uuid = $1
-  AND resort_uuid = $2
-  AND forecast_date = $3
-ORDER BY sent_at DESC LIMIT 1;
+FROM (SELECT ah.snow_amount, ah.sent_at AS alerted_at
+      FROM alert_history ah
+      WHERE ah.user_uuid = $1
+        AND ah.resort_uuid = $2
+        AND ah.forecast_date = $3
+        AND ah.alert_kind <> 'bluebird'
+      UNION ALL
+      SELECT o.snow_amount, o.created_at AS alerted_at
+      FROM notification_outbox o
+      WHERE o.user_uuid = $1
+        AND o.resort_uuid = $2
+        AND o.forecast_date = $3
+        AND o.alert_kind <> 'bluebird'
+        AND o.status <> 'sent') alerts
+ORDER BY alerted_at DESC LIMIT 1;
 
 -- name: InsertAlertHistory :exec
-INSERT INTO alert_history (user_uuid, resort_uuid, forecast_date, snow_amount, sent_at)
-VALUES ($1, $2, $3, $4, NOW());
+INSERT INTO alert_history (user_uuid, resort_uuid, forecast_date, snow_amount, alert_kind, sent_at)
+VALUES ($1, $2, $3, $4, $5, NOW());
@@ -9,10 +9,12 @@ WHERE user_uuid = $1
   AND resort_uuid = $2 LIMIT 1;
 
 -- name: GetResortAlerts :many
-SELECT *
-FROM user_alerts
-WHERE resort_uuid = $1
-  and active = true;
+SELECT ua.*
+FROM user_alerts ua
+         JOIN users u ON ua.user_uuid = u.uuid
+WHERE ua.resort_uuid = $1
+  AND ua.active = true
+  AND (u.alerts_paused_until IS NULL OR u.alerts_paused_until <= NOW());
 
 -- name: UpdateUserAlert :one
 UPDATE user_alerts
@@ -58,3 +60,17 @@ WHERE user_uuid = (SELECT uuid FROM users WHERE email = $1)
 -- name: DeleteAllUserAlerts :exec
 DELETE FROM user_alerts
 WHERE user_uuid = (SELECT uuid FROM users WHERE email = $1);
+
+-- name: DeleteUserAlertForUser :execrows
+DELETE FROM user_alerts
+WHERE user_uuid = $1
+  AND resort_uuid = $2;
+
+-- name: DeleteAllAlertsForUser :execrows
+DELETE FROM user_alerts
+WHERE user_uuid = $1;
+
+-- name: CountActiveAlerts :one
+SELECT COUNT(*)
+FROM user_alerts
+WHERE active = true;
@@ -0,0 +1,70 @@
+-- name: UpsertCalendarFeed :one
+INSERT INTO calendar_feeds (user_uuid, token_hash)
+VALUES ($1, $2)
+ON CONFLICT (user_uuid) DO UPDATE
+SET token_hash = EXCLUDED.token_hash,
+    created_at = NOW()
+RETURNING *;
+
+-- name: DeleteCalendarFeedByTokenHash :execrows
+DELETE FROM calendar_feeds
+WHERE token_hash = $1;
+
+-- name: GetCalendarFeedUser :one
+SELECT u.uuid, u.units, u.locale
+FROM calendar_feeds cf
+         JOIN users u ON u.uuid = cf.user_uuid
+WHERE cf.token_hash = $1;
+
+-- name: ListCalendarEventsForUser :many
+SELECT ce.uuid,
+       ce.forecast_date,
+       ce.snow_amount,
+       ce.sequence,
+       ce.cancelled_at,
+       ce.updated_at,
+       r.name AS resort_name,
+       r.url_host,
+       r.url_pathname
+FROM calendar_events ce
+         JOIN resorts r ON r.uuid = ce.resort_uuid
+WHERE ce.user_uuid = $1
+  AND ce.forecast_date >= $2
+ORDER BY ce.forecast_date, r.name;
+
+-- name: UpsertCalendarEvents :execrows
+-- Records the forecast for every active alert on the resort it meets. checked_at is set to the transaction's
+-- start time, which CancelUncheckedCalendarEvents compares against.
+INSERT INTO calendar_events (user_uuid, resort_uuid, forecast_date, snow_amount, checked_at)
+SELECT ua.user_uuid, ua.resort_uuid, @forecast_date::date, @snow_amount::double precision, NOW()
+FROM user_alerts ua
+WHERE ua.resort_uuid = @resort_uuid::uuid
+  AND ua.active = true
+  AND ua.min_snow_amount <= @snow_amount::double precision
+  AND ua.notification_days >= @days_ahead::integer
+ON CONFLICT (user_uuid, resort_uuid, forecast_date) DO UPDATE
+SET sequence     = CASE
+                       WHEN calendar_events.snow_amount <> EXCLUDED.snow_amount
+                           OR calendar_events.cancelled_at IS NOT NULL
+                           THEN calendar_events.sequence + 1
+                       ELSE calendar_events.sequence END,
+    updated_at   = CASE
+                       WHEN calendar_events.snow_amount <> EXCLUDED.snow_amount
+                           OR calendar_events.cancelled_at IS NOT NULL
+                           THEN NOW()
+                       ELSE calendar_events.updated_at END,
+    snow_amount  = EXCLUDED.snow_amount,
+    cancelled_at = NULL,
+    checked_at   = EXCLUDED.checked_at;
+
+-- name: CancelUncheckedCalendarEvents :execrows
+-- Cancels the resort's upcoming events that UpsertCalendarEvents didn't touch in this transaction: the
+-- forecast dropped below the alert's minimum, no snow is forecast any more, or the alert was removed.
+UPDATE calendar_events
+SET cancelled_at = NOW(),
+    sequence     = sequence + 1,
+    updated_at   = NOW()
+WHERE resort_uuid = $1
+  AND forecast_date >= $2
+  AND cancelled_at IS NULL
+  AND checked_at < NOW();
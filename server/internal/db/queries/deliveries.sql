@@ -0,0 +1,40 @@
+-- name: InsertDelivery :one
+INSERT INTO notification_deliveries (
+  user_uuid, resort_uuid, channel, provider, provider_message_id, recipient, status, error_code, error_message
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: UpdateDeliveryStatus :execrows
+UPDATE notification_deliveries
+SET status        = @status,
+    error_code    = @error_code,
+    error_message = @error_message,
+    updated_at    = NOW()
+WHERE provider = @provider
+  AND provider_message_id = @provider_message_id
+  AND status = ANY(@previous_statuses::varchar[]);
+
+-- name: ListUserDeliveriesByEmail :many
+SELECT nd.*
+FROM notification_deliveries nd
+         JOIN users u ON nd.user_uuid = u.uuid
+WHERE u.email = $1
+ORDER BY nd.created_at DESC
+LIMIT $2;
+
+-- name: GetUserNotificationBudget :one
+SELECT u.max_alerts_per_day,
+       u.min_alert_spacing_minutes,
+       COUNT(nd.id)       AS sent_count,
+       MIN(nd.created_at) AS oldest_sent_at,
+       MAX(nd.created_at) AS last_sent_at
+FROM users u
+         LEFT JOIN notification_deliveries nd
+                   ON nd.user_uuid = u.uuid
+                       AND nd.status IN ('queued', 'sent', 'delivered')
+                       AND nd.channel = @channel
+                       AND nd.created_at > @since
+WHERE u.uuid = @user_uuid
+GROUP BY u.uuid, u.max_alerts_per_day, u.min_alert_spacing_minutes;
@@ -0,0 +1,99 @@
+-- name: EnqueueOutboxMessage :exec
+INSERT INTO notification_outbox (
+  user_uuid, resort_uuid, channel, recipient, forecast_date, snow_amount, is_update, alert_kind
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: ClaimOutboxMessages :many
+WITH due AS (
+    SELECT id
+    FROM notification_outbox
+    WHERE (status = 'pending' AND next_attempt_at <= NOW())
+       OR (status = 'sending' AND locked_until <= NOW())
+    ORDER BY next_attempt_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE notification_outbox o
+SET status       = 'sending',
+    attempts     = o.attempts + 1,
+    locked_until = NOW() + (@lease_seconds::int * INTERVAL '1 second'),
+    updated_at   = NOW()
+FROM due, users u, resorts r
+WHERE o.id = due.id
+  AND u.uuid = o.user_uuid
+  AND r.uuid = o.resort_uuid
+RETURNING o.uuid, o.user_uuid, u.email, u.units, u.locale, o.resort_uuid, r.name AS resort_name, r.url_host,
+          r.url_pathname, o.channel, o.recipient, o.forecast_date, o.snow_amount, o.is_update, o.alert_kind, o.attempts,
+          o.max_attempts;
+
+-- name: MarkOutboxMessageSent :one
+UPDATE notification_outbox
+SET status       = 'sent',
+    sent_at      = NOW(),
+    locked_until = NULL,
+    last_error   = NULL,
+    updated_at   = NOW()
+WHERE uuid = $1
+  AND status = 'sending'
+RETURNING user_uuid, resort_uuid, forecast_date, snow_amount, alert_kind;
+
+-- name: FailOutboxMessage :one
+UPDATE notification_outbox
+SET status          = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+    next_attempt_at = $2,
+    locked_until    = NULL,
+    last_error      = $3,
+    updated_at      = NOW()
+WHERE uuid = $1
+  AND status = 'sending'
+RETURNING status;
+
+-- name: ListOutboxMessagesByStatus :many
+SELECT *
+FROM notification_outbox
+WHERE status = $1
+ORDER BY updated_at DESC
+LIMIT $2;
+
+-- name: RequeueOutboxMessage :execrows
+UPDATE notification_outbox
+SET status          = 'pending',
+    attempts        = 0,
+    next_attempt_at = NOW(),
+    last_error      = NULL,
+    updated_at      = NOW()
+WHERE uuid = $1
+  AND status = 'dead';
+
+-- name: RequeueDeadOutboxMessages :execrows
+UPDATE notification_outbox
+SET status          = 'pending',
+    attempts        = 0,
+    next_attempt_at = NOW(),
+    last_error      = NULL,
+    updated_at      = NOW()
+WHERE status = 'dead';
+
+-- name: DeferOutboxMessages :exec
+UPDATE notification_outbox
+SET status          = 'pending',
+    attempts        = attempts - 1,
+    next_attempt_at = @next_attempt_at,
+    locked_until    = NULL,
+    updated_at      = NOW()
+WHERE uuid = ANY (@uuids::uuid[])
+  AND status = 'sending';
+
+-- name: CancelPendingOutboxMessages :execrows
+DELETE FROM notification_outbox
+WHERE channel = $1
+  AND recipient = $2
+  AND status = 'pending';
+
+-- name: CancelPendingOutboxMessagesForUser :execrows
+DELETE FROM notification_outbox
+WHERE user_uuid = @user_uuid
+  AND (sqlc.narg(resort_uuid)::uuid IS NULL OR resort_uuid = sqlc.narg(resort_uuid)::uuid)
+  AND status = 'pending';
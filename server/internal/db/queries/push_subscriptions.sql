@@ -0,0 +1,38 @@
+-- name: UpsertPushSubscription :one
+INSERT INTO push_subscriptions (
+  user_uuid, endpoint, p256dh, auth
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (endpoint) DO UPDATE
+SET user_uuid = EXCLUDED.user_uuid,
+    p256dh    = EXCLUDED.p256dh,
+    auth      = EXCLUDED.auth
+RETURNING *;
+
+-- name: CountOtherPushSubscriptionsForUser :one
+SELECT COUNT(*)
+FROM push_subscriptions
+WHERE user_uuid = $1
+  AND endpoint <> $2;
+
+-- name: ListPushSubscriptionsForUser :many
+SELECT *
+FROM push_subscriptions
+WHERE user_uuid = $1
+ORDER BY created_at;
+
+-- name: GetPushSubscription :one
+SELECT *
+FROM push_subscriptions
+WHERE uuid = $1;
+
+-- name: DeletePushSubscriptionForEmail :one
+DELETE FROM push_subscriptions
+WHERE endpoint = $1
+  AND user_uuid = (SELECT uuid FROM users WHERE email = $2)
+RETURNING uuid;
+
+-- name: DeletePushSubscription :execrows
+DELETE FROM push_subscriptions
+WHERE uuid = $1;
@@ -0,0 +1,21 @@
+-- name: CreateRateLimitBucket :exec
+INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: GetRateLimitBucketForUpdate :one
+SELECT key, tokens, updated_at, expires_at
+FROM rate_limit_buckets
+WHERE key = $1
+FOR UPDATE;
+
+-- name: UpdateRateLimitBucket :exec
+UPDATE rate_limit_buckets
+SET tokens     = $2,
+    updated_at = $3,
+    expires_at = $4
+WHERE key = $1;
+
+-- name: DeleteExpiredRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE expires_at <= $1;
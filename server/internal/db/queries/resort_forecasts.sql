@@ -0,0 +1,41 @@
+-- name: UpsertResortForecast :exec
+INSERT INTO resort_forecasts (resort_uuid, forecast_date, snow_amount, temp_min, temp_max, temp_avg)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (resort_uuid, forecast_date) DO UPDATE
+SET updated_at  = CASE
+                      WHEN resort_forecasts.snow_amount <> EXCLUDED.snow_amount THEN NOW()
+                      ELSE resort_forecasts.updated_at END,
+    snow_amount = EXCLUDED.snow_amount,
+    temp_min    = EXCLUDED.temp_min,
+    temp_max    = EXCLUDED.temp_max,
+    temp_avg    = EXCLUDED.temp_avg,
+    fetched_at  = NOW();
+
+-- name: DeleteResortForecastsBefore :execrows
+DELETE FROM resort_forecasts
+WHERE forecast_date < $1;
+
+-- name: ListForecastFeed :many
+-- Lists forecasts of at least min_snow from since onwards, most recently changed first, for one resort or,
+-- when resort_uuid is null, all of them.
+SELECT rf.resort_uuid,
+       rf.forecast_date,
+       rf.snow_amount,
+       rf.created_at,
+       rf.updated_at,
+       r.name AS resort_name,
+       r.url_host,
+       r.url_pathname
+FROM resort_forecasts rf
+         JOIN resorts r ON r.uuid = rf.resort_uuid
+WHERE rf.snow_amount >= @min_snow::double precision
+  AND rf.forecast_date >= @since::date
+  AND (sqlc.narg(resort_uuid)::uuid IS NULL OR rf.resort_uuid = sqlc.narg(resort_uuid)::uuid)
+ORDER BY rf.updated_at DESC, rf.forecast_date, r.name
+LIMIT @max_entries::integer;
+
+-- name: ListResortForecasts :many
+SELECT * FROM resort_forecasts
+WHERE resort_uuid = $1
+  AND forecast_date >= $2
+ORDER BY forecast_date;
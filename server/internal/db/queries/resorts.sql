@@ -5,3 +5,9 @@ ORDER BY name;
 -- name: GetResortByUUID :one
 SELECT * FROM resorts
 WHERE uuid = $1 LIMIT 1;
+
+-- name: CountResortSubscribers :one
+SELECT COUNT(DISTINCT user_uuid)
+FROM user_alerts
+WHERE resort_uuid = $1
+  AND active = true;
@@ -0,0 +1,6 @@
+-- name: InsertUnsubscribeEvent :exec
+INSERT INTO unsubscribe_events (
+  user_uuid, resort_uuid, channel, method, alerts_removed
+) VALUES (
+  $1, $2, $3, $4, $5
+);
@@ -13,3 +13,32 @@ WHERE email = $1 LIMIT 1;
 -- name: GetUserByUUID :one
 SELECT * FROM users
 WHERE uuid = $1 LIMIT 1;
+
+-- name: SetUserSMSOptOut :exec
+UPDATE users
+SET sms_opted_out_at = NOW()
+WHERE phone = $1;
+
+-- name: ClearUserSMSOptOut :exec
+UPDATE users
+SET sms_opted_out_at    = NULL,
+    alerts_paused_until = NULL
+WHERE phone = $1;
+
+-- name: PauseUserAlerts :exec
+UPDATE users
+SET alerts_paused_until = $2
+WHERE phone = $1;
+
+-- name: SetUserPreferences :execrows
+UPDATE users
+SET max_alerts_per_day        = $2,
+    min_alert_spacing_minutes = $3,
+    units                     = $4,
+    locale                    = $5,
+    ntfy_topic_url            = $6
+WHERE email = $1;
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users;
@@ -0,0 +1,42 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+  user_uuid, url, secret
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: CountWebhooksForUser :one
+SELECT COUNT(*)
+FROM webhooks
+WHERE user_uuid = $1;
+
+-- name: ListWebhooksForUser :many
+SELECT *
+FROM webhooks
+WHERE user_uuid = $1
+ORDER BY created_at;
+
+-- name: ListWebhooksByEmail :many
+SELECT w.*
+FROM webhooks w
+         JOIN users u ON w.user_uuid = u.uuid
+WHERE u.email = $1
+ORDER BY w.created_at;
+
+-- name: GetWebhook :one
+SELECT *
+FROM webhooks
+WHERE uuid = $1;
+
+-- name: GetWebhookForEmail :one
+SELECT w.*
+FROM webhooks w
+         JOIN users u ON w.user_uuid = u.uuid
+WHERE w.uuid = $1
+  AND u.email = $2;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE uuid = $1
+  AND user_uuid = (SELECT uuid FROM users WHERE email = $2);
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// RateLimitBucket is one of the rate limiter's token buckets.
+type RateLimitBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	// ExpiresAt is when the bucket will have refilled, after which it can be deleted.
+	ExpiresAt time.Time
+}
+
+// UpdateRateLimitBucket locks the bucket with the given key, creating it as initial if there's none, and
+// replaces it with what update returns. Concurrent updates of a bucket wait for each other, so every instance
+// sharing the database sees the tokens the others took.
+func (s *Store) UpdateRateLimitBucket(
+	ctx context.Context,
+	key string,
+	initial RateLimitBucket,
+	update func(RateLimitBucket) RateLimitBucket,
+) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		err := q.CreateRateLimitBucket(ctx, dbgen.CreateRateLimitBucketParams{
+			Key:       key,
+			Tokens:    initial.Tokens,
+			UpdatedAt: initial.UpdatedAt,
+			ExpiresAt: initial.ExpiresAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating rate limit bucket: %w", err)
+		}
+
+		row, err := q.GetRateLimitBucketForUpdate(ctx, key)
+		if err != nil {
+			return fmt.Errorf("error locking rate limit bucket: %w", err)
+		}
+
+		bucket := update(RateLimitBucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt, ExpiresAt: row.ExpiresAt})
+		err = q.UpdateRateLimitBucket(ctx, dbgen.UpdateRateLimitBucketParams{
+			Key:       key,
+			Tokens:    bucket.Tokens,
+			UpdatedAt: bucket.UpdatedAt,
+			ExpiresAt: bucket.ExpiresAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error updating rate limit bucket: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteExpiredRateLimitBuckets deletes the buckets that have refilled by now, returning how many it deleted.
+func (s *Store) DeleteExpiredRateLimitBuckets(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := s.queries.DeleteExpiredRateLimitBuckets(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired rate limit buckets: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// ResortForecast is one day of a resort's forecast, in inches and °F.
+type ResortForecast struct {
+	Date           time.Time
+	SnowAmount     float64
+	MinTemperature float64
+	MaxTemperature float64
+	AvgTemperature float64
+}
+
+// ResortDetail is a resort with its stored forecast and how many users follow it.
+type ResortDetail struct {
+	Resort dbgen.Resort
+	// SubscriberCount is the number of users with an active alert on the resort.
+	SubscriberCount int64
+	Forecasts       []ResortForecast
+	// RefreshedAt is when the forecast was last fetched, or zero if it never has been.
+	RefreshedAt time.Time
+}
+
+// ForecastFeedQuery selects the forecasts in a feed.
+type ForecastFeedQuery struct {
+	// ResortUUID limits the feed to one resort. All resorts are included when it isn't valid.
+	ResortUUID uuid.NullUUID
+	MinSnow    float64
+	Since      time.Time
+	Limit      int32
+}
+
+// ForecastFeedEntry is a resort's forecast for one day. UpdatedAt is when its snow amount last changed.
+type ForecastFeedEntry struct {
+	ResortUUID   uuid.UUID
+	ResortName   string
+	ResortURL    string
+	ForecastDate time.Time
+	SnowAmount   float64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// SaveResortForecasts stores the latest forecast for a resort, replacing the earlier forecast for each day.
+func (s *Store) SaveResortForecasts(ctx context.Context, resortUUID uuid.UUID, forecasts []ResortForecast) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		for _, forecast := range forecasts {
+			err := q.UpsertResortForecast(ctx, dbgen.UpsertResortForecastParams{
+				ResortUuid:   resortUUID,
+				ForecastDate: forecast.Date,
+				SnowAmount:   forecast.SnowAmount,
+				TempMin:      forecast.MinTemperature,
+				TempMax:      forecast.MaxTemperature,
+				TempAvg:      forecast.AvgTemperature,
+			})
+			if err != nil {
+				return fmt.Errorf("error saving forecast for resort %s: %w", resortUUID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// PruneResortForecasts deletes the stored forecasts for days before before, returning how many were deleted.
+func (s *Store) PruneResortForecasts(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := s.queries.DeleteResortForecastsBefore(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning resort forecasts: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListForecastFeed returns the stored forecasts matching query, most recently changed first.
+func (s *Store) ListForecastFeed(ctx context.Context, query ForecastFeedQuery) ([]ForecastFeedEntry, error) {
+	rows, err := s.queries.ListForecastFeed(ctx, dbgen.ListForecastFeedParams{
+		MinSnow:    query.MinSnow,
+		Since:      query.Since,
+		ResortUuid: query.ResortUUID,
+		MaxEntries: query.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing forecast feed: %w", err)
+	}
+
+	entries := make([]ForecastFeedEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, ForecastFeedEntry{
+			ResortUUID:   row.ResortUuid,
+			ResortName:   row.ResortName,
+			ResortURL:    ResortURL(row.UrlHost, row.UrlPathname),
+			ForecastDate: row.ForecastDate,
+			SnowAmount:   row.SnowAmount,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// GetResortDetail returns a resort with its stored forecast for from onwards, without calling the weather
+// provider.
+func (s *Store) GetResortDetail(ctx context.Context, resortUUID uuid.UUID, from time.Time) (ResortDetail, error) {
+	resort, err := s.queries.GetResortByUUID(ctx, resortUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ResortDetail{}, ErrResortNotFound
+		}
+		return ResortDetail{}, fmt.Errorf("error getting resort: %w", err)
+	}
+
+	subscribers, err := s.queries.CountResortSubscribers(ctx, uuid.NullUUID{UUID: resortUUID, Valid: true})
+	if err != nil {
+		return ResortDetail{}, fmt.Errorf("error counting resort subscribers: %w", err)
+	}
+
+	rows, err := s.queries.ListResortForecasts(ctx, dbgen.ListResortForecastsParams{
+		ResortUuid:   resortUUID,
+		ForecastDate: from,
+	})
+	if err != nil {
+		return ResortDetail{}, fmt.Errorf("error listing resort forecasts: %w", err)
+	}
+
+	detail := ResortDetail{
+		Resort:          resort,
+		SubscriberCount: subscribers,
+		Forecasts:       make([]ResortForecast, 0, len(rows)),
+	}
+	for _, row := range rows {
+		detail.Forecasts = append(detail.Forecasts, ResortForecast{
+			Date:           row.ForecastDate,
+			SnowAmount:     row.SnowAmount,
+			MinTemperature: row.TempMin,
+			MaxTemperature: row.TempMax,
+			AvgTemperature: row.TempAvg,
+		})
+		if row.FetchedAt.After(detail.RefreshedAt) {
+			detail.RefreshedAt = row.FetchedAt
+		}
+	}
+
+	return detail, nil
+}
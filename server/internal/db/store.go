@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/units"
 )
 
 //go:generate mockgen -destination=mocks/mock_store.go -package=mocks github.com/MattSilvaa/powhunter/internal/db StoreService
@@ -31,6 +33,45 @@ type StoreService interface {
 	// RecordAlertSent records that an alert was sent
 	RecordAlertSent(ctx context.Context, alert AlertToSend) error
 
+	// QueueAlertMatches finds alerts matching forecast criteria and queues them in the notification outbox
+	QueueAlertMatches(
+		ctx context.Context,
+		resortUUID string,
+		forecastDate time.Time,
+		predictedSnowAmount float64,
+		daysAhead int32,
+	) ([]AlertToSend, error)
+
+	// QueueBluebirdAlerts queues bluebird alerts for a clear day after snow in the notification outbox
+	QueueBluebirdAlerts(
+		ctx context.Context,
+		resortUUID string,
+		forecastDate time.Time,
+		snowAmount float64,
+		daysAhead int32,
+	) ([]AlertToSend, error)
+
+	// ClaimOutboxMessages claims due outbox messages for sending
+	ClaimOutboxMessages(ctx context.Context, batchSize int32, lease time.Duration) ([]OutboxMessage, error)
+
+	// MarkOutboxMessagesSent marks claimed outbox messages as sent and records them in the alert history
+	MarkOutboxMessagesSent(ctx context.Context, messageUUIDs []uuid.UUID) error
+
+	// DeferOutboxMessages releases claimed outbox messages to be sent later
+	DeferOutboxMessages(ctx context.Context, messageUUIDs []uuid.UUID, nextAttemptAt time.Time) error
+
+	// FailOutboxMessage schedules a retry for a claimed outbox message, or dead-letters it
+	FailOutboxMessage(ctx context.Context, messageUUID uuid.UUID, lastError string, nextAttemptAt time.Time) (bool, error)
+
+	// ListOutboxMessages returns outbox messages with the given status
+	ListOutboxMessages(ctx context.Context, status string, limit int32) ([]dbgen.NotificationOutbox, error)
+
+	// RequeueOutboxMessage moves a dead-lettered outbox message back to pending
+	RequeueOutboxMessage(ctx context.Context, messageUUID uuid.UUID) (bool, error)
+
+	// RequeueDeadOutboxMessages moves all dead-lettered outbox messages back to pending
+	RequeueDeadOutboxMessages(ctx context.Context) (int64, error)
+
 	// CreateUserWithAlerts creates a new user with alert preferences
 	CreateUserWithAlerts(
 		ctx context.Context,
@@ -48,6 +89,122 @@ type StoreService interface {
 
 	// DeleteAllUserAlerts deletes all alerts for a user
 	DeleteAllUserAlerts(ctx context.Context, email string) error
+
+	// SetSMSOptOut records whether the owner of a phone number has opted out of SMS
+	SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error
+
+	// PauseAlerts pauses all alerts for the owner of a phone number until the given time
+	PauseAlerts(ctx context.Context, phone string, until time.Time) error
+
+	// GetSMSNumber returns the phone number a user can be texted at
+	GetSMSNumber(ctx context.Context, email string) (string, error)
+
+	// RecordDelivery records an outbound message handed to a notification provider
+	RecordDelivery(ctx context.Context, delivery Delivery) error
+
+	// UpdateDeliveryStatus applies a provider status callback to a recorded delivery
+	UpdateDeliveryStatus(
+		ctx context.Context,
+		provider, providerMessageID, status, errorCode, errorMessage string,
+	) (bool, error)
+
+	// GetRecentDeliveriesByEmail returns the most recent deliveries for a user
+	GetRecentDeliveriesByEmail(ctx context.Context, email string, limit int32) ([]dbgen.NotificationDelivery, error)
+
+	// GetNotificationBudget returns a user's notification limits and the messages sent to them on a channel
+	// since a time
+	GetNotificationBudget(
+		ctx context.Context,
+		userUUID uuid.UUID,
+		channel string,
+		since time.Time,
+	) (NotificationBudget, error)
+
+	// SetPreferences replaces a user's notification preferences
+	SetPreferences(ctx context.Context, email string, prefs Preferences) error
+
+	// GetResort returns a resort by UUID
+	GetResort(ctx context.Context, resortUUID uuid.UUID) (dbgen.Resort, error)
+
+	// GetResortDetail returns a resort with its stored forecast and subscriber count
+	GetResortDetail(ctx context.Context, resortUUID uuid.UUID, from time.Time) (ResortDetail, error)
+
+	// Unsubscribe removes the alerts covered by an unsubscribe link and records the event
+	Unsubscribe(ctx context.Context, unsubscribe Unsubscribe) (int64, error)
+
+	// CreateWebhook registers a webhook for a user
+	CreateWebhook(ctx context.Context, email, url, secret string) (dbgen.Webhook, error)
+
+	// ListWebhooks returns a user's webhooks
+	ListWebhooks(ctx context.Context, email string) ([]dbgen.Webhook, error)
+
+	// GetWebhook returns a webhook by UUID
+	GetWebhook(ctx context.Context, webhookUUID uuid.UUID) (dbgen.Webhook, error)
+
+	// GetUserWebhook returns a webhook by UUID if it belongs to a user
+	GetUserWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) (dbgen.Webhook, error)
+
+	// DeleteWebhook removes a user's webhook
+	DeleteWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) error
+
+	// CreateAlertDestination adds a chat channel to post a user's alert for a resort to
+	CreateAlertDestination(
+		ctx context.Context,
+		email string,
+		resortUUID uuid.UUID,
+		channel, url string,
+	) (dbgen.AlertDestination, error)
+
+	// ListAlertDestinations returns the destinations of a user's alerts
+	ListAlertDestinations(ctx context.Context, email string) ([]dbgen.AlertDestination, error)
+
+	// GetAlertDestination returns an alert destination by UUID
+	GetAlertDestination(ctx context.Context, destinationUUID uuid.UUID) (dbgen.AlertDestination, error)
+
+	// DeleteAlertDestination removes a destination from a user's alert
+	DeleteAlertDestination(ctx context.Context, email string, destinationUUID uuid.UUID) error
+
+	// SavePushSubscription stores a browser's Web Push subscription for a user
+	SavePushSubscription(
+		ctx context.Context,
+		email string,
+		endpoint, p256dh, auth string,
+	) (dbgen.PushSubscription, error)
+
+	// GetPushSubscription returns a push subscription by UUID
+	GetPushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) (dbgen.PushSubscription, error)
+
+	// DeletePushSubscription removes a user's push subscription by endpoint
+	DeletePushSubscription(ctx context.Context, email, endpoint string) error
+
+	// ExpirePushSubscription removes a push subscription the push service no longer accepts
+	ExpirePushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error
+
+	// CreateCalendarFeed creates or replaces the token of a user's calendar feed
+	CreateCalendarFeed(ctx context.Context, email, tokenHash string) error
+
+	// DeleteCalendarFeed removes a calendar feed by token hash
+	DeleteCalendarFeed(ctx context.Context, tokenHash string) error
+
+	// GetCalendarFeed returns a calendar feed and its events by token hash
+	GetCalendarFeed(ctx context.Context, tokenHash string, since time.Time) (CalendarFeed, error)
+
+	// SyncCalendarEvents updates and cancels a resort's calendar events to match its latest forecast
+	SyncCalendarEvents(
+		ctx context.Context,
+		resortUUID uuid.UUID,
+		from time.Time,
+		forecasts []CalendarForecast,
+	) (CalendarSync, error)
+
+	// SaveResortForecasts stores the latest daily forecast for a resort
+	SaveResortForecasts(ctx context.Context, resortUUID uuid.UUID, forecasts []ResortForecast) error
+
+	// PruneResortForecasts deletes stored forecasts for days before the given date
+	PruneResortForecasts(ctx context.Context, before time.Time) (int64, error)
+
+	// ListForecastFeed returns stored forecasts for the public feeds
+	ListForecastFeed(ctx context.Context, query ForecastFeedQuery) ([]ForecastFeedEntry, error)
 }
 
 type Store struct {
@@ -58,7 +215,7 @@ type Store struct {
 func NewStore(db *sql.DB) *Store {
 	return &Store{
 		db:      db,
-		queries: dbgen.New(db),
+		queries: dbgen.New(instrumentedDB{db}),
 	}
 }
 
@@ -68,7 +225,7 @@ func (s *Store) ExecTx(ctx context.Context, fn func(*dbgen.Queries) error) error
 		return fmt.Errorf("error starting transaction: %w", err)
 	}
 
-	q := dbgen.New(tx)
+	q := dbgen.New(instrumentedDB{tx})
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -93,6 +250,24 @@ func (s *Store) ListAllResorts(ctx context.Context) ([]dbgen.Resort, error) {
 	return resorts, nil
 }
 
+// CountActiveAlerts returns the number of active alerts.
+func (s *Store) CountActiveAlerts(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountActiveAlerts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error counting active alerts: %w", err)
+	}
+	return count, nil
+}
+
+// CountUsers returns the number of users.
+func (s *Store) CountUsers(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error counting users: %w", err)
+	}
+	return count, nil
+}
+
 func (s *Store) CreateUserWithAlerts(ctx context.Context, email, phone string,
 	minSnowAmount float64, notificationDays int32, resortUUIDs []string) error {
 	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
@@ -146,14 +321,64 @@ func (s *Store) CreateUserWithAlerts(ctx context.Context, email, phone string,
 }
 
 type AlertToSend struct {
-	UserUuid     uuid.UUID
-	UserEmail    string
-	UserPhone    string
-	ResortName   string
-	ResortUUID   uuid.UUID
+	UserUuid  uuid.UUID
+	UserEmail string
+	UserPhone string
+	// UserSMSOptedOut is set when the user replied STOP, so alerts go to every destination but SMS.
+	UserSMSOptedOut bool
+	// UserNtfyTopic is the ntfy topic URL the user receives push alerts on, or empty.
+	UserNtfyTopic string
+	ResortName    string
+	ResortUUID    uuid.UUID
+	// ResortURL is the resort's snow report page, or empty if it has none.
+	ResortURL    string
 	SnowAmount   float64
 	ForecastDate time.Time
 	IsUpdate     bool
+	// Kind is why the alert is sent, one of the AlertKind constants. Empty is a powder alert.
+	Kind string
+	// Units and Locale are the user's presentation preferences. SnowAmount is always in inches.
+	Units  units.System
+	Locale string
+}
+
+// Alert kinds. Powder alerts are sent when a forecast meets an alert, and again when it grows by 3 inches.
+// A downgrade follows one when the forecast shrinks by 3 inches, and a bluebird alert announces a clear day
+// after a day whose snow meets the alert. For bluebird alerts, SnowAmount is the snow of the day before.
+const (
+	AlertKindPowder    = "powder"
+	AlertKindDowngrade = "downgrade"
+	AlertKindBluebird  = "bluebird"
+)
+
+// kind returns the alert's kind, defaulting to a powder alert.
+func (a AlertToSend) kind() string {
+	if a.Kind == "" {
+		return AlertKindPowder
+	}
+	return a.Kind
+}
+
+// ResortURL joins a resort's stored host and path into a link to its snow report. Hosts are stored with or
+// without a scheme and trailing slash, so both are normalized. It returns "" for resorts without a host.
+func ResortURL(host, pathname sql.NullString) string {
+	if !host.Valid || strings.TrimSpace(host.String) == "" {
+		return ""
+	}
+
+	link := strings.TrimRight(strings.TrimSpace(host.String), "/")
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	if path := strings.TrimSpace(pathname.String); pathname.Valid && path != "" {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		link += path
+	}
+
+	return link
 }
 
 // GetAlertMatches finds alerts that match a specific resort, date, and snow amount.
@@ -167,99 +392,184 @@ func (s *Store) GetAlertMatches(
 	var alertsToSend []AlertToSend
 
 	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
-		var ruuid uuid.NullUUID
-		if resortUUID != "" {
-			parsedUUID, err := uuid.Parse(resortUUID)
-			if err != nil {
-				return fmt.Errorf("error parsing resort UUID %s: %w", resortUUID, err)
-			}
-			ruuid = uuid.NullUUID{UUID: parsedUUID, Valid: true}
-		} else {
-			ruuid = uuid.NullUUID{Valid: false}
+		var err error
+		alertsToSend, err = findAlertMatches(ctx, q, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return alertsToSend, nil
+}
+
+// findAlertMatches returns the alerts a forecast should trigger: the first alert for a user, resort and
+// forecast date, an update when the forecast has grown by at least 3 inches since the last alert, or a
+// downgrade when it has shrunk by at least 3 inches.
+func findAlertMatches(
+	ctx context.Context,
+	q *dbgen.Queries,
+	resortUUID string,
+	forecastDate time.Time,
+	predictedSnowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	var alertsToSend []AlertToSend
+
+	ruuid, err := parseResortUUID(resortUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := q.GetResortAlerts(ctx, ruuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting alert for resort %s: %w", resortUUID, err)
+	}
+
+	for _, alert := range alerts {
+		// Only process alerts where the forecast is within the user's notification window
+		if daysAhead > alert.NotificationDays {
+			continue
+		}
+
+		kind, isUpdate := AlertKindPowder, true
+		lastAlertSnowAmount, err := q.GetLastAlertSnowAmount(ctx, dbgen.GetLastAlertSnowAmountParams{
+			UserUuid:     alert.UserUuid,
+			ResortUuid:   ruuid,
+			ForecastDate: forecastDate,
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// Alert if no alert has ever been sent to this user
+			isUpdate = false
+		case err != nil:
+			return nil, fmt.Errorf("error getting latest alert for resort %s: %w", resortUUID, err)
+		case lastAlertSnowAmount-predictedSnowAmount >= 3:
+			// Tell the user when the snow they were alerted about shrinks by 3 inches or more, even when it
+			// no longer meets their minimum
+			kind = AlertKindDowngrade
+		case predictedSnowAmount-lastAlertSnowAmount < 3:
+			// Only alert again when the new snow amount is greater than or equal to 3 inches
+			continue
 		}
 
-		alerts, err := q.GetResortAlerts(ctx, ruuid)
+		// Only send powder alerts where the predicted snow meets the user's minimum threshold
+		if kind == AlertKindPowder && predictedSnowAmount < alert.MinSnowAmount {
+			continue
+		}
+
+		alertToSend, err := newAlertToSend(ctx, q, alert, forecastDate, predictedSnowAmount)
 		if err != nil {
-			return fmt.Errorf("error getting alert for resort %s: %w", resortUUID, err)
+			return nil, err
 		}
+		alertToSend.IsUpdate = isUpdate
+		alertToSend.Kind = kind
+		alertsToSend = append(alertsToSend, alertToSend)
+	}
 
-		for _, alert := range alerts {
-			// Only process alerts where the forecast is within the user's notification window
-			if daysAhead > alert.NotificationDays {
-				continue
-			}
+	return alertsToSend, nil
+}
 
-			// Only process alerts where the predicted snow meets the user's minimum threshold
-			if predictedSnowAmount < alert.MinSnowAmount {
-				continue
-			}
+// findBluebirdMatches returns the bluebird alerts a clear day after snowAmount inches of snow should
+// trigger: one per user, resort and day, for each alert the snow meets whose notification window covers
+// the day.
+func findBluebirdMatches(
+	ctx context.Context,
+	q *dbgen.Queries,
+	resortUUID string,
+	forecastDate time.Time,
+	snowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	var alertsToSend []AlertToSend
 
-			lastAlertSnowAmount, err := q.GetLastAlertSnowAmount(ctx, dbgen.GetLastAlertSnowAmountParams{
-				UserUuid:     alert.UserUuid,
-				ResortUuid:   ruuid,
-				ForecastDate: forecastDate,
-			})
-			if err != nil {
-				// Alert if no alert has ever been sent to this user
-				if errors.Is(err, sql.ErrNoRows) {
-					userToAlert, err := q.GetUserByUUID(ctx, alert.UserUuid.UUID)
-					if err != nil {
-						return fmt.Errorf("error getting user %s: %w", alert.UserUuid.UUID.String(), err)
-					}
-
-					resortToAlertUserOn, err := q.GetResortByUUID(ctx, alert.ResortUuid.UUID)
-					if err != nil {
-						return fmt.Errorf("error getting resort %s: %w", alert.ResortUuid.UUID.String(), err)
-					}
-
-					alertsToSend = append(alertsToSend, AlertToSend{
-						UserUuid:     userToAlert.Uuid,
-						UserEmail:    userToAlert.Email,
-						UserPhone:    userToAlert.Phone.String,
-						ResortName:   resortToAlertUserOn.Name,
-						ResortUUID:   resortToAlertUserOn.Uuid,
-						SnowAmount:   predictedSnowAmount,
-						ForecastDate: forecastDate,
-						IsUpdate:     false,
-					})
-				} else {
-					return fmt.Errorf("error getting latest alert for resort %s: %w", resortUUID, err)
-				}
-			}
-			// Alert when the new snow amount is greater than or equal to 3 inches
-			if predictedSnowAmount-lastAlertSnowAmount >= 3 {
-				userToAlert, err := q.GetUserByUUID(ctx, alert.UserUuid.UUID)
-				if err != nil {
-					return fmt.Errorf("error getting user %s: %w", alert.UserUuid.UUID.String(), err)
-				}
+	ruuid, err := parseResortUUID(resortUUID)
+	if err != nil {
+		return nil, err
+	}
 
-				resortToAlertUserOn, err := q.GetResortByUUID(ctx, alert.ResortUuid.UUID)
-				if err != nil {
-					return fmt.Errorf("error getting resort %s: %w", alert.ResortUuid.UUID.String(), err)
-				}
+	alerts, err := q.GetResortAlerts(ctx, ruuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting alert for resort %s: %w", resortUUID, err)
+	}
 
-				alertsToSend = append(alertsToSend, AlertToSend{
-					UserUuid:     userToAlert.Uuid,
-					UserEmail:    userToAlert.Email,
-					UserPhone:    userToAlert.Phone.String,
-					ResortName:   resortToAlertUserOn.Name,
-					ResortUUID:   resortToAlertUserOn.Uuid,
-					SnowAmount:   predictedSnowAmount,
-					ForecastDate: forecastDate,
-					IsUpdate:     true,
-				})
-			}
+	for _, alert := range alerts {
+		if daysAhead > alert.NotificationDays || snowAmount < alert.MinSnowAmount {
+			continue
 		}
-		return nil
-	})
 
-	if err != nil {
-		return nil, err
+		sent, err := q.CheckBluebirdAlertSent(ctx, dbgen.CheckBluebirdAlertSentParams{
+			UserUuid:     alert.UserUuid,
+			ResortUuid:   ruuid,
+			ForecastDate: forecastDate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error checking bluebird alert for resort %s: %w", resortUUID, err)
+		}
+		if sent {
+			continue
+		}
+
+		alertToSend, err := newAlertToSend(ctx, q, alert, forecastDate, snowAmount)
+		if err != nil {
+			return nil, err
+		}
+		alertToSend.Kind = AlertKindBluebird
+		alertsToSend = append(alertsToSend, alertToSend)
 	}
 
 	return alertsToSend, nil
 }
 
+// parseResortUUID parses the UUID of the resort alerts are matched for. An empty UUID matches no resort.
+func parseResortUUID(resortUUID string) (uuid.NullUUID, error) {
+	if resortUUID == "" {
+		return uuid.NullUUID{Valid: false}, nil
+	}
+
+	parsedUUID, err := uuid.Parse(resortUUID)
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("error parsing resort UUID %s: %w", resortUUID, err)
+	}
+	return uuid.NullUUID{UUID: parsedUUID, Valid: true}, nil
+}
+
+// newAlertToSend returns the alert to send for a user's alert at a resort, with the user's destinations
+// and preferences.
+func newAlertToSend(
+	ctx context.Context,
+	q *dbgen.Queries,
+	alert dbgen.UserAlert,
+	forecastDate time.Time,
+	snowAmount float64,
+) (AlertToSend, error) {
+	userToAlert, err := q.GetUserByUUID(ctx, alert.UserUuid.UUID)
+	if err != nil {
+		return AlertToSend{}, fmt.Errorf("error getting user %s: %w", alert.UserUuid.UUID.String(), err)
+	}
+
+	resortToAlertUserOn, err := q.GetResortByUUID(ctx, alert.ResortUuid.UUID)
+	if err != nil {
+		return AlertToSend{}, fmt.Errorf("error getting resort %s: %w", alert.ResortUuid.UUID.String(), err)
+	}
+
+	return AlertToSend{
+		UserUuid:        userToAlert.Uuid,
+		UserEmail:       userToAlert.Email,
+		UserPhone:       userToAlert.Phone.String,
+		UserSMSOptedOut: userToAlert.SmsOptedOutAt.Valid,
+		UserNtfyTopic:   userToAlert.NtfyTopicUrl.String,
+		ResortName:      resortToAlertUserOn.Name,
+		ResortUUID:      resortToAlertUserOn.Uuid,
+		ResortURL:       ResortURL(resortToAlertUserOn.UrlHost, resortToAlertUserOn.UrlPathname),
+		SnowAmount:      snowAmount,
+		ForecastDate:    forecastDate,
+		Units:           units.System(userToAlert.Units),
+		Locale:          userToAlert.Locale,
+	}, nil
+}
+
 // RecordAlertSent records that an alert was sent to avoid sending duplicates.
 func (s *Store) RecordAlertSent(ctx context.Context, alert AlertToSend) error {
 	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
@@ -268,6 +578,7 @@ func (s *Store) RecordAlertSent(ctx context.Context, alert AlertToSend) error {
 			ResortUuid:   uuid.NullUUID{UUID: alert.ResortUUID, Valid: true},
 			ForecastDate: alert.ForecastDate,
 			SnowAmount:   alert.SnowAmount,
+			AlertKind:    alert.kind(),
 		})
 
 		return err
@@ -308,3 +619,71 @@ func (s *Store) DeleteAllUserAlerts(ctx context.Context, email string) error {
 	}
 	return nil
 }
+
+// SetSMSOptOut records an SMS opt-out (STOP) or opt-in (START) for every user with the given phone number.
+// Opting out also cancels the SMS alerts still queued for the number, including those held by the user's
+// limits or waiting to be retried, since carriers forbid messaging a number after it replies STOP. Opting
+// back in also resumes paused alerts.
+func (s *Store) SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error {
+	phoneParam := sql.NullString{String: phone, Valid: true}
+
+	if optedOut {
+		return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+			if err := q.SetUserSMSOptOut(ctx, phoneParam); err != nil {
+				return fmt.Errorf("error opting out phone number: %w", err)
+			}
+			return cancelQueuedSMS(ctx, q, phone)
+		})
+	}
+
+	if err := s.queries.ClearUserSMSOptOut(ctx, phoneParam); err != nil {
+		return fmt.Errorf("error opting in phone number: %w", err)
+	}
+	return nil
+}
+
+// ErrNoSMSNumber is returned when a user has no phone number to text, or has opted out of SMS.
+var ErrNoSMSNumber = errors.New("user has no phone number to text")
+
+// GetSMSNumber returns the phone number the user with the given email can be texted at. It returns
+// ErrNoSMSNumber when they have none or have replied STOP.
+func (s *Store) GetSMSNumber(ctx context.Context, email string) (string, error) {
+	user, err := s.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("error getting user: %w", err)
+	}
+	if user.Phone.String == "" || user.SmsOptedOutAt.Valid {
+		return "", ErrNoSMSNumber
+	}
+	return user.Phone.String, nil
+}
+
+// PauseAlerts pauses alerts for every user with the given phone number until the given time. SMS alerts
+// already queued for the number are cancelled, so they aren't sent during the pause.
+func (s *Store) PauseAlerts(ctx context.Context, phone string, until time.Time) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		err := q.PauseUserAlerts(ctx, dbgen.PauseUserAlertsParams{
+			Phone:             sql.NullString{String: phone, Valid: true},
+			AlertsPausedUntil: sql.NullTime{Time: until, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("error pausing alerts: %w", err)
+		}
+		return cancelQueuedSMS(ctx, q, phone)
+	})
+}
+
+// cancelQueuedSMS removes the SMS alerts queued for a phone number that haven't been claimed for sending.
+func cancelQueuedSMS(ctx context.Context, q *dbgen.Queries, phone string) error {
+	_, err := q.CancelPendingOutboxMessages(ctx, dbgen.CancelPendingOutboxMessagesParams{
+		Channel:   ChannelSMS,
+		Recipient: phone,
+	})
+	if err != nil {
+		return fmt.Errorf("error cancelling queued SMS alerts: %w", err)
+	}
+	return nil
+}
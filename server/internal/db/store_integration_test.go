@@ -1,15 +1,17 @@
+//go:build integration
 // +build integration
 
-package db
+package db_test
 
 import (
 	"context"
 	"testing"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/MattSilvaa/powhunter/internal/db"
 	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
 	"github.com/MattSilvaa/powhunter/internal/testutil"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -125,7 +127,7 @@ func TestStoreIntegration_GetAlertMatches(t *testing.T) {
 		forecastDate := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
 
 		// Record first alert
-		firstMatch := AlertToSend{
+		firstMatch := db.AlertToSend{
 			UserUuid:     user.Uuid,
 			UserEmail:    user.Email,
 			UserPhone:    user.Phone.String,
@@ -159,7 +161,7 @@ func TestStoreIntegration_GetAlertMatches(t *testing.T) {
 		forecastDate := time.Now().Add(48 * time.Hour).Truncate(24 * time.Hour)
 
 		// Record first alert
-		firstMatch := AlertToSend{
+		firstMatch := db.AlertToSend{
 			UserUuid:     user.Uuid,
 			UserEmail:    user.Email,
 			UserPhone:    user.Phone.String,
@@ -185,6 +187,72 @@ func TestStoreIntegration_GetAlertMatches(t *testing.T) {
 	})
 }
 
+func TestStoreIntegration_QueueAlertMatchesDowngrades(t *testing.T) {
+	testDB, store, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := dbgen.New(testDB)
+	resort := testutil.SeedTestResort(t, queries, "Test Resort", 39.6403, -106.3742)
+	user := testutil.SeedTestUser(t, queries, "downgrade@example.com", "+15551234567")
+	testutil.SeedTestAlert(t, queries, user.Uuid, resort.Uuid, 5.0, 3)
+
+	forecastDate := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	matches, err := store.QueueAlertMatches(ctx, resort.Uuid.String(), forecastDate, 10.0, 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, db.AlertKindPowder, matches[0].Kind)
+
+	matches, err = store.QueueAlertMatches(ctx, resort.Uuid.String(), forecastDate, 8.0, 1)
+	require.NoError(t, err)
+	assert.Empty(t, matches, "a drop of less than 3 inches isn't a downgrade")
+
+	matches, err = store.QueueAlertMatches(ctx, resort.Uuid.String(), forecastDate, 3.0, 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "downgrades are sent below the alert's minimum")
+	assert.Equal(t, db.AlertKindDowngrade, matches[0].Kind)
+	assert.True(t, matches[0].IsUpdate)
+
+	claimed, err := store.ClaimOutboxMessages(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.ElementsMatch(t, []string{db.AlertKindPowder, db.AlertKindDowngrade},
+		[]string{claimed[0].Alert.Kind, claimed[1].Alert.Kind})
+}
+
+func TestStoreIntegration_QueueBluebirdAlerts(t *testing.T) {
+	testDB, store, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := dbgen.New(testDB)
+	resort := testutil.SeedTestResort(t, queries, "Test Resort", 39.6403, -106.3742)
+	user := testutil.SeedTestUser(t, queries, "bluebird@example.com", "+15551234567")
+	testutil.SeedTestAlert(t, queries, user.Uuid, resort.Uuid, 5.0, 3)
+
+	clearDay := time.Now().Add(48 * time.Hour).Truncate(24 * time.Hour)
+
+	matches, err := store.QueueBluebirdAlerts(ctx, resort.Uuid.String(), clearDay, 3.0, 2)
+	require.NoError(t, err)
+	assert.Empty(t, matches, "the snow before must meet the alert's minimum")
+
+	matches, err = store.QueueBluebirdAlerts(ctx, resort.Uuid.String(), clearDay, 8.0, 2)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, db.AlertKindBluebird, matches[0].Kind)
+	assert.Equal(t, 8.0, matches[0].SnowAmount)
+
+	matches, err = store.QueueBluebirdAlerts(ctx, resort.Uuid.String(), clearDay, 8.0, 2)
+	require.NoError(t, err)
+	assert.Empty(t, matches, "each day gets one bluebird alert")
+
+	matches, err = store.QueueAlertMatches(ctx, resort.Uuid.String(), clearDay, 6.0, 2)
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "a bluebird alert isn't compared with the day's own forecast")
+	assert.Equal(t, db.AlertKindPowder, matches[0].Kind)
+	assert.False(t, matches[0].IsUpdate)
+}
+
 func TestStoreIntegration_RecordAlertSent(t *testing.T) {
 	testDB, store, cleanup := testutil.SetupTestDB(t)
 	defer cleanup()
@@ -199,7 +267,7 @@ func TestStoreIntegration_RecordAlertSent(t *testing.T) {
 		ctx := context.Background()
 		forecastDate := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
 
-		alertToSend := AlertToSend{
+		alertToSend := db.AlertToSend{
 			UserUuid:     user.Uuid,
 			UserEmail:    user.Email,
 			UserPhone:    user.Phone.String,
@@ -251,3 +319,131 @@ func TestStoreIntegration_ListAllResorts(t *testing.T) {
 		assert.Contains(t, names, "Resort C")
 	})
 }
+
+func TestStoreIntegration_SMSRepliesCancelQueuedSMS(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply func(ctx context.Context, store *db.Store, phone string) error
+	}{
+		{
+			name: "STOP",
+			reply: func(ctx context.Context, store *db.Store, phone string) error {
+				return store.SetSMSOptOut(ctx, phone, true)
+			},
+		},
+		{
+			name: "PAUSE",
+			reply: func(ctx context.Context, store *db.Store, phone string) error {
+				return store.PauseAlerts(ctx, phone, time.Now().Add(72*time.Hour))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDB, store, cleanup := testutil.SetupTestDB(t)
+			defer cleanup()
+
+			ctx := context.Background()
+			queries := dbgen.New(testDB)
+			resort := testutil.SeedTestResort(t, queries, "Test Resort", 39.6403, -106.3742)
+			user := testutil.SeedTestUser(t, queries, "reply@example.com", "+15551234567")
+
+			for _, message := range []struct{ channel, recipient string }{
+				{db.ChannelSMS, user.Phone.String},
+				{db.ChannelPush, "powhunter-reply"},
+			} {
+				require.NoError(t, queries.EnqueueOutboxMessage(ctx, dbgen.EnqueueOutboxMessageParams{
+					UserUuid:     user.Uuid,
+					ResortUuid:   resort.Uuid,
+					Channel:      message.channel,
+					Recipient:    message.recipient,
+					ForecastDate: time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour),
+					SnowAmount:   8,
+				}))
+			}
+
+			require.NoError(t, tt.reply(ctx, store, user.Phone.String))
+
+			pending, err := store.ListOutboxMessages(ctx, db.OutboxStatusPending, 50)
+			require.NoError(t, err)
+			require.Len(t, pending, 1, "the queued SMS is cancelled")
+			assert.Equal(t, db.ChannelPush, pending[0].Channel)
+		})
+	}
+}
+
+func TestStoreIntegration_QueueAlertMatchesSkipsOnlySMSAfterOptOut(t *testing.T) {
+	testDB, store, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := dbgen.New(testDB)
+	resort := testutil.SeedTestResort(t, queries, "Test Resort", 39.6403, -106.3742)
+	user := testutil.SeedTestUser(t, queries, "optout@example.com", "+15551234567")
+	testutil.SeedTestAlert(t, queries, user.Uuid, resort.Uuid, 5.0, 3)
+	_, err := testDB.ExecContext(ctx, "UPDATE users SET ntfy_topic_url = $1 WHERE uuid = $2",
+		"https://ntfy.sh/powhunter-optout", user.Uuid)
+	require.NoError(t, err)
+	require.NoError(t, store.SetSMSOptOut(ctx, user.Phone.String, true))
+
+	forecastDate := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	matches, err := store.QueueAlertMatches(ctx, resort.Uuid.String(), forecastDate, 8.0, 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "opting out of SMS doesn't stop alerts")
+
+	pending, err := store.ListOutboxMessages(ctx, db.OutboxStatusPending, 50)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, db.ChannelPush, pending[0].Channel)
+}
+
+func TestStoreIntegration_UnsubscribeCancelsQueuedAlerts(t *testing.T) {
+	tests := []struct {
+		name        string
+		oneResort   bool
+		wantPending int
+	}{
+		{name: "one resort", oneResort: true, wantPending: 1},
+		{name: "all resorts", oneResort: false, wantPending: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDB, store, cleanup := testutil.SetupTestDB(t)
+			defer cleanup()
+
+			ctx := context.Background()
+			queries := dbgen.New(testDB)
+			resort1 := testutil.SeedTestResort(t, queries, "Test Resort 1", 39.6403, -106.3742)
+			resort2 := testutil.SeedTestResort(t, queries, "Test Resort 2", 39.4817, -106.0384)
+			user := testutil.SeedTestUser(t, queries, "unsubscribe@example.com", "+15551234567")
+
+			for _, resort := range []dbgen.Resort{resort1, resort2} {
+				testutil.SeedTestAlert(t, queries, user.Uuid, resort.Uuid, 5.0, 3)
+				require.NoError(t, queries.EnqueueOutboxMessage(ctx, dbgen.EnqueueOutboxMessageParams{
+					UserUuid:     user.Uuid,
+					ResortUuid:   resort.Uuid,
+					Channel:      db.ChannelSMS,
+					Recipient:    user.Phone.String,
+					ForecastDate: time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour),
+					SnowAmount:   8,
+				}))
+			}
+
+			unsubscribe := db.Unsubscribe{UserUUID: user.Uuid, Method: db.UnsubscribeMethodLink}
+			if tt.oneResort {
+				unsubscribe.ResortUUID = uuid.NullUUID{UUID: resort1.Uuid, Valid: true}
+			}
+			_, err := store.Unsubscribe(ctx, unsubscribe)
+			require.NoError(t, err)
+
+			pending, err := store.ListOutboxMessages(ctx, db.OutboxStatusPending, 50)
+			require.NoError(t, err)
+			require.Len(t, pending, tt.wantPending)
+			for _, message := range pending {
+				assert.Equal(t, resort2.Uuid, message.ResortUuid, "alerts for other resorts are still sent")
+			}
+		})
+	}
+}
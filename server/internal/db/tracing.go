@@ -0,0 +1,430 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+)
+
+// tracedStore starts a span around each call to the store it wraps, named after the method called and
+// marked failed when the call returns an error.
+type tracedStore struct {
+	store StoreService
+}
+
+// Traced returns store with a span started around each of its calls, as a child of the span in the context
+// it's called with.
+func Traced(store StoreService) StoreService {
+	return tracedStore{store: store}
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "StoreService."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")),
+	)
+}
+
+func (s tracedStore) ListAllResorts(ctx context.Context) ([]dbgen.Resort, error) {
+	ctx, span := startSpan(ctx, "ListAllResorts")
+	result, err := s.store.ListAllResorts(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetAlertMatches(
+	ctx context.Context,
+	resortUUID string,
+	forecastDate time.Time,
+	predictedSnowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	ctx, span := startSpan(ctx, "GetAlertMatches")
+	result, err := s.store.GetAlertMatches(ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) RecordAlertSent(ctx context.Context, alert AlertToSend) error {
+	ctx, span := startSpan(ctx, "RecordAlertSent")
+	err := s.store.RecordAlertSent(ctx, alert)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) QueueAlertMatches(
+	ctx context.Context,
+	resortUUID string,
+	forecastDate time.Time,
+	predictedSnowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	ctx, span := startSpan(ctx, "QueueAlertMatches")
+	result, err := s.store.QueueAlertMatches(ctx, resortUUID, forecastDate, predictedSnowAmount, daysAhead)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) QueueBluebirdAlerts(
+	ctx context.Context,
+	resortUUID string,
+	forecastDate time.Time,
+	snowAmount float64,
+	daysAhead int32,
+) ([]AlertToSend, error) {
+	ctx, span := startSpan(ctx, "QueueBluebirdAlerts")
+	result, err := s.store.QueueBluebirdAlerts(ctx, resortUUID, forecastDate, snowAmount, daysAhead)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) ClaimOutboxMessages(
+	ctx context.Context,
+	batchSize int32,
+	lease time.Duration,
+) ([]OutboxMessage, error) {
+	ctx, span := startSpan(ctx, "ClaimOutboxMessages")
+	result, err := s.store.ClaimOutboxMessages(ctx, batchSize, lease)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) MarkOutboxMessagesSent(ctx context.Context, messageUUIDs []uuid.UUID) error {
+	ctx, span := startSpan(ctx, "MarkOutboxMessagesSent")
+	err := s.store.MarkOutboxMessagesSent(ctx, messageUUIDs)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) DeferOutboxMessages(ctx context.Context, messageUUIDs []uuid.UUID, nextAttemptAt time.Time) error {
+	ctx, span := startSpan(ctx, "DeferOutboxMessages")
+	err := s.store.DeferOutboxMessages(ctx, messageUUIDs, nextAttemptAt)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) FailOutboxMessage(
+	ctx context.Context,
+	messageUUID uuid.UUID,
+	lastError string,
+	nextAttemptAt time.Time,
+) (bool, error) {
+	ctx, span := startSpan(ctx, "FailOutboxMessage")
+	result, err := s.store.FailOutboxMessage(ctx, messageUUID, lastError, nextAttemptAt)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) ListOutboxMessages(
+	ctx context.Context,
+	status string,
+	limit int32,
+) ([]dbgen.NotificationOutbox, error) {
+	ctx, span := startSpan(ctx, "ListOutboxMessages")
+	result, err := s.store.ListOutboxMessages(ctx, status, limit)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) RequeueOutboxMessage(ctx context.Context, messageUUID uuid.UUID) (bool, error) {
+	ctx, span := startSpan(ctx, "RequeueOutboxMessage")
+	result, err := s.store.RequeueOutboxMessage(ctx, messageUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) RequeueDeadOutboxMessages(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "RequeueDeadOutboxMessages")
+	result, err := s.store.RequeueDeadOutboxMessages(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) CreateUserWithAlerts(
+	ctx context.Context,
+	email, phone string,
+	minSnowAmount float64,
+	notificationDays int32,
+	resortUUIDs []string,
+) error {
+	ctx, span := startSpan(ctx, "CreateUserWithAlerts")
+	err := s.store.CreateUserWithAlerts(ctx, email, phone, minSnowAmount, notificationDays, resortUUIDs)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) GetUserAlertsByEmail(ctx context.Context, email string) ([]dbgen.GetUserAlertsByEmailRow, error) {
+	ctx, span := startSpan(ctx, "GetUserAlertsByEmail")
+	result, err := s.store.GetUserAlertsByEmail(ctx, email)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) DeleteUserAlert(ctx context.Context, email, resortUuid string) error {
+	ctx, span := startSpan(ctx, "DeleteUserAlert")
+	err := s.store.DeleteUserAlert(ctx, email, resortUuid)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) DeleteAllUserAlerts(ctx context.Context, email string) error {
+	ctx, span := startSpan(ctx, "DeleteAllUserAlerts")
+	err := s.store.DeleteAllUserAlerts(ctx, email)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error {
+	ctx, span := startSpan(ctx, "SetSMSOptOut")
+	err := s.store.SetSMSOptOut(ctx, phone, optedOut)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) PauseAlerts(ctx context.Context, phone string, until time.Time) error {
+	ctx, span := startSpan(ctx, "PauseAlerts")
+	err := s.store.PauseAlerts(ctx, phone, until)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) GetSMSNumber(ctx context.Context, email string) (string, error) {
+	ctx, span := startSpan(ctx, "GetSMSNumber")
+	phone, err := s.store.GetSMSNumber(ctx, email)
+	tracing.End(span, err)
+	return phone, err
+}
+
+func (s tracedStore) RecordDelivery(ctx context.Context, delivery Delivery) error {
+	ctx, span := startSpan(ctx, "RecordDelivery")
+	err := s.store.RecordDelivery(ctx, delivery)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) UpdateDeliveryStatus(
+	ctx context.Context,
+	provider, providerMessageID, status, errorCode, errorMessage string,
+) (bool, error) {
+	ctx, span := startSpan(ctx, "UpdateDeliveryStatus")
+	result, err := s.store.UpdateDeliveryStatus(ctx, provider, providerMessageID, status, errorCode, errorMessage)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetRecentDeliveriesByEmail(
+	ctx context.Context,
+	email string,
+	limit int32,
+) ([]dbgen.NotificationDelivery, error) {
+	ctx, span := startSpan(ctx, "GetRecentDeliveriesByEmail")
+	result, err := s.store.GetRecentDeliveriesByEmail(ctx, email, limit)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetNotificationBudget(
+	ctx context.Context,
+	userUUID uuid.UUID,
+	channel string,
+	since time.Time,
+) (NotificationBudget, error) {
+	ctx, span := startSpan(ctx, "GetNotificationBudget")
+	result, err := s.store.GetNotificationBudget(ctx, userUUID, channel, since)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) SetPreferences(ctx context.Context, email string, prefs Preferences) error {
+	ctx, span := startSpan(ctx, "SetPreferences")
+	err := s.store.SetPreferences(ctx, email, prefs)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) GetResort(ctx context.Context, resortUUID uuid.UUID) (dbgen.Resort, error) {
+	ctx, span := startSpan(ctx, "GetResort")
+	result, err := s.store.GetResort(ctx, resortUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetResortDetail(ctx context.Context, resortUUID uuid.UUID, from time.Time) (ResortDetail, error) {
+	ctx, span := startSpan(ctx, "GetResortDetail")
+	result, err := s.store.GetResortDetail(ctx, resortUUID, from)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) Unsubscribe(ctx context.Context, unsubscribe Unsubscribe) (int64, error) {
+	ctx, span := startSpan(ctx, "Unsubscribe")
+	result, err := s.store.Unsubscribe(ctx, unsubscribe)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) CreateWebhook(ctx context.Context, email, url, secret string) (dbgen.Webhook, error) {
+	ctx, span := startSpan(ctx, "CreateWebhook")
+	result, err := s.store.CreateWebhook(ctx, email, url, secret)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) ListWebhooks(ctx context.Context, email string) ([]dbgen.Webhook, error) {
+	ctx, span := startSpan(ctx, "ListWebhooks")
+	result, err := s.store.ListWebhooks(ctx, email)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetWebhook(ctx context.Context, webhookUUID uuid.UUID) (dbgen.Webhook, error) {
+	ctx, span := startSpan(ctx, "GetWebhook")
+	result, err := s.store.GetWebhook(ctx, webhookUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetUserWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) (dbgen.Webhook, error) {
+	ctx, span := startSpan(ctx, "GetUserWebhook")
+	result, err := s.store.GetUserWebhook(ctx, email, webhookUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) DeleteWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "DeleteWebhook")
+	err := s.store.DeleteWebhook(ctx, email, webhookUUID)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) CreateAlertDestination(
+	ctx context.Context,
+	email string,
+	resortUUID uuid.UUID,
+	channel, url string,
+) (dbgen.AlertDestination, error) {
+	ctx, span := startSpan(ctx, "CreateAlertDestination")
+	result, err := s.store.CreateAlertDestination(ctx, email, resortUUID, channel, url)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) ListAlertDestinations(ctx context.Context, email string) ([]dbgen.AlertDestination, error) {
+	ctx, span := startSpan(ctx, "ListAlertDestinations")
+	result, err := s.store.ListAlertDestinations(ctx, email)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetAlertDestination(
+	ctx context.Context,
+	destinationUUID uuid.UUID,
+) (dbgen.AlertDestination, error) {
+	ctx, span := startSpan(ctx, "GetAlertDestination")
+	result, err := s.store.GetAlertDestination(ctx, destinationUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) DeleteAlertDestination(ctx context.Context, email string, destinationUUID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "DeleteAlertDestination")
+	err := s.store.DeleteAlertDestination(ctx, email, destinationUUID)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) SavePushSubscription(
+	ctx context.Context,
+	email string,
+	endpoint, p256dh, auth string,
+) (dbgen.PushSubscription, error) {
+	ctx, span := startSpan(ctx, "SavePushSubscription")
+	result, err := s.store.SavePushSubscription(ctx, email, endpoint, p256dh, auth)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) GetPushSubscription(
+	ctx context.Context,
+	subscriptionUUID uuid.UUID,
+) (dbgen.PushSubscription, error) {
+	ctx, span := startSpan(ctx, "GetPushSubscription")
+	result, err := s.store.GetPushSubscription(ctx, subscriptionUUID)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) DeletePushSubscription(ctx context.Context, email, endpoint string) error {
+	ctx, span := startSpan(ctx, "DeletePushSubscription")
+	err := s.store.DeletePushSubscription(ctx, email, endpoint)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) ExpirePushSubscription(ctx context.Context, subscriptionUUID uuid.UUID) error {
+	ctx, span := startSpan(ctx, "ExpirePushSubscription")
+	err := s.store.ExpirePushSubscription(ctx, subscriptionUUID)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) CreateCalendarFeed(ctx context.Context, email, tokenHash string) error {
+	ctx, span := startSpan(ctx, "CreateCalendarFeed")
+	err := s.store.CreateCalendarFeed(ctx, email, tokenHash)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) DeleteCalendarFeed(ctx context.Context, tokenHash string) error {
+	ctx, span := startSpan(ctx, "DeleteCalendarFeed")
+	err := s.store.DeleteCalendarFeed(ctx, tokenHash)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) GetCalendarFeed(ctx context.Context, tokenHash string, since time.Time) (CalendarFeed, error) {
+	ctx, span := startSpan(ctx, "GetCalendarFeed")
+	result, err := s.store.GetCalendarFeed(ctx, tokenHash, since)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) SyncCalendarEvents(
+	ctx context.Context,
+	resortUUID uuid.UUID,
+	from time.Time,
+	forecasts []CalendarForecast,
+) (CalendarSync, error) {
+	ctx, span := startSpan(ctx, "SyncCalendarEvents")
+	result, err := s.store.SyncCalendarEvents(ctx, resortUUID, from, forecasts)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) SaveResortForecasts(ctx context.Context, resortUUID uuid.UUID, forecasts []ResortForecast) error {
+	ctx, span := startSpan(ctx, "SaveResortForecasts")
+	err := s.store.SaveResortForecasts(ctx, resortUUID, forecasts)
+	tracing.End(span, err)
+	return err
+}
+
+func (s tracedStore) PruneResortForecasts(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "PruneResortForecasts")
+	result, err := s.store.PruneResortForecasts(ctx, before)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (s tracedStore) ListForecastFeed(ctx context.Context, query ForecastFeedQuery) ([]ForecastFeedEntry, error) {
+	ctx, span := startSpan(ctx, "ListForecastFeed")
+	result, err := s.store.ListForecastFeed(ctx, query)
+	tracing.End(span, err)
+	return result, err
+}
@@ -0,0 +1,53 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/testutil"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+)
+
+func TestTraced(t *testing.T) {
+	recorder := testutil.RecordSpans(t)
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	resortUUID := uuid.New()
+	mockStore.EXPECT().GetResort(gomock.Any(), resortUUID).DoAndReturn(
+		func(ctx context.Context, _ uuid.UUID) (dbgen.Resort, error) {
+			assert.True(t, trace.SpanContextFromContext(ctx).IsValid(), "the store is called in the span")
+			return dbgen.Resort{Name: "Alta"}, nil
+		})
+	mockStore.EXPECT().DeleteAllUserAlerts(gomock.Any(), "user@example.com").Return(errors.New("connection refused"))
+
+	store := db.Traced(mockStore)
+	ctx, parent := tracing.Start(context.Background(), "request")
+	resort, err := store.GetResort(ctx, resortUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alta", resort.Name)
+	assert.EqualError(t, store.DeleteAllUserAlerts(ctx, "user@example.com"), "connection refused")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "StoreService.GetResort", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "StoreService.DeleteAllUserAlerts", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	for _, span := range spans[:2] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// Unsubscribe methods, recorded with each unsubscribe event.
+const (
+	// UnsubscribeMethodLink is an unsubscribe confirmed on the page an unsubscribe link opens.
+	UnsubscribeMethodLink = "link"
+	// UnsubscribeMethodOneClick is an RFC 8058 List-Unsubscribe=One-Click POST from a mail client.
+	UnsubscribeMethodOneClick = "one_click"
+)
+
+// ErrResortNotFound is returned when no resort matches the given UUID.
+var ErrResortNotFound = errors.New("resort not found")
+
+// Unsubscribe removes a user's alert for one resort, or all of their alerts when ResortUUID is not set.
+type Unsubscribe struct {
+	UserUUID   uuid.UUID
+	ResortUUID uuid.NullUUID
+	// Channel is the channel the link was sent on, if known.
+	Channel string
+	Method  string
+}
+
+// Unsubscribe removes the alerts an unsubscribe link covers, cancels any of their alerts still queued to be
+// sent, and records the event. It returns the number of alerts removed, which is zero when the link has
+// already been used.
+func (s *Store) Unsubscribe(ctx context.Context, unsubscribe Unsubscribe) (int64, error) {
+	var removed int64
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		if _, err := q.GetUserByUUID(ctx, unsubscribe.UserUUID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		userUUID := uuid.NullUUID{UUID: unsubscribe.UserUUID, Valid: true}
+
+		var err error
+		if unsubscribe.ResortUUID.Valid {
+			removed, err = q.DeleteUserAlertForUser(ctx, dbgen.DeleteUserAlertForUserParams{
+				UserUuid:   userUUID,
+				ResortUuid: unsubscribe.ResortUUID,
+			})
+		} else {
+			removed, err = q.DeleteAllAlertsForUser(ctx, userUUID)
+		}
+		if err != nil {
+			return fmt.Errorf("error removing alerts: %w", err)
+		}
+
+		// Alerts already queued for the removed alerts, including ones held or waiting to be retried, would
+		// still be sent after the user asked to stop.
+		_, err = q.CancelPendingOutboxMessagesForUser(ctx, dbgen.CancelPendingOutboxMessagesForUserParams{
+			UserUuid:   unsubscribe.UserUUID,
+			ResortUuid: unsubscribe.ResortUUID,
+		})
+		if err != nil {
+			return fmt.Errorf("error cancelling queued alerts: %w", err)
+		}
+
+		err = q.InsertUnsubscribeEvent(ctx, dbgen.InsertUnsubscribeEventParams{
+			UserUuid:      unsubscribe.UserUUID,
+			ResortUuid:    unsubscribe.ResortUUID,
+			Channel:       sql.NullString{String: unsubscribe.Channel, Valid: unsubscribe.Channel != ""},
+			Method:        unsubscribe.Method,
+			AlertsRemoved: int32(removed),
+		})
+		if err != nil {
+			return fmt.Errorf("error recording unsubscribe: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// GetResort returns a resort by UUID.
+func (s *Store) GetResort(ctx context.Context, resortUUID uuid.UUID) (dbgen.Resort, error) {
+	resort, err := s.queries.GetResortByUUID(ctx, resortUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbgen.Resort{}, ErrResortNotFound
+		}
+		return dbgen.Resort{}, fmt.Errorf("error getting resort: %w", err)
+	}
+	return resort, nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// ChannelWebhook delivers alerts as signed JSON POSTs to URLs users register. Its outbox recipient is the
+// webhook's UUID, so changes to the webhook apply to messages already queued.
+const ChannelWebhook = "webhook"
+
+// MaxWebhooksPerUser is how many webhooks one user can register.
+const MaxWebhooksPerUser = 5
+
+var (
+	// ErrWebhookNotFound is returned when no webhook matches the given UUID, or it belongs to another user.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookLimit is returned when a user already has MaxWebhooksPerUser webhooks.
+	ErrWebhookLimit = errors.New("webhook limit reached")
+)
+
+// CreateWebhook registers a webhook for the user with the given email.
+func (s *Store) CreateWebhook(ctx context.Context, email, url, secret string) (dbgen.Webhook, error) {
+	var webhook dbgen.Webhook
+
+	err := s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		user, err := q.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("error getting user: %w", err)
+		}
+
+		count, err := q.CountWebhooksForUser(ctx, user.Uuid)
+		if err != nil {
+			return fmt.Errorf("error counting webhooks: %w", err)
+		}
+		if count >= MaxWebhooksPerUser {
+			return ErrWebhookLimit
+		}
+
+		webhook, err = q.CreateWebhook(ctx, dbgen.CreateWebhookParams{
+			UserUuid: user.Uuid,
+			Url:      url,
+			Secret:   secret,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating webhook: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dbgen.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks registered by the user with the given email, oldest first.
+func (s *Store) ListWebhooks(ctx context.Context, email string) ([]dbgen.Webhook, error) {
+	webhooks, err := s.queries.ListWebhooksByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook returns a webhook by UUID.
+func (s *Store) GetWebhook(ctx context.Context, webhookUUID uuid.UUID) (dbgen.Webhook, error) {
+	webhook, err := s.queries.GetWebhook(ctx, webhookUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbgen.Webhook{}, ErrWebhookNotFound
+		}
+		return dbgen.Webhook{}, fmt.Errorf("error getting webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetUserWebhook returns a webhook by UUID if it belongs to the user with the given email.
+func (s *Store) GetUserWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) (dbgen.Webhook, error) {
+	webhook, err := s.queries.GetWebhookForEmail(ctx, dbgen.GetWebhookForEmailParams{
+		Uuid:  webhookUUID,
+		Email: email,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dbgen.Webhook{}, ErrWebhookNotFound
+		}
+		return dbgen.Webhook{}, fmt.Errorf("error getting webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook belonging to the user with the given email, along with any alerts still
+// queued for it.
+func (s *Store) DeleteWebhook(ctx context.Context, email string, webhookUUID uuid.UUID) error {
+	return s.ExecTx(ctx, func(q *dbgen.Queries) error {
+		deleted, err := q.DeleteWebhook(ctx, dbgen.DeleteWebhookParams{
+			Uuid:  webhookUUID,
+			Email: email,
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting webhook: %w", err)
+		}
+		if deleted == 0 {
+			return ErrWebhookNotFound
+		}
+
+		_, err = q.CancelPendingOutboxMessages(ctx, dbgen.CancelPendingOutboxMessagesParams{
+			Channel:   ChannelWebhook,
+			Recipient: webhookUUID.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("error cancelling queued webhook alerts: %w", err)
+		}
+
+		return nil
+	})
+}
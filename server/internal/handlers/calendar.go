@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/calendar"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+)
+
+const (
+	// CalendarFeedPrefix is the path feeds are served under, followed by the token and ".ics".
+	CalendarFeedPrefix = "/api/calendar/"
+
+	// calendarHistoryDays is how long past powder days stay in a feed. Clients delete events that drop out
+	// of it.
+	calendarHistoryDays = 30
+
+	calendarFeedName = "Pow Hunter powder days"
+)
+
+// CalendarHandler serves each user's iCalendar feed of forecast powder days at their resorts. Feeds are
+// fetched by calendar apps without a login, so each is protected by a random token in its URL. Anyone can
+// ask for a feed by email, so the URL is only ever texted to the user's phone.
+type CalendarHandler struct {
+	store   db.StoreService
+	sms     notify.NotificationService
+	baseURL string
+	now     func() time.Time
+}
+
+type CreateCalendarRequest struct {
+	Email string `json:"email" validate:"email,max=254"`
+}
+
+// NewCalendarHandler returns a handler building feed URLs on baseURL and texting them with sms, which may be
+// nil when SMS isn't configured.
+func NewCalendarHandler(
+	store db.StoreService,
+	sms notify.NotificationService,
+	baseURL string,
+) (*CalendarHandler, error) {
+	return &CalendarHandler{
+		store:   store,
+		sms:     sms,
+		baseURL: baseURL,
+		now:     time.Now,
+	}, nil
+}
+
+// CreateCalendar texts the URL of a new calendar feed to the user's phone, then saves the feed, replacing
+// the URL of any feed they have so the old one stops working. Anyone can post an email, so the response is
+// the same whether or not the email has an account with a number to text, and the URL is never returned.
+func (h *CalendarHandler) CreateCalendar(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	if h.sms == nil {
+		sendError(w, r, apierror.SMSNotConfigured, "Text messages are not available")
+		return
+	}
+
+	var req CreateCalendarRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.createCalendar(ctx, req.Email); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to create calendar", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	response := apiv1.NewStatus("If that email has alerts and a phone number we can text, its calendar link is on its way")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode calendar response", "error", err)
+	}
+}
+
+// createCalendar texts a new feed's URL to the user with the given email and saves the feed. Users without a
+// number to text are skipped.
+func (h *CalendarHandler) createCalendar(ctx context.Context, email string) error {
+	to, err := h.store.GetSMSNumber(ctx, email)
+	if errors.Is(err, db.ErrUserNotFound) || errors.Is(err, db.ErrNoSMSNumber) {
+		slog.InfoContext(ctx, "Not texting calendar link", "reason", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := calendar.NewToken()
+	if err != nil {
+		return fmt.Errorf("failed to create calendar token: %w", err)
+	}
+
+	// The link is texted before the token is saved, so the feed the user subscribed to keeps working when
+	// the text can't be sent.
+	feedURL := h.baseURL + CalendarFeedPrefix + token + ".ics"
+	if _, err := h.sms.SendSMS(to, formatCalendarMessage(feedURL)); err != nil {
+		return fmt.Errorf("failed to text calendar link: %w", err)
+	}
+
+	return h.store.CreateCalendarFeed(ctx, email, calendar.HashToken(token))
+}
+
+// formatCalendarMessage formats the text carrying a feed's URL. The webcal:// version opens the calendar app
+// directly when tapped.
+func formatCalendarMessage(feedURL string) string {
+	webcalURL := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(feedURL, "https://"), "http://")
+	return "Powhunter: Add your powder days to your calendar: " + webcalURL +
+		" Anyone with this link can see them, so keep it private."
+}
+
+// DeleteCalendar removes a calendar feed, at its own URL, so that only someone holding the URL can remove it.
+func (h *CalendarHandler) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	token, ok := feedToken(r)
+	if !ok {
+		sendError(w, r, apierror.CalendarNotFound, "Calendar not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.DeleteCalendarFeed(ctx, calendar.HashToken(token)); err != nil {
+		sendStoreError(w, r, err, "Failed to delete calendar")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// feedToken returns the token of the feed requested at /api/calendar/{token}.ics.
+func feedToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	return token, ok && token != ""
+}
+
+// ServeFeed serves a calendar feed, at /api/calendar/{token}.ics.
+func (h *CalendarHandler) ServeFeed(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	token, ok := feedToken(r)
+	if !ok {
+		sendError(w, r, apierror.CalendarNotFound, "Calendar not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	since := h.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -calendarHistoryDays)
+	feed, err := h.store.GetCalendarFeed(ctx, calendar.HashToken(token), since)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve calendar")
+		return
+	}
+
+	w.Header().Set("Content-Type", calendar.ContentType)
+	w.Header().Set("Content-Disposition", `inline; filename="powhunter.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if err := newCalendarFeed(feed).Encode(w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write calendar feed", "error", err)
+	}
+}
+
+// newCalendarFeed describes a user's powder days in their units and locale.
+func newCalendarFeed(feed db.CalendarFeed) calendar.Feed {
+	events := make([]calendar.Event, 0, len(feed.Events))
+	for _, event := range feed.Events {
+		snow := notify.FormatSnow(event.SnowAmount, feed.Units, feed.Locale)
+
+		summary := "Powder day: " + event.ResortName + ", " + snow
+		description := snow + " of snow forecast at " + event.ResortName + "."
+		if event.Cancelled {
+			summary = "Cancelled: " + summary
+			description = "The forecast at " + event.ResortName + " no longer meets your alert."
+		}
+		if event.ResortURL != "" {
+			description += "\nSnow report: " + event.ResortURL
+		}
+
+		events = append(events, calendar.Event{
+			UID:         event.UUID.String() + "@powhunter.app",
+			Date:        event.ForecastDate,
+			Summary:     summary,
+			Description: description,
+			URL:         event.ResortURL,
+			Sequence:    event.Sequence,
+			Cancelled:   event.Cancelled,
+			Modified:    event.UpdatedAt,
+		})
+	}
+
+	return calendar.Feed{Name: calendarFeedName, Events: events}
+}
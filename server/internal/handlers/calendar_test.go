@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/calendar"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	notifymocks "github.com/MattSilvaa/powhunter/internal/notify/mocks"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+func TestCalendarHandler_HandleCalendar(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		setupMock      func(*mocks.MockStoreService)
+		setupSMS       func(*notifymocks.MockNotificationService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+	}{
+		{
+			name:   "Creates a feed and texts its URL",
+			method: http.MethodPost,
+			target: "/api/v1/me/calendar",
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("+12065550100", nil)
+				m.EXPECT().CreateCalendarFeed(gomock.Any(), "test@example.com", gomock.Any()).Return(nil)
+			},
+			setupSMS: func(m *notifymocks.MockNotificationService) {
+				m.EXPECT().SendSMS("+12065550100", gomock.Any()).Return("SM123", nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Missing email",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/calendar",
+			body:           `{}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_EMAIL",
+				Message: "Email is required",
+			},
+		},
+		{
+			name:   "Unknown user",
+			method: http.MethodPost,
+			target: "/api/v1/me/calendar",
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("", db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "User who can't be texted",
+			method: http.MethodPost,
+			target: "/api/v1/me/calendar",
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("", db.ErrNoSMSNumber)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "Texting fails, leaving the feed unchanged",
+			method: http.MethodPost,
+			target: "/api/v1/me/calendar",
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("+12065550100", nil)
+			},
+			setupSMS: func(m *notifymocks.MockNotificationService) {
+				m.EXPECT().SendSMS("+12065550100", gomock.Any()).Return("", errors.New("twilio unavailable"))
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "Saving the feed fails after texting",
+			method: http.MethodPost,
+			target: "/api/v1/me/calendar",
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("+12065550100", nil)
+				m.EXPECT().CreateCalendarFeed(gomock.Any(), "test@example.com", gomock.Any()).
+					Return(errors.New("connection refused"))
+			},
+			setupSMS: func(m *notifymocks.MockNotificationService) {
+				m.EXPECT().SendSMS("+12065550100", gomock.Any()).Return("SM123", nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "Deletes a feed",
+			method: http.MethodDelete,
+			target: "/api/calendar/dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu.ics",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().DeleteCalendarFeed(gomock.Any(), calendar.HashToken("dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu")).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Deleting a missing feed",
+			method: http.MethodDelete,
+			target: "/api/calendar/dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu.ics",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().DeleteCalendarFeed(gomock.Any(), calendar.HashToken("dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu")).
+					Return(db.ErrCalendarNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "CALENDAR_NOT_FOUND",
+				Message: "Calendar not found",
+			},
+		},
+		{
+			name:           "Deleting without a token",
+			method:         http.MethodDelete,
+			target:         "/api/calendar/feed",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "CALENDAR_NOT_FOUND",
+				Message: "Calendar not found",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodGet,
+			target:         "/api/v1/me/calendar",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+			mockSMS := notifymocks.NewMockNotificationService(ctrl)
+			if tt.setupSMS != nil {
+				tt.setupSMS(mockSMS)
+			}
+
+			handler, err := NewCalendarHandler(mockStore, mockSMS, "https://api.powhunter.app")
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Calendar: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+		})
+	}
+}
+
+func TestCalendarHandler_TextsFeedURLMatchingStoredHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockSMS := notifymocks.NewMockNotificationService(ctrl)
+
+	var storedHash, message string
+	mockStore.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return("+12065550100", nil)
+	sent := mockSMS.EXPECT().SendSMS("+12065550100", gomock.Any()).DoAndReturn(func(_, text string) (string, error) {
+		message = text
+		return "SM123", nil
+	})
+	mockStore.EXPECT().CreateCalendarFeed(gomock.Any(), "test@example.com", gomock.Any()).After(sent).DoAndReturn(
+		func(_ context.Context, _ string, tokenHash string) error {
+			storedHash = tokenHash
+			return nil
+		})
+
+	handler, err := NewCalendarHandler(mockStore, mockSMS, "https://api.powhunter.app")
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Calendar: handler}, rr, httptest.NewRequest(
+		http.MethodPost, "/api/v1/me/calendar", strings.NewReader(`{"email":"test@example.com"}`)))
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	assert.NotContains(t, rr.Body.String(), "/api/calendar/", "the feed URL is never returned")
+	var response apiv1.Status
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.NotContains(t, response.Message, "0100", "the number texted isn't returned")
+
+	_, rest, ok := strings.Cut(message, "webcal://api.powhunter.app/api/calendar/")
+	require.True(t, ok, message)
+	token, _, ok := strings.Cut(rest, ".ics")
+	require.True(t, ok, message)
+	assert.Equal(t, calendar.HashToken(token), storedHash, "only the token's hash is stored")
+}
+
+// TestCalendarHandler_AnswersEveryEmailTheSame checks that posting an email doesn't tell whether it has an
+// account or a number to text.
+func TestCalendarHandler_AnswersEveryEmailTheSame(t *testing.T) {
+	tests := []struct {
+		name      string
+		smsNumber string
+		err       error
+	}{
+		{name: "Texted", smsNumber: "+12065550100"},
+		{name: "Unknown user", err: db.ErrUserNotFound},
+		{name: "No number to text", err: db.ErrNoSMSNumber},
+	}
+
+	var bodies []string
+	for _, tt := range tests {
+		ctrl := gomock.NewController(t)
+		mockStore := mocks.NewMockStoreService(ctrl)
+		mockSMS := notifymocks.NewMockNotificationService(ctrl)
+		mockStore.EXPECT().GetSMSNumber(gomock.Any(), "test@example.com").Return(tt.smsNumber, tt.err)
+		if tt.err == nil {
+			mockSMS.EXPECT().SendSMS(tt.smsNumber, gomock.Any()).Return("SM123", nil)
+			mockStore.EXPECT().CreateCalendarFeed(gomock.Any(), "test@example.com", gomock.Any()).Return(nil)
+		}
+
+		handler, err := NewCalendarHandler(mockStore, mockSMS, "https://api.powhunter.app")
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		serve(t, &Handlers{Calendar: handler}, rr, httptest.NewRequest(
+			http.MethodPost, "/api/v1/me/calendar", strings.NewReader(`{"email":"test@example.com"}`)))
+		require.Equal(t, http.StatusAccepted, rr.Code, tt.name)
+		bodies = append(bodies, rr.Body.String())
+	}
+
+	for i := range bodies[1:] {
+		assert.Equal(t, bodies[0], bodies[i+1], tests[i+1].name)
+	}
+}
+
+func TestCalendarHandler_SMSNotConfigured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler, err := NewCalendarHandler(mocks.NewMockStoreService(ctrl), nil, "https://api.powhunter.app")
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Calendar: handler}, rr, httptest.NewRequest(
+		http.MethodPost, "/api/v1/me/calendar", strings.NewReader(`{"email":"test@example.com"}`)))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), "SMS_NOT_CONFIGURED")
+}
+
+func TestCalendarHandler_ServeFeed(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 12, 18, 5, 0, 0, 0, time.UTC)
+	token := "dGhpcyBpcyBub3QgYSByZWFsIHRva2VuIGF0IGFsbA"
+
+	feed := db.CalendarFeed{
+		UserUUID: uuid.New(),
+		Units:    units.Metric,
+		Locale:   "en-CA",
+		Events: []db.CalendarEvent{
+			{
+				UUID:         uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"),
+				ResortName:   "Crystal Mountain",
+				ResortURL:    "https://www.crystalmountainresort.com",
+				ForecastDate: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+				SnowAmount:   8.5,
+				Sequence:     1,
+				UpdatedAt:    updated,
+			},
+			{
+				UUID:         uuid.MustParse("1d8ddb3d-3839-473d-a8b7-89015d961d25"),
+				ResortName:   "Stevens Pass",
+				ForecastDate: time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC),
+				SnowAmount:   6,
+				Sequence:     2,
+				Cancelled:    true,
+				UpdatedAt:    updated,
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedLines  []string
+	}{
+		{
+			name:   "Serves the feed",
+			method: http.MethodGet,
+			target: "/api/calendar/" + token + ".ics",
+			setupMock: func(m *mocks.MockStoreService) {
+				since := time.Date(2025, 11, 18, 0, 0, 0, 0, time.UTC)
+				m.EXPECT().GetCalendarFeed(gomock.Any(), calendar.HashToken(token), since).Return(feed, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLines: []string{
+				"UID:9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d@powhunter.app",
+				"DTSTART;VALUE=DATE:20251220",
+				`SUMMARY:Powder day: Crystal Mountain\, 22 cm`,
+				`DESCRIPTION:22 cm of snow forecast at Crystal Mountain.\nSnow report: https`,
+				"URL:https://www.crystalmountainresort.com",
+				"STATUS:CONFIRMED",
+				"UID:1d8ddb3d-3839-473d-a8b7-89015d961d25@powhunter.app",
+				"SEQUENCE:2",
+				`SUMMARY:Cancelled: Powder day: Stevens Pass\, 15 cm`,
+				"STATUS:CANCELLED",
+			},
+		},
+		{
+			name:   "Unknown token",
+			method: http.MethodGet,
+			target: "/api/calendar/" + token + ".ics",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetCalendarFeed(gomock.Any(), calendar.HashToken(token), gomock.Any()).
+					Return(db.CalendarFeed{}, db.ErrCalendarNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Missing extension",
+			method:         http.MethodGet,
+			target:         "/api/calendar/" + token,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Store error",
+			method: http.MethodGet,
+			target: "/api/calendar/" + token + ".ics",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetCalendarFeed(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(db.CalendarFeed{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			target:         "/api/calendar/" + token + ".ics",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewCalendarHandler(mockStore, nil, "https://api.powhunter.app")
+			require.NoError(t, err)
+			handler.now = func() time.Time { return now }
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Calendar: handler}, rr, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+			if tt.expectedLines == nil {
+				return
+			}
+
+			assert.Equal(t, calendar.ContentType, rr.Header().Get("Content-Type"))
+			lines := strings.Split(rr.Body.String(), "\r\n")
+			for _, line := range tt.expectedLines {
+				assert.Contains(t, lines, line)
+			}
+		})
+	}
+}
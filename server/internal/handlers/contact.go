@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/resend/resend-go/v2"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
 )
 
 type ContactHandler struct{}
@@ -20,70 +23,55 @@ func NewContactHandler() (*ContactHandler, error) {
 }
 
 type ContactRequest struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Message string `json:"message"`
+	Name    string `json:"name" validate:"max=100"`
+	Email   string `json:"email" validate:"email,max=254"`
+	Message string `json:"message" validate:"max=5000"`
 }
 
 func (h *ContactHandler) HandleContact(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		sendErrorResponse(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	setSecurityHeaders(w)
 
 	var req ContactRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendErrorResponse(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Validate required fields
 	if strings.TrimSpace(req.Name) == "" {
-		sendErrorResponse(w, "MISSING_NAME", "Name is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingName, "Name is required")
 		return
 	}
 
 	if strings.TrimSpace(req.Email) == "" {
-		sendErrorResponse(w, "MISSING_EMAIL", "Email is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingEmail, "Email is required")
 		return
 	}
 
 	if strings.TrimSpace(req.Message) == "" {
-		sendErrorResponse(w, "MISSING_MESSAGE", "Message is required", http.StatusBadRequest)
-		return
-	}
-
-	// Basic email validation
-	if !strings.Contains(req.Email, "@") || !strings.Contains(req.Email, ".") {
-		sendErrorResponse(w, "INVALID_EMAIL", "Invalid email address", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingMessage, "Message is required")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	// Log the contact message
-	log.Printf("Contact form submission from %s (%s): %s", req.Name, req.Email, req.Message)
+	// The sender and their message are personal data, redacted unless LOG_PII is set.
+	slog.InfoContext(ctx, "Contact form submission", "name", req.Name, "email", req.Email, "message", req.Message)
 
 	// TODO: Send email notification
 	// For now, we'll just log it and optionally write to a file
 	if err := h.recordContactMessage(ctx, req); err != nil {
-		log.Printf("Failed to record contact message: %v", err)
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to process contact message", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Failed to record contact message", "error", err)
+		sendError(w, r, apierror.InternalError, "Failed to process contact message")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Thank you for contacting us! We'll get back to you soon.",
-	})
+	err := json.NewEncoder(w).Encode(apiv1.NewStatus("Thank you for contacting us! We'll get back to you soon."))
 
 	if err != nil {
-		log.Printf("Failed to write response: %v", err)
+		slog.ErrorContext(r.Context(), "Failed to write response", "error", err)
 		return
 	}
 }
@@ -111,7 +99,7 @@ func (h *ContactHandler) recordContactMessage(ctx context.Context, req ContactRe
 
 	// Send email notification
 	if err := h.sendContactEmail(ctx, req); err != nil {
-		log.Printf("Warning: Failed to send contact email notification: %v", err)
+		slog.WarnContext(ctx, "Failed to send contact email notification", "error", err)
 		// We don't return error here to not block the request if email fails
 	}
 
@@ -122,7 +110,7 @@ func (h *ContactHandler) sendContactEmail(ctx context.Context, req ContactReques
 	// Get Resend API key from environment
 	apiKey := os.Getenv("RESEND_API_KEY")
 	if apiKey == "" {
-		log.Println("RESEND_API_KEY not configured, skipping email send")
+		slog.WarnContext(ctx, "RESEND_API_KEY not configured, skipping email send")
 		return nil
 	}
 
@@ -152,6 +140,6 @@ func (h *ContactHandler) sendContactEmail(ctx context.Context, req ContactReques
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
-	log.Printf("Contact email sent to support@powhunter.app from %s (%s) - ID: %s", req.Name, req.Email, sent.Id)
+	slog.InfoContext(ctx, "Contact email sent to support@powhunter.app", "email", req.Email, "email_id", sent.Id)
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/MattSilvaa/powhunter/internal/validate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -107,8 +108,9 @@ func TestContactHandler_HandleContact(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedError: &ErrorResponse{
-				Error:   "INVALID_EMAIL",
-				Message: "Invalid email address",
+				Error:   "VALIDATION_ERROR",
+				Message: "Data validation failed",
+				Fields:  validate.Errors{{Field: "email", Rule: "email", Message: "must be an email address"}},
 			},
 		},
 		{
@@ -138,11 +140,11 @@ func TestContactHandler_HandleContact(t *testing.T) {
 				require.NoError(t, err, "Failed to encode request body")
 			}
 
-			req := httptest.NewRequest(tt.method, "/api/contact", &body)
+			req := httptest.NewRequest(tt.method, "/api/v1/contact", &body)
 			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
-			handler.HandleContact(rr, req)
+			serve(t, &Handlers{Contact: handler}, rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
 
@@ -174,11 +176,11 @@ func TestContactHandler_SecurityHeaders(t *testing.T) {
 	body, err := json.Marshal(requestBody)
 	require.NoError(t, err)
 
-	req := httptest.NewRequest(http.MethodPost, "/api/contact", bytes.NewReader(body))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/contact", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
-	handler.HandleContact(rr, req)
+	serve(t, &Handlers{Contact: handler}, rr, req)
 
 	expectedHeaders := map[string]string{
 		"X-Content-Type-Options": "nosniff",
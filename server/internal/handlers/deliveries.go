@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+type DeliveryHandler struct {
+	store db.StoreService
+}
+
+func NewDeliveryHandler(store db.StoreService) (*DeliveryHandler, error) {
+	return &DeliveryHandler{
+		store: store,
+	}, nil
+}
+
+// GetUserDeliveries returns the most recent notifications sent to a user and their delivery status.
+func (h *DeliveryHandler) GetUserDeliveries(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 || parsed > maxDeliveriesLimit {
+			sendError(w, r, apierror.InvalidLimit, "Limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	deliveries, err := h.store.GetRecentDeliveriesByEmail(ctx, email, int32(limit))
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve deliveries")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(apiv1.NewDeliveries(deliveries)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode deliveries response", "error", err)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliveryHandler_GetUserDeliveries(t *testing.T) {
+	deliveryUUID := uuid.New()
+	resortUUID := uuid.New()
+	createdAt := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+
+	stored := dbgen.NotificationDelivery{
+		ID:                1,
+		Uuid:              deliveryUUID,
+		UserUuid:          uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ResortUuid:        uuid.NullUUID{UUID: resortUUID, Valid: true},
+		Channel:           "sms",
+		Provider:          "twilio",
+		ProviderMessageID: sql.NullString{String: "SM123", Valid: true},
+		Recipient:         "+12065550100",
+		Status:            "undelivered",
+		ErrorCode:         sql.NullString{String: "30006", Valid: true},
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt.Add(time.Minute),
+	}
+	push := dbgen.NotificationDelivery{
+		ID:                2,
+		Uuid:              uuid.New(),
+		UserUuid:          stored.UserUuid,
+		Channel:           "push",
+		Provider:          "ntfy",
+		ProviderMessageID: sql.NullString{String: "ntfy123", Valid: true},
+		Recipient:         "https://ntfy.sh/powhunter-secret-topic",
+		Status:            "sent",
+		CreatedAt:         createdAt,
+		UpdatedAt:         createdAt,
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		query            string
+		setupMock        func(*mocks.MockStoreService)
+		expectedStatus   int
+		expectedResponse []apiv1.Delivery
+		expectedError    *ErrorResponse
+	}{
+		{
+			name:   "Success with default limit, masking the number and leaving out other recipients",
+			method: http.MethodGet,
+			query:  "?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetRecentDeliveriesByEmail(gomock.Any(), "test@example.com", int32(20)).
+					Return([]dbgen.NotificationDelivery{stored, push}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: []apiv1.Delivery{
+				{
+					UUID:       deliveryUUID.String(),
+					ResortUUID: resortUUID.String(),
+					Channel:    "sms",
+					Recipient:  "+1••••••0100",
+					Status:     "undelivered",
+					ErrorCode:  "30006",
+					CreatedAt:  createdAt,
+					UpdatedAt:  createdAt.Add(time.Minute),
+				},
+				{
+					UUID:      push.Uuid.String(),
+					Channel:   "push",
+					Status:    "sent",
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				},
+			},
+		},
+		{
+			name:   "Custom limit and no deliveries",
+			method: http.MethodGet,
+			query:  "?email=test@example.com&limit=5",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetRecentDeliveriesByEmail(gomock.Any(), "test@example.com", int32(5)).
+					Return(nil, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: []apiv1.Delivery{},
+		},
+		{
+			name:           "Invalid limit",
+			method:         http.MethodGet,
+			query:          "?email=test@example.com&limit=500",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_LIMIT",
+				Message: "Limit must be between 1 and 100",
+			},
+		},
+		{
+			name:           "Missing email",
+			method:         http.MethodGet,
+			query:          "",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_EMAIL",
+				Message: "Email parameter is required",
+			},
+		},
+		{
+			name:   "Store error",
+			method: http.MethodGet,
+			query:  "?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetRecentDeliveriesByEmail(gomock.Any(), "test@example.com", int32(20)).
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to retrieve deliveries",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			query:          "?email=test@example.com",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewDeliveryHandler(mockStore)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, "/api/v1/me/deliveries"+tt.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Delivery: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+				return
+			}
+
+			var response []apiv1.Delivery
+			err = json.NewDecoder(rr.Body).Decode(&response)
+			require.NoError(t, err, "Failed to decode response body")
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
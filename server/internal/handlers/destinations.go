@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+)
+
+// DestinationHandler lets users post an alert to Slack or Discord channels as well as their own channels.
+type DestinationHandler struct {
+	store db.StoreService
+}
+
+type CreateDestinationRequest struct {
+	Email      string `json:"email" validate:"email,max=254"`
+	ResortUUID string `json:"resort_uuid" validate:"uuid"`
+	// Channel is "slack" or "discord".
+	Channel string `json:"channel"`
+	// URL is the channel's incoming webhook URL.
+	URL string `json:"url" validate:"max=2048"`
+}
+
+func NewDestinationHandler(store db.StoreService) (*DestinationHandler, error) {
+	return &DestinationHandler{
+		store: store,
+	}, nil
+}
+
+// CreateDestination adds a Slack or Discord destination to one of a user's alerts.
+func (h *DestinationHandler) CreateDestination(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	var req CreateDestinationRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	resortUUID, err := uuid.Parse(req.ResortUUID)
+	if err != nil {
+		sendError(w, r, apierror.MissingResort, "Resort UUID is required")
+		return
+	}
+
+	if req.Channel != db.ChannelSlack && req.Channel != db.ChannelDiscord {
+		sendError(w, r, apierror.InvalidChannel, "Channel must be slack or discord")
+		return
+	}
+
+	if err := notify.ValidateChatWebhookURL(req.Channel, req.URL); err != nil {
+		sendError(w, r, apierror.InvalidURL, "Webhook URL is not valid: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	destination, err := h.store.CreateAlertDestination(ctx, req.Email, resortUUID, req.Channel, req.URL)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to add destination")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(apiv1.NewDestination(destination)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode destination response", "error", err)
+	}
+}
+
+// ListDestinations lists a user's destinations.
+func (h *DestinationHandler) ListDestinations(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	destinations, err := h.store.ListAlertDestinations(ctx, email)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve destinations")
+		return
+	}
+
+	response := apiv1.NewDestinations(destinations)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode destinations response", "error", err)
+	}
+}
+
+// DeleteDestination removes one of a user's destinations.
+func (h *DestinationHandler) DeleteDestination(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	destinationUUID, err := resourceUUID(r, r.URL.Query().Get("uuid"))
+	if err != nil {
+		sendError(w, r, apierror.InvalidDestination, "Destination UUID parameter is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.DeleteAlertDestination(ctx, email, destinationUUID); err != nil {
+		sendStoreError(w, r, err, "Failed to delete destination")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
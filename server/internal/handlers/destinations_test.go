@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+)
+
+func TestDestinationHandler_HandleDestinations(t *testing.T) {
+	resortUUID := uuid.MustParse("2b7d1f6a-3c4e-4d5f-8a9b-0c1d2e3f4a5b")
+	destination := dbgen.AlertDestination{
+		Uuid:       uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"),
+		ResortUuid: resortUUID,
+		Channel:    db.ChannelSlack,
+		Url:        "https://hooks.slack.com/services/T000/B000/XXXX",
+		CreatedAt:  time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC),
+	}
+	createBody := `{"email":"test@example.com","resort_uuid":"2b7d1f6a-3c4e-4d5f-8a9b-0c1d2e3f4a5b",` +
+		`"channel":"slack","url":"https://hooks.slack.com/services/T000/B000/XXXX"}`
+	destinationJSON := `{
+		"uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+		"resort_uuid": "2b7d1f6a-3c4e-4d5f-8a9b-0c1d2e3f4a5b",
+		"channel": "slack",
+		"created_at": "2025-12-18T06:00:00Z"
+	}`
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+		expectedBody   string
+	}{
+		{
+			name:   "Adds a Slack destination",
+			method: http.MethodPost,
+			target: "/api/v1/me/destinations",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateAlertDestination(gomock.Any(), "test@example.com", resortUUID, db.ChannelSlack, destination.Url).
+					Return(destination, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   destinationJSON,
+		},
+		{
+			name:           "Unsupported channel",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/destinations",
+			body:           strings.Replace(createBody, `"slack"`, `"teams"`, 1),
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_CHANNEL",
+				Message: "Channel must be slack or discord",
+			},
+		},
+		{
+			name:           "URL for a different service",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/destinations",
+			body:           strings.Replace(createBody, `"slack"`, `"discord"`, 1),
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_URL",
+				Message: "Webhook URL is not valid: webhook URL is not a discord incoming webhook",
+			},
+		},
+		{
+			name:           "Missing resort",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/destinations",
+			body:           `{"email":"test@example.com","channel":"slack"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_RESORT",
+				Message: "Resort UUID is required",
+			},
+		},
+		{
+			name:   "No alert for the resort",
+			method: http.MethodPost,
+			target: "/api/v1/me/destinations",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateAlertDestination(gomock.Any(), "test@example.com", resortUUID, gomock.Any(), gomock.Any()).
+					Return(dbgen.AlertDestination{}, db.ErrAlertNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "ALERT_NOT_FOUND",
+				Message: "You don't have an alert for this resort",
+			},
+		},
+		{
+			name:   "Destination limit reached",
+			method: http.MethodPost,
+			target: "/api/v1/me/destinations",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateAlertDestination(gomock.Any(), "test@example.com", resortUUID, gomock.Any(), gomock.Any()).
+					Return(dbgen.AlertDestination{}, db.ErrDestinationLimit)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError: &ErrorResponse{
+				Error:   "DESTINATION_LIMIT",
+				Message: "An alert can have up to 3 destinations",
+			},
+		},
+		{
+			name:   "Lists destinations without URLs",
+			method: http.MethodGet,
+			target: "/api/v1/me/destinations?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					ListAlertDestinations(gomock.Any(), "test@example.com").
+					Return([]dbgen.AlertDestination{destination}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + destinationJSON + "]",
+		},
+		{
+			name:   "Store error listing destinations",
+			method: http.MethodGet,
+			target: "/api/v1/me/destinations?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					ListAlertDestinations(gomock.Any(), "test@example.com").
+					Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to retrieve destinations",
+			},
+		},
+		{
+			name:   "Deletes a destination",
+			method: http.MethodDelete,
+			target: "/api/v1/me/destinations/9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().DeleteAlertDestination(gomock.Any(), "test@example.com", destination.Uuid).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Deleting an unknown destination",
+			method: http.MethodDelete,
+			target: "/api/v1/me/destinations/9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					DeleteAlertDestination(gomock.Any(), "test@example.com", destination.Uuid).
+					Return(db.ErrDestinationNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "DESTINATION_NOT_FOUND",
+				Message: "No destination with that UUID",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPatch,
+			target:         "/api/v1/me/destinations",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewDestinationHandler(mockStore)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Destination: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/atom"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+const (
+	// DefaultFeedMinSnow is the forecast, in inches, a day needs to appear in the feeds.
+	DefaultFeedMinSnow = 6.0
+
+	// feedHistoryDays is how long past forecasts stay in the feeds.
+	feedHistoryDays = 7
+	feedMaxEntries  = 50
+
+	// feedIDPrefix starts the tag URIs (RFC 4151) identifying feeds and entries. It must never change, or
+	// readers will show every entry again.
+	feedIDPrefix = "tag:powhunter.app,2025:"
+)
+
+// FeedHandler serves public Atom feeds of the forecasts that reach a snow threshold. Entries are identified
+// by resort and forecast date, and their updated time moves whenever the forecast amount changes.
+type FeedHandler struct {
+	store   db.StoreService
+	baseURL string
+	// minSnow is the threshold in inches.
+	minSnow float64
+	now     func() time.Time
+}
+
+// NewFeedHandler returns a handler for feeds of forecasts of at least minSnow inches, linking to baseURL.
+func NewFeedHandler(store db.StoreService, baseURL string, minSnow float64) (*FeedHandler, error) {
+	if minSnow <= 0 {
+		return nil, fmt.Errorf("feed minimum snow must be positive, got %v", minSnow)
+	}
+
+	return &FeedHandler{
+		store:   store,
+		baseURL: baseURL,
+		minSnow: minSnow,
+		now:     time.Now,
+	}, nil
+}
+
+// feedMinSnowFromEnv reads the feed threshold from FEED_MIN_SNOW, in inches.
+func feedMinSnowFromEnv() (float64, error) {
+	value := os.Getenv("FEED_MIN_SNOW")
+	if value == "" {
+		return DefaultFeedMinSnow, nil
+	}
+
+	minSnow, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid FEED_MIN_SNOW %q: %w", value, err)
+	}
+	return minSnow, nil
+}
+
+// ServeFeed serves the feed of every resort at /api/feeds/resorts.atom, and a resort's at
+// /api/feeds/resorts/{uuid}.atom. Amounts are in inches unless ?units=metric is given.
+func (h *FeedHandler) ServeFeed(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	system, err := units.ParseSystem(r.URL.Query().Get("units"))
+	if err != nil {
+		sendError(w, r, apierror.InvalidUnits, "Units must be imperial or metric")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	query := db.ForecastFeedQuery{
+		MinSnow: h.minSnow,
+		Since:   h.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -feedHistoryDays),
+		Limit:   feedMaxEntries,
+	}
+	feed := atom.Feed{
+		ID:           feedIDPrefix + "resorts/feed",
+		Title:        "Pow Hunter powder forecasts",
+		Author:       "Pow Hunter",
+		SelfURL:      h.baseURL + r.URL.Path,
+		AlternateURL: h.baseURL,
+	}
+
+	if file := r.PathValue("file"); file != "" {
+		resortID, ok := strings.CutSuffix(file, ".atom")
+		resortUUID, err := uuid.Parse(resortID)
+		if !ok || err != nil {
+			sendError(w, r, apierror.FeedNotFound, "Feed not found")
+			return
+		}
+
+		resort, err := h.store.GetResort(ctx, resortUUID)
+		if errors.Is(err, db.ErrResortNotFound) {
+			sendError(w, r, apierror.FeedNotFound, "Feed not found")
+			return
+		}
+		if err != nil {
+			sendStoreError(w, r, err, "Failed to retrieve feed")
+			return
+		}
+
+		query.ResortUUID = uuid.NullUUID{UUID: resort.Uuid, Valid: true}
+		feed.ID = feedIDPrefix + "resorts/" + resort.Uuid.String() + "/feed"
+		feed.Title = "Pow Hunter powder forecasts: " + resort.Name
+		if resortURL := db.ResortURL(resort.UrlHost, resort.UrlPathname); resortURL != "" {
+			feed.AlternateURL = resortURL
+		}
+	}
+
+	entries, err := h.store.ListForecastFeed(ctx, query)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve feed")
+		return
+	}
+
+	feed.Subtitle = "Forecasts of " + notify.FormatSnow(h.minSnow, system, notify.DefaultLocale) + " of snow or more"
+	feed.Entries = h.feedEntries(entries, system)
+	feed.Updated = h.now()
+	if len(feed.Entries) > 0 {
+		// Entries are listed most recently updated first.
+		feed.Updated = feed.Entries[0].Updated
+	}
+
+	w.Header().Set("Content-Type", atom.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=1800")
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if err := feed.Encode(w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write feed", "error", err)
+	}
+}
+
+// feedEntries describes forecasts in a measurement system.
+func (h *FeedHandler) feedEntries(forecasts []db.ForecastFeedEntry, system units.System) []atom.Entry {
+	entries := make([]atom.Entry, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		snow := notify.FormatSnow(forecast.SnowAmount, system, notify.DefaultLocale)
+		date := forecast.ForecastDate.Format("Monday, Jan 2")
+
+		link := forecast.ResortURL
+		if link == "" {
+			link = h.baseURL
+		}
+
+		entries = append(entries, atom.Entry{
+			ID: feedIDPrefix + "resorts/" + forecast.ResortUUID.String() +
+				"/forecasts/" + forecast.ForecastDate.Format("2006-01-02"),
+			Title:     forecast.ResortName + ": " + snow + " on " + date,
+			Summary:   snow + " of snow forecast at " + forecast.ResortName + " on " + date + ".",
+			URL:       link,
+			Published: forecast.CreatedAt,
+			Updated:   forecast.UpdatedAt,
+		})
+	}
+	return entries
+}
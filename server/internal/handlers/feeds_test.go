@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/atom"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+)
+
+// testAtomFeed holds the parts of an Atom feed the tests check.
+type testAtomFeed struct {
+	ID      string `xml:"id"`
+	Title   string `xml:"title"`
+	Updated string `xml:"updated"`
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Link    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+func TestFeedHandler_ServeFeed(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	since := time.Date(2025, 12, 11, 0, 0, 0, 0, time.UTC)
+	crystal := dbgen.Resort{
+		Uuid:        uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"),
+		Name:        "Crystal Mountain",
+		UrlHost:     sql.NullString{String: "www.crystalmountainresort.com", Valid: true},
+		UrlPathname: sql.NullString{String: "/snow-report", Valid: true},
+	}
+	entries := []db.ForecastFeedEntry{
+		{
+			ResortUUID:   crystal.Uuid,
+			ResortName:   crystal.Name,
+			ResortURL:    "https://www.crystalmountainresort.com/snow-report",
+			ForecastDate: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			SnowAmount:   8.5,
+			CreatedAt:    time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2025, 12, 18, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			ResortUUID:   uuid.MustParse("1d8ddb3d-3839-473d-a8b7-89015d961d25"),
+			ResortName:   "Stevens Pass",
+			ForecastDate: time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC),
+			SnowAmount:   6,
+			CreatedAt:    time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		setupMock       func(*mocks.MockStoreService)
+		expectedStatus  int
+		expectedID      string
+		expectedTitle   string
+		expectedEntries []string
+		expectedUpdated string
+	}{
+		{
+			name:   "Combined feed",
+			method: http.MethodGet,
+			target: "/api/feeds/resorts.atom",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListForecastFeed(gomock.Any(), db.ForecastFeedQuery{
+					MinSnow: 6,
+					Since:   since,
+					Limit:   feedMaxEntries,
+				}).Return(entries, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedID:     "tag:powhunter.app,2025:resorts/feed",
+			expectedTitle:  "Pow Hunter powder forecasts",
+			expectedEntries: []string{
+				"Crystal Mountain: 8.5 inches on Saturday, Dec 20",
+				"Stevens Pass: 6.0 inches on Sunday, Dec 21",
+			},
+			expectedUpdated: "2025-12-18T05:00:00Z",
+		},
+		{
+			name:   "Resort feed in metric",
+			method: http.MethodGet,
+			target: "/api/feeds/resorts/9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d.atom?units=metric",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResort(gomock.Any(), crystal.Uuid).Return(crystal, nil)
+				m.EXPECT().ListForecastFeed(gomock.Any(), db.ForecastFeedQuery{
+					ResortUUID: uuid.NullUUID{UUID: crystal.Uuid, Valid: true},
+					MinSnow:    6,
+					Since:      since,
+					Limit:      feedMaxEntries,
+				}).Return(entries[:1], nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedID:      "tag:powhunter.app,2025:resorts/9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d/feed",
+			expectedTitle:   "Pow Hunter powder forecasts: Crystal Mountain",
+			expectedEntries: []string{"Crystal Mountain: 22 cm on Saturday, Dec 20"},
+			expectedUpdated: "2025-12-18T05:00:00Z",
+		},
+		{
+			name:   "Empty feed",
+			method: http.MethodGet,
+			target: "/api/feeds/resorts.atom",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListForecastFeed(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedID:      "tag:powhunter.app,2025:resorts/feed",
+			expectedTitle:   "Pow Hunter powder forecasts",
+			expectedUpdated: "2025-12-18T06:00:00Z",
+		},
+		{
+			name:   "Unknown resort",
+			method: http.MethodGet,
+			target: "/api/feeds/resorts/1d8ddb3d-3839-473d-a8b7-89015d961d25.atom",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResort(gomock.Any(), gomock.Any()).Return(dbgen.Resort{}, db.ErrResortNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Malformed resort UUID",
+			method:         http.MethodGet,
+			target:         "/api/feeds/resorts/crystal.atom",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unknown feed",
+			method:         http.MethodGet,
+			target:         "/api/feeds/alerts.atom",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unknown units",
+			method:         http.MethodGet,
+			target:         "/api/feeds/resorts.atom?units=kelvin",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Store error",
+			method: http.MethodGet,
+			target: "/api/feeds/resorts.atom",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListForecastFeed(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			target:         "/api/feeds/resorts.atom",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewFeedHandler(mockStore, "https://api.powhunter.app", DefaultFeedMinSnow)
+			require.NoError(t, err)
+			handler.now = func() time.Time { return now }
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Feed: handler}, rr, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, atom.ContentType, rr.Header().Get("Content-Type"))
+			var feed testAtomFeed
+			require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+			assert.Equal(t, tt.expectedID, feed.ID)
+			assert.Equal(t, tt.expectedTitle, feed.Title)
+			assert.Equal(t, tt.expectedUpdated, feed.Updated)
+
+			var titles []string
+			for _, entry := range feed.Entries {
+				titles = append(titles, entry.Title)
+			}
+			assert.Equal(t, tt.expectedEntries, titles)
+		})
+	}
+}
+
+func TestFeedHandler_EntriesAreStablePerResortAndDate(t *testing.T) {
+	handler, err := NewFeedHandler(nil, "https://api.powhunter.app", DefaultFeedMinSnow)
+	require.NoError(t, err)
+
+	forecast := db.ForecastFeedEntry{
+		ResortUUID:   uuid.MustParse("1d8ddb3d-3839-473d-a8b7-89015d961d25"),
+		ResortName:   "Stevens Pass",
+		ForecastDate: time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC),
+		SnowAmount:   6,
+		UpdatedAt:    time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+	}
+	changed := forecast
+	changed.SnowAmount = 9
+	changed.UpdatedAt = time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+
+	entries := handler.feedEntries([]db.ForecastFeedEntry{forecast, changed}, "")
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tag:powhunter.app,2025:resorts/1d8ddb3d-3839-473d-a8b7-89015d961d25/forecasts/2025-12-21",
+		entries[0].ID)
+	assert.Equal(t, entries[0].ID, entries[1].ID, "a changed amount keeps the entry's ID")
+	assert.Equal(t, changed.UpdatedAt, entries[1].Updated)
+	assert.Equal(t, "https://api.powhunter.app", entries[0].URL, "entries link to the site without a resort URL")
+}
+
+func TestNewFeedHandler_RejectsNonPositiveThreshold(t *testing.T) {
+	_, err := NewFeedHandler(nil, "https://api.powhunter.app", 0)
+	assert.Error(t, err)
+}
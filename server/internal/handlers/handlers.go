@@ -5,33 +5,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
 	"github.com/MattSilvaa/powhunter/internal/db"
-	"github.com/lib/pq"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/phone"
+	"github.com/MattSilvaa/powhunter/internal/ratelimit"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+	"github.com/MattSilvaa/powhunter/internal/validate"
+	"github.com/MattSilvaa/powhunter/internal/weather"
+	"github.com/MattSilvaa/powhunter/internal/webpush"
 )
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
-type Resort struct {
-	Name string `json:"name"`
-	UUID string `json:"uuid"`
-	URL  struct {
-		Host     string `json:"host"`
-		PathName string `json:"pathname"`
-	} `json:"url"`
-	Lat float64 `json:"lat"`
-	Lon float64 `json:"lon"`
-}
+// ErrorResponse is the body of an error response.
+type ErrorResponse = apierror.ErrorResponse
 
 type ResortHandler struct {
-	resorts []Resort
-	store   db.StoreService
+	store db.StoreService
 }
 
 type AlertHandler struct {
@@ -39,14 +35,25 @@ type AlertHandler struct {
 }
 
 type Handlers struct {
-	Resort  *ResortHandler
-	Alert   *AlertHandler
-	Contact *ContactHandler
-	store   *db.Store
+	Resort      *ResortHandler
+	Alert       *AlertHandler
+	Contact     *ContactHandler
+	SMS         *SMSHandler
+	Delivery    *DeliveryHandler
+	Preferences *PreferencesHandler
+	Unsubscribe *UnsubscribeHandler
+	Webhook     *WebhookHandler
+	Destination *DestinationHandler
+	Push        *PushHandler
+	Calendar    *CalendarHandler
+	Feed        *FeedHandler
+	// Limiter limits requests by route, unless it's nil.
+	Limiter *ratelimit.Limiter
+	// Health checks the API is ready to serve.
+	Health *health.Checker
+	store  *db.Store
 }
 
-var METHOD_NOT_ALLOWED = "METHOD_NOT_ALLOWED"
-
 // Store returns the store used by the handlers.
 func (h *Handlers) Store() *db.Store {
 	return h.store
@@ -59,18 +66,34 @@ func setSecurityHeaders(w http.ResponseWriter) {
 	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 }
 
-func sendErrorResponse(w http.ResponseWriter, errorCode string, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// sendError writes an error response with a code from the apierror catalogue, in the format r accepts.
+func sendError(w http.ResponseWriter, r *http.Request, code apierror.Code, message string) {
+	apierror.Write(w, r, apierror.New(code, message))
+}
 
-	errorResp := ErrorResponse{
-		Error:   errorCode,
-		Message: message,
+// sendStoreError writes the error response for an error from the store, logging errors the API doesn't
+// report. message says what failed, and is returned with internal errors.
+func sendStoreError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	apiErr := apierror.FromStore(err, message)
+	if apiErr.Code == apierror.InternalError {
+		slog.ErrorContext(r.Context(), message, "error", err)
 	}
+	apierror.Write(w, r, apiErr)
+}
 
-	if err := json.NewEncoder(w).Encode(errorResp); err != nil {
-		log.Printf("Failed to encode error response: %v", err)
+// decodeRequest decodes a JSON request body into the struct v points to and checks it against the rules in
+// its validate tags. If the body isn't valid, it writes the error response, listing the fields at fault, and
+// returns false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := validate.DecodeJSON(r.Body, v); err != nil {
+		apierror.Write(w, r, apierror.Invalid(apierror.InvalidRequest, "Invalid request body", err))
+		return false
+	}
+	if err := validate.Struct(v); err != nil {
+		apierror.Write(w, r, apierror.Invalid(apierror.ValidationError, "Data validation failed", err))
+		return false
 	}
+	return true
 }
 
 func NewHandlers() (*Handlers, error) {
@@ -80,13 +103,15 @@ func NewHandlers() (*Handlers, error) {
 	}
 
 	store := db.NewStore(dbConn)
+	// Handlers call the store through spans, so a request's trace shows the queries it ran.
+	traced := db.Traced(store)
 
-	resortHandler, err := NewResortHandler(store)
+	resortHandler, err := NewResortHandler(traced)
 	if err != nil {
 		return nil, err
 	}
 
-	alertHandler, err := NewAlertHandler(store)
+	alertHandler, err := NewAlertHandler(traced)
 	if err != nil {
 		return nil, err
 	}
@@ -96,11 +121,92 @@ func NewHandlers() (*Handlers, error) {
 		return nil, err
 	}
 
+	smsHandler, err := NewSMSHandler(traced, os.Getenv("TWILIO_AUTH_TOKEN"), publicBaseURL())
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryHandler, err := NewDeliveryHandler(traced)
+	if err != nil {
+		return nil, err
+	}
+
+	preferencesHandler, err := NewPreferencesHandler(traced)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := unsubscribe.SignerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribeHandler, err := NewUnsubscribeHandler(traced, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookHandler, err := NewWebhookHandler(traced, notify.WebhookNotifierFromEnv(traced))
+	if err != nil {
+		return nil, err
+	}
+
+	destinationHandler, err := NewDestinationHandler(traced)
+	if err != nil {
+		return nil, err
+	}
+
+	vapidKeys, err := webpush.VAPIDKeysFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	pushHandler, err := NewPushHandler(traced, vapidKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarHandler, err := NewCalendarHandler(traced, smsClientFromEnv(), publicBaseURL())
+	if err != nil {
+		return nil, err
+	}
+
+	feedMinSnow, err := feedMinSnowFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	feedHandler, err := NewFeedHandler(traced, publicBaseURL(), feedMinSnow)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter, err := ratelimit.FromEnv(store)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := health.DatabaseChecks(store, db.LatestMigration())
+	if health.CheckProvidersFromEnv() {
+		checks = append(checks, health.ProviderCheck(weather.ProviderOpenMeteo, weather.NewOpenMeteoClient().Ping))
+	}
+
 	return &Handlers{
-		Resort:  resortHandler,
-		Alert:   alertHandler,
-		Contact: contactHandler,
-		store:   store,
+		Resort:      resortHandler,
+		Alert:       alertHandler,
+		Contact:     contactHandler,
+		SMS:         smsHandler,
+		Delivery:    deliveryHandler,
+		Preferences: preferencesHandler,
+		Unsubscribe: unsubscribeHandler,
+		Webhook:     webhookHandler,
+		Destination: destinationHandler,
+		Push:        pushHandler,
+		Calendar:    calendarHandler,
+		Feed:        feedHandler,
+		Limiter:     limiter,
+		Health:      health.NewChecker(checks...),
+		store:       store,
 	}, nil
 }
 
@@ -111,12 +217,6 @@ func NewResortHandler(store db.StoreService) (*ResortHandler, error) {
 }
 
 func (h *ResortHandler) ListAllResorts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendErrorResponse(w, METHOD_NOT_ALLOWED, "Method not allowed", http.StatusMethodNotAllowed)
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
@@ -124,14 +224,13 @@ func (h *ResortHandler) ListAllResorts(w http.ResponseWriter, r *http.Request) {
 
 	resorts, err := h.store.ListAllResorts(ctx)
 	if err != nil {
-		http.Error(w, "Failed to retrieve resorts", http.StatusInternalServerError)
+		sendStoreError(w, r, err, "Failed to retrieve resorts")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resorts); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
+	if err := json.NewEncoder(w).Encode(apiv1.NewResorts(resorts)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode resorts response", "error", err)
 	}
 }
 
@@ -142,24 +241,20 @@ func NewAlertHandler(store db.StoreService) (*AlertHandler, error) {
 }
 
 type CreateAlertRequest struct {
-	Email            string   `json:"email"`
-	Phone            string   `json:"phone"`
-	NotificationDays int      `json:"notificationDays"`
-	MinSnowAmount    float64  `json:"minSnowAmount"`
-	ResortsUuids     []string `json:"resortsUuids"`
+	Email            string   `json:"email" validate:"email,max=254"`
+	Phone            string   `json:"phone" validate:"max=32"`
+	Country          string   `json:"country,omitempty" validate:"max=2"`
+	NotificationDays int      `json:"notificationDays" validate:"min=1,max=10"`
+	MinSnowAmount    float64  `json:"minSnowAmount" validate:"min=0,max=100"`
+	ResortsUuids     []string `json:"resortsUuids" validate:"max=50,dive,uuid"`
 }
 
 func (h *AlertHandler) GetUserAlerts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		sendErrorResponse(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	setSecurityHeaders(w)
 
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		sendErrorResponse(w, "MISSING_EMAIL", "Email parameter is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
 		return
 	}
 
@@ -168,36 +263,34 @@ func (h *AlertHandler) GetUserAlerts(w http.ResponseWriter, r *http.Request) {
 
 	alerts, err := h.store.GetUserAlertsByEmail(ctx, email)
 	if err != nil {
-		log.Printf("Failed to get user alerts: %v", err)
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to retrieve alerts", http.StatusInternalServerError)
+		sendStoreError(w, r, err, "Failed to retrieve alerts")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(alerts); err != nil {
-		log.Printf("Failed to encode alerts response: %v", err)
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to encode response", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(apiv1.NewAlerts(alerts)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode alerts response", "error", err)
+		sendError(w, r, apierror.InternalError, "Failed to encode response")
 		return
 	}
 }
 
 func (h *AlertHandler) DeleteUserAlert(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		sendErrorResponse(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	setSecurityHeaders(w)
 
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		sendErrorResponse(w, "MISSING_EMAIL", "Email parameter is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
 		return
 	}
 
-	resortUuid := r.URL.Query().Get("resort_uuid")
+	resortUuid := r.PathValue("resortId")
 	if resortUuid == "" {
-		sendErrorResponse(w, "MISSING_RESORT", "Resort UUID parameter is required", http.StatusBadRequest)
+		// Deprecated route.
+		resortUuid = r.URL.Query().Get("resort_uuid")
+	}
+	if resortUuid == "" {
+		sendError(w, r, apierror.MissingResort, "Resort UUID parameter is required")
 		return
 	}
 
@@ -206,30 +299,21 @@ func (h *AlertHandler) DeleteUserAlert(w http.ResponseWriter, r *http.Request) {
 
 	err := h.store.DeleteUserAlert(ctx, email, resortUuid)
 	if err != nil {
-		log.Printf("Failed to delete user alert: %v", err)
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to delete alert", http.StatusInternalServerError)
+		sendStoreError(w, r, err, "Failed to delete alert")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Alert deleted successfully",
-	})
+	json.NewEncoder(w).Encode(apiv1.NewStatus("Alert deleted successfully"))
 }
 
 func (h *AlertHandler) DeleteAllUserAlerts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		sendErrorResponse(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	setSecurityHeaders(w)
 
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		sendErrorResponse(w, "MISSING_EMAIL", "Email parameter is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
 		return
 	}
 
@@ -238,99 +322,70 @@ func (h *AlertHandler) DeleteAllUserAlerts(w http.ResponseWriter, r *http.Reques
 
 	err := h.store.DeleteAllUserAlerts(ctx, email)
 	if err != nil {
-		log.Printf("Failed to delete all user alerts: %v", err)
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to delete alerts", http.StatusInternalServerError)
+		sendStoreError(w, r, err, "Failed to delete alerts")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "All alerts deleted successfully",
-	})
+	json.NewEncoder(w).Encode(apiv1.NewStatus("All alerts deleted successfully"))
 }
 
 func (h *AlertHandler) CreateAlert(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		sendErrorResponse(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	setSecurityHeaders(w)
 
 	var req CreateAlertRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendErrorResponse(w, "INVALID_REQUEST", "Invalid request body", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
 	if req.Email == "" {
-		sendErrorResponse(w, "MISSING_EMAIL", "Email is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingEmail, "Email is required")
 		return
 	}
 
 	if req.Phone == "" {
-		sendErrorResponse(w, "MISSING_PHONE", "Phone number is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingPhone, "Phone number is required")
+		return
+	}
+
+	phoneNumber, err := phone.Normalize(req.Phone, req.Country)
+	if err != nil {
+		if errors.Is(err, phone.ErrPremiumRate) {
+			sendError(w, r, apierror.InvalidPhone, "Premium-rate phone numbers are not supported")
+			return
+		}
+		sendError(w, r, apierror.InvalidPhone, "Phone number is not valid")
 		return
 	}
 
 	if len(req.ResortsUuids) == 0 {
-		sendErrorResponse(w, "MISSING_RESORTS", "At least one resort is required", http.StatusBadRequest)
+		sendError(w, r, apierror.MissingResorts, "At least one resort is required")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	err := h.store.CreateUserWithAlerts(
+	err = h.store.CreateUserWithAlerts(
 		ctx,
 		req.Email,
-		req.Phone,
+		phoneNumber,
 		req.MinSnowAmount,
 		int32(req.NotificationDays),
 		req.ResortsUuids,
 	)
 	if err != nil {
-		log.Printf("Failed to create alert: %v", err)
-
-		pqErr := &pq.Error{}
-		if errors.As(err, &pqErr) {
-			switch pqErr.Code {
-			case "23505": // unique_violation
-				if pqErr.Constraint == "user_alerts_user_uuid_resort_uuid_key" {
-					sendErrorResponse(
-						w,
-						"DUPLICATE_ALERT",
-						"You already have an alert for this resort",
-						http.StatusConflict,
-					)
-					return
-				}
-				sendErrorResponse(w, "DUPLICATE_ENTRY", "This entry already exists", http.StatusConflict)
-				return
-			case "23502": // not_null_violation
-				sendErrorResponse(w, "MISSING_REQUIRED_FIELD", "Required field is missing", http.StatusBadRequest)
-				return
-			case "23514": // check_violation
-				sendErrorResponse(w, "VALIDATION_ERROR", "Data validation failed", http.StatusBadRequest)
-				return
-			}
-		}
-
-		sendErrorResponse(w, "INTERNAL_ERROR", "Failed to create alert", http.StatusInternalServerError)
+		sendStoreError(w, r, err, "Failed to create alert")
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Alert created successfully",
-	})
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(apiv1.NewStatus("Alert created successfully"))
 
 	if err != nil {
-		log.Printf("Failed to write resposne: %v", err)
+		slog.ErrorContext(r.Context(), "Failed to write resposne", "error", err)
 		return
 	}
 }
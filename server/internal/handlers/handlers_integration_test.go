@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
 	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
 	"github.com/MattSilvaa/powhunter/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -33,7 +34,7 @@ func TestAlertHandlerIntegration_CreateAlert(t *testing.T) {
 	t.Run("Successfully create alert with real database", func(t *testing.T) {
 		requestBody := CreateAlertRequest{
 			Email:            "integration@test.com",
-			Phone:            "+15551234567",
+			Phone:            "+12065550100",
 			NotificationDays: 3,
 			MinSnowAmount:    8.0,
 			ResortsUuids:     []string{resort1.Uuid.String(), resort2.Uuid.String()},
@@ -70,7 +71,7 @@ func TestAlertHandlerIntegration_CreateAlert(t *testing.T) {
 		// First request
 		requestBody := CreateAlertRequest{
 			Email:            "duplicate@test.com",
-			Phone:            "+15559876543",
+			Phone:            "+12065550123",
 			NotificationDays: 2,
 			MinSnowAmount:    5.0,
 			ResortsUuids:     []string{resort1.Uuid.String()},
@@ -103,7 +104,7 @@ func TestAlertHandlerIntegration_CreateAlert(t *testing.T) {
 	t.Run("Invalid resort UUID returns error", func(t *testing.T) {
 		requestBody := CreateAlertRequest{
 			Email:            "invalidresort@test.com",
-			Phone:            "+15551111111",
+			Phone:            "+12065550111",
 			NotificationDays: 3,
 			MinSnowAmount:    8.0,
 			ResortsUuids:     []string{"not-a-valid-uuid"},
@@ -142,7 +143,7 @@ func TestResortHandlerIntegration_ListAllResorts(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 
-		var resorts []dbgen.Resort
+		var resorts []apiv1.Resort
 		err := json.NewDecoder(rr.Body).Decode(&resorts)
 		require.NoError(t, err)
 		assert.Len(t, resorts, 3)
@@ -150,8 +151,8 @@ func TestResortHandlerIntegration_ListAllResorts(t *testing.T) {
 		names := make(map[string]bool)
 		for _, r := range resorts {
 			names[r.Name] = true
-			assert.True(t, r.Latitude.Valid)
-			assert.True(t, r.Longitude.Valid)
+			assert.NotNil(t, r.Lat)
+			assert.NotNil(t, r.Lon)
 		}
 
 		assert.True(t, names["Whistler Blackcomb"])
@@ -172,7 +173,7 @@ func TestResortHandlerIntegration_ListAllResorts(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 
-		var resorts []dbgen.Resort
+		var resorts []apiv1.Resort
 		err = json.NewDecoder(rr.Body).Decode(&resorts)
 		require.NoError(t, err)
 		assert.Len(t, resorts, 0)
@@ -193,7 +194,7 @@ func TestEndToEndFlow_CreateAlertAndVerifyInDatabase(t *testing.T) {
 
 	requestBody := CreateAlertRequest{
 		Email:            "endtoend@test.com",
-		Phone:            "+15557654321",
+		Phone:            "+12065550199",
 		NotificationDays: 5,
 		MinSnowAmount:    10.0,
 		ResortsUuids:     []string{resort.Uuid.String()},
@@ -226,7 +227,7 @@ func TestEndToEndFlow_CreateAlertAndVerifyInDatabase(t *testing.T) {
 
 	match := matches[0]
 	assert.Equal(t, "endtoend@test.com", match.UserEmail)
-	assert.Equal(t, "+15557654321", match.UserPhone)
+	assert.Equal(t, "+12065550199", match.UserPhone)
 	assert.Equal(t, "Test Mountain", match.ResortName)
 
 	// Record alert sent
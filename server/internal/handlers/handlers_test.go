@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
 	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
 	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/validate"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +21,9 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// testResortUUID is the resort alerts are created on.
+const testResortUUID = "5f0e8d3c-2b1a-4c9d-8e7f-6a5b4c3d2e1f"
+
 func testAlertHandler(t *testing.T) (*AlertHandler, *mocks.MockStoreService) {
 	ctrl := gomock.NewController(t)
 	mockStore := mocks.NewMockStoreService(ctrl)
@@ -55,20 +62,20 @@ func TestCreateAlert(t *testing.T) {
 			method: http.MethodPost,
 			requestBody: CreateAlertRequest{
 				Email:            "test@example.com",
-				Phone:            "1234567890",
+				Phone:            "(206) 555-0100",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1", "resort2"},
+				ResortsUuids:     []string{testResortUUID, "3c2d1e0f-9a8b-4c7d-8e5f-4a3b2c1d0e9f"},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				m.EXPECT().
 					CreateUserWithAlerts(
 						gomock.Any(),
 						"test@example.com",
-						"1234567890",
+						"+12065550100",
 						5.0,
 						int32(3),
-						[]string{"resort1", "resort2"},
+						[]string{testResortUUID, "3c2d1e0f-9a8b-4c7d-8e5f-4a3b2c1d0e9f"},
 					).
 					Return(nil)
 			},
@@ -90,10 +97,10 @@ func TestCreateAlert(t *testing.T) {
 			method: http.MethodGet,
 			requestBody: CreateAlertRequest{
 				Email:            "test@example.com",
-				Phone:            "1234567890",
+				Phone:            "(206) 555-0100",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1"},
+				ResortsUuids:     []string{testResortUUID},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				// No calls expected
@@ -117,15 +124,54 @@ func TestCreateAlert(t *testing.T) {
 				Message: "Invalid request body",
 			},
 		},
+		{
+			name:        "Unknown Field",
+			method:      http.MethodPost,
+			requestBody: `{"email":"test@example.com","phone":"(206) 555-0100","notifyDays":3}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_REQUEST",
+				Message: "Invalid request body",
+				Fields:  validate.Errors{{Field: "notifyDays", Rule: "unknown", Message: "is not a known field"}},
+			},
+		},
+		{
+			name:   "Invalid Fields",
+			method: http.MethodPost,
+			requestBody: CreateAlertRequest{
+				Email:            "not-an-email",
+				Phone:            "(206) 555-0100",
+				NotificationDays: 10000,
+				MinSnowAmount:    -2,
+				ResortsUuids:     []string{testResortUUID, "resort1"},
+			},
+			setupMock: func(m *mocks.MockStoreService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "VALIDATION_ERROR",
+				Message: "Data validation failed",
+				Fields: validate.Errors{
+					{Field: "email", Rule: "email", Message: "must be an email address"},
+					{Field: "notificationDays", Rule: "max", Message: "must be at most 10"},
+					{Field: "minSnowAmount", Rule: "min", Message: "must be at least 0"},
+					{Field: "resortsUuids[1]", Rule: "uuid", Message: "must be a UUID"},
+				},
+			},
+		},
 		{
 			name:   "Missing Required Fields - Empty Email",
 			method: http.MethodPost,
 			requestBody: CreateAlertRequest{
 				Email:            "", // Empty email
-				Phone:            "1234567890",
+				Phone:            "(206) 555-0100",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1"},
+				ResortsUuids:     []string{testResortUUID},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				// No calls expected
@@ -144,7 +190,7 @@ func TestCreateAlert(t *testing.T) {
 				Phone:            "", // Empty phone
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1"},
+				ResortsUuids:     []string{testResortUUID},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				// No calls expected
@@ -156,13 +202,80 @@ func TestCreateAlert(t *testing.T) {
 			},
 		},
 		{
-			name:   "Missing Required Fields - Empty Resorts",
+			name:   "Invalid Phone Number",
 			method: http.MethodPost,
 			requestBody: CreateAlertRequest{
 				Email:            "test@example.com",
 				Phone:            "1234567890",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
+				ResortsUuids:     []string{testResortUUID},
+			},
+			setupMock: func(m *mocks.MockStoreService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_PHONE",
+				Message: "Phone number is not valid",
+			},
+		},
+		{
+			name:   "Premium Rate Phone Number",
+			method: http.MethodPost,
+			requestBody: CreateAlertRequest{
+				Email:            "test@example.com",
+				Phone:            "+1 900 555 0123",
+				NotificationDays: 3,
+				MinSnowAmount:    5.0,
+				ResortsUuids:     []string{testResortUUID},
+			},
+			setupMock: func(m *mocks.MockStoreService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_PHONE",
+				Message: "Premium-rate phone numbers are not supported",
+			},
+		},
+		{
+			name:   "Phone Number Parsed With Requested Country",
+			method: http.MethodPost,
+			requestBody: CreateAlertRequest{
+				Email:            "test@example.com",
+				Phone:            "06 12 34 56 78",
+				Country:          "FR",
+				NotificationDays: 3,
+				MinSnowAmount:    5.0,
+				ResortsUuids:     []string{testResortUUID},
+			},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateUserWithAlerts(
+						gomock.Any(),
+						"test@example.com",
+						"+33612345678",
+						5.0,
+						int32(3),
+						[]string{testResortUUID},
+					).
+					Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody: map[string]string{
+				"status":  "success",
+				"message": "Alert created successfully",
+			},
+		},
+		{
+			name:   "Missing Required Fields - Empty Resorts",
+			method: http.MethodPost,
+			requestBody: CreateAlertRequest{
+				Email:            "test@example.com",
+				Phone:            "(206) 555-0100",
+				NotificationDays: 3,
+				MinSnowAmount:    5.0,
 				ResortsUuids:     []string{}, // Empty resorts
 			},
 			setupMock: func(m *mocks.MockStoreService) {
@@ -179,10 +292,10 @@ func TestCreateAlert(t *testing.T) {
 			method: http.MethodPost,
 			requestBody: CreateAlertRequest{
 				Email:            "existing@example.com",
-				Phone:            "1234567890",
+				Phone:            "(206) 555-0100",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1"},
+				ResortsUuids:     []string{testResortUUID},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				pqErr := &pq.Error{
@@ -193,10 +306,10 @@ func TestCreateAlert(t *testing.T) {
 					CreateUserWithAlerts(
 						gomock.Any(),
 						"existing@example.com",
-						"1234567890",
+						"+12065550100",
 						5.0,
 						int32(3),
-						[]string{"resort1"},
+						[]string{testResortUUID},
 					).
 					Return(pqErr)
 			},
@@ -206,25 +319,57 @@ func TestCreateAlert(t *testing.T) {
 				Message: "You already have an alert for this resort",
 			},
 		},
+		{
+			name:   "Unknown resort",
+			method: http.MethodPost,
+			requestBody: CreateAlertRequest{
+				Email:            "test@example.com",
+				Phone:            "(206) 555-0100",
+				NotificationDays: 3,
+				MinSnowAmount:    5.0,
+				ResortsUuids:     []string{"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"},
+			},
+			setupMock: func(m *mocks.MockStoreService) {
+				pqErr := &pq.Error{
+					Code:       "23503", // foreign_key_violation
+					Constraint: "user_alerts_resort_uuid_fkey",
+				}
+				m.EXPECT().
+					CreateUserWithAlerts(
+						gomock.Any(),
+						"test@example.com",
+						"+12065550100",
+						5.0,
+						int32(3),
+						[]string{"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"},
+					).
+					Return(pqErr)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "VALIDATION_ERROR",
+				Message: "Data validation failed",
+			},
+		},
 		{
 			name:   "Database Error",
 			method: http.MethodPost,
 			requestBody: CreateAlertRequest{
 				Email:            "test@example.com",
-				Phone:            "1234567890",
+				Phone:            "(206) 555-0100",
 				NotificationDays: 3,
 				MinSnowAmount:    5.0,
-				ResortsUuids:     []string{"resort1"},
+				ResortsUuids:     []string{testResortUUID},
 			},
 			setupMock: func(m *mocks.MockStoreService) {
 				m.EXPECT().
 					CreateUserWithAlerts(
 						gomock.Any(),
 						"test@example.com",
-						"1234567890",
+						"+12065550100",
 						5.0,
 						int32(3),
-						[]string{"resort1"},
+						[]string{testResortUUID},
 					).
 					Return(errors.New("database error"))
 			},
@@ -251,13 +396,13 @@ func TestCreateAlert(t *testing.T) {
 				require.NoError(t, err, "Failed to encode request body")
 			}
 
-			req, err := http.NewRequest(tt.method, "/api/alert", &body)
+			req, err := http.NewRequest(tt.method, "/api/v1/alerts", &body)
 			require.NoError(t, err)
 			req.Header.Set("Content-Type", "application/json")
 
 			rr := httptest.NewRecorder()
 
-			handler.CreateAlert(rr, req)
+			serve(t, &Handlers{Alert: handler}, rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
 
@@ -282,14 +427,51 @@ func TestCreateAlert(t *testing.T) {
 	}
 }
 
+func TestCreateAlert_ProblemDetails(t *testing.T) {
+	handler, mockStore := testAlertHandler(t)
+	mockStore.EXPECT().
+		CreateUserWithAlerts(gomock.Any(), "existing@example.com", "+12065550100", 5.0, int32(3), []string{testResortUUID}).
+		Return(&pq.Error{Code: "23505", Constraint: "user_alerts_user_uuid_resort_uuid_key"})
+
+	body, err := json.Marshal(CreateAlertRequest{
+		Email:            "existing@example.com",
+		Phone:            "(206) 555-0100",
+		NotificationDays: 3,
+		MinSnowAmount:    5.0,
+		ResortsUuids:     []string{testResortUUID},
+	})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(body))
+	req.Header.Set("Accept", "application/problem+json, application/json;q=0.9")
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Alert: handler}, rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+
+	var problem apierror.Problem
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+	assert.Equal(t, apierror.Problem{
+		Type:   "https://powhunter.app/problems/duplicate-alert",
+		Title:  "You already have an alert for this resort",
+		Status: http.StatusConflict,
+		Detail: "You already have an alert for this resort",
+		Code:   "DUPLICATE_ALERT",
+	}, problem)
+}
+
 func TestListAllResorts(t *testing.T) {
+	whistlerLat, whistlerLon := 50.1163, -122.9574
+
 	tests := []struct {
 		name            string
 		method          string
 		setupMock       func(*mocks.MockStoreService)
 		expectedStatus  int
 		expectedHeaders map[string]string
-		expectedResorts []dbgen.Resort
+		expectedResorts []apiv1.Resort
 	}{
 		{
 			name:   "Success",
@@ -297,9 +479,13 @@ func TestListAllResorts(t *testing.T) {
 			setupMock: func(m *mocks.MockStoreService) {
 				mockResorts := []dbgen.Resort{
 					{
-						ID:   1,
-						Uuid: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
-						Name: "Whistler Blackcomb",
+						ID:          1,
+						Uuid:        uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
+						Name:        "Whistler Blackcomb",
+						UrlHost:     sql.NullString{String: "www.whistlerblackcomb.com", Valid: true},
+						UrlPathname: sql.NullString{String: "/snow-report", Valid: true},
+						Latitude:    sql.NullFloat64{Float64: whistlerLat, Valid: true},
+						Longitude:   sql.NullFloat64{Float64: whistlerLon, Valid: true},
 					},
 					{
 						ID:   2,
@@ -319,15 +505,16 @@ func TestListAllResorts(t *testing.T) {
 				"X-XSS-Protection":       "1; mode=block",
 				"Referrer-Policy":        "strict-origin-when-cross-origin",
 			},
-			expectedResorts: []dbgen.Resort{
+			expectedResorts: []apiv1.Resort{
 				{
-					ID:   1,
-					Uuid: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
+					UUID: "550e8400-e29b-41d4-a716-446655440001",
 					Name: "Whistler Blackcomb",
+					URL:  &apiv1.ResortURL{Host: "www.whistlerblackcomb.com", PathName: "/snow-report"},
+					Lat:  &whistlerLat,
+					Lon:  &whistlerLon,
 				},
 				{
-					ID:   2,
-					Uuid: uuid.MustParse("550e8400-e29b-41d4-a716-446655440002"),
+					UUID: "550e8400-e29b-41d4-a716-446655440002",
 					Name: "Vail",
 				},
 			},
@@ -356,12 +543,12 @@ func TestListAllResorts(t *testing.T) {
 
 			tt.setupMock(mockStore)
 
-			req, err := http.NewRequest(tt.method, "/api/resorts", nil)
+			req, err := http.NewRequest(tt.method, "/api/v1/resorts", nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
 
-			handler.ListAllResorts(rr, req)
+			serve(t, &Handlers{Resort: handler}, rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
 
@@ -372,7 +559,10 @@ func TestListAllResorts(t *testing.T) {
 			}
 
 			if tt.expectedStatus == http.StatusOK && tt.expectedResorts != nil {
-				var response []dbgen.Resort
+				assert.NotContains(t, rr.Body.String(), "Valid", "nullable columns aren't leaked")
+				assert.NotContains(t, rr.Body.String(), `"id"`, "serial IDs aren't leaked")
+
+				var response []apiv1.Resort
 				err = json.NewDecoder(rr.Body).Decode(&response)
 				require.NoError(t, err, "Failed to decode response body")
 				assert.Equal(t, tt.expectedResorts, response)
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/atom"
+	"github.com/MattSilvaa/powhunter/internal/calendar"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/openapi"
+)
+
+// OpenAPIPath is where the OpenAPI document describing the API is served.
+const OpenAPIPath = "/api/openapi.json"
+
+// openAPIDocument is the encoded document, built on first use.
+var openAPIDocument = sync.OnceValue(func() []byte {
+	document, err := json.MarshalIndent(apiSpec(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return document
+})
+
+// ServeOpenAPI serves the OpenAPI document describing the API.
+func ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write(openAPIDocument()); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write OpenAPI document", "error", err)
+	}
+}
+
+// apiSpec describes every route Routes serves, except the deprecated ones. Schemas are reflected from the
+// request and apiv1 types, and tests check every response the handlers write against it, so a handler
+// returning a status, field or error code that isn't documented here fails them.
+func apiSpec() *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:   "Pow Hunter API",
+		Version: "1.0.0",
+		Description: "Snow forecast alerts for ski resorts. Routes under /api/v1 identify users by the email " +
+			"they signed up with. Errors are JSON objects with a machine-readable error code and a message, or " +
+			"RFC 7807 problem details with the code in a code member when the request accepts " +
+			"application/problem+json. Routes are rate limited by client address, and some by the email a " +
+			"request is for; requests over a limit get RATE_LIMITED with a Retry-After header.",
+	})
+
+	email := openapi.QueryParam("email", "The user's email", true, openapi.String("email"))
+	units := openapi.QueryParam("units", "Units of amounts. Defaults to imperial.", false,
+		openapi.Enum("imperial", "metric"))
+	id := func(description string) openapi.Parameter {
+		return openapi.PathParam("id", description, openapi.String("uuid"))
+	}
+	status := b.JSONResponse("Done", apiv1.Status{})
+	noContent := &openapi.Response{Description: "Done"}
+
+	add := func(pattern string, op *openapi.Operation, errs []apierror.Code) {
+		if !routePolicy(pattern).IsZero() {
+			errs = append(errs, apierror.RateLimited)
+		}
+		byStatus := map[int][]apierror.Code{}
+		for _, code := range errs {
+			byStatus[code.Status()] = append(byStatus[code.Status()], code)
+		}
+		for status, codes := range byStatus {
+			op.Responses[strconv.Itoa(status)] = errorResponse(b, status, codes...)
+		}
+		if limited, ok := op.Responses[strconv.Itoa(http.StatusTooManyRequests)]; ok {
+			limited.Headers = map[string]openapi.Header{"Retry-After": {
+				Description: "Seconds until the request would be allowed",
+				Required:    true,
+				Schema:      openapi.Integer(),
+			}}
+		}
+		b.Add(pattern, op)
+	}
+
+	add("GET /health", &openapi.Operation{
+		OperationID: "getHealth",
+		Summary:     "Check the API is up",
+		Tags:        []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "OK", Content: map[string]openapi.MediaType{"text/plain": {}}},
+		},
+	}, nil)
+	add("GET "+health.LivePath, &openapi.Operation{
+		OperationID: "getLiveness",
+		Summary:     "Check the API is serving",
+		Description: "Checks nothing but that the process answers, for restarting it when it doesn't.",
+		Tags:        []string{"meta"},
+		Responses:   map[string]*openapi.Response{"200": b.JSONResponse("Live", health.Report{})},
+	}, nil)
+	add("GET "+health.ReadyPath, &openapi.Operation{
+		OperationID: "getReadiness",
+		Summary:     "Check the API is ready to serve",
+		Description: "Checks the database can be reached and is migrated to the schema the API was built with, " +
+			"and that providers can be reached if READYZ_CHECK_PROVIDERS is set, each within a timeout. The result " +
+			"of each check is reported by name. Not proxied to the public site.",
+		Tags: []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": b.JSONResponse("Every check passed", health.Report{}),
+			"503": b.JSONResponse("A check failed", health.Report{}),
+		},
+	}, nil)
+	add("GET "+OpenAPIPath, &openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "Get this document",
+		Tags:        []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The OpenAPI document", Content: map[string]openapi.MediaType{"application/json": {}}},
+		},
+	}, nil)
+
+	add("GET "+metrics.Path, &openapi.Operation{
+		OperationID: "getMetrics",
+		Summary:     "Get Prometheus metrics",
+		Description: "Request counts and latencies, database query latencies and counts of active alerts and users, " +
+			"in the Prometheus text exposition format. Not proxied to the public site.",
+		Tags: []string{"meta"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The metrics", Content: map[string]openapi.MediaType{"text/plain": {}}},
+		},
+	}, nil)
+
+	add("GET /api/v1/resorts", &openapi.Operation{
+		OperationID: "listResorts",
+		Summary:     "List resorts",
+		Tags:        []string{"resorts"},
+		Responses:   map[string]*openapi.Response{"200": b.JSONResponse("The resorts", []apiv1.Resort{})},
+	}, []apierror.Code{apierror.InternalError})
+	add("GET /api/v1/resorts/{id}", &openapi.Operation{
+		OperationID: "getResort",
+		Summary:     "Get a resort and its latest forecast",
+		Description: "Served from the forecast the forecaster last stored, from today onwards.",
+		Tags:        []string{"resorts"},
+		Parameters:  []openapi.Parameter{id("The resort's UUID"), units},
+		Responses: map[string]*openapi.Response{
+			"200": b.JSONResponse("The resort", apiv1.ResortDetail{}),
+		},
+	}, []apierror.Code{apierror.InvalidUnits, apierror.ResortNotFound, apierror.InternalError})
+
+	add("POST /api/v1/alerts", &openapi.Operation{
+		OperationID: "createAlert",
+		Summary:     "Sign up for alerts on resorts",
+		Description: "Creates the user if they're new, and an alert on each resort.",
+		Tags:        []string{"alerts"},
+		RequestBody: b.JSONBody(CreateAlertRequest{}),
+		Responses:   map[string]*openapi.Response{"201": b.JSONResponse("Alerts created", apiv1.Status{})},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.MissingEmail, apierror.MissingPhone, apierror.InvalidPhone,
+		apierror.MissingResorts, apierror.MissingRequiredField, apierror.ValidationError, apierror.DuplicateAlert,
+		apierror.DuplicateEntry, apierror.InternalError,
+	})
+	add("GET /api/v1/me/alerts", &openapi.Operation{
+		OperationID: "listMyAlerts",
+		Summary:     "List a user's alerts",
+		Tags:        []string{"alerts"},
+		Parameters:  []openapi.Parameter{email},
+		Responses:   map[string]*openapi.Response{"200": b.JSONResponse("The alerts", []apiv1.Alert{})},
+	}, []apierror.Code{apierror.MissingEmail, apierror.InternalError})
+	add("DELETE /api/v1/me/alerts", &openapi.Operation{
+		OperationID: "deleteMyAlerts",
+		Summary:     "Delete all of a user's alerts",
+		Tags:        []string{"alerts"},
+		Parameters:  []openapi.Parameter{email},
+		Responses:   map[string]*openapi.Response{"200": status},
+	}, []apierror.Code{apierror.MissingEmail, apierror.InternalError})
+	add("DELETE /api/v1/me/alerts/{resortId}", &openapi.Operation{
+		OperationID: "deleteMyAlert",
+		Summary:     "Delete a user's alert on a resort",
+		Tags:        []string{"alerts"},
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("resortId", "The resort's UUID", openapi.String("uuid")),
+			email,
+		},
+		Responses: map[string]*openapi.Response{"200": status},
+	}, []apierror.Code{apierror.MissingEmail, apierror.MissingResort, apierror.InternalError})
+
+	add("GET /api/v1/me/deliveries", &openapi.Operation{
+		OperationID: "listMyDeliveries",
+		Summary:     "List a user's recent notifications and their delivery status",
+		Tags:        []string{"notifications"},
+		Parameters: []openapi.Parameter{
+			email,
+			openapi.QueryParam("limit", "How many to list, from 1 to 100. Defaults to 20.", false, openapi.Integer()),
+		},
+		Responses: map[string]*openapi.Response{"200": b.JSONResponse("The deliveries", []apiv1.Delivery{})},
+	}, []apierror.Code{apierror.MissingEmail, apierror.InvalidLimit, apierror.InternalError})
+	add("PUT /api/v1/me/preferences", &openapi.Operation{
+		OperationID: "updateMyPreferences",
+		Summary:     "Set a user's notification preferences",
+		Description: "Omitted or null fields reset to the default.",
+		Tags:        []string{"notifications"},
+		RequestBody: b.JSONBody(UpdatePreferencesRequest{}),
+		Responses:   map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail, apierror.InvalidLimit,
+		apierror.InvalidUnits, apierror.InvalidLocale, apierror.InvalidTopic, apierror.UserNotFound,
+		apierror.InternalError,
+	})
+
+	add("POST /api/v1/me/destinations", &openapi.Operation{
+		OperationID: "createMyDestination",
+		Summary:     "Also send an alert to a Slack or Discord channel",
+		Tags:        []string{"notifications"},
+		RequestBody: b.JSONBody(CreateDestinationRequest{}),
+		Responses:   map[string]*openapi.Response{"201": b.JSONResponse("The destination", apiv1.Destination{})},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail, apierror.MissingResort,
+		apierror.InvalidChannel,
+		apierror.InvalidURL, apierror.UserNotFound, apierror.AlertNotFound, apierror.DestinationLimit,
+		apierror.InternalError,
+	})
+	add("GET /api/v1/me/destinations", &openapi.Operation{
+		OperationID: "listMyDestinations",
+		Summary:     "List a user's destinations",
+		Tags:        []string{"notifications"},
+		Parameters:  []openapi.Parameter{email},
+		Responses: map[string]*openapi.Response{
+			"200": b.JSONResponse("The destinations", []apiv1.Destination{}),
+		},
+	}, []apierror.Code{apierror.MissingEmail, apierror.InternalError})
+	add("DELETE /api/v1/me/destinations/{id}", &openapi.Operation{
+		OperationID: "deleteMyDestination",
+		Summary:     "Delete one of a user's destinations",
+		Tags:        []string{"notifications"},
+		Parameters:  []openapi.Parameter{id("The destination's UUID"), email},
+		Responses:   map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{
+		apierror.MissingEmail, apierror.InvalidDestination, apierror.DestinationNotFound, apierror.InternalError,
+	})
+
+	add("POST /api/v1/me/webhooks", &openapi.Operation{
+		OperationID: "createMyWebhook",
+		Summary:     "Register a webhook",
+		Description: "The response includes the webhook's signing secret. This is the only time it is shown.",
+		Tags:        []string{"notifications"},
+		RequestBody: b.JSONBody(CreateWebhookRequest{}),
+		Responses:   map[string]*openapi.Response{"201": b.JSONResponse("The webhook", apiv1.Webhook{})},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail, apierror.MissingURL,
+		apierror.InvalidURL, apierror.UserNotFound, apierror.WebhookLimit, apierror.InternalError,
+	})
+	add("GET /api/v1/me/webhooks", &openapi.Operation{
+		OperationID: "listMyWebhooks",
+		Summary:     "List a user's webhooks, without secrets",
+		Tags:        []string{"notifications"},
+		Parameters:  []openapi.Parameter{email},
+		Responses:   map[string]*openapi.Response{"200": b.JSONResponse("The webhooks", []apiv1.Webhook{})},
+	}, []apierror.Code{apierror.MissingEmail, apierror.InternalError})
+	add("DELETE /api/v1/me/webhooks/{id}", &openapi.Operation{
+		OperationID: "deleteMyWebhook",
+		Summary:     "Delete one of a user's webhooks",
+		Tags:        []string{"notifications"},
+		Parameters:  []openapi.Parameter{id("The webhook's UUID"), email},
+		Responses:   map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{
+		apierror.MissingEmail, apierror.InvalidWebhook, apierror.WebhookNotFound, apierror.InternalError,
+	})
+	add("POST /api/v1/me/webhooks/{id}/test", &openapi.Operation{
+		OperationID: "testMyWebhook",
+		Summary:     "Send a test event to a webhook",
+		Tags:        []string{"notifications"},
+		Parameters:  []openapi.Parameter{id("The webhook's UUID")},
+		RequestBody: b.JSONBody(TestWebhookRequest{}),
+		Responses: map[string]*openapi.Response{
+			"200": b.JSONResponse("The webhook accepted the event", apiv1.WebhookTest{}),
+		},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail, apierror.InvalidWebhook,
+		apierror.WebhookNotFound, apierror.InternalError, apierror.WebhookFailed,
+	})
+
+	add("GET /api/v1/push/vapid-public-key", &openapi.Operation{
+		OperationID: "getVAPIDPublicKey",
+		Summary:     "Get the applicationServerKey browsers subscribe to Web Push with",
+		Tags:        []string{"notifications"},
+		Responses: map[string]*openapi.Response{
+			"200": b.JSONResponse("The public key", apiv1.VAPIDPublicKey{}),
+		},
+	}, []apierror.Code{apierror.PushNotConfigured})
+	add("POST /api/v1/me/push-subscriptions", &openapi.Operation{
+		OperationID: "createMyPushSubscription",
+		Summary:     "Save a browser's push subscription",
+		Description: "Subscribing again replaces the subscription's keys.",
+		Tags:        []string{"notifications"},
+		RequestBody: b.JSONBody(CreatePushSubscriptionRequest{}),
+		Responses: map[string]*openapi.Response{
+			"201": b.JSONResponse("The subscription", apiv1.PushSubscription{}),
+		},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail, apierror.InvalidSubscription,
+		apierror.PushNotConfigured, apierror.UserNotFound, apierror.SubscriptionLimit, apierror.InternalError,
+	})
+	add("DELETE /api/v1/me/push-subscriptions", &openapi.Operation{
+		OperationID: "deleteMyPushSubscription",
+		Summary:     "Delete a browser's push subscription",
+		Tags:        []string{"notifications"},
+		Parameters: []openapi.Parameter{
+			email,
+			openapi.QueryParam("endpoint", "The subscription's push endpoint", true, openapi.String("uri")),
+		},
+		Responses: map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{
+		apierror.MissingEmail, apierror.MissingEndpoint, apierror.SubscriptionNotFound, apierror.InternalError,
+	})
+
+	add("POST /api/v1/me/calendar", &openapi.Operation{
+		OperationID: "createMyCalendar",
+		Summary:     "Create a calendar feed of a user's powder days",
+		Description: "The feed's URL is texted to the user's phone, never returned. Creating a feed again " +
+			"replaces its URL, and the old one stops working. The response is the same whether or not the " +
+			"email has an account with a number to text.",
+		Tags:        []string{"calendar"},
+		RequestBody: b.JSONBody(CreateCalendarRequest{}),
+		Responses: map[string]*openapi.Response{
+			"202": b.JSONResponse("The request was accepted", apiv1.Status{}),
+		},
+	}, []apierror.Code{
+		apierror.SMSNotConfigured, apierror.InvalidRequest, apierror.ValidationError, apierror.MissingEmail,
+		apierror.InternalError,
+	})
+	add("GET "+CalendarFeedPrefix+"{file}", &openapi.Operation{
+		OperationID: "getCalendarFeed",
+		Summary:     "Get a calendar feed",
+		Tags:        []string{"calendar"},
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("file", "The feed's token followed by .ics", openapi.String("")),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The feed", Content: map[string]openapi.MediaType{mediaType(calendar.ContentType): {}}},
+		},
+	}, []apierror.Code{apierror.CalendarNotFound, apierror.InternalError})
+	add("DELETE "+CalendarFeedPrefix+"{file}", &openapi.Operation{
+		OperationID: "deleteCalendarFeed",
+		Summary:     "Delete a calendar feed",
+		Tags:        []string{"calendar"},
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("file", "The feed's token followed by .ics", openapi.String("")),
+		},
+		Responses: map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{apierror.CalendarNotFound, apierror.InternalError})
+
+	atomFeed := &openapi.Response{
+		Description: "The feed",
+		Content:     map[string]openapi.MediaType{mediaType(atom.ContentType): {}},
+	}
+	add("GET /api/feeds/resorts.atom", &openapi.Operation{
+		OperationID: "getResortsFeed",
+		Summary:     "Get an Atom feed of powder forecasts at every resort",
+		Tags:        []string{"feeds"},
+		Parameters:  []openapi.Parameter{units},
+		Responses:   map[string]*openapi.Response{"200": atomFeed},
+	}, []apierror.Code{apierror.InvalidUnits, apierror.InternalError})
+	add("GET /api/feeds/resorts/{file}", &openapi.Operation{
+		OperationID: "getResortFeed",
+		Summary:     "Get an Atom feed of powder forecasts at a resort",
+		Tags:        []string{"feeds"},
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("file", "The resort's UUID followed by .atom", openapi.String("")),
+			units,
+		},
+		Responses: map[string]*openapi.Response{"200": atomFeed},
+	}, []apierror.Code{apierror.InvalidUnits, apierror.FeedNotFound, apierror.InternalError})
+
+	add("POST /api/v1/contact", &openapi.Operation{
+		OperationID: "sendContactMessage",
+		Summary:     "Send a message to the team",
+		Tags:        []string{"contact"},
+		RequestBody: b.JSONBody(ContactRequest{}),
+		Responses:   map[string]*openapi.Response{"200": status},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.ValidationError, apierror.MissingName, apierror.MissingEmail,
+		apierror.MissingMessage, apierror.InternalError,
+	})
+
+	twilioForm := &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]openapi.MediaType{"application/x-www-form-urlencoded": {}},
+	}
+	add("POST /api/v1/sms/inbound", &openapi.Operation{
+		OperationID: "receiveSMS",
+		Summary:     "Twilio webhook for incoming texts",
+		Description: "Handles STOP, START, HELP and PAUSE. Requests must be signed by Twilio.",
+		Tags:        []string{"twilio"},
+		RequestBody: twilioForm,
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "A TwiML reply", Content: map[string]openapi.MediaType{"text/xml": {}}},
+		},
+	}, []apierror.Code{apierror.InvalidRequest, apierror.InvalidSignature, apierror.InternalError})
+	add("POST /api/v1/sms/status", &openapi.Operation{
+		OperationID: "receiveSMSStatus",
+		Summary:     "Twilio status callback for sent texts",
+		Description: "Requests must be signed by Twilio.",
+		Tags:        []string{"twilio"},
+		RequestBody: twilioForm,
+		Responses:   map[string]*openapi.Response{"204": noContent},
+	}, []apierror.Code{
+		apierror.InvalidRequest, apierror.MissingMessageSID, apierror.InvalidSignature, apierror.InternalError,
+	})
+
+	page := func(description string) *openapi.Response {
+		return &openapi.Response{
+			Description: description,
+			Content:     map[string]openapi.MediaType{"text/html": {}},
+		}
+	}
+	token := openapi.PathParam("token", "The signed token from the link", openapi.String(""))
+	add("GET /u/{token}", &openapi.Operation{
+		OperationID: "getUnsubscribePage",
+		Summary:     "Show the page confirming an unsubscribe link",
+		Tags:        []string{"unsubscribe"},
+		Parameters:  []openapi.Parameter{token},
+		Responses: map[string]*openapi.Response{
+			"200": page("The confirmation page"),
+			"400": page("The link is not valid"),
+			"404": page("Unsubscribe links are not available"),
+			"410": page("The link has expired"),
+		},
+	}, nil)
+	add("POST /u/{token}", &openapi.Operation{
+		OperationID: "unsubscribe",
+		Summary:     "Unsubscribe from the alert, or all alerts, a link is for",
+		Tags:        []string{"unsubscribe"},
+		Parameters:  []openapi.Parameter{token},
+		Responses: map[string]*openapi.Response{
+			"200": page("Unsubscribed"),
+			"400": page("The link is not valid"),
+			"404": page("Unsubscribe links are not available"),
+			"410": page("The link has expired"),
+			"500": page("Unsubscribing failed"),
+		},
+	}, nil)
+
+	return b.Document()
+}
+
+// errorResponse documents an error response, allowing only the codes an operation returns with its status.
+// Requests that accept application/problem+json get the same codes as problem details.
+func errorResponse(b *openapi.Builder, status int, codes ...apierror.Code) *openapi.Response {
+	names := make([]string, 0, len(codes))
+	for _, code := range codes {
+		names = append(names, string(code))
+	}
+	withCodes := func(body any, property string) openapi.MediaType {
+		return openapi.MediaType{Schema: &openapi.Schema{AllOf: []*openapi.Schema{
+			b.Schema(body),
+			{Properties: map[string]*openapi.Schema{property: openapi.Enum(names...)}},
+		}}}
+	}
+
+	return &openapi.Response{
+		Description: http.StatusText(status) + ": " + strings.Join(names, ", "),
+		Content: map[string]openapi.MediaType{
+			"application/json":        withCodes(ErrorResponse{}, "error"),
+			apierror.ProblemMediaType: withCodes(apierror.Problem{}, "code"),
+		},
+	}
+}
+
+// mediaType strips the parameters from a content type.
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return mediaType
+}
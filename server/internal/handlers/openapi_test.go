@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve routes req to the handlers set in h, as the API does, and checks the response against the
+// OpenAPI document. Every handler test goes through it, so they fail when a handler and the document drift
+// apart.
+func serve(t *testing.T, h *Handlers, rr *httptest.ResponseRecorder, req *http.Request) {
+	t.Helper()
+
+	routes := h.Routes().(*router)
+	_, pattern := routes.mux.Handler(req)
+	routes.ServeHTTP(rr, req)
+
+	// Requests no route matches get the router's own errors, and HEAD responses have no body to check.
+	if pattern == "" || req.Method == http.MethodHead {
+		return
+	}
+
+	spec := apiSpec()
+	op := spec.Find(pattern)
+	if op == nil {
+		// Deprecated routes aren't documented.
+		return
+	}
+	assert.NoError(t, spec.ValidateResponse(op, rr.Code, rr.Header(), rr.Body.Bytes()),
+		"%s %s: response doesn't match the OpenAPI document", req.Method, req.URL)
+}
+
+func TestOpenAPI_DescribesEveryRoute(t *testing.T) {
+	routes := (&Handlers{}).Routes().(*router)
+	assert.ElementsMatch(t, routes.patterns, apiSpec().Patterns())
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{}, rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var document struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	assert.Contains(t, document.Paths["/api/v1/resorts/{id}"], "get")
+	assert.Contains(t, document.Paths["/api/v1/me/alerts/{resortId}"], "delete")
+	assert.NotContains(t, document.Paths, "/api/resorts", "deprecated routes aren't documented")
+	for _, name := range []string{"Resort", "ResortDetail", "CreateAlertRequest", "ErrorResponse"} {
+		assert.Contains(t, document.Components.Schemas, name)
+	}
+
+	operationIDs := map[string]bool{}
+	for path, item := range document.Paths {
+		for method, op := range item {
+			id, _ := op["operationId"].(string)
+			require.NotEmpty(t, id, "%s %s has no operationId", method, path)
+			assert.False(t, operationIDs[id], "operationId %s is used twice", id)
+			operationIDs[id] = true
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+const (
+	maxAlertsPerDayLimit        = 50
+	maxAlertSpacingMinutesLimit = 24 * 60
+)
+
+type PreferencesHandler struct {
+	store        db.StoreService
+	requireHTTPS bool
+}
+
+// UpdatePreferencesRequest sets a user's notification preferences. Omitted or null fields reset to the
+// default: the deployment-wide limits, imperial units, the en-US locale and no push notifications.
+type UpdatePreferencesRequest struct {
+	Email                  string `json:"email" validate:"email,max=254"`
+	MaxAlertsPerDay        *int32 `json:"max_alerts_per_day"`
+	MinAlertSpacingMinutes *int32 `json:"min_alert_spacing_minutes"`
+	// Units is "imperial" or "metric". Alert thresholds are always given in inches.
+	Units string `json:"units"`
+	// Locale is a BCP 47 tag such as "en-US" or "fr-CA", matched to the closest supported locale.
+	Locale string `json:"locale"`
+	// NtfyTopicURL is an ntfy topic to push alerts to, e.g. "https://ntfy.sh/my-powder-alerts".
+	NtfyTopicURL string `json:"ntfy_topic_url" validate:"max=2048"`
+}
+
+func NewPreferencesHandler(store db.StoreService) (*PreferencesHandler, error) {
+	return &PreferencesHandler{
+		store:        store,
+		requireHTTPS: os.Getenv("ENVIRONMENT") == "production",
+	}, nil
+}
+
+// UpdatePreferences replaces a user's notification limits, units, locale and push topic.
+func (h *PreferencesHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	var req UpdatePreferencesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	if req.MaxAlertsPerDay != nil && (*req.MaxAlertsPerDay < 1 || *req.MaxAlertsPerDay > maxAlertsPerDayLimit) {
+		sendError(w, r, apierror.InvalidLimit, "Max alerts per day must be between 1 and 50")
+		return
+	}
+
+	if req.MinAlertSpacingMinutes != nil &&
+		(*req.MinAlertSpacingMinutes < 0 || *req.MinAlertSpacingMinutes > maxAlertSpacingMinutesLimit) {
+		sendError(w, r, apierror.InvalidLimit, "Alert spacing must be between 0 and 1440 minutes")
+		return
+	}
+
+	system, err := units.ParseSystem(req.Units)
+	if err != nil {
+		sendError(w, r, apierror.InvalidUnits, "Units must be imperial or metric")
+		return
+	}
+
+	locale, err := notify.ParseLocale(req.Locale)
+	if err != nil {
+		sendError(w, r, apierror.InvalidLocale, "Locale is not supported")
+		return
+	}
+
+	if req.NtfyTopicURL != "" {
+		if err := notify.ValidateNtfyTopicURL(req.NtfyTopicURL, h.requireHTTPS); err != nil {
+			sendError(w, r, apierror.InvalidTopic, "Push topic is not valid: "+err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	err = h.store.SetPreferences(ctx, req.Email, db.Preferences{
+		Limits: db.NotificationLimits{
+			MaxPerDay:         req.MaxAlertsPerDay,
+			MinSpacingMinutes: req.MinAlertSpacingMinutes,
+		},
+		Units:        system,
+		Locale:       locale,
+		NtfyTopicURL: req.NtfyTopicURL,
+	})
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to update preferences")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPreferencesHandler_UpdatePreferences(t *testing.T) {
+	three, ninety := int32(3), int32(90)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+	}{
+		{
+			name:   "Sets both limits",
+			method: http.MethodPut,
+			body:   `{"email":"test@example.com","max_alerts_per_day":3,"min_alert_spacing_minutes":90}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "test@example.com", db.Preferences{
+						Limits: db.NotificationLimits{
+							MaxPerDay:         &three,
+							MinSpacingMinutes: &ninety,
+						},
+						Units:  units.Imperial,
+						Locale: "en-US",
+					}).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Sets units and locale",
+			method: http.MethodPut,
+			body:   `{"email":"test@example.com","units":"metric","locale":"fr-CA"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "test@example.com", db.Preferences{
+						Units:  units.Metric,
+						Locale: "fr-CA",
+					}).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Omitted preferences reset to defaults",
+			method: http.MethodPut,
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "test@example.com", db.Preferences{
+						Units:  units.Imperial,
+						Locale: "en-US",
+					}).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Sets a push topic",
+			method: http.MethodPut,
+			body:   `{"email":"test@example.com","ntfy_topic_url":"https://ntfy.sh/my-powder-alerts"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "test@example.com", db.Preferences{
+						Units:        units.Imperial,
+						Locale:       "en-US",
+						NtfyTopicURL: "https://ntfy.sh/my-powder-alerts",
+					}).
+					Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Invalid push topic",
+			method:         http.MethodPut,
+			body:           `{"email":"test@example.com","ntfy_topic_url":"https://ntfy.sh/"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_TOPIC",
+				Message: "Push topic is not valid: topic URL must end in a topic name of up to 64 letters, digits, - or _",
+			},
+		},
+		{
+			name:           "Unknown units",
+			method:         http.MethodPut,
+			body:           `{"email":"test@example.com","units":"furlongs"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_UNITS",
+				Message: "Units must be imperial or metric",
+			},
+		},
+		{
+			name:           "Unsupported locale",
+			method:         http.MethodPut,
+			body:           `{"email":"test@example.com","locale":"ja-JP"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_LOCALE",
+				Message: "Locale is not supported",
+			},
+		},
+		{
+			name:           "Max per day out of range",
+			method:         http.MethodPut,
+			body:           `{"email":"test@example.com","max_alerts_per_day":0}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_LIMIT",
+				Message: "Max alerts per day must be between 1 and 50",
+			},
+		},
+		{
+			name:           "Spacing out of range",
+			method:         http.MethodPut,
+			body:           `{"email":"test@example.com","min_alert_spacing_minutes":2000}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_LIMIT",
+				Message: "Alert spacing must be between 0 and 1440 minutes",
+			},
+		},
+		{
+			name:           "Missing email",
+			method:         http.MethodPut,
+			body:           `{"max_alerts_per_day":3}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_EMAIL",
+				Message: "Email is required",
+			},
+		},
+		{
+			name:   "Unknown user",
+			method: http.MethodPut,
+			body:   `{"email":"nobody@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "nobody@example.com", gomock.Any()).
+					Return(db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "USER_NOT_FOUND",
+				Message: "No user with that email",
+			},
+		},
+		{
+			name:   "Store error",
+			method: http.MethodPut,
+			body:   `{"email":"test@example.com"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SetPreferences(gomock.Any(), "test@example.com", gomock.Any()).
+					Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to update preferences",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodGet,
+			body:           "",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewPreferencesHandler(mockStore)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, "/api/v1/me/preferences", strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Preferences: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/webpush"
+)
+
+// PushHandler lets browsers subscribe to Web Push alerts.
+type PushHandler struct {
+	store db.StoreService
+	// publicKey is the VAPID public key browsers subscribe with. It is empty when Web Push isn't configured.
+	publicKey    string
+	requireHTTPS bool
+}
+
+// PushSubscriptionJSON is a browser PushSubscription, as returned by its toJSON method.
+type PushSubscriptionJSON struct {
+	Endpoint string `json:"endpoint" validate:"max=2048"`
+	// ExpirationTime is sent by browsers, but not used: expired subscriptions are removed when pushes to them
+	// fail.
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256DH string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+type CreatePushSubscriptionRequest struct {
+	Email        string               `json:"email" validate:"email,max=254"`
+	Subscription PushSubscriptionJSON `json:"subscription"`
+}
+
+// NewPushHandler returns a handler for subscriptions to pushes signed with keys, which may be nil when Web
+// Push isn't configured. Push endpoints must use https in production.
+func NewPushHandler(store db.StoreService, keys *webpush.VAPIDKeys) (*PushHandler, error) {
+	handler := &PushHandler{
+		store:        store,
+		requireHTTPS: os.Getenv("ENVIRONMENT") == "production",
+	}
+	if keys != nil {
+		handler.publicKey = keys.PublicKey()
+	}
+	return handler, nil
+}
+
+// GetPublicKey serves the applicationServerKey the client subscribes with.
+func (h *PushHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	if h.publicKey == "" {
+		sendError(w, r, apierror.PushNotConfigured, "Push notifications are not available")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(apiv1.VAPIDPublicKey{PublicKey: h.publicKey}); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode public key response", "error", err)
+	}
+}
+
+// CreateSubscription saves a browser's push subscription.
+func (h *PushHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	if h.publicKey == "" {
+		sendError(w, r, apierror.PushNotConfigured, "Push notifications are not available")
+		return
+	}
+
+	var req CreatePushSubscriptionRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	subscription := webpush.Subscription{
+		Endpoint: req.Subscription.Endpoint,
+		P256DH:   req.Subscription.Keys.P256DH,
+		Auth:     req.Subscription.Keys.Auth,
+	}
+	if err := notify.ValidateWebhookURL(subscription.Endpoint, h.requireHTTPS); err != nil {
+		sendError(w, r, apierror.InvalidSubscription, "Push endpoint is not valid")
+		return
+	}
+	if err := subscription.Validate(); err != nil {
+		sendError(w, r, apierror.InvalidSubscription, "Push subscription is not valid: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	saved, err := h.store.SavePushSubscription(
+		ctx,
+		req.Email,
+		subscription.Endpoint,
+		subscription.P256DH,
+		subscription.Auth,
+	)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to save subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(apiv1.NewPushSubscription(saved)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode push subscription response", "error", err)
+	}
+}
+
+// DeleteSubscription removes a browser's push subscription.
+func (h *PushHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	endpoint := r.URL.Query().Get("endpoint")
+	if endpoint == "" {
+		sendError(w, r, apierror.MissingEndpoint, "Endpoint parameter is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.DeletePushSubscription(ctx, email, endpoint); err != nil {
+		sendStoreError(w, r, err, "Failed to delete subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
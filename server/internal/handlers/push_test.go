@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/webpush"
+)
+
+const (
+	testPushEndpoint = "https://fcm.googleapis.com/fcm/send/abc:def"
+	testPushP256DH   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	testPushAuth     = "BTBZMqHH6r4Tts7J_aSIgg"
+)
+
+func TestPushHandler_GetPublicKey(t *testing.T) {
+	keys, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	handler, err := NewPushHandler(nil, keys)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Push: handler}, rr, httptest.NewRequest(http.MethodGet, "/api/v1/push/vapid-public-key", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"public_key":"`+keys.PublicKey()+`"}`, rr.Body.String())
+
+	unconfigured, err := NewPushHandler(nil, nil)
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	unconfigured.GetPublicKey(rr, httptest.NewRequest(http.MethodGet, "/api/v1/push/vapid-public-key", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), "PUSH_NOT_CONFIGURED")
+}
+
+func TestPushHandler_HandleSubscriptions(t *testing.T) {
+	subscription := dbgen.PushSubscription{
+		Uuid:      uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"),
+		Endpoint:  testPushEndpoint,
+		P256dh:    testPushP256DH,
+		Auth:      testPushAuth,
+		CreatedAt: time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC),
+	}
+	createBody := `{"email":"test@example.com","subscription":{"endpoint":"` + testPushEndpoint +
+		`","expirationTime":null,"keys":{"p256dh":"` + testPushP256DH + `","auth":"` + testPushAuth + `"}}}`
+	deleteTarget := "/api/v1/me/push-subscriptions?email=test@example.com&endpoint=" + testPushEndpoint
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+		expectedBody   string
+	}{
+		{
+			name:   "Saves a subscription",
+			method: http.MethodPost,
+			target: "/api/v1/me/push-subscriptions",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SavePushSubscription(gomock.Any(), "test@example.com", testPushEndpoint, testPushP256DH, testPushAuth).
+					Return(subscription, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"uuid":"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d","created_at":"2025-12-18T06:00:00Z"}`,
+		},
+		{
+			name:           "Endpoint isn't a URL",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/push-subscriptions",
+			body:           strings.Replace(createBody, testPushEndpoint, "fcm.googleapis.com/fcm/send/abc", 1),
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_SUBSCRIPTION",
+				Message: "Push endpoint is not valid",
+			},
+		},
+		{
+			name:           "Malformed keys",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/push-subscriptions",
+			body:           strings.Replace(createBody, testPushAuth, "short", 1),
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_SUBSCRIPTION",
+				Message: "Push subscription is not valid: subscription auth secret must be 16 base64url bytes",
+			},
+		},
+		{
+			name:   "Unknown user",
+			method: http.MethodPost,
+			target: "/api/v1/me/push-subscriptions",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SavePushSubscription(gomock.Any(), "test@example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(dbgen.PushSubscription{}, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "USER_NOT_FOUND",
+				Message: "No user with that email",
+			},
+		},
+		{
+			name:   "Subscription limit reached",
+			method: http.MethodPost,
+			target: "/api/v1/me/push-subscriptions",
+			body:   createBody,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					SavePushSubscription(gomock.Any(), "test@example.com", gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(dbgen.PushSubscription{}, db.ErrPushSubscriptionLimit)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError: &ErrorResponse{
+				Error:   "SUBSCRIPTION_LIMIT",
+				Message: "Push alerts can go to up to 10 browsers",
+			},
+		},
+		{
+			name:   "Deletes a subscription",
+			method: http.MethodDelete,
+			target: deleteTarget,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().DeletePushSubscription(gomock.Any(), "test@example.com", testPushEndpoint).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Deleting an unknown subscription",
+			method: http.MethodDelete,
+			target: deleteTarget,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					DeletePushSubscription(gomock.Any(), "test@example.com", testPushEndpoint).
+					Return(db.ErrPushSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "SUBSCRIPTION_NOT_FOUND",
+				Message: "No subscription for that endpoint",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodGet,
+			target:         "/api/v1/me/push-subscriptions",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	keys, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewPushHandler(mockStore, keys)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Push: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/ratelimit"
+)
+
+var (
+	// defaultLimits protect every route without limits of its own from floods.
+	defaultLimits = ratelimit.Policy{
+		Name: "api",
+		IP:   ratelimit.Limit{Requests: 120, Per: time.Minute},
+	}
+	// alertLimits limit signing up, which writes to the database, sends email and will send SMS verification
+	// codes to whatever number it's given.
+	alertLimits = ratelimit.Policy{
+		Name:    "alerts",
+		IP:      ratelimit.Limit{Requests: 10, Per: time.Hour},
+		Account: ratelimit.Limit{Requests: 5, Per: time.Hour},
+	}
+	// contactLimits limit contact messages, which are emailed to support.
+	contactLimits = ratelimit.Policy{
+		Name:    "contact",
+		IP:      ratelimit.Limit{Requests: 5, Per: time.Hour},
+		Account: ratelimit.Limit{Requests: 3, Per: time.Hour},
+	}
+	// calendarLimits limit calendar links, which are texted to the user's phone.
+	calendarLimits = ratelimit.Policy{
+		Name:    "calendar",
+		IP:      ratelimit.Limit{Requests: 10, Per: time.Hour},
+		Account: ratelimit.Limit{Requests: 3, Per: time.Hour},
+	}
+	// webhookTestLimits limit webhook tests, which make requests to URLs users give.
+	webhookTestLimits = ratelimit.Policy{
+		Name:    "webhook-test",
+		IP:      ratelimit.Limit{Requests: 20, Per: time.Hour},
+		Account: ratelimit.Limit{Requests: 10, Per: time.Hour},
+	}
+)
+
+// routeLimits are the routes limited by something other than defaultLimits, by pattern. Deprecated aliases
+// have the same policy as their successor, so they share its buckets. Health checks, the OpenAPI document,
+// metrics, Twilio's signed webhooks and unsubscribe links aren't limited.
+var routeLimits = map[string]ratelimit.Policy{
+	"GET /health":             {},
+	"GET " + health.LivePath:  {},
+	"GET " + health.ReadyPath: {},
+	"GET " + OpenAPIPath:      {},
+	"GET " + metrics.Path:     {},
+
+	"POST /api/v1/alerts": alertLimits,
+	"POST /api/alerts":    alertLimits,
+
+	"POST /api/v1/contact": contactLimits,
+	"POST /api/contact":    contactLimits,
+
+	"POST /api/v1/me/calendar": calendarLimits,
+	"POST /api/user/calendar":  calendarLimits,
+
+	"POST /api/v1/me/webhooks/{id}/test": webhookTestLimits,
+	"POST /api/user/webhooks/test":       webhookTestLimits,
+
+	"POST /api/v1/sms/inbound": {},
+	"POST /api/v1/sms/status":  {},
+	"POST /api/sms/inbound":    {},
+	"POST /api/sms/status":     {},
+
+	"GET /u/{token}":  {},
+	"POST /u/{token}": {},
+}
+
+// routePolicy returns the policy limiting the route with the given pattern.
+func routePolicy(pattern string) ratelimit.Policy {
+	if policy, ok := routeLimits[pattern]; ok {
+		return policy
+	}
+	return defaultLimits
+}
+
+// limited returns a handler serving the route with the given pattern with next, once the route's policy
+// allows the request. Requests over a limit are answered with RATE_LIMITED and a Retry-After header. When
+// the limiter fails, requests are let through rather than failing with it.
+func (h *Handlers) limited(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	policy := routePolicy(pattern)
+	if h.Limiter == nil || policy.IsZero() {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		wait, err := h.Limiter.Allow(r, policy)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Rate limiter failed, allowing request", "error", err)
+		}
+		if err != nil || wait <= 0 {
+			next(w, r)
+			return
+		}
+
+		seconds := int(math.Ceil(wait.Seconds()))
+		setSecurityHeaders(w)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		sendError(w, r, apierror.RateLimited, fmt.Sprintf("Too many requests, try again in %d seconds", seconds))
+	}
+}
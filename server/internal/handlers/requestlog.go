@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request. An ID sent by the client or a proxy in front of the API is
+// kept, so that their logs can be matched with ours, and every response carries the ID it was logged with.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of the request IDs taken from clients.
+const maxRequestIDLength = 128
+
+// requestID returns the ID of r: the one it was sent with if it's a sane one, or a new one.
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+	return uuid.NewString()
+}
+
+// validRequestID reports whether id is short and made only of characters safe to log and echo in a header.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// logRequest logs a served request. Only the route's pattern is logged, never the path or query, which can
+// hold email addresses and the tokens of calendar feeds and unsubscribe links.
+func logRequest(ctx context.Context, r *http.Request, pattern string, rec *responseRecorder, elapsed time.Duration) {
+	level := slog.LevelInfo
+	if rec.status >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "Request served",
+		"method", r.Method,
+		"route", pattern,
+		"status", rec.status,
+		"bytes", rec.bytes,
+		"duration_ms", float64(elapsed)/float64(time.Millisecond),
+	)
+}
+
+// responseRecorder passes a response through, recording its status and size.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// GetResort serves a resort's details, from the forecast the forecaster last stored. Amounts are in
+// inches and °F unless ?units=metric is given.
+func (h *ResortHandler) GetResort(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	resortUUID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		sendError(w, r, apierror.ResortNotFound, "Resort not found")
+		return
+	}
+
+	system, err := units.ParseSystem(r.URL.Query().Get("units"))
+	if err != nil {
+		sendError(w, r, apierror.InvalidUnits, "Units must be imperial or metric")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	detail, err := h.store.GetResortDetail(ctx, resortUUID, today)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve resort")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(apiv1.NewResortDetail(detail, system)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode resort response", "error", err)
+	}
+}
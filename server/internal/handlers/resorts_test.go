@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+)
+
+func TestResortHandler_GetResort(t *testing.T) {
+	resortUUID := uuid.MustParse("9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d")
+	detail := db.ResortDetail{
+		Resort: dbgen.Resort{
+			ID:          7,
+			Uuid:        resortUUID,
+			Name:        "Crystal Mountain",
+			UrlHost:     sql.NullString{String: "www.crystalmountainresort.com", Valid: true},
+			UrlPathname: sql.NullString{String: "/snow-report", Valid: true},
+			Latitude:    sql.NullFloat64{Float64: 46.9282, Valid: true},
+			Longitude:   sql.NullFloat64{Float64: -121.5045, Valid: true},
+		},
+		SubscriberCount: 42,
+		Forecasts: []db.ResortForecast{
+			{
+				Date:           time.Date(2025, 12, 18, 0, 0, 0, 0, time.UTC),
+				SnowAmount:     8.5,
+				MinTemperature: 23,
+				MaxTemperature: 32,
+				AvgTemperature: 27.5,
+			},
+			{
+				Date:           time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC),
+				MinTemperature: 28,
+				MaxTemperature: 39,
+				AvgTemperature: 33,
+			},
+		},
+		RefreshedAt: time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+		expectedBody   string
+	}{
+		{
+			name:   "Returns the resort and its forecast",
+			method: http.MethodGet,
+			target: "/api/v1/resorts/" + resortUUID.String(),
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResortDetail(gomock.Any(), resortUUID, gomock.Any()).Return(detail, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"name": "Crystal Mountain",
+				"url": {"host": "www.crystalmountainresort.com", "pathname": "/snow-report"},
+				"lat": 46.9282,
+				"lon": -121.5045,
+				"subscriber_count": 42,
+				"units": "imperial",
+				"forecast_updated_at": "2025-12-18T06:00:00Z",
+				"forecast": [
+					{"date": "2025-12-18", "snow_amount": 8.5, "min_temperature": 23, "max_temperature": 32, "avg_temperature": 27.5},
+					{"date": "2025-12-19", "snow_amount": 0, "min_temperature": 28, "max_temperature": 39, "avg_temperature": 33}
+				]
+			}`,
+		},
+		{
+			name:   "Metric units",
+			method: http.MethodGet,
+			target: "/api/v1/resorts/" + resortUUID.String() + "?units=metric",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResortDetail(gomock.Any(), resortUUID, gomock.Any()).Return(detail, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"name": "Crystal Mountain",
+				"url": {"host": "www.crystalmountainresort.com", "pathname": "/snow-report"},
+				"lat": 46.9282,
+				"lon": -121.5045,
+				"subscriber_count": 42,
+				"units": "metric",
+				"forecast_updated_at": "2025-12-18T06:00:00Z",
+				"forecast": [
+					{"date": "2025-12-18", "snow_amount": 21.6, "min_temperature": -5, "max_temperature": 0, "avg_temperature": -2.5},
+					{"date": "2025-12-19", "snow_amount": 0, "min_temperature": -2.2, "max_temperature": 3.9, "avg_temperature": 0.6}
+				]
+			}`,
+		},
+		{
+			name:   "No stored forecast yet",
+			method: http.MethodGet,
+			target: "/api/v1/resorts/" + resortUUID.String(),
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetResortDetail(gomock.Any(), resortUUID, gomock.Any()).
+					Return(db.ResortDetail{Resort: dbgen.Resort{Uuid: resortUUID, Name: "Crystal Mountain"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"uuid": "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+				"name": "Crystal Mountain",
+				"url": null,
+				"lat": null,
+				"lon": null,
+				"subscriber_count": 0,
+				"units": "imperial",
+				"forecast_updated_at": null,
+				"forecast": []
+			}`,
+		},
+		{
+			name:   "Unknown resort",
+			method: http.MethodGet,
+			target: "/api/v1/resorts/" + resortUUID.String(),
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetResortDetail(gomock.Any(), resortUUID, gomock.Any()).
+					Return(db.ResortDetail{}, db.ErrResortNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "RESORT_NOT_FOUND",
+				Message: "Resort not found",
+			},
+		},
+		{
+			name:           "Malformed UUID",
+			method:         http.MethodGet,
+			target:         "/api/v1/resorts/crystal",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "RESORT_NOT_FOUND",
+				Message: "Resort not found",
+			},
+		},
+		{
+			name:           "Unknown units",
+			method:         http.MethodGet,
+			target:         "/api/v1/resorts/" + resortUUID.String() + "?units=kelvin",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_UNITS",
+				Message: "Units must be imperial or metric",
+			},
+		},
+		{
+			name:   "Store error",
+			method: http.MethodGet,
+			target: "/api/v1/resorts/" + resortUUID.String(),
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					GetResortDetail(gomock.Any(), resortUUID, gomock.Any()).
+					Return(db.ResortDetail{}, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to retrieve resort",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			target:         "/api/v1/resorts/" + resortUUID.String(),
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewResortHandler(mockStore)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Resort: handler}, rr, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+)
+
+// legacyRoutesDeprecatedAt is when the routes that predate /api/v1 were deprecated, sent in their
+// Deprecation header.
+var legacyRoutesDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// Routes returns the handler serving every route of the API.
+//
+// Feeds, calendars and unsubscribe links keep their unversioned paths, since they're published in feed
+// readers, calendar apps and sent alerts. Every other route is under /api/v1, and the paths it had before
+// are served as deprecated aliases until clients have moved.
+func (h *Handlers) Routes() http.Handler {
+	rt := newRouter(h.limited)
+
+	rt.handle("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	rt.handle("GET "+health.LivePath, health.Live)
+	rt.handle("GET "+health.ReadyPath, h.Health.Ready)
+	rt.handle("GET "+OpenAPIPath, ServeOpenAPI)
+	rt.handle("GET "+metrics.Path, metrics.Handler().ServeHTTP)
+
+	rt.handle("GET /api/v1/resorts", h.Resort.ListAllResorts)
+	rt.handle("GET /api/v1/resorts/{id}", h.Resort.GetResort)
+
+	rt.handle("POST /api/v1/alerts", h.Alert.CreateAlert)
+	rt.handle("GET /api/v1/me/alerts", h.Alert.GetUserAlerts)
+	rt.handle("DELETE /api/v1/me/alerts", h.Alert.DeleteAllUserAlerts)
+	rt.handle("DELETE /api/v1/me/alerts/{resortId}", h.Alert.DeleteUserAlert)
+
+	rt.handle("GET /api/v1/me/deliveries", h.Delivery.GetUserDeliveries)
+	rt.handle("PUT /api/v1/me/preferences", h.Preferences.UpdatePreferences)
+
+	rt.handle("POST /api/v1/me/destinations", h.Destination.CreateDestination)
+	rt.handle("GET /api/v1/me/destinations", h.Destination.ListDestinations)
+	rt.handle("DELETE /api/v1/me/destinations/{id}", h.Destination.DeleteDestination)
+
+	rt.handle("POST /api/v1/me/webhooks", h.Webhook.CreateWebhook)
+	rt.handle("GET /api/v1/me/webhooks", h.Webhook.ListWebhooks)
+	rt.handle("DELETE /api/v1/me/webhooks/{id}", h.Webhook.DeleteWebhook)
+	rt.handle("POST /api/v1/me/webhooks/{id}/test", h.Webhook.TestWebhook)
+
+	rt.handle("GET /api/v1/push/vapid-public-key", h.Push.GetPublicKey)
+	rt.handle("POST /api/v1/me/push-subscriptions", h.Push.CreateSubscription)
+	rt.handle("DELETE /api/v1/me/push-subscriptions", h.Push.DeleteSubscription)
+
+	rt.handle("POST /api/v1/me/calendar", h.Calendar.CreateCalendar)
+
+	rt.handle("POST /api/v1/contact", h.Contact.HandleContact)
+	rt.handle("POST /api/v1/sms/inbound", h.SMS.HandleInbound)
+	rt.handle("POST /api/v1/sms/status", h.SMS.HandleStatusCallback)
+
+	// GET patterns also match HEAD.
+	rt.handle("GET "+CalendarFeedPrefix+"{file}", h.Calendar.ServeFeed)
+	rt.handle("DELETE "+CalendarFeedPrefix+"{file}", h.Calendar.DeleteCalendar)
+	rt.handle("GET /api/feeds/resorts.atom", h.Feed.ServeFeed)
+	rt.handle("GET /api/feeds/resorts/{file}", h.Feed.ServeFeed)
+	rt.handle("GET /u/{token}", h.Unsubscribe.HandleUnsubscribe)
+	rt.handle("POST /u/{token}", h.Unsubscribe.HandleUnsubscribe)
+
+	rt.deprecated("GET /api/resorts", "/api/v1/resorts", h.Resort.ListAllResorts)
+	rt.deprecated("GET /api/resorts/{id}", "/api/v1/resorts/{id}", h.Resort.GetResort)
+	rt.deprecated("POST /api/alerts", "/api/v1/alerts", h.Alert.CreateAlert)
+	rt.deprecated("GET /api/user/alerts", "/api/v1/me/alerts", h.Alert.GetUserAlerts)
+	rt.deprecated("DELETE /api/user/alerts/delete", "/api/v1/me/alerts/{resortId}", h.Alert.DeleteUserAlert)
+	rt.deprecated("DELETE /api/user/alerts/delete-all", "/api/v1/me/alerts", h.Alert.DeleteAllUserAlerts)
+	rt.deprecated("GET /api/user/deliveries", "/api/v1/me/deliveries", h.Delivery.GetUserDeliveries)
+	rt.deprecated("PUT /api/user/preferences", "/api/v1/me/preferences", h.Preferences.UpdatePreferences)
+	rt.deprecated("POST /api/user/alerts/destinations", "/api/v1/me/destinations", h.Destination.CreateDestination)
+	rt.deprecated("GET /api/user/alerts/destinations", "/api/v1/me/destinations", h.Destination.ListDestinations)
+	rt.deprecated(
+		"DELETE /api/user/alerts/destinations",
+		"/api/v1/me/destinations/{id}",
+		h.Destination.DeleteDestination,
+	)
+	rt.deprecated("POST /api/user/webhooks", "/api/v1/me/webhooks", h.Webhook.CreateWebhook)
+	rt.deprecated("GET /api/user/webhooks", "/api/v1/me/webhooks", h.Webhook.ListWebhooks)
+	rt.deprecated("DELETE /api/user/webhooks", "/api/v1/me/webhooks/{id}", h.Webhook.DeleteWebhook)
+	rt.deprecated("POST /api/user/webhooks/test", "/api/v1/me/webhooks/{id}/test", h.Webhook.TestWebhook)
+	rt.deprecated("GET /api/push/vapid-public-key", "/api/v1/push/vapid-public-key", h.Push.GetPublicKey)
+	rt.deprecated("POST /api/user/push-subscriptions", "/api/v1/me/push-subscriptions", h.Push.CreateSubscription)
+	rt.deprecated("DELETE /api/user/push-subscriptions", "/api/v1/me/push-subscriptions", h.Push.DeleteSubscription)
+	rt.deprecated("POST /api/user/calendar", "/api/v1/me/calendar", h.Calendar.CreateCalendar)
+	rt.deprecated("POST /api/contact", "/api/v1/contact", h.Contact.HandleContact)
+	rt.deprecated("POST /api/sms/inbound", "/api/v1/sms/inbound", h.SMS.HandleInbound)
+	rt.deprecated("POST /api/sms/status", "/api/v1/sms/status", h.SMS.HandleStatusCallback)
+
+	return rt
+}
+
+// router routes requests by method and path pattern, answering those that match no route with the same
+// JSON errors as the handlers.
+type router struct {
+	mux *http.ServeMux
+	// limit wraps the handler of each route in its rate limits.
+	limit func(pattern string, handler http.HandlerFunc) http.HandlerFunc
+	// patterns are the routes registered with handle, which the OpenAPI document describes.
+	patterns []string
+}
+
+func newRouter(limit func(string, http.HandlerFunc) http.HandlerFunc) *router {
+	return &router{mux: http.NewServeMux(), limit: limit}
+}
+
+func (rt *router) handle(pattern string, handler http.HandlerFunc) {
+	rt.mux.HandleFunc(pattern, rt.limit(pattern, handler))
+	rt.patterns = append(rt.patterns, pattern)
+}
+
+// deprecated serves a route that has moved to successor. Responses carry a Deprecation header (RFC 9745),
+// and a Link to the successor when its path has no wildcards to fill in.
+func (rt *router) deprecated(pattern, successor string, handler http.HandlerFunc) {
+	deprecation := "@" + strconv.FormatInt(legacyRoutesDeprecatedAt.Unix(), 10)
+	handler = rt.limit(pattern, handler)
+	rt.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", deprecation)
+		if !strings.Contains(successor, "{") {
+			w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		handler(w, r)
+	})
+}
+
+// ServeHTTP serves r with the route it matches in a span of its own, logging it with its request ID and
+// recording its metrics. Records the handlers log in the request's context, including those of the store,
+// carry the ID too, and their spans are children of the request's.
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	handler, pattern := rt.mux.Handler(r)
+
+	ctx, span := startRequestSpan(r, pattern)
+	id := requestID(r)
+	w.Header().Set(RequestIDHeader, id)
+	ctx = logging.WithRequestID(ctx, id)
+	r = r.WithContext(ctx)
+
+	rec := &responseRecorder{ResponseWriter: w}
+	rt.route(rec, r, handler, pattern)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	elapsed := time.Since(start)
+	endRequestSpan(span, rec.status)
+	logRequest(ctx, r, pattern, rec, elapsed)
+	metrics.ObserveHTTPRequest(pattern, r.Method, rec.status, elapsed)
+}
+
+// route serves r with the handler of the route it matches, or with the router's errors when it matches none.
+func (rt *router) route(w http.ResponseWriter, r *http.Request, handler http.Handler, pattern string) {
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// The mux's own handler for unmatched requests writes a plain text error, and sets Allow when the
+	// path matches a route for another method.
+	unmatched := &statusRecorder{header: http.Header{}}
+	handler.ServeHTTP(unmatched, r)
+
+	setSecurityHeaders(w)
+	if unmatched.status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", unmatched.header.Get("Allow"))
+		sendError(w, r, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	sendError(w, r, apierror.NotFound, "Not found")
+}
+
+// statusRecorder is a ResponseWriter keeping only the status and headers written to it.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header { return s.header }
+
+func (s *statusRecorder) Write(b []byte) (int, error) { return len(b), nil }
+
+func (s *statusRecorder) WriteHeader(status int) { s.status = status }
+
+// resourceUUID parses the UUID of the resource a request is for from its {id} path wildcard or, on the
+// deprecated routes without one, from fallback.
+func resourceUUID(r *http.Request, fallback string) (uuid.UUID, error) {
+	if id := r.PathValue("id"); id != "" {
+		return uuid.Parse(id)
+	}
+	return uuid.Parse(fallback)
+}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/health"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/ratelimit"
+	powtestutil "github.com/MattSilvaa/powhunter/internal/testutil"
+)
+
+func TestRoutes(t *testing.T) {
+	deprecation := "@" + strconv.FormatInt(legacyRoutesDeprecatedAt.Unix(), 10)
+
+	tests := []struct {
+		name               string
+		method             string
+		target             string
+		setupMock          func(*mocks.MockStoreService)
+		expectedStatus     int
+		expectedError      *ErrorResponse
+		expectedAllow      string
+		expectedDeprecated bool
+		expectedLink       string
+	}{
+		{
+			name:   "Version 1 route",
+			method: http.MethodGet,
+			target: "/api/v1/resorts",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListAllResorts(gomock.Any()).Return([]dbgen.Resort{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Deprecated route",
+			method: http.MethodGet,
+			target: "/api/resorts",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListAllResorts(gomock.Any()).Return([]dbgen.Resort{}, nil)
+			},
+			expectedStatus:     http.StatusOK,
+			expectedDeprecated: true,
+			expectedLink:       `</api/v1/resorts>; rel="successor-version"`,
+		},
+		{
+			name:   "Deleting an alert",
+			method: http.MethodDelete,
+			target: "/api/v1/me/alerts/9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					DeleteUserAlert(gomock.Any(), "test@example.com", "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d").
+					Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Deleting an alert on the deprecated route",
+			method: http.MethodDelete,
+			target: "/api/user/alerts/delete?email=test@example.com&resort_uuid=9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					DeleteUserAlert(gomock.Any(), "test@example.com", "9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d").
+					Return(nil)
+			},
+			expectedStatus:     http.StatusOK,
+			expectedDeprecated: true,
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPost,
+			target:         "/api/v1/resorts",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+			expectedAllow: "GET, HEAD",
+		},
+		{
+			name:           "Method not allowed on an unsubscribe link",
+			method:         http.MethodDelete,
+			target:         "/u/token",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+			expectedAllow: "GET, HEAD, POST",
+		},
+		{
+			name:           "Not found",
+			method:         http.MethodGet,
+			target:         "/api/v1/lifts",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "NOT_FOUND",
+				Message: "Not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			resortHandler, err := NewResortHandler(mockStore)
+			require.NoError(t, err)
+			alertHandler, err := NewAlertHandler(mockStore)
+			require.NoError(t, err)
+			unsubscribeHandler, err := NewUnsubscribeHandler(mockStore, nil)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.target, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Resort: resortHandler, Alert: alertHandler, Unsubscribe: unsubscribeHandler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+			assert.Equal(t, tt.expectedAllow, rr.Header().Get("Allow"))
+			if tt.expectedDeprecated {
+				assert.Equal(t, deprecation, rr.Header().Get("Deprecation"))
+			} else {
+				assert.Empty(t, rr.Header().Get("Deprecation"))
+			}
+			assert.Equal(t, tt.expectedLink, rr.Header().Get("Link"))
+
+			if tt.expectedError != nil {
+				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+				decoder := json.NewDecoder(rr.Body)
+				var errorResponse ErrorResponse
+				require.NoError(t, decoder.Decode(&errorResponse), "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+				assert.False(t, decoder.More(), "the error is written once")
+			}
+		})
+	}
+}
+
+func TestRoutes_RateLimited(t *testing.T) {
+	handler, mockStore := testAlertHandler(t)
+	mockStore.EXPECT().
+		CreateUserWithAlerts(gomock.Any(), gomock.Any(), "+12065550100", 5.0, int32(3), []string{testResortUUID}).
+		Return(nil).
+		Times(alertLimits.Account.Requests + 1)
+	h := &Handlers{Alert: handler, Limiter: ratelimit.New(ratelimit.NewMemoryStore(), nil)}
+
+	createAlert := func(target, email string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(CreateAlertRequest{
+			Email:            email,
+			Phone:            "(206) 555-0100",
+			NotificationDays: 3,
+			MinSnowAmount:    5.0,
+			ResortsUuids:     []string{testResortUUID},
+		})
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		serve(t, h, rr, httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body)))
+		return rr
+	}
+
+	// The deprecated route shares the limits of its successor.
+	for i := range alertLimits.Account.Requests {
+		target := "/api/v1/alerts"
+		if i%2 == 1 {
+			target = "/api/alerts"
+		}
+		assert.Equal(t, http.StatusCreated, createAlert(target, "skier@example.com").Code, "request %d", i+1)
+	}
+
+	rr := createAlert("/api/v1/alerts", "Skier@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "720", rr.Header().Get("Retry-After"))
+	var errorResponse ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&errorResponse))
+	assert.Equal(t, ErrorResponse{
+		Error:   "RATE_LIMITED",
+		Message: "Too many requests, try again in 720 seconds",
+	}, errorResponse)
+
+	// The account's limit doesn't hold up others from the same address, which has requests left.
+	assert.Equal(t, http.StatusCreated, createAlert("/api/v1/alerts", "other@example.com").Code)
+}
+
+func TestRoutes_RequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		sentID     string
+		expectedID string
+	}{
+		{
+			name:       "Sent by the client",
+			sentID:     "edge-4f2a.1",
+			expectedID: "edge-4f2a.1",
+		},
+		{
+			name: "Not sent",
+		},
+		{
+			name:   "Not safe to log",
+			sentID: "id\" with=quotes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			var storeRequestID string
+			mockStore.EXPECT().ListAllResorts(gomock.Any()).DoAndReturn(
+				func(ctx context.Context) ([]dbgen.Resort, error) {
+					storeRequestID = logging.RequestID(ctx)
+					return []dbgen.Resort{}, nil
+				})
+			resortHandler, err := NewResortHandler(mockStore)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/resorts", nil)
+			if tt.sentID != "" {
+				req.Header.Set(RequestIDHeader, tt.sentID)
+			}
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Resort: resortHandler}, rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			requestID := rr.Header().Get(RequestIDHeader)
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, requestID)
+			} else {
+				assert.NoError(t, uuid.Validate(requestID), "a new ID is generated")
+			}
+			assert.Equal(t, requestID, storeRequestID, "the store is called with the request's ID")
+		})
+	}
+}
+
+func TestRoutes_Metrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().ListAllResorts(gomock.Any()).Return([]dbgen.Resort{}, nil)
+	resortHandler, err := NewResortHandler(mockStore)
+	require.NoError(t, err)
+	h := &Handlers{Resort: resortHandler}
+
+	requests := metrics.HTTPRequests.WithLabelValues("GET /api/v1/resorts", http.MethodGet, "200")
+	before := testutil.ToFloat64(requests)
+	serve(t, h, httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/resorts", nil))
+	assert.Equal(t, before+1, testutil.ToFloat64(requests))
+
+	rr := httptest.NewRecorder()
+	serve(t, h, rr, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `powhunter_http_requests_total{method="GET",route="GET /api/v1/resorts",status="200"}`)
+}
+
+func TestRoutes_Health(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		check          func(context.Context) error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Live",
+			path:           "/livez",
+			check:          func(context.Context) error { return errors.New("connection refused") },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok"}`,
+		},
+		{
+			name:           "Ready",
+			path:           "/readyz",
+			check:          func(context.Context) error { return nil },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok","checks":{"database":{"status":"ok"}}}`,
+		},
+		{
+			name:           "Not ready",
+			path:           "/readyz",
+			check:          func(context.Context) error { return errors.New("connection refused") },
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"fail","checks":{"database":{"status":"fail","error":"connection refused"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handlers{Health: health.NewChecker(health.Check{Name: "database", Run: tt.check})}
+
+			rr := httptest.NewRecorder()
+			serve(t, h, rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			var report map[string]any
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+			if checks, ok := report["checks"].(map[string]any); ok {
+				// Durations vary from run to run.
+				delete(checks["database"].(map[string]any), "duration_ms")
+			}
+			body, err := json.Marshal(report)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
+
+func TestRoutes_Tracing(t *testing.T) {
+	// A trace the client started, continued by the API.
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name           string
+		path           string
+		storeErr       error
+		expectedStatus int
+		expectedName   string
+		expectedCode   codes.Code
+	}{
+		{
+			name:           "Served",
+			path:           "/api/v1/resorts",
+			expectedStatus: http.StatusOK,
+			expectedName:   "GET /api/v1/resorts",
+			expectedCode:   codes.Unset,
+		},
+		{
+			name:           "Failed",
+			path:           "/api/v1/resorts",
+			storeErr:       errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedName:   "GET /api/v1/resorts",
+			expectedCode:   codes.Error,
+		},
+		{
+			name:           "Unmatched",
+			path:           "/api/v1/nothing-here",
+			expectedStatus: http.StatusNotFound,
+			expectedName:   "GET",
+			expectedCode:   codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := powtestutil.RecordSpans(t)
+
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			if tt.path == "/api/v1/resorts" {
+				mockStore.EXPECT().ListAllResorts(gomock.Any()).Return([]dbgen.Resort{}, tt.storeErr)
+			}
+			resortHandler, err := NewResortHandler(db.Traced(mockStore))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("traceparent", traceparent)
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Resort: resortHandler}, rr, req)
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			spans := recorder.Ended()
+			server := spans[len(spans)-1]
+			assert.Equal(t, tt.expectedName, server.Name())
+			assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+			assert.Equal(t, tt.expectedCode, server.Status().Code)
+			assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", tt.expectedStatus))
+
+			if tt.path == "/api/v1/resorts" {
+				require.Len(t, spans, 2)
+				assert.Equal(t, "StoreService.ListAllResorts", spans[0].Name())
+				assert.Equal(t, server.SpanContext().SpanID(), spans[0].Parent().SpanID())
+				assert.Contains(t, server.Attributes(), attribute.String("http.route", "/api/v1/resorts"))
+			} else {
+				assert.Len(t, spans, 1)
+			}
+		})
+	}
+}
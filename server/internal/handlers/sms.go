@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/MattSilvaa/powhunter/internal/phone"
+	"github.com/twilio/twilio-go/client"
+	"github.com/twilio/twilio-go/twiml"
+)
+
+const defaultPublicBaseURL = "https://powhunter.app"
+
+// SMSHandler handles webhooks from Twilio for inbound messages.
+type SMSHandler struct {
+	store db.StoreService
+	// validator checks Twilio's signatures. It's nil when no auth token is configured, and every webhook is
+	// rejected then, since an empty key would accept signatures anyone can compute.
+	validator *client.RequestValidator
+	baseURL   string
+	now       func() time.Time
+}
+
+// publicBaseURL returns the externally visible URL of the API, used to verify
+// webhook signatures and build links.
+func publicBaseURL() string {
+	baseURL := os.Getenv("PUBLIC_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultPublicBaseURL
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// smsClientFromEnv returns a client texting from TWILIO_FROM_NUMBER, or nil if Twilio isn't configured.
+func smsClientFromEnv() notify.NotificationService {
+	fromNumber := os.Getenv("TWILIO_FROM_NUMBER")
+	if os.Getenv("TWILIO_ACCOUNT_SID") == "" || os.Getenv("TWILIO_AUTH_TOKEN") == "" || fromNumber == "" {
+		slog.Warn("Twilio credentials not found, so calendar links can't be texted")
+		return nil
+	}
+	return notify.NewTwilioClient(fromNumber, "")
+}
+
+func NewSMSHandler(store db.StoreService, authToken, baseURL string) (*SMSHandler, error) {
+	var validator *client.RequestValidator
+	if authToken == "" {
+		slog.Warn("TWILIO_AUTH_TOKEN not configured, inbound SMS webhooks will be rejected")
+	} else {
+		v := client.NewRequestValidator(authToken)
+		validator = &v
+	}
+
+	return &SMSHandler{
+		store:     store,
+		validator: validator,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		now:       time.Now,
+	}, nil
+}
+
+// verifySignature checks the X-Twilio-Signature header against the form parameters of the request.
+// r.ParseForm must have been called. Without an auth token, no signature is valid.
+func (h *SMSHandler) verifySignature(r *http.Request) bool {
+	signature := r.Header.Get("X-Twilio-Signature")
+	if h.validator == nil || signature == "" {
+		return false
+	}
+
+	params := make(map[string]string, len(r.PostForm))
+	for key, values := range r.PostForm {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	return h.validator.Validate(h.baseURL+r.URL.RequestURI(), params, signature)
+}
+
+// HandleInbound processes replies to SMS alerts. It supports the STOP, START, HELP and
+// PAUSE keywords and answers with a TwiML confirmation message.
+func (h *SMSHandler) HandleInbound(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	if err := r.ParseForm(); err != nil {
+		sendError(w, r, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	if !h.verifySignature(r) {
+		sendError(w, r, apierror.InvalidSignature, "Request signature is not valid")
+		return
+	}
+
+	from, err := phone.Normalize(r.PostForm.Get("From"), "")
+	if err != nil {
+		slog.WarnContext(r.Context(), "Ignoring inbound SMS from unparseable number", "error", err)
+		sendTwiML(w, r, "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	command := notify.ParseInboundSMS(r.PostForm.Get("Body"))
+	switch command.Keyword {
+	case notify.KeywordStop:
+		if err := h.store.SetSMSOptOut(ctx, from, true); err != nil {
+			sendStoreError(w, r, err, "Failed to process message")
+			return
+		}
+		sendTwiML(w, r, notify.StopReply)
+	case notify.KeywordStart:
+		if err := h.store.SetSMSOptOut(ctx, from, false); err != nil {
+			sendStoreError(w, r, err, "Failed to process message")
+			return
+		}
+		sendTwiML(w, r, notify.StartReply)
+	case notify.KeywordHelp:
+		sendTwiML(w, r, notify.HelpReply)
+	case notify.KeywordPause:
+		if command.Invalid {
+			sendTwiML(w, r, notify.PauseUsageReply)
+			return
+		}
+
+		until := h.now().Add(command.PauseFor)
+		if err := h.store.PauseAlerts(ctx, from, until); err != nil {
+			sendStoreError(w, r, err, "Failed to process message")
+			return
+		}
+		sendTwiML(w, r, notify.FormatPauseReply(until))
+	case notify.KeywordNone:
+		// Free-form replies are not answered.
+		sendTwiML(w, r, "")
+	}
+}
+
+// sendTwiML writes a TwiML messaging response, replying with message when it is not empty.
+func sendTwiML(w http.ResponseWriter, r *http.Request, message string) {
+	var verbs []twiml.Element
+	if message != "" {
+		verbs = append(verbs, &twiml.MessagingMessage{Body: message})
+	}
+
+	response, err := twiml.Messages(verbs)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to build TwiML response", "error", err)
+		sendError(w, r, apierror.InternalError, "Failed to build response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(response)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write TwiML response", "error", err)
+	}
+}
+
+// HandleStatusCallback records delivery status changes reported by Twilio for outbound messages.
+func (h *SMSHandler) HandleStatusCallback(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	if err := r.ParseForm(); err != nil {
+		sendError(w, r, apierror.InvalidRequest, "Invalid request body")
+		return
+	}
+
+	if !h.verifySignature(r) {
+		sendError(w, r, apierror.InvalidSignature, "Request signature is not valid")
+		return
+	}
+
+	messageSID := r.PostForm.Get("MessageSid")
+	if messageSID == "" {
+		sendError(w, r, apierror.MissingMessageSID, "MessageSid is required")
+		return
+	}
+
+	status, ok := notify.DeliveryStatusFromTwilio(r.PostForm.Get("MessageStatus"))
+	if !ok {
+		// Acknowledge statuses we don't track so Twilio doesn't retry them.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	updated, err := h.store.UpdateDeliveryStatus(
+		ctx,
+		notify.ProviderTwilio,
+		messageSID,
+		status,
+		r.PostForm.Get("ErrorCode"),
+		r.PostForm.Get("ErrorMessage"),
+	)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to update delivery status")
+		return
+	}
+	if !updated {
+		slog.InfoContext(r.Context(), "Ignoring status for unknown message or stale status",
+			"status", status, "message_sid", messageSID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testAuthToken = "test-auth-token"
+	testBaseURL   = "https://powhunter.test"
+)
+
+func testSMSHandler(t *testing.T, now time.Time) (*SMSHandler, *mocks.MockStoreService) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+
+	handler, err := NewSMSHandler(mockStore, testAuthToken, testBaseURL)
+	require.NoError(t, err)
+	handler.now = func() time.Time { return now }
+
+	return handler, mockStore
+}
+
+// twilioSignature computes the X-Twilio-Signature header for a form POST.
+func twilioSignature(authToken, fullURL string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(fullURL)
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteString(form.Get(key))
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newInboundSMSRequest(t *testing.T, from, body string, signed bool) *http.Request {
+	form := url.Values{
+		"MessageSid": {"SM123"},
+		"From":       {from},
+		"To":         {"+12065550000"},
+		"Body":       {body},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/sms/inbound", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if signed {
+		req.Header.Set("X-Twilio-Signature", twilioSignature(testAuthToken, testBaseURL+"/api/v1/sms/inbound", form))
+	}
+
+	return req
+}
+
+func TestSMSHandler_HandleInbound(t *testing.T) {
+	now := time.Date(2025, 12, 18, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		from            string
+		body            string
+		signed          bool
+		setupMock       func(*mocks.MockStoreService)
+		expectedStatus  int
+		expectedMessage string
+		expectedError   *ErrorResponse
+	}{
+		{
+			name:   "Stop opts out",
+			from:   "(206) 555-0100",
+			body:   "STOP",
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().SetSMSOptOut(gomock.Any(), "+12065550100", true).Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: notify.StopReply,
+		},
+		{
+			name:   "Start opts back in",
+			from:   "+12065550100",
+			body:   "start",
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().SetSMSOptOut(gomock.Any(), "+12065550100", false).Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: notify.StartReply,
+		},
+		{
+			name:            "Help replies without store calls",
+			from:            "+12065550100",
+			body:            "HELP",
+			signed:          true,
+			setupMock:       func(m *mocks.MockStoreService) {},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: notify.HelpReply,
+		},
+		{
+			name:   "Pause 7D pauses alerts",
+			from:   "+12065550100",
+			body:   "PAUSE 7D",
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().PauseAlerts(gomock.Any(), "+12065550100", now.Add(7*24*time.Hour)).Return(nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: notify.FormatPauseReply(now.Add(7 * 24 * time.Hour)),
+		},
+		{
+			name:            "Pause with invalid duration explains usage",
+			from:            "+12065550100",
+			body:            "PAUSE forever",
+			signed:          true,
+			setupMock:       func(m *mocks.MockStoreService) {},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: notify.PauseUsageReply,
+		},
+		{
+			name:           "Unknown text gets an empty response",
+			from:           "+12065550100",
+			body:           "Thanks!",
+			signed:         true,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing signature is rejected",
+			from:           "+12065550100",
+			body:           "STOP",
+			signed:         false,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusForbidden,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_SIGNATURE",
+				Message: "Request signature is not valid",
+			},
+		},
+		{
+			name:   "Store error",
+			from:   "+12065550100",
+			body:   "STOP",
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().SetSMSOptOut(gomock.Any(), "+12065550100", true).Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to process message",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockStore := testSMSHandler(t, now)
+			tt.setupMock(mockStore)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{SMS: handler}, rr, newInboundSMSRequest(t, tt.from, tt.body, tt.signed))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+				return
+			}
+
+			assert.Equal(t, "text/xml", rr.Header().Get("Content-Type"))
+
+			var response struct {
+				Messages []string `xml:"Message"`
+			}
+			err := xml.NewDecoder(rr.Body).Decode(&response)
+			require.NoError(t, err, "Failed to decode TwiML response")
+
+			if tt.expectedMessage != "" {
+				assert.Equal(t, []string{tt.expectedMessage}, response.Messages)
+			} else {
+				assert.Empty(t, response.Messages)
+			}
+		})
+	}
+}
+
+func TestSMSHandler_TamperedBodyIsRejected(t *testing.T) {
+	handler, _ := testSMSHandler(t, time.Now())
+
+	req := newInboundSMSRequest(t, "+12065550100", "HELP", true)
+	form := url.Values{
+		"MessageSid": {"SM123"},
+		"From":       {"+12065550100"},
+		"To":         {"+12065550000"},
+		"Body":       {"STOP"},
+	}
+	tampered, err := http.NewRequest(http.MethodPost, req.URL.String(), strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	tampered.Header = req.Header
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{SMS: handler}, rr, tampered)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestSMSHandler_NoAuthTokenRejectsEverything(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler, err := NewSMSHandler(mocks.NewMockStoreService(ctrl), "", testBaseURL)
+	require.NoError(t, err)
+
+	// Signed with the empty key an unconfigured validator would check against.
+	form := url.Values{"MessageSid": {"SM123"}, "From": {"+12065550100"}, "To": {"+12065550000"}, "Body": {"STOP"}}
+	inbound, err := http.NewRequest(http.MethodPost, "/api/v1/sms/inbound", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	inbound.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	inbound.Header.Set("X-Twilio-Signature", twilioSignature("", testBaseURL+"/api/v1/sms/inbound", form))
+
+	status := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}}
+	callback, err := http.NewRequest(http.MethodPost, "/api/v1/sms/status", strings.NewReader(status.Encode()))
+	require.NoError(t, err)
+	callback.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	callback.Header.Set("X-Twilio-Signature", twilioSignature("", testBaseURL+"/api/v1/sms/status", status))
+
+	for _, req := range []*http.Request{inbound, callback} {
+		rr := httptest.NewRecorder()
+		serve(t, &Handlers{SMS: handler}, rr, req)
+		assert.Equal(t, http.StatusForbidden, rr.Code, req.URL.Path)
+	}
+}
+
+func newStatusCallbackRequest(t *testing.T, form url.Values, signed bool) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/sms/status", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if signed {
+		req.Header.Set("X-Twilio-Signature", twilioSignature(testAuthToken, testBaseURL+"/api/v1/sms/status", form))
+	}
+
+	return req
+}
+
+func TestSMSHandler_HandleStatusCallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		form           url.Values
+		signed         bool
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+	}{
+		{
+			name:   "Delivered",
+			form:   url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}},
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					UpdateDeliveryStatus(gomock.Any(), "twilio", "SM123", db.DeliveryStatusDelivered, "", "").
+					Return(true, nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Undelivered with error code",
+			form: url.Values{
+				"MessageSid":    {"SM123"},
+				"MessageStatus": {"undelivered"},
+				"ErrorCode":     {"30006"},
+			},
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					UpdateDeliveryStatus(gomock.Any(), "twilio", "SM123", db.DeliveryStatusUndelivered, "30006", "").
+					Return(true, nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Sending maps to queued",
+			form:   url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"sending"}},
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					UpdateDeliveryStatus(gomock.Any(), "twilio", "SM123", db.DeliveryStatusQueued, "", "").
+					Return(false, nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Untracked status is acknowledged",
+			form:           url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"receiving"}},
+			signed:         true,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Missing message SID",
+			form:           url.Values{"MessageStatus": {"delivered"}},
+			signed:         true,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_MESSAGE_SID",
+				Message: "MessageSid is required",
+			},
+		},
+		{
+			name:           "Missing signature is rejected",
+			form:           url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}},
+			signed:         false,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusForbidden,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_SIGNATURE",
+				Message: "Request signature is not valid",
+			},
+		},
+		{
+			name:   "Store error",
+			form:   url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"failed"}},
+			signed: true,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					UpdateDeliveryStatus(gomock.Any(), "twilio", "SM123", db.DeliveryStatusFailed, "", "").
+					Return(false, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to update delivery status",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockStore := testSMSHandler(t, time.Now())
+			tt.setupMock(mockStore)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{SMS: handler}, rr, newStatusCallbackRequest(t, tt.form, tt.signed))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+		})
+	}
+}
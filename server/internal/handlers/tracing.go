@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+)
+
+// startRequestSpan starts the server span of a request to the route with the given pattern, continuing the
+// trace of the client if its headers carry one. Like request logs, spans are named after the route pattern
+// rather than the path, which holds tokens and IDs.
+func startRequestSpan(r *http.Request, pattern string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	name := r.Method
+	attrs := []attribute.KeyValue{attribute.String("http.request.method", r.Method)}
+	if pattern != "" {
+		name = pattern
+		_, route, _ := strings.Cut(pattern, " ")
+		attrs = append(attrs, attribute.String("http.route", route))
+	}
+	return tracing.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// endRequestSpan ends the span of a request served with status. Only server errors mark it failed, since
+// client errors are the API working as intended.
+func endRequestSpan(span trace.Span, status int) {
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
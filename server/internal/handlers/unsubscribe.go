@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+)
+
+// UnsubscribeHandler serves the one-click unsubscribe links included in alerts. Opening a link shows a
+// confirmation page; the alerts are only removed by a POST, either from that page or an RFC 8058
+// List-Unsubscribe=One-Click request from a mail client, so link scanners can't unsubscribe anyone.
+type UnsubscribeHandler struct {
+	store  db.StoreService
+	signer *unsubscribe.Signer
+}
+
+// unsubscribePage is the data for unsubscribePageTemplate.
+type unsubscribePage struct {
+	Title   string
+	Message string
+	// Confirm shows the unsubscribe form.
+	Confirm    bool
+	ResortName string
+	All        bool
+}
+
+var unsubscribePageTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}} - Pow Hunter</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Message}}
+<p>{{.Message}}</p>
+{{- end}}
+{{- if .Confirm}}
+<form method="post">
+{{- if .All}}
+<p>Stop all Pow Hunter snow alerts?</p>
+<button type="submit" name="scope" value="all">Unsubscribe from all alerts</button>
+{{- else}}
+<p>Stop snow alerts for {{.ResortName}}?</p>
+<button type="submit">Unsubscribe from {{.ResortName}}</button>
+<button type="submit" name="scope" value="all">Unsubscribe from all alerts</button>
+{{- end}}
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+// NewUnsubscribeHandler returns a handler verifying links with signer. With a nil signer, unsubscribe links
+// are disabled and every link is rejected.
+func NewUnsubscribeHandler(store db.StoreService, signer *unsubscribe.Signer) (*UnsubscribeHandler, error) {
+	if signer == nil {
+		slog.Warn("UNSUBSCRIBE_SECRET not configured, unsubscribe links are disabled")
+	}
+
+	return &UnsubscribeHandler{
+		store:  store,
+		signer: signer,
+	}, nil
+}
+
+// HandleUnsubscribe serves GET and POST /u/{token}.
+func (h *UnsubscribeHandler) HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+	w.Header().Set("Cache-Control", "no-store")
+
+	if h.signer == nil {
+		h.renderPage(w, r, http.StatusNotFound, unsubscribePage{
+			Title:   "Link not found",
+			Message: "Unsubscribe links are not available right now.",
+		})
+		return
+	}
+
+	claims, err := h.signer.Verify(r.PathValue("token"))
+	switch {
+	case errors.Is(err, unsubscribe.ErrExpiredToken):
+		h.renderPage(w, r, http.StatusGone, unsubscribePage{
+			Title:   "Link expired",
+			Message: "This unsubscribe link has expired. Use the link in a more recent alert, or reply STOP to any text alert.",
+		})
+		return
+	case err != nil:
+		h.renderPage(w, r, http.StatusBadRequest, unsubscribePage{
+			Title:   "Invalid link",
+			Message: "This unsubscribe link is not valid. Check that the whole link was copied.",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		page := unsubscribePage{Title: "Unsubscribe", Confirm: true, All: claims.All()}
+		if !claims.All() {
+			page.ResortName = "this resort"
+			resort, err := h.store.GetResort(ctx, claims.ResortUUID)
+			switch {
+			case err == nil:
+				page.ResortName = resort.Name
+			case !errors.Is(err, db.ErrResortNotFound):
+				slog.ErrorContext(r.Context(), "Failed to get resort for unsubscribe page", "error", err)
+			}
+		}
+		h.renderPage(w, r, http.StatusOK, page)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderPage(w, r, http.StatusBadRequest, unsubscribePage{Title: "Invalid request"})
+		return
+	}
+
+	request := db.Unsubscribe{
+		UserUUID: claims.UserUUID,
+		Channel:  claims.Channel,
+		Method:   db.UnsubscribeMethodLink,
+	}
+	if r.PostForm.Get("List-Unsubscribe") == "One-Click" {
+		request.Method = db.UnsubscribeMethodOneClick
+	}
+	all := claims.All() || (request.Method == db.UnsubscribeMethodLink && r.PostForm.Get("scope") == "all")
+	if !all {
+		request.ResortUUID = uuid.NullUUID{UUID: claims.ResortUUID, Valid: true}
+	}
+
+	if _, err := h.store.Unsubscribe(ctx, request); err != nil && !errors.Is(err, db.ErrUserNotFound) {
+		slog.ErrorContext(r.Context(), "Failed to unsubscribe", "error", err)
+		h.renderPage(w, r, http.StatusInternalServerError, unsubscribePage{
+			Title:   "Something went wrong",
+			Message: "We couldn't unsubscribe you. Please try again in a few minutes.",
+		})
+		return
+	}
+
+	message := "You won't get any more snow alerts for this resort."
+	if all {
+		message = "You won't get any more snow alerts from Pow Hunter."
+	}
+	h.renderPage(w, r, http.StatusOK, unsubscribePage{Title: "You're unsubscribed", Message: message})
+}
+
+func (h *UnsubscribeHandler) renderPage(w http.ResponseWriter, r *http.Request, status int, page unsubscribePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := unsubscribePageTemplate.Execute(w, page); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render unsubscribe page", "error", err)
+	}
+}
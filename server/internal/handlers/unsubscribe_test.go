@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUnsubscribeHandler_HandleUnsubscribe(t *testing.T) {
+	signer, err := unsubscribe.NewSigner([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	require.NoError(t, err)
+
+	userUUID := uuid.New()
+	resortUUID := uuid.New()
+	resortToken := signer.Sign(unsubscribe.Claims{UserUUID: userUUID, ResortUUID: resortUUID, Channel: db.ChannelEmail})
+	allToken := signer.Sign(unsubscribe.Claims{UserUUID: userUUID, Channel: db.ChannelSMS})
+	expiredToken := signer.Sign(unsubscribe.Claims{UserUUID: userUUID, ExpiresAt: time.Now().Add(-time.Minute)})
+
+	resortUnsubscribe := db.Unsubscribe{
+		UserUUID:   userUUID,
+		ResortUUID: uuid.NullUUID{UUID: resortUUID, Valid: true},
+		Channel:    db.ChannelEmail,
+		Method:     db.UnsubscribeMethodLink,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		token          string
+		form           url.Values
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Confirmation page names the resort",
+			method: http.MethodGet,
+			token:  resortToken,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResort(gomock.Any(), resortUUID).Return(dbgen.Resort{Name: "Crystal Mountain"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Unsubscribe from Crystal Mountain",
+		},
+		{
+			name:   "Confirmation page for a removed resort",
+			method: http.MethodGet,
+			token:  resortToken,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().GetResort(gomock.Any(), resortUUID).Return(dbgen.Resort{}, db.ErrResortNotFound)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Unsubscribe from this resort",
+		},
+		{
+			name:           "Confirmation page for all alerts",
+			method:         http.MethodGet,
+			token:          allToken,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Stop all Pow Hunter snow alerts?",
+		},
+		{
+			name:   "Confirming removes the resort alert",
+			method: http.MethodPost,
+			token:  resortToken,
+			form:   url.Values{},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().Unsubscribe(gomock.Any(), resortUnsubscribe).Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "You won&#39;t get any more snow alerts for this resort.",
+		},
+		{
+			name:   "Confirming all from a resort link",
+			method: http.MethodPost,
+			token:  resortToken,
+			form:   url.Values{"scope": {"all"}},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().Unsubscribe(gomock.Any(), db.Unsubscribe{
+					UserUUID: userUUID,
+					Channel:  db.ChannelEmail,
+					Method:   db.UnsubscribeMethodLink,
+				}).Return(int64(3), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "You won&#39;t get any more snow alerts from Pow Hunter.",
+		},
+		{
+			name:   "RFC 8058 one-click POST",
+			method: http.MethodPost,
+			token:  resortToken,
+			form:   url.Values{"List-Unsubscribe": {"One-Click"}},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().Unsubscribe(gomock.Any(), db.Unsubscribe{
+					UserUUID:   userUUID,
+					ResortUUID: uuid.NullUUID{UUID: resortUUID, Valid: true},
+					Channel:    db.ChannelEmail,
+					Method:     db.UnsubscribeMethodOneClick,
+				}).Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Deleted user",
+			method: http.MethodPost,
+			token:  allToken,
+			form:   url.Values{},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().Unsubscribe(gomock.Any(), gomock.Any()).Return(int64(0), db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "You&#39;re unsubscribed",
+		},
+		{
+			name:   "Store error",
+			method: http.MethodPost,
+			token:  allToken,
+			form:   url.Values{},
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().Unsubscribe(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Something went wrong",
+		},
+		{
+			name:           "Tampered token",
+			method:         http.MethodPost,
+			token:          allToken[:len(allToken)-2] + "xx",
+			form:           url.Values{},
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid link",
+		},
+		{
+			name:           "Expired token",
+			method:         http.MethodGet,
+			token:          expiredToken,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusGone,
+			expectedBody:   "Link expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewUnsubscribeHandler(mockStore, signer)
+			require.NoError(t, err)
+
+			var body *strings.Reader
+			if tt.form != nil {
+				body = strings.NewReader(tt.form.Encode())
+			} else {
+				body = strings.NewReader("")
+			}
+			req, err := http.NewRequest(tt.method, unsubscribe.PathPrefix+tt.token, body)
+			require.NoError(t, err)
+			if tt.form != nil {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Unsubscribe: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			if tt.expectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeHandler_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler, err := NewUnsubscribeHandler(mocks.NewMockStoreService(ctrl), nil)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, unsubscribe.PathPrefix+"anything", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Unsubscribe: handler}, rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/apierror"
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+)
+
+// WebhookHandler lets users register webhooks that receive their alerts as signed JSON.
+type WebhookHandler struct {
+	store        db.StoreService
+	notifier     *notify.WebhookNotifier
+	requireHTTPS bool
+}
+
+type CreateWebhookRequest struct {
+	Email string `json:"email" validate:"email,max=254"`
+	URL   string `json:"url" validate:"max=2048"`
+}
+
+type TestWebhookRequest struct {
+	Email string `json:"email" validate:"email,max=254"`
+	// UUID identifies the webhook on the deprecated route, which has no {id} in its path.
+	UUID string `json:"uuid" validate:"uuid"`
+}
+
+// NewWebhookHandler returns a handler sending test events through notifier. Webhook URLs must use https in
+// production.
+func NewWebhookHandler(store db.StoreService, notifier *notify.WebhookNotifier) (*WebhookHandler, error) {
+	return &WebhookHandler{
+		store:        store,
+		notifier:     notifier,
+		requireHTTPS: os.Getenv("ENVIRONMENT") == "production",
+	}, nil
+}
+
+// CreateWebhook registers a webhook for a user.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	var req CreateWebhookRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	if req.URL == "" {
+		sendError(w, r, apierror.MissingURL, "Webhook URL is required")
+		return
+	}
+
+	if err := notify.ValidateWebhookURL(req.URL, h.requireHTTPS); err != nil {
+		sendError(w, r, apierror.InvalidURL, "Webhook URL is not valid: "+err.Error())
+		return
+	}
+
+	secret, err := notify.NewWebhookSecret()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to create webhook secret", "error", err)
+		sendError(w, r, apierror.InternalError, "Failed to create webhook")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	webhook, err := h.store.CreateWebhook(ctx, req.Email, req.URL, secret)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to create webhook")
+		return
+	}
+
+	response := apiv1.NewWebhook(webhook)
+	response.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode webhook response", "error", err)
+	}
+}
+
+// ListWebhooks lists a user's webhooks.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	webhooks, err := h.store.ListWebhooks(ctx, email)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to retrieve webhooks")
+		return
+	}
+
+	response := apiv1.NewWebhooks(webhooks)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode webhooks response", "error", err)
+	}
+}
+
+// DeleteWebhook removes one of a user's webhooks.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email parameter is required")
+		return
+	}
+
+	webhookUUID, err := resourceUUID(r, r.URL.Query().Get("uuid"))
+	if err != nil {
+		sendError(w, r, apierror.InvalidWebhook, "Webhook UUID parameter is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.DeleteWebhook(ctx, email, webhookUUID); err != nil {
+		sendStoreError(w, r, err, "Failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestWebhook sends a test event to a webhook and reports whether it was accepted.
+func (h *WebhookHandler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	setSecurityHeaders(w)
+
+	var req TestWebhookRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if req.Email == "" {
+		sendError(w, r, apierror.MissingEmail, "Email is required")
+		return
+	}
+
+	webhookUUID, err := resourceUUID(r, req.UUID)
+	if err != nil {
+		sendError(w, r, apierror.InvalidWebhook, "Webhook UUID is required")
+		return
+	}
+
+	// Long enough for the notifier's own timeout and retries.
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	webhook, err := h.store.GetUserWebhook(ctx, req.Email, webhookUUID)
+	if err != nil {
+		sendStoreError(w, r, err, "Failed to test webhook")
+		return
+	}
+
+	receipt, err := h.notifier.SendTest(ctx, webhook)
+	if err != nil {
+		// Other errors can say how the server's network is laid out, so only the receiver's status is
+		// reported.
+		slog.WarnContext(r.Context(), "Webhook test failed", "webhook_uuid", webhook.Uuid.String(), "error", err)
+		message := "Webhook test failed"
+		var statusErr *notify.WebhookStatusError
+		if errors.As(err, &statusErr) {
+			message = fmt.Sprintf("Webhook test failed: the webhook responded with status %d", statusErr.StatusCode)
+		}
+		sendError(w, r, apierror.WebhookFailed, message)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(apiv1.NewWebhookTest(receipt)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode webhook test response", "error", err)
+	}
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/apiv1"
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/notify"
+)
+
+func TestWebhookHandler_HandleWebhooks(t *testing.T) {
+	webhook := dbgen.Webhook{
+		Uuid:      uuid.MustParse("6f1c2d4e-8a9b-4c0d-9e1f-2a3b4c5d6e7f"),
+		Url:       "https://example.com/hooks/snow?token=s3cret",
+		Secret:    "whsec_secret",
+		CreatedAt: time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		setupMock      func(*mocks.MockStoreService)
+		expectedStatus int
+		expectedError  *ErrorResponse
+		check          func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Creates a webhook and returns its secret once",
+			method: http.MethodPost,
+			target: "/api/v1/me/webhooks",
+			body:   `{"email":"test@example.com","url":"https://example.com/hooks/snow"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateWebhook(gomock.Any(), "test@example.com", "https://example.com/hooks/snow", gomock.Any()).
+					DoAndReturn(func(_, _, _ any, secret string) (dbgen.Webhook, error) {
+						assert.True(t, strings.HasPrefix(secret, "whsec_"))
+						created := webhook
+						created.Secret = secret
+						return created, nil
+					})
+			},
+			expectedStatus: http.StatusCreated,
+			check: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var response apiv1.Webhook
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, webhook.Uuid.String(), response.UUID)
+				assert.Equal(t, "example.com", response.Host, "the rest of the URL isn't returned")
+				assert.True(t, strings.HasPrefix(response.Secret, "whsec_"))
+			},
+		},
+		{
+			name:           "Rejects URLs that aren't http or https",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/webhooks",
+			body:           `{"email":"test@example.com","url":"ftp://example.com/hooks"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_URL",
+				Message: "Webhook URL is not valid: webhook URL must use http or https",
+			},
+		},
+		{
+			name:           "Missing URL",
+			method:         http.MethodPost,
+			target:         "/api/v1/me/webhooks",
+			body:           `{"email":"test@example.com"}`,
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "MISSING_URL",
+				Message: "Webhook URL is required",
+			},
+		},
+		{
+			name:   "Webhook limit reached",
+			method: http.MethodPost,
+			target: "/api/v1/me/webhooks",
+			body:   `{"email":"test@example.com","url":"https://example.com/hooks/snow"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateWebhook(gomock.Any(), "test@example.com", gomock.Any(), gomock.Any()).
+					Return(dbgen.Webhook{}, db.ErrWebhookLimit)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError: &ErrorResponse{
+				Error:   "WEBHOOK_LIMIT",
+				Message: "You can register up to 5 webhooks",
+			},
+		},
+		{
+			name:   "Unknown user",
+			method: http.MethodPost,
+			target: "/api/v1/me/webhooks",
+			body:   `{"email":"nobody@example.com","url":"https://example.com/hooks/snow"}`,
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					CreateWebhook(gomock.Any(), "nobody@example.com", gomock.Any(), gomock.Any()).
+					Return(dbgen.Webhook{}, db.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "USER_NOT_FOUND",
+				Message: "No user with that email",
+			},
+		},
+		{
+			name:   "Lists webhooks without secrets or URLs",
+			method: http.MethodGet,
+			target: "/api/v1/me/webhooks?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListWebhooks(gomock.Any(), "test@example.com").Return([]dbgen.Webhook{webhook}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `[{
+					"uuid": "6f1c2d4e-8a9b-4c0d-9e1f-2a3b4c5d6e7f",
+					"host": "example.com",
+					"created_at": "2025-12-18T06:00:00Z"
+				}]`, rr.Body.String())
+			},
+		},
+		{
+			name:   "Store error listing webhooks",
+			method: http.MethodGet,
+			target: "/api/v1/me/webhooks?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().ListWebhooks(gomock.Any(), "test@example.com").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError: &ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to retrieve webhooks",
+			},
+		},
+		{
+			name:   "Deletes a webhook",
+			method: http.MethodDelete,
+			target: "/api/v1/me/webhooks/6f1c2d4e-8a9b-4c0d-9e1f-2a3b4c5d6e7f?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().DeleteWebhook(gomock.Any(), "test@example.com", webhook.Uuid).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Deleting another user's webhook",
+			method: http.MethodDelete,
+			target: "/api/v1/me/webhooks/6f1c2d4e-8a9b-4c0d-9e1f-2a3b4c5d6e7f?email=test@example.com",
+			setupMock: func(m *mocks.MockStoreService) {
+				m.EXPECT().
+					DeleteWebhook(gomock.Any(), "test@example.com", webhook.Uuid).
+					Return(db.ErrWebhookNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError: &ErrorResponse{
+				Error:   "WEBHOOK_NOT_FOUND",
+				Message: "No webhook with that UUID",
+			},
+		},
+		{
+			name:           "Deleting with an invalid UUID",
+			method:         http.MethodDelete,
+			target:         "/api/v1/me/webhooks/webhook-1?email=test@example.com",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError: &ErrorResponse{
+				Error:   "INVALID_WEBHOOK",
+				Message: "Webhook UUID parameter is required",
+			},
+		},
+		{
+			name:           "Method not allowed",
+			method:         http.MethodPut,
+			target:         "/api/v1/me/webhooks",
+			setupMock:      func(m *mocks.MockStoreService) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError: &ErrorResponse{
+				Error:   "METHOD_NOT_ALLOWED",
+				Message: "Method not allowed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			tt.setupMock(mockStore)
+
+			handler, err := NewWebhookHandler(mockStore, notify.NewWebhookNotifier(mockStore, true))
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Webhook: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				err := json.NewDecoder(rr.Body).Decode(&errorResponse)
+				require.NoError(t, err, "Failed to decode error response body")
+				assert.Equal(t, *tt.expectedError, errorResponse)
+			}
+			if tt.check != nil {
+				tt.check(t, rr)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_TestWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		receiverStatus int
+		expectedStatus int
+		expectedError  *ErrorResponse
+	}{
+		{
+			name:           "Receiver accepts the test event",
+			receiverStatus: http.StatusNoContent,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Receiver rejects the test event",
+			receiverStatus: http.StatusGone,
+			expectedStatus: http.StatusBadGateway,
+			expectedError: &ErrorResponse{
+				Error:   "WEBHOOK_FAILED",
+				Message: "Webhook test failed: the webhook responded with status 410",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []byte
+			var signature, timestamp string
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ = io.ReadAll(r.Body)
+				signature = r.Header.Get(notify.WebhookSignatureHeader)
+				timestamp = r.Header.Get(notify.WebhookTimestampHeader)
+				w.WriteHeader(tt.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			webhook := dbgen.Webhook{Uuid: uuid.New(), Url: receiver.URL, Secret: "whsec_secret"}
+
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			mockStore.EXPECT().GetUserWebhook(gomock.Any(), "test@example.com", webhook.Uuid).Return(webhook, nil)
+
+			handler, err := NewWebhookHandler(mockStore, notify.NewWebhookNotifier(mockStore, true))
+			require.NoError(t, err)
+
+			target := "/api/v1/me/webhooks/" + webhook.Uuid.String() + "/test"
+			req, err := http.NewRequest(http.MethodPost, target, strings.NewReader(`{"email":"test@example.com"}`))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			serve(t, &Handlers{Webhook: handler}, rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, "Status code mismatch")
+			assert.True(t, notify.VerifyWebhookSignature(webhook.Secret, timestamp, signature, received))
+
+			if tt.expectedError != nil {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&errorResponse))
+				assert.Equal(t, *tt.expectedError, errorResponse)
+				return
+			}
+
+			var response apiv1.WebhookTest
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+			assert.Equal(t, db.DeliveryStatusDelivered, response.Status)
+			assert.NotEmpty(t, response.DeliveryID)
+		})
+	}
+}
+
+func TestWebhookHandler_TestWebhookNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	webhookUUID := uuid.New()
+	mockStore.EXPECT().
+		GetUserWebhook(gomock.Any(), "test@example.com", webhookUUID).
+		Return(dbgen.Webhook{}, db.ErrWebhookNotFound)
+
+	handler, err := NewWebhookHandler(mockStore, notify.NewWebhookNotifier(mockStore, true))
+	require.NoError(t, err)
+
+	// The deprecated route takes the UUID in the body.
+	body := `{"email":"test@example.com","uuid":"` + webhookUUID.String() + `"}`
+	req, err := http.NewRequest(http.MethodPost, "/api/user/webhooks/test", strings.NewReader(body))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Webhook: handler}, rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestWebhookHandler_TestWebhookHidesNetworkErrors(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhook := dbgen.Webhook{Uuid: uuid.New(), Url: receiver.URL, Secret: "whsec_secret"}
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().GetUserWebhook(gomock.Any(), "test@example.com", webhook.Uuid).Return(webhook, nil)
+
+	// Private networks are denied, so the receiver on localhost can't be reached.
+	handler, err := NewWebhookHandler(mockStore, notify.NewWebhookNotifier(mockStore, false))
+	require.NoError(t, err)
+
+	target := "/api/v1/me/webhooks/" + webhook.Uuid.String() + "/test"
+	req, err := http.NewRequest(http.MethodPost, target, strings.NewReader(`{"email":"test@example.com"}`))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	serve(t, &Handlers{Webhook: handler}, rr, req)
+
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	var errorResponse ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&errorResponse))
+	assert.Equal(t, ErrorResponse{Error: "WEBHOOK_FAILED", Message: "Webhook test failed"}, errorResponse)
+}
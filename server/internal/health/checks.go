@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ProviderTimeout bounds checks of providers, which are further away than the database.
+const ProviderTimeout = 5 * time.Second
+
+// Database is the database a service checks.
+type Database interface {
+	// Ping checks the database can be reached
+	Ping(ctx context.Context) error
+
+	// MigrationVersion returns the version of the latest migration applied to the database
+	MigrationVersion(ctx context.Context) (int64, error)
+}
+
+// DatabaseChecks returns checks that db can be reached and has been migrated to at least version want,
+// the latest migration the service was built with. A database migrated past it is fine, since migrations
+// run before a new version is rolled out.
+func DatabaseChecks(db Database, want int64) []Check {
+	return []Check{
+		{Name: "database", Run: db.Ping},
+		{Name: "migrations", Run: func(ctx context.Context) error {
+			version, err := db.MigrationVersion(ctx)
+			if err != nil {
+				return err
+			}
+			if version < want {
+				return fmt.Errorf("database is at migration %d, want %d", version, want)
+			}
+			return nil
+		}},
+	}
+}
+
+// ProviderCheck returns a check that the provider named name can be reached with ping.
+func ProviderCheck(name string, ping func(ctx context.Context) error) Check {
+	return Check{Name: name, Timeout: ProviderTimeout, Run: ping}
+}
+
+// Heartbeat tracks when a periodic job last finished, so that readiness fails when it stops running.
+type Heartbeat struct {
+	started time.Time
+	last    atomic.Int64
+	now     func() time.Time
+}
+
+// NewHeartbeat returns a heartbeat for a job starting now.
+func NewHeartbeat() *Heartbeat {
+	return &Heartbeat{started: time.Now(), now: time.Now}
+}
+
+// Beat records that the job finished a run.
+func (h *Heartbeat) Beat() {
+	h.last.Store(h.now().UnixNano())
+}
+
+// Check returns a check that the job finished a run within maxAge. Until it first finishes one, the age is
+// measured from when it started.
+func (h *Heartbeat) Check(name string, maxAge time.Duration) Check {
+	return Check{Name: name, Run: func(context.Context) error {
+		last, ran := h.started, false
+		if nanos := h.last.Load(); nanos != 0 {
+			last, ran = time.Unix(0, nanos), true
+		}
+
+		age := h.now().Sub(last)
+		if age <= maxAge {
+			return nil
+		}
+		if !ran {
+			return fmt.Errorf("no run finished in the %s since starting, want one within %s",
+				age.Round(time.Second), maxAge)
+		}
+		return fmt.Errorf("last run finished %s ago at %s, want one within %s",
+			age.Round(time.Second), last.UTC().Format(time.RFC3339), maxAge)
+	}}
+}
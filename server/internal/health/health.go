@@ -0,0 +1,168 @@
+// Package health serves the liveness and readiness checks of the API and the forecaster daemon. Liveness
+// only says the process is serving; readiness runs checks of what it depends on, such as the database, each
+// bounded by a timeout, and reports the result of each as JSON.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Paths the checks are served at.
+const (
+	LivePath  = "/livez"
+	ReadyPath = "/readyz"
+)
+
+// Statuses of reports and checks.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout bounds checks that don't set a timeout of their own.
+const DefaultTimeout = 2 * time.Second
+
+// Check is a check of something the service needs to serve.
+type Check struct {
+	// Name identifies the check in reports.
+	Name string
+	// Timeout bounds the check. DefaultTimeout is used when it's zero.
+	Timeout time.Duration
+	// Run returns an error saying what's wrong if the check fails.
+	Run func(ctx context.Context) error
+}
+
+// Report is the result of a liveness or readiness check.
+type Report struct {
+	// Status is ok if every check passed, and fail otherwise.
+	Status string `json:"status"`
+	// Checks are the results of each check, by name. Liveness runs none.
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of one check.
+type CheckResult struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	// Error says why the check failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Checker serves the readiness of a service, as the result of its checks.
+type Checker struct {
+	checks []Check
+}
+
+// NewChecker returns a checker running checks.
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Run runs every check concurrently, each bounded by its timeout, and reports their results.
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := CheckResult{
+		Status:     StatusOK,
+		DurationMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Ready serves the readiness of the service: 200 when every check passes, and 503 when any fails, with the
+// result of each check.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+		for name, result := range report.Checks {
+			if result.Status != StatusOK {
+				slog.WarnContext(r.Context(), "Readiness check failed", "check", name, "error", result.Error)
+			}
+		}
+	}
+	write(w, r, status, report)
+}
+
+// Live serves the liveness of the service, which is up as long as it can serve a request. It checks
+// nothing else, so that an orchestrator doesn't restart a service whose dependencies are down.
+func Live(w http.ResponseWriter, r *http.Request) {
+	write(w, r, http.StatusOK, Report{Status: StatusOK})
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write health report", "error", err)
+	}
+}
+
+// Routes adds the liveness and readiness checks of c to mux.
+func (c *Checker) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+LivePath, Live)
+	mux.HandleFunc("GET "+ReadyPath, c.Ready)
+}
+
+// CheckProvidersFromEnv reports whether readiness checks that providers are reachable, as set by
+// READYZ_CHECK_PROVIDERS (false by default). Providers are left out by default since they're outside our
+// control, and a provider being down shouldn't take every instance out of service.
+func CheckProvidersFromEnv() bool {
+	value := os.Getenv("READYZ_CHECK_PROVIDERS")
+	if value == "" {
+		return false
+	}
+	check, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid READYZ_CHECK_PROVIDERS", "value", value)
+		return false
+	}
+	return check
+}
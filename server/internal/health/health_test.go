@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDatabase struct {
+	pingErr    error
+	version    int64
+	versionErr error
+}
+
+func (f fakeDatabase) Ping(context.Context) error { return f.pingErr }
+
+func (f fakeDatabase) MigrationVersion(context.Context) (int64, error) {
+	return f.version, f.versionErr
+}
+
+func TestChecker_Ready(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []Check
+		expectedStatus int
+		expectedChecks map[string]string
+		expectedError  map[string]string
+	}{
+		{
+			name:           "Every check passes",
+			checks:         DatabaseChecks(fakeDatabase{version: 15}, 15),
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"database": StatusOK, "migrations": StatusOK},
+		},
+		{
+			name:           "Migrated past the latest migration",
+			checks:         DatabaseChecks(fakeDatabase{version: 16}, 15),
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"database": StatusOK, "migrations": StatusOK},
+		},
+		{
+			name: "Database down",
+			checks: DatabaseChecks(fakeDatabase{
+				pingErr:    errors.New("connection refused"),
+				versionErr: errors.New("connection refused"),
+			}, 15),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"database": StatusFail, "migrations": StatusFail},
+			expectedError:  map[string]string{"database": "connection refused"},
+		},
+		{
+			name:           "Migrations behind",
+			checks:         DatabaseChecks(fakeDatabase{version: 14}, 15),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"database": StatusOK, "migrations": StatusFail},
+			expectedError:  map[string]string{"migrations": "database is at migration 14, want 15"},
+		},
+		{
+			name: "Check times out",
+			checks: []Check{{Name: "open-meteo", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"open-meteo": StatusFail},
+			expectedError:  map[string]string{"open-meteo": "timed out after 10ms"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			NewChecker(tt.checks...).Ready(rr, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+			var report Report
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, StatusOK, report.Status)
+			} else {
+				assert.Equal(t, StatusFail, report.Status)
+			}
+			require.Len(t, report.Checks, len(tt.expectedChecks))
+			for name, status := range tt.expectedChecks {
+				assert.Equal(t, status, report.Checks[name].Status, name)
+			}
+			for name, message := range tt.expectedError {
+				assert.Equal(t, message, report.Checks[name].Error, name)
+			}
+		})
+	}
+}
+
+func TestChecker_RunsChecksConcurrently(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	checker := NewChecker(
+		Check{Name: "a", Timeout: 80 * time.Millisecond, Run: slow},
+		Check{Name: "b", Timeout: 80 * time.Millisecond, Run: slow},
+		Check{Name: "c", Timeout: 80 * time.Millisecond, Run: slow},
+	)
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusOK, report.Status, "each check has its own timeout")
+}
+
+func TestLive(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Live(rr, httptest.NewRequest(http.MethodGet, LivePath, nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+}
+
+func TestHeartbeat_Check(t *testing.T) {
+	started := time.Date(2026, 1, 10, 6, 0, 0, 0, time.UTC)
+	now := started
+	heartbeat := &Heartbeat{started: started, now: func() time.Time { return now }}
+	check := heartbeat.Check("last_run", time.Hour)
+
+	now = started.Add(30 * time.Minute)
+	assert.NoError(t, check.Run(context.Background()), "a job that just started has time to finish a run")
+
+	now = started.Add(2 * time.Hour)
+	assert.EqualError(t, check.Run(context.Background()),
+		"no run finished in the 2h0m0s since starting, want one within 1h0m0s")
+
+	heartbeat.Beat()
+	assert.NoError(t, check.Run(context.Background()))
+
+	now = now.Add(90 * time.Minute)
+	assert.EqualError(t, check.Run(context.Background()),
+		"last run finished 1h30m0s ago at 2026-01-10T08:00:00Z, want one within 1h0m0s")
+}
@@ -0,0 +1,186 @@
+// Package logging configures the structured logs of the API and the workers. Records are written as JSON
+// with the attributes of the context they're logged in, such as the ID of the request or forecaster run
+// they're for, and personal data is redacted unless it's asked for.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted replaces the values of personal data in logs.
+const Redacted = "[REDACTED]"
+
+// Keys of the attributes logged alongside records.
+const (
+	RequestIDKey = "request_id"
+	RunIDKey     = "run_id"
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+)
+
+// piiKeys are the keys of attributes holding personal data, whose values are redacted.
+var piiKeys = map[string]bool{
+	"email":     true,
+	"message":   true,
+	"name":      true,
+	"phone":     true,
+	"recipient": true,
+}
+
+// Options configure a logger.
+type Options struct {
+	// Level is the lowest level logged.
+	Level slog.Leveler
+	// Text writes records as logfmt text rather than JSON, for reading in a terminal.
+	Text bool
+	// LogPII keeps personal data in logs rather than redacting it.
+	LogPII bool
+}
+
+// optionsFromEnv reads logging options from LOG_LEVEL (debug, info, warn or error; info by default),
+// LOG_FORMAT (json, the default, or text) and LOG_PII (false by default). It also returns the variables with
+// values it ignored.
+func optionsFromEnv() (Options, []string) {
+	opts := Options{Level: slog.LevelInfo}
+
+	var invalid []string
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			invalid = append(invalid, "LOG_LEVEL")
+		} else {
+			opts.Level = level
+		}
+	}
+	switch value := strings.ToLower(os.Getenv("LOG_FORMAT")); value {
+	case "", "json":
+	case "text":
+		opts.Text = true
+	default:
+		invalid = append(invalid, "LOG_FORMAT")
+	}
+	if value := os.Getenv("LOG_PII"); value != "" {
+		logPII, err := strconv.ParseBool(value)
+		if err != nil {
+			invalid = append(invalid, "LOG_PII")
+		}
+		opts.LogPII = logPII
+	}
+	return opts, invalid
+}
+
+// New returns a logger writing to w. Records carry the attributes of the context they're logged in.
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	if !opts.LogPII {
+		handlerOpts.ReplaceAttr = redact
+	}
+
+	var handler slog.Handler
+	if opts.Text {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// Setup makes a logger configured from the environment the default, for slog and the log package, and
+// tags its records with the service logging them.
+func Setup(service string) *slog.Logger {
+	opts, invalid := optionsFromEnv()
+	logger := New(os.Stderr, opts).With("service", service)
+	slog.SetDefault(logger)
+
+	for _, name := range invalid {
+		logger.Warn("Ignoring invalid logging option", "variable", name, "value", os.Getenv(name))
+	}
+	return logger
+}
+
+// redact replaces the values of attributes holding personal data.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if piiKeys[a.Key] && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a copy of ctx whose records are logged with attrs, after those ctx already has.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := contextAttrs(ctx)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// WithRequestID returns a copy of ctx whose records are logged with the ID of the request it's for.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return WithAttrs(ctx, slog.String(RequestIDKey, requestID))
+}
+
+// RequestID returns the ID of the request ctx is for, or "" if it isn't for one.
+func RequestID(ctx context.Context) string {
+	for _, a := range contextAttrs(ctx) {
+		if a.Key == RequestIDKey {
+			return a.Value.String()
+		}
+	}
+	return ""
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes of the context a record is logged in to the record, along with the IDs
+// of the trace and span it's logged in, so that logs and traces can be joined.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := contextAttrs(ctx)
+	spanContext := trace.SpanContextFromContext(ctx)
+	if len(attrs) == 0 && !spanContext.IsValid() {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	record = record.Clone()
+	record.AddAttrs(attrs...)
+	if spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String(TraceIDKey, spanContext.TraceID().String()),
+			slog.String(SpanIDKey, spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Fatal logs msg at error level with args and exits, for errors the service can't start or go on after.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
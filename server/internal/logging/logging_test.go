@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNew_Redaction(t *testing.T) {
+	tests := []struct {
+		name          string
+		logPII        bool
+		expectedEmail string
+		expectedPhone string
+	}{
+		{
+			name:          "Redacted by default",
+			expectedEmail: Redacted,
+			expectedPhone: Redacted,
+		},
+		{
+			name:          "Kept when asked for",
+			logPII:        true,
+			expectedEmail: "test@example.com",
+			expectedPhone: "+15551234567",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, Options{Level: slog.LevelInfo, LogPII: tt.logPII})
+
+			logger.Info("Contact form submission", "email", "test@example.com", "resort", "Alta",
+				slog.Group("user", "phone", "+15551234567"))
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, "Contact form submission", record["msg"])
+			assert.Equal(t, tt.expectedEmail, record["email"])
+			assert.Equal(t, "Alta", record["resort"])
+			assert.Equal(t, tt.expectedPhone, record["user"].(map[string]any)["phone"])
+		})
+	}
+}
+
+func TestNew_ContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: slog.LevelInfo})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithAttrs(ctx, slog.String("resort", "Alta"))
+	logger.InfoContext(ctx, "Checking forecast")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-1", record[RequestIDKey])
+	assert.Equal(t, "Alta", record["resort"])
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Empty(t, RequestID(context.Background()))
+}
+
+func TestNew_TraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Options{Level: slog.LevelInfo})
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "pass")
+	defer span.End()
+	logger.InfoContext(ctx, "Forecast check starting")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, span.SpanContext().TraceID().String(), record[TraceIDKey])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record[SpanIDKey])
+
+	buf.Reset()
+	logger.InfoContext(context.Background(), "Forecast check starting")
+	var untraced map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &untraced))
+	assert.NotContains(t, untraced, TraceIDKey)
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "yaml")
+	t.Setenv("LOG_PII", "true")
+
+	opts, invalid := optionsFromEnv()
+
+	assert.Equal(t, slog.LevelDebug, opts.Level)
+	assert.False(t, opts.Text)
+	assert.True(t, opts.LogPII)
+	assert.Equal(t, []string{"LOG_FORMAT"}, invalid)
+}
@@ -0,0 +1,183 @@
+// Package metrics defines the Prometheus metrics of the API and the workers and serves them for scraping.
+// Metrics are registered in one registry per process, so each binary exposes the metrics of the packages it
+// runs, alongside Go runtime and process metrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where metrics are served.
+const Path = "/metrics"
+
+const namespace = "powhunter"
+
+// Registry holds every metric of the process.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// HTTPRequests counts the API's requests by route pattern, method and status.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "API requests by route pattern, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration observes how long the API takes to serve requests, by route pattern, method and
+	// status.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve API requests by route pattern, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// ProviderRequestDuration observes calls to the weather and notification providers, by provider.
+	ProviderRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken by calls to weather and notification providers.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"provider"})
+
+	// ProviderErrors counts the calls to providers that failed, by provider.
+	ProviderErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "errors_total",
+		Help:      "Failed calls to weather and notification providers.",
+	}, []string{"provider"})
+
+	// ResortsProcessed counts the resorts whose forecast the forecaster fetched and stored.
+	ResortsProcessed = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "forecaster",
+		Name:      "resorts_processed_total",
+		Help:      "Resorts whose forecast was fetched and stored.",
+	})
+
+	// AlertMatches counts the alerts the forecaster found a forecast matching and queued.
+	AlertMatches = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "forecaster",
+		Name:      "alert_matches_total",
+		Help:      "Alerts matching a forecast, queued to be sent.",
+	})
+
+	// ForecasterLastRun is when the forecaster last finished a pass.
+	ForecasterLastRun = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "forecaster",
+		Name:      "last_run_timestamp_seconds",
+		Help:      "Unix time the forecaster last finished a pass.",
+	})
+
+	// Notifications counts notifications handed to providers, by channel and whether they were sent or
+	// failed.
+	Notifications = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Notifications by channel and result (sent or failed).",
+	}, []string{"channel", "result"})
+
+	// DBQueryDuration observes database queries by the name sqlc gives them.
+	DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Time taken by database queries, by query name.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"query"})
+)
+
+// Results of notifications.
+const (
+	ResultSent   = "sent"
+	ResultFailed = "failed"
+)
+
+// ObserveHTTPRequest records a request to the route with the given pattern, served with status after
+// elapsed. Requests matching no route are recorded under the "unmatched" route, and unknown methods as
+// "other", so that scanning clients can't create a series per path or method.
+func ObserveHTTPRequest(pattern, method string, status int, elapsed time.Duration) {
+	if pattern == "" {
+		pattern = "unmatched"
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodOptions:
+	default:
+		method = "other"
+	}
+	code := strconv.Itoa(status)
+	HTTPRequests.WithLabelValues(pattern, method, code).Inc()
+	HTTPRequestDuration.WithLabelValues(pattern, method, code).Observe(elapsed.Seconds())
+}
+
+// ObserveProviderCall records a call to provider that started at start and failed with err, if it did.
+func ObserveProviderCall(provider string, start time.Time, err error) {
+	ProviderRequestDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+	if err != nil {
+		ProviderErrors.WithLabelValues(provider).Inc()
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		Registry: Registry,
+		// A count the store fails to take is left out, rather than failing the whole scrape.
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// Serve serves the metrics on addr until ctx is done, for the workers, which have no API server to serve
+// them, along with the routes of mux unless it's nil. Errors are logged rather than stopping the worker.
+func Serve(ctx context.Context, addr string, mux *http.ServeMux) {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mux.Handle("GET "+Path, Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Serving metrics", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shut down metrics server", "error", err)
+		}
+	}()
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		method        string
+		expectedRoute string
+		expectedVerb  string
+	}{
+		{
+			name:          "Route",
+			pattern:       "GET /api/v1/resorts/{id}",
+			method:        http.MethodGet,
+			expectedRoute: "GET /api/v1/resorts/{id}",
+			expectedVerb:  http.MethodGet,
+		},
+		{
+			name:          "No route",
+			method:        http.MethodGet,
+			expectedRoute: "unmatched",
+			expectedVerb:  http.MethodGet,
+		},
+		{
+			name:          "Unknown method",
+			method:        "PROPFIND",
+			expectedRoute: "unmatched",
+			expectedVerb:  "other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := HTTPRequests.WithLabelValues(tt.expectedRoute, tt.expectedVerb, "404")
+			before := testutil.ToFloat64(counter)
+
+			ObserveHTTPRequest(tt.pattern, tt.method, http.StatusNotFound, 20*time.Millisecond)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+func TestObserveProviderCall(t *testing.T) {
+	errorsBefore := testutil.ToFloat64(ProviderErrors.WithLabelValues("test-provider"))
+
+	ObserveProviderCall("test-provider", time.Now(), nil)
+	ObserveProviderCall("test-provider", time.Now(), errors.New("timeout"))
+
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(ProviderErrors.WithLabelValues("test-provider")))
+	assert.Equal(t, 1, testutil.CollectAndCount(ProviderRequestDuration))
+}
+
+type fakeCounter struct {
+	alerts   int64
+	users    int64
+	usersErr error
+}
+
+func (f fakeCounter) CountActiveAlerts(context.Context) (int64, error) { return f.alerts, nil }
+
+func (f fakeCounter) CountUsers(context.Context) (int64, error) { return f.users, f.usersErr }
+
+func TestStoreCollector(t *testing.T) {
+	t.Run("Counts", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(storeCollector{counter: fakeCounter{alerts: 12, users: 5}})
+
+		expected := `
+# HELP powhunter_active_alerts Active alerts.
+# TYPE powhunter_active_alerts gauge
+powhunter_active_alerts 12
+# HELP powhunter_users Users with an account.
+# TYPE powhunter_users gauge
+powhunter_users 5
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+	})
+
+	t.Run("Failed count", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(storeCollector{counter: fakeCounter{alerts: 12, usersErr: errors.New("connection refused")}})
+
+		families, err := registry.Gather()
+		require.Error(t, err)
+		require.Len(t, families, 1, "the counts that succeeded are still gathered")
+		assert.Equal(t, "powhunter_active_alerts", families[0].GetName())
+	})
+}
+
+func TestHandler(t *testing.T) {
+	ResortsProcessed.Inc()
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, Path, nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, rr.Body.String(), "powhunter_forecaster_resorts_processed_total")
+	assert.Contains(t, rr.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storeTimeout bounds the store's counts, so that a slow database doesn't hold up a scrape.
+const storeTimeout = 5 * time.Second
+
+// Counter counts what the store gauges report.
+type Counter interface {
+	// CountActiveAlerts returns the number of active alerts
+	CountActiveAlerts(ctx context.Context) (int64, error)
+
+	// CountUsers returns the number of users
+	CountUsers(ctx context.Context) (int64, error)
+}
+
+var (
+	activeAlertsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_alerts"),
+		"Active alerts.",
+		nil, nil,
+	)
+	usersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "users"),
+		"Users with an account.",
+		nil, nil,
+	)
+)
+
+// storeCollector reports gauges counted by the store when metrics are scraped.
+type storeCollector struct {
+	counter Counter
+}
+
+// RegisterStore registers gauges of the active alerts and users, counted by counter whenever metrics are
+// scraped. Only the API registers them, so that a single series is reported when the workers are scraped
+// too.
+func RegisterStore(counter Counter) {
+	Registry.MustRegister(storeCollector{counter: counter})
+}
+
+func (c storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeAlertsDesc
+	ch <- usersDesc
+}
+
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	gauges := []struct {
+		name  string
+		desc  *prometheus.Desc
+		count func(context.Context) (int64, error)
+	}{
+		{"active_alerts", activeAlertsDesc, c.counter.CountActiveAlerts},
+		{"users", usersDesc, c.counter.CountUsers},
+	}
+	for _, g := range gauges {
+		count, err := g.count(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to count for metrics", "metric", g.name, "error", err)
+			ch <- prometheus.NewInvalidMetric(g.desc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, float64(count))
+	}
+}
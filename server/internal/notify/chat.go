@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+// chatWebhookHosts lists the hosts and path prefixes of each chat service's incoming webhooks.
+var chatWebhookHosts = map[string]struct {
+	hosts      []string
+	pathPrefix string
+}{
+	db.ChannelSlack: {hosts: []string{"hooks.slack.com"}, pathPrefix: "/services/"},
+	db.ChannelDiscord: {
+		hosts:      []string{"discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com"},
+		pathPrefix: "/api/webhooks/",
+	},
+}
+
+// ChatNotifier posts alerts to a Slack or Discord channel through an incoming webhook, formatted as Block Kit
+// blocks or embeds. The notification's recipient is the UUID of the alert destination holding the URL.
+type ChatNotifier struct {
+	channel    string
+	store      db.StoreService
+	client     *http.Client
+	format     func(alerts []chatAlert, more int) any
+	retryDelay time.Duration
+	now        func() time.Time
+}
+
+// NewSlackNotifier returns a notifier posting Block Kit messages to Slack. allowPrivateNetworks is as for
+// NewWebhookNotifier.
+func NewSlackNotifier(store db.StoreService, allowPrivateNetworks bool) *ChatNotifier {
+	return newChatNotifier(db.ChannelSlack, store, allowPrivateNetworks, slackMessageFor)
+}
+
+// NewDiscordNotifier returns a notifier posting embeds to Discord. allowPrivateNetworks is as for
+// NewWebhookNotifier.
+func NewDiscordNotifier(store db.StoreService, allowPrivateNetworks bool) *ChatNotifier {
+	return newChatNotifier(db.ChannelDiscord, store, allowPrivateNetworks, discordMessageFor)
+}
+
+// SlackNotifierFromEnv returns a Slack notifier honouring WEBHOOK_ALLOW_PRIVATE_NETWORKS.
+func SlackNotifierFromEnv(store db.StoreService) *ChatNotifier {
+	return NewSlackNotifier(store, privateNetworksAllowed())
+}
+
+// DiscordNotifierFromEnv returns a Discord notifier honouring WEBHOOK_ALLOW_PRIVATE_NETWORKS.
+func DiscordNotifierFromEnv(store db.StoreService) *ChatNotifier {
+	return NewDiscordNotifier(store, privateNetworksAllowed())
+}
+
+func newChatNotifier(
+	channel string,
+	store db.StoreService,
+	allowPrivateNetworks bool,
+	format func([]chatAlert, int) any,
+) *ChatNotifier {
+	return &ChatNotifier{
+		channel:    channel,
+		store:      store,
+		client:     newWebhookClient(allowPrivateNetworks),
+		format:     format,
+		retryDelay: defaultWebhookRetryDelay,
+		now:        time.Now,
+	}
+}
+
+func (n *ChatNotifier) Provider() string {
+	return n.channel
+}
+
+// Send posts the notification's alerts to the destination named by its recipient.
+func (n *ChatNotifier) Send(ctx context.Context, notification Notification) (Receipt, error) {
+	destinationUUID, err := uuid.Parse(notification.Recipient)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("invalid %s recipient %q", n.channel, notification.Recipient)
+	}
+
+	destination, err := n.store.GetAlertDestination(ctx, destinationUUID)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if destination.Channel != n.channel {
+		return Receipt{}, fmt.Errorf("alert destination %s is a %s channel, not %s",
+			destinationUUID, destination.Channel, n.channel)
+	}
+
+	alerts, more := chatAlertsFor(notification.Alerts, n.now())
+	body, err := json.Marshal(n.format(alerts, more))
+	if err != nil {
+		return Receipt{}, fmt.Errorf("error encoding %s message: %w", n.channel, err)
+	}
+
+	err = postWithRetries(ctx, n.client, n.retryDelay, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, destination.Url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error creating %s request: %w", n.channel, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	return Receipt{Status: db.DeliveryStatusDelivered}, nil
+}
+
+// ValidateChatWebhookURL checks that rawURL is an https incoming webhook URL of the chat service named by
+// channel, so destinations can only post to Slack or Discord.
+func ValidateChatWebhookURL(channel, rawURL string) error {
+	service, ok := chatWebhookHosts[channel]
+	if !ok {
+		return fmt.Errorf("unsupported chat channel %q", channel)
+	}
+
+	if err := ValidateWebhookURL(rawURL, true); err != nil {
+		return err
+	}
+
+	parsed, _ := url.Parse(rawURL)
+	for _, host := range service.hosts {
+		if strings.EqualFold(parsed.Host, host) && strings.HasPrefix(parsed.Path, service.pathPrefix) {
+			return nil
+		}
+	}
+	return errors.New("webhook URL is not a " + channel + " incoming webhook")
+}
+
+// chatAlert is one forecast formatted for a chat message in the user's units and locale.
+type chatAlert struct {
+	ResortName string
+	ResortURL  string
+	Snow       string
+	// When is the forecast date, with "today" or "tomorrow" when it is that close.
+	When     string
+	IsUpdate bool
+}
+
+// chatAlertsFor formats up to maxDigestAlerts alerts, keeping only the largest forecast for each resort and
+// day as templates do, and returns how many were left out.
+func chatAlertsFor(alerts []db.AlertToSend, now time.Time) ([]chatAlert, int) {
+	alerts = mergeAlerts(alerts)
+	formatted := make([]chatAlert, 0, min(len(alerts), maxDigestAlerts))
+	for _, alert := range alerts[:min(len(alerts), maxDigestAlerts)] {
+		loc := localeFor(alert.Locale)
+		_, snow, _ := loc.snow(alert.SnowAmount, alert.Units)
+
+		when := loc.dateLayout(alert.ForecastDate)
+		if day := loc.dayLabel(alert.ForecastDate, now); day == loc.today || day == loc.tomorrow {
+			when = fmt.Sprintf("%s (%s)", when, day)
+		}
+
+		formatted = append(formatted, chatAlert{
+			ResortName: alert.ResortName,
+			ResortURL:  alert.ResortURL,
+			Snow:       snow,
+			When:       when,
+			IsUpdate:   alert.IsUpdate,
+		})
+	}
+	return formatted, len(alerts) - len(formatted)
+}
@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+func testChatAlerts(now time.Time) []db.AlertToSend {
+	return []db.AlertToSend{
+		{
+			ResortName:   "Crystal Mountain",
+			ResortUUID:   uuid.New(),
+			ResortURL:    "https://www.crystalmountainresort.com/the-mountain/mountain-report-and-webcams",
+			SnowAmount:   8.5,
+			ForecastDate: now.Add(24 * time.Hour),
+		},
+		{
+			ResortName:   "Stevens Pass",
+			ResortUUID:   uuid.New(),
+			SnowAmount:   12,
+			ForecastDate: time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC),
+			IsUpdate:     true,
+		},
+	}
+}
+
+func sendChatNotification(t *testing.T, channel string, alerts []db.AlertToSend, now time.Time) []byte {
+	t.Helper()
+
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	destination := dbgen.AlertDestination{Uuid: uuid.New(), Channel: channel, Url: receiver.URL}
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().GetAlertDestination(gomock.Any(), destination.Uuid).Return(destination, nil)
+
+	notifier := NewSlackNotifier(mockStore, true)
+	if channel == db.ChannelDiscord {
+		notifier = NewDiscordNotifier(mockStore, true)
+	}
+	notifier.retryDelay = time.Millisecond
+	notifier.now = func() time.Time { return now }
+
+	receipt, err := notifier.Send(context.Background(), Notification{
+		ID:        uuid.New(),
+		Channel:   channel,
+		Recipient: destination.Uuid.String(),
+		Alerts:    alerts,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.DeliveryStatusDelivered, receipt.Status)
+
+	require.Len(t, receiver.requests, 2, "the failed first attempt is retried")
+	assert.Equal(t, "application/json", receiver.requests[1].Header.Get("Content-Type"))
+	return receiver.bodies[1]
+}
+
+func TestSlackNotifier_Send(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+
+	body := sendChatNotification(t, db.ChannelSlack, testChatAlerts(now), now)
+	assert.JSONEq(t, `{
+		"text": "❄️ Powder alerts: 2 forecasts",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": "❄️ Powder alerts: 2 forecasts"}},
+			{
+				"type": "section",
+				"text": {"type": "mrkdwn", "text": "*<https://www.crystalmountainresort.com/the-mountain/mountain-report-and-webcams|Crystal Mountain>*"},
+				"fields": [
+					{"type": "mrkdwn", "text": "*Snow*\n8.5 inches"},
+					{"type": "mrkdwn", "text": "*When*\nFriday, Dec 19 (tomorrow)"}
+				]
+			},
+			{
+				"type": "section",
+				"text": {"type": "mrkdwn", "text": "*Stevens Pass*  `+"`UPDATED`"+`"},
+				"fields": [
+					{"type": "mrkdwn", "text": "*Snow*\n12.0 inches"},
+					{"type": "mrkdwn", "text": "*When*\nMonday, Dec 22"}
+				]
+			},
+			{"type": "context", "elements": [{"type": "mrkdwn", "text": "Sent by Pow Hunter"}]}
+		]
+	}`, string(body))
+}
+
+func TestSlackNotifier_EscapesMarkup(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	alerts := testChatAlerts(now)[:1]
+	alerts[0].ResortName = "Snow <Bowl> & Co"
+
+	var message slackMessage
+	require.NoError(t, json.Unmarshal(sendChatNotification(t, db.ChannelSlack, alerts, now), &message))
+	assert.Equal(t, "Powder alert: 8.5 inches at Snow &lt;Bowl&gt; &amp; Co Friday, Dec 19 (tomorrow)", message.Text)
+	assert.Equal(t, "❄️ Powder alert: Snow <Bowl> & Co", message.Blocks[0].Text.Text, "plain text isn't escaped")
+	assert.Equal(t, "*<https://www.crystalmountainresort.com/the-mountain/mountain-report-and-webcams|"+
+		"Snow &lt;Bowl&gt; &amp; Co>*", message.Blocks[1].Text.Text)
+}
+
+func TestDiscordNotifier_Send(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	alerts := testChatAlerts(now)
+	for i := range alerts {
+		alerts[i].Units = units.Metric
+		alerts[i].Locale = "fr-CA"
+	}
+
+	body := sendChatNotification(t, db.ChannelDiscord, alerts, now)
+	assert.JSONEq(t, `{
+		"username": "Pow Hunter",
+		"allowed_mentions": {"parse": []},
+		"embeds": [
+			{
+				"title": "❄️ Crystal Mountain",
+				"url": "https://www.crystalmountainresort.com/the-mountain/mountain-report-and-webcams",
+				"color": 3900150,
+				"fields": [
+					{"name": "Snow", "value": "22 cm", "inline": true},
+					{"name": "When", "value": "vendredi 19 décembre (demain)", "inline": true}
+				]
+			},
+			{
+				"title": "❄️ Stevens Pass",
+				"color": 16096779,
+				"fields": [
+					{"name": "Snow", "value": "30 cm", "inline": true},
+					{"name": "When", "value": "lundi 22 décembre", "inline": true}
+				],
+				"footer": {"text": "🔄 Updated forecast"}
+			}
+		]
+	}`, string(body))
+}
+
+func TestChatNotifier_RejectsOtherChannels(t *testing.T) {
+	destination := dbgen.AlertDestination{Uuid: uuid.New(), Channel: db.ChannelDiscord, Url: "https://discord.com/api/webhooks/1/x"}
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().GetAlertDestination(gomock.Any(), destination.Uuid).Return(destination, nil)
+
+	_, err := NewSlackNotifier(mockStore, true).Send(context.Background(), Notification{
+		Recipient: destination.Uuid.String(),
+		Alerts:    testChatAlerts(time.Now()),
+	})
+	assert.ErrorContains(t, err, "is a discord channel, not slack")
+}
+
+func TestChatAlertsFor_CapsLongSummaries(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	alerts := make([]db.AlertToSend, 7)
+	for i := range alerts {
+		alerts[i] = db.AlertToSend{
+			ResortName:   "Crystal Mountain",
+			ResortUUID:   uuid.New(),
+			SnowAmount:   6,
+			ForecastDate: now,
+		}
+	}
+
+	formatted, more := chatAlertsFor(alerts, now)
+	assert.Len(t, formatted, maxDigestAlerts)
+	assert.Equal(t, 2, more)
+	assert.Equal(t, "Thursday, Dec 18 (today)", formatted[0].When)
+}
+
+func TestChatAlertsFor_MergesRepeatedForecasts(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	crystal, stevens := uuid.New(), uuid.New()
+	tomorrow := now.AddDate(0, 0, 1)
+
+	// A held alert merged with a later update of the same forecast, as the outbox sends them.
+	alerts := []db.AlertToSend{
+		{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 12, ForecastDate: now.AddDate(0, 0, 3)},
+		{ResortName: "Crystal Mountain", ResortUUID: crystal, SnowAmount: 6, ForecastDate: tomorrow},
+		{ResortName: "Crystal Mountain", ResortUUID: crystal, SnowAmount: 9, ForecastDate: tomorrow, IsUpdate: true},
+	}
+
+	formatted, more := chatAlertsFor(alerts, now)
+	require.Len(t, formatted, 2, "each resort and day is listed once")
+	assert.Zero(t, more)
+	assert.Equal(t, "Crystal Mountain", formatted[0].ResortName, "alerts are listed by date")
+	assert.Equal(t, "9.0 inches", formatted[0].Snow, "the largest forecast is kept")
+	assert.True(t, formatted[0].IsUpdate)
+	assert.Equal(t, "Stevens Pass", formatted[1].ResortName)
+}
+
+func TestValidateChatWebhookURL(t *testing.T) {
+	tests := []struct {
+		channel string
+		url     string
+		valid   bool
+	}{
+		{channel: db.ChannelSlack, url: "https://hooks.slack.com/services/T000/B000/XXXX", valid: true},
+		{channel: db.ChannelSlack, url: "http://hooks.slack.com/services/T000/B000/XXXX"},
+		{channel: db.ChannelSlack, url: "https://hooks.slack.com.evil.example/services/T000"},
+		{channel: db.ChannelSlack, url: "https://discord.com/api/webhooks/123/abc"},
+		{channel: db.ChannelDiscord, url: "https://discord.com/api/webhooks/123/abc", valid: true},
+		{channel: db.ChannelDiscord, url: "https://discordapp.com/api/webhooks/123/abc", valid: true},
+		{channel: db.ChannelDiscord, url: "https://discord.com/channels/123"},
+		{channel: "teams", url: "https://example.webhook.office.com/webhookb2/abc"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateChatWebhookURL(tt.channel, tt.url)
+		if tt.valid {
+			assert.NoError(t, err, tt.url)
+		} else {
+			assert.Error(t, err, tt.url)
+		}
+	}
+}
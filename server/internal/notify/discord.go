@@ -0,0 +1,69 @@
+package notify
+
+import "fmt"
+
+const (
+	discordColorNew     = 0x3b82f6
+	discordColorUpdated = 0xf59e0b
+)
+
+// discordMessage is a Discord webhook payload. Mentions are disabled so resort names can't ping anyone.
+type discordMessage struct {
+	Username        string                 `json:"username"`
+	Content         string                 `json:"content,omitempty"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+type discordEmbed struct {
+	Title  string         `json:"title"`
+	URL    string         `json:"url,omitempty"`
+	Color  int            `json:"color"`
+	Fields []discordField `json:"fields"`
+	Footer *discordFooter `json:"footer,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// discordMessageFor formats alerts as one embed per forecast, marking updated forecasts with a badge and
+// a different color.
+func discordMessageFor(alerts []chatAlert, more int) any {
+	message := discordMessage{
+		Username:        "Pow Hunter",
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	}
+	if more > 0 {
+		message.Content = fmt.Sprintf("❄️ %d forecasts with fresh snow, showing the first %d.", len(alerts)+more, len(alerts))
+	}
+
+	for _, alert := range alerts {
+		embed := discordEmbed{
+			Title: "❄️ " + alert.ResortName,
+			URL:   alert.ResortURL,
+			Color: discordColorNew,
+			Fields: []discordField{
+				{Name: "Snow", Value: alert.Snow, Inline: true},
+				{Name: "When", Value: alert.When, Inline: true},
+			},
+		}
+		if alert.IsUpdate {
+			embed.Color = discordColorUpdated
+			embed.Footer = &discordFooter{Text: "🔄 Updated forecast"}
+		}
+		message.Embeds = append(message.Embeds, embed)
+	}
+
+	return message
+}
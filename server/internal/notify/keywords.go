@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keyword is a command a user can reply to an SMS alert with.
+type Keyword string
+
+const (
+	KeywordNone  Keyword = ""
+	KeywordStop  Keyword = "STOP"
+	KeywordStart Keyword = "START"
+	KeywordHelp  Keyword = "HELP"
+	KeywordPause Keyword = "PAUSE"
+)
+
+const (
+	// DefaultPauseDuration is used when a user replies PAUSE without a duration.
+	DefaultPauseDuration = 7 * 24 * time.Hour
+
+	// MaxPauseDuration caps how long alerts can be paused from a single reply.
+	MaxPauseDuration = 90 * 24 * time.Hour
+)
+
+// Carrier-mandated opt-out and opt-in synonyms.
+var keywordAliases = map[string]Keyword{
+	"STOP":        KeywordStop,
+	"STOPALL":     KeywordStop,
+	"UNSUBSCRIBE": KeywordStop,
+	"CANCEL":      KeywordStop,
+	"END":         KeywordStop,
+	"QUIT":        KeywordStop,
+	"OPTOUT":      KeywordStop,
+	"REVOKE":      KeywordStop,
+	"START":       KeywordStart,
+	"UNSTOP":      KeywordStart,
+	"YES":         KeywordStart,
+	"HELP":        KeywordHelp,
+	"INFO":        KeywordHelp,
+	"PAUSE":       KeywordPause,
+}
+
+const (
+	StopReply = "Powhunter: You have been unsubscribed and will no longer receive powder alerts. " +
+		"Reply START to resubscribe."
+	StartReply = "Powhunter: You are resubscribed to powder alerts. Msg frequency varies. " +
+		"Msg&data rates may apply. Reply HELP for help, STOP to cancel."
+	HelpReply = "Powhunter powder alerts: Msg frequency varies. Msg&data rates may apply. " +
+		"Reply PAUSE 7D to pause alerts for 7 days, STOP to cancel. Support: support@powhunter.app"
+	PauseUsageReply = "Powhunter: To pause alerts reply PAUSE followed by a number of days or weeks, " +
+		"e.g. PAUSE 7D or PAUSE 2W (up to 90 days)."
+)
+
+// InboundCommand is the parsed form of an inbound SMS.
+type InboundCommand struct {
+	Keyword Keyword
+	// PauseFor is set for PAUSE commands with a valid duration.
+	PauseFor time.Duration
+	// Invalid is set when a known keyword had arguments that could not be parsed.
+	Invalid bool
+}
+
+// ParseInboundSMS parses the body of an inbound SMS into a command. Matching is case-insensitive
+// and ignores surrounding whitespace and punctuation, so "stop.", "Stop" and " STOP " all opt out.
+func ParseInboundSMS(body string) InboundCommand {
+	fields := strings.Fields(strings.ToUpper(body))
+	if len(fields) == 0 {
+		return InboundCommand{Keyword: KeywordNone}
+	}
+
+	keyword, ok := keywordAliases[strings.Trim(fields[0], ".!?,")]
+	if !ok {
+		return InboundCommand{Keyword: KeywordNone}
+	}
+
+	if keyword != KeywordPause {
+		return InboundCommand{Keyword: keyword}
+	}
+
+	if len(fields) == 1 {
+		return InboundCommand{Keyword: KeywordPause, PauseFor: DefaultPauseDuration}
+	}
+
+	pauseFor, err := parsePauseDuration(strings.Join(fields[1:], ""))
+	if err != nil {
+		return InboundCommand{Keyword: KeywordPause, Invalid: true}
+	}
+
+	return InboundCommand{Keyword: KeywordPause, PauseFor: pauseFor}
+}
+
+// parsePauseDuration parses durations such as "7D", "7", "2W" or "10DAYS".
+func parsePauseDuration(s string) (time.Duration, error) {
+	s = strings.Trim(s, ".!?,")
+	digits := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if digits == 0 {
+		return 0, fmt.Errorf("pause duration %q must start with a number", s)
+	}
+
+	numStr, unit := s, ""
+	if digits > 0 {
+		numStr, unit = s[:digits], s[digits:]
+	}
+
+	n, err := strconv.Atoi(numStr)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid pause duration %q", s)
+	}
+
+	var d time.Duration
+	switch unit {
+	case "", "D", "DAY", "DAYS":
+		d = time.Duration(n) * 24 * time.Hour
+	case "W", "WK", "WEEK", "WEEKS":
+		d = time.Duration(n) * 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("unknown pause duration unit %q", unit)
+	}
+
+	if d > MaxPauseDuration {
+		return 0, fmt.Errorf("pause duration %q exceeds the maximum", s)
+	}
+
+	return d, nil
+}
+
+// FormatPauseReply formats the confirmation sent after alerts are paused.
+func FormatPauseReply(until time.Time) string {
+	return fmt.Sprintf("Powhunter: Powder alerts are paused until %s. Reply START to resume sooner or STOP to cancel.",
+		until.Format("Monday, Jan 2"))
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInboundSMS(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected InboundCommand
+	}{
+		{
+			name:     "Stop",
+			body:     "STOP",
+			expected: InboundCommand{Keyword: KeywordStop},
+		},
+		{
+			name:     "Stop lowercase with punctuation",
+			body:     "  stop. ",
+			expected: InboundCommand{Keyword: KeywordStop},
+		},
+		{
+			name:     "Unsubscribe alias",
+			body:     "Unsubscribe",
+			expected: InboundCommand{Keyword: KeywordStop},
+		},
+		{
+			name:     "Start",
+			body:     "start",
+			expected: InboundCommand{Keyword: KeywordStart},
+		},
+		{
+			name:     "Unstop alias",
+			body:     "UNSTOP",
+			expected: InboundCommand{Keyword: KeywordStart},
+		},
+		{
+			name:     "Help",
+			body:     "help",
+			expected: InboundCommand{Keyword: KeywordHelp},
+		},
+		{
+			name:     "Info alias",
+			body:     "INFO",
+			expected: InboundCommand{Keyword: KeywordHelp},
+		},
+		{
+			name:     "Pause without duration",
+			body:     "PAUSE",
+			expected: InboundCommand{Keyword: KeywordPause, PauseFor: 7 * 24 * time.Hour},
+		},
+		{
+			name:     "Pause 7D",
+			body:     "PAUSE 7D",
+			expected: InboundCommand{Keyword: KeywordPause, PauseFor: 7 * 24 * time.Hour},
+		},
+		{
+			name:     "Pause days with space",
+			body:     "pause 3 days",
+			expected: InboundCommand{Keyword: KeywordPause, PauseFor: 3 * 24 * time.Hour},
+		},
+		{
+			name:     "Pause weeks",
+			body:     "Pause 2w",
+			expected: InboundCommand{Keyword: KeywordPause, PauseFor: 14 * 24 * time.Hour},
+		},
+		{
+			name:     "Pause bare number",
+			body:     "PAUSE 10",
+			expected: InboundCommand{Keyword: KeywordPause, PauseFor: 10 * 24 * time.Hour},
+		},
+		{
+			name:     "Pause too long",
+			body:     "PAUSE 91D",
+			expected: InboundCommand{Keyword: KeywordPause, Invalid: true},
+		},
+		{
+			name:     "Pause unknown unit",
+			body:     "PAUSE 7Y",
+			expected: InboundCommand{Keyword: KeywordPause, Invalid: true},
+		},
+		{
+			name:     "Pause zero",
+			body:     "PAUSE 0D",
+			expected: InboundCommand{Keyword: KeywordPause, Invalid: true},
+		},
+		{
+			name:     "Pause non numeric",
+			body:     "PAUSE forever",
+			expected: InboundCommand{Keyword: KeywordPause, Invalid: true},
+		},
+		{
+			name:     "Unknown text",
+			body:     "Thanks, see you on the hill!",
+			expected: InboundCommand{Keyword: KeywordNone},
+		},
+		{
+			name:     "Keyword must be first word",
+			body:     "please stop",
+			expected: InboundCommand{Keyword: KeywordNone},
+		},
+		{
+			name:     "Empty body",
+			body:     "",
+			expected: InboundCommand{Keyword: KeywordNone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseInboundSMS(tt.body)
+			if result != tt.expected {
+				t.Errorf("ParseInboundSMS(%q) = %+v, want %+v", tt.body, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatPauseReply(t *testing.T) {
+	until := time.Date(2025, 12, 25, 15, 0, 0, 0, time.UTC)
+	expected := "Powhunter: Powder alerts are paused until Thursday, Dec 25. Reply START to resume sooner or STOP to cancel."
+
+	if result := FormatPauseReply(until); result != expected {
+		t.Errorf("FormatPauseReply() = %q, want %q", result, expected)
+	}
+}
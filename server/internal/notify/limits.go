@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+const (
+	// DefaultMaxPerDay is how many messages a user is sent in any 24 hours unless they set their own limit.
+	DefaultMaxPerDay = 5
+
+	// DefaultMinSpacing is the shortest time between two messages to a user unless they set their own.
+	DefaultMinSpacing = time.Hour
+
+	// capWindow is the period MaxPerDay applies to.
+	capWindow = 24 * time.Hour
+)
+
+// Limits caps how often a user is notified. Alerts held back by a limit are not dropped; they wait in the
+// outbox and are merged into a single summary once the user can be notified again.
+type Limits struct {
+	// MaxPerDay is the maximum number of messages in any 24 hours. Zero disables the cap.
+	MaxPerDay int
+	// MinSpacing is the minimum time between two messages.
+	MinSpacing time.Duration
+}
+
+// limitExempt reports whether messages on a channel are sent regardless of the user's limits. Webhooks feed
+// the user's own automations and chat channels are shared with others, so neither interrupts the user, and
+// they aren't counted towards the limits either.
+func limitExempt(channel string) bool {
+	switch channel {
+	case db.ChannelWebhook, db.ChannelSlack, db.ChannelDiscord:
+		return true
+	default:
+		return false
+	}
+}
+
+// LimitsFromEnv returns the default limits, overridden by NOTIFY_MAX_PER_DAY and NOTIFY_MIN_SPACING.
+func LimitsFromEnv() Limits {
+	limits := Limits{
+		MaxPerDay:  DefaultMaxPerDay,
+		MinSpacing: DefaultMinSpacing,
+	}
+
+	if value := os.Getenv("NOTIFY_MAX_PER_DAY"); value != "" {
+		maxPerDay, err := strconv.Atoi(value)
+		if err != nil || maxPerDay < 0 {
+			slog.Warn("Ignoring invalid NOTIFY_MAX_PER_DAY", "value", value)
+		} else {
+			limits.MaxPerDay = maxPerDay
+		}
+	}
+
+	if value := os.Getenv("NOTIFY_MIN_SPACING"); value != "" {
+		minSpacing, err := time.ParseDuration(value)
+		if err != nil || minSpacing < 0 {
+			slog.Warn("Ignoring invalid NOTIFY_MIN_SPACING", "value", value)
+		} else {
+			limits.MinSpacing = minSpacing
+		}
+	}
+
+	return limits
+}
+
+// ForUser applies a user's overrides to the default limits.
+func (l Limits) ForUser(overrides db.NotificationLimits) Limits {
+	if overrides.MaxPerDay != nil {
+		l.MaxPerDay = int(*overrides.MaxPerDay)
+	}
+	if overrides.MinSpacingMinutes != nil {
+		l.MinSpacing = time.Duration(*overrides.MinSpacingMinutes) * time.Minute
+	}
+	return l
+}
+
+// NextAllowedSend returns the earliest time the user a budget belongs to may be sent another message.
+// The budget must cover the 24 hours before now. A time at or before now means a message may be sent now.
+func (l Limits) NextAllowedSend(budget db.NotificationBudget, now time.Time) time.Time {
+	limits := l.ForUser(budget.Limits)
+	next := now
+
+	if limits.MaxPerDay > 0 && budget.SentCount >= limits.MaxPerDay && !budget.OldestSentAt.IsZero() {
+		// The next slot opens when the oldest message in the window falls out of it.
+		if freed := budget.OldestSentAt.Add(capWindow); freed.After(next) {
+			next = freed
+		}
+	}
+
+	if limits.MinSpacing > 0 && !budget.LastSentAt.IsZero() {
+		if spaced := budget.LastSentAt.Add(limits.MinSpacing); spaced.After(next) {
+			next = spaced
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestLimits_NextAllowedSend(t *testing.T) {
+	now := time.Date(2025, 12, 18, 12, 0, 0, 0, time.UTC)
+	limits := Limits{MaxPerDay: 3, MinSpacing: time.Hour}
+
+	tests := []struct {
+		name     string
+		limits   Limits
+		budget   db.NotificationBudget
+		expected time.Time
+	}{
+		{
+			name:     "Nothing sent",
+			limits:   limits,
+			budget:   db.NotificationBudget{},
+			expected: now,
+		},
+		{
+			name:   "Under the cap and spaced out",
+			limits: limits,
+			budget: db.NotificationBudget{
+				SentCount:    2,
+				OldestSentAt: now.Add(-10 * time.Hour),
+				LastSentAt:   now.Add(-2 * time.Hour),
+			},
+			expected: now,
+		},
+		{
+			name:   "Too soon after the last message",
+			limits: limits,
+			budget: db.NotificationBudget{
+				SentCount:    1,
+				OldestSentAt: now.Add(-20 * time.Minute),
+				LastSentAt:   now.Add(-20 * time.Minute),
+			},
+			expected: now.Add(40 * time.Minute),
+		},
+		{
+			name:   "Daily cap reached",
+			limits: limits,
+			budget: db.NotificationBudget{
+				SentCount:    3,
+				OldestSentAt: now.Add(-20 * time.Hour),
+				LastSentAt:   now.Add(-2 * time.Hour),
+			},
+			expected: now.Add(4 * time.Hour),
+		},
+		{
+			name:   "Zero cap disables the daily limit",
+			limits: Limits{MaxPerDay: 0, MinSpacing: time.Hour},
+			budget: db.NotificationBudget{
+				SentCount:    30,
+				OldestSentAt: now.Add(-20 * time.Hour),
+				LastSentAt:   now.Add(-2 * time.Hour),
+			},
+			expected: now,
+		},
+		{
+			name:   "User overrides raise the cap and remove spacing",
+			limits: limits,
+			budget: db.NotificationBudget{
+				Limits: db.NotificationLimits{
+					MaxPerDay:         int32Ptr(10),
+					MinSpacingMinutes: int32Ptr(0),
+				},
+				SentCount:    3,
+				OldestSentAt: now.Add(-20 * time.Hour),
+				LastSentAt:   now.Add(-time.Minute),
+			},
+			expected: now,
+		},
+		{
+			name:   "User override lowers the cap",
+			limits: limits,
+			budget: db.NotificationBudget{
+				Limits:       db.NotificationLimits{MaxPerDay: int32Ptr(1)},
+				SentCount:    1,
+				OldestSentAt: now.Add(-3 * time.Hour),
+				LastSentAt:   now.Add(-3 * time.Hour),
+			},
+			expected: now.Add(21 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.limits.NextAllowedSend(tt.budget, now))
+		})
+	}
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("NOTIFY_MAX_PER_DAY", "")
+	t.Setenv("NOTIFY_MIN_SPACING", "")
+	assert.Equal(t, Limits{MaxPerDay: DefaultMaxPerDay, MinSpacing: DefaultMinSpacing}, LimitsFromEnv())
+
+	t.Setenv("NOTIFY_MAX_PER_DAY", "10")
+	t.Setenv("NOTIFY_MIN_SPACING", "30m")
+	assert.Equal(t, Limits{MaxPerDay: 10, MinSpacing: 30 * time.Minute}, LimitsFromEnv())
+
+	t.Setenv("NOTIFY_MAX_PER_DAY", "lots")
+	t.Setenv("NOTIFY_MIN_SPACING", "-1h")
+	assert.Equal(t, Limits{MaxPerDay: DefaultMaxPerDay, MinSpacing: DefaultMinSpacing}, LimitsFromEnv())
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// DefaultLocale is used for users who haven't chosen a locale.
+const DefaultLocale = "en-US"
+
+// supportedLocales are the locales alerts can be formatted for. The first is the fallback.
+var supportedLocales = []language.Tag{
+	language.AmericanEnglish,
+	language.MustParse("en-CA"),
+	language.BritishEnglish,
+	language.CanadianFrench,
+	language.MustParse("fr-FR"),
+}
+
+var localeMatcher = language.NewMatcher(supportedLocales)
+
+// locale holds how alerts are worded and formatted for one supported locale.
+type locale struct {
+	// language selects translated templates. English templates are the defaults and have no prefix.
+	language string
+	// decimal is the decimal separator.
+	decimal             string
+	today, tomorrow     string
+	on                  string
+	dateLayout          func(time.Time) string
+	inches, inchesShort string
+	centimeters         string
+}
+
+var (
+	englishWeekdayMonth = func(t time.Time) string { return t.Format("Monday, Jan 2") }
+	britishWeekdayMonth = func(t time.Time) string { return t.Format("Monday 2 Jan") }
+	frenchWeekdayMonth  = func(t time.Time) string {
+		return fmt.Sprintf("%s %d %s", frenchWeekdays[t.Weekday()], t.Day(), frenchMonths[t.Month()-1])
+	}
+)
+
+var frenchWeekdays = [...]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"}
+
+var frenchMonths = [...]string{
+	"janvier", "février", "mars", "avril", "mai", "juin",
+	"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+}
+
+var english = locale{
+	decimal:     ".",
+	today:       "today",
+	tomorrow:    "tomorrow",
+	on:          "on ",
+	dateLayout:  englishWeekdayMonth,
+	inches:      "inches",
+	inchesShort: "in",
+	centimeters: "cm",
+}
+
+var french = locale{
+	language:    "fr",
+	decimal:     ",",
+	today:       "aujourd'hui",
+	tomorrow:    "demain",
+	on:          "le ",
+	dateLayout:  frenchWeekdayMonth,
+	inches:      "pouces",
+	inchesShort: "po",
+	centimeters: "cm",
+}
+
+var locales = map[string]locale{
+	"en-US": english,
+	"en-CA": english,
+	"en-GB": withDateLayout(english, britishWeekdayMonth),
+	"fr-CA": french,
+	"fr-FR": french,
+}
+
+// templateLanguages are the languages with translated templates.
+var templateLanguages = []string{french.language}
+
+func withDateLayout(l locale, layout func(time.Time) string) locale {
+	l.dateLayout = layout
+	return l
+}
+
+// ParseLocale returns the supported locale closest to a BCP 47 tag, e.g. "fr" gives "fr-CA". An empty tag
+// is the default locale. Tags in a language alerts aren't written in are an error.
+func ParseLocale(tag string) (string, error) {
+	if strings.TrimSpace(tag) == "" {
+		return DefaultLocale, nil
+	}
+
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid locale %q: %w", tag, err)
+	}
+
+	_, index, confidence := localeMatcher.Match(parsed)
+	if confidence == language.No {
+		return "", fmt.Errorf("unsupported locale %q", tag)
+	}
+	return supportedLocales[index].String(), nil
+}
+
+// localeFor returns the formatting for a locale, falling back to the default for unknown locales.
+func localeFor(tag string) locale {
+	if l, ok := locales[tag]; ok {
+		return l
+	}
+	if supported, err := ParseLocale(tag); err == nil {
+		return locales[supported]
+	}
+	return locales[DefaultLocale]
+}
+
+// dayLabel describes a forecast date relative to now, e.g. "today", "tomorrow" or "on Monday, Jan 2".
+func (l locale) dayLabel(forecastDate, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tomorrow := today.Add(24 * time.Hour)
+	forecastDay := time.Date(forecastDate.Year(), forecastDate.Month(), forecastDate.Day(), 0, 0, 0, 0, forecastDate.Location())
+
+	switch {
+	case forecastDay.Equal(today):
+		return l.today
+	case forecastDay.Equal(tomorrow):
+		return l.tomorrow
+	default:
+		return l.on + l.dateLayout(forecastDate)
+	}
+}
+
+// number formats an amount with the locale's decimal separator.
+func (l locale) number(amount float64, decimals int) string {
+	return strings.Replace(strconv.FormatFloat(amount, 'f', decimals, 64), ".", l.decimal, 1)
+}
+
+// FormatSnow formats a snow amount in inches for a user's units and locale, e.g. "8.5 inches" or "22 cm".
+func FormatSnow(inches float64, system units.System, tag string) string {
+	_, long, _ := localeFor(tag).snow(inches, system)
+	return long
+}
+
+// snow formats a snow amount in inches in a measurement system, with a long and a short unit, e.g.
+// "8.5 inches" and "8.5 in", or "22 cm" for both.
+func (l locale) snow(inches float64, system units.System) (amount float64, long, short string) {
+	amount = system.Snow(inches)
+	if system == units.Metric {
+		formatted := l.number(amount, 0) + " " + l.centimeters
+		return amount, formatted, formatted
+	}
+
+	formatted := l.number(amount, 1)
+	return amount, formatted + " " + l.inches, formatted + " " + l.inchesShort
+}
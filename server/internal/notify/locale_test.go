@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{input: "", expected: "en-US"},
+		{input: "en-US", expected: "en-US"},
+		{input: "en", expected: "en-US"},
+		{input: "en-gb", expected: "en-GB"},
+		{input: "fr-CA", expected: "fr-CA"},
+		{input: "fr-FR", expected: "fr-FR"},
+		{input: "de-DE", expectError: true},
+		{input: "not a locale", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseLocale(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestLocale_DayLabel(t *testing.T) {
+	now := time.Date(2025, 12, 18, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale   string
+		days     int
+		expected string
+	}{
+		{locale: "en-US", days: 0, expected: "today"},
+		{locale: "en-US", days: 1, expected: "tomorrow"},
+		{locale: "en-US", days: 4, expected: "on Monday, Dec 22"},
+		{locale: "en-GB", days: 4, expected: "on Monday 22 Dec"},
+		{locale: "fr-CA", days: 0, expected: "aujourd'hui"},
+		{locale: "fr-CA", days: 1, expected: "demain"},
+		{locale: "fr-FR", days: 15, expected: "le vendredi 2 janvier"},
+		{locale: "xx", days: 1, expected: "tomorrow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+"_"+tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, localeFor(tt.locale).dayLabel(now.AddDate(0, 0, tt.days), now))
+		})
+	}
+}
+
+func TestLocale_Snow(t *testing.T) {
+	tests := []struct {
+		name          string
+		locale        string
+		system        units.System
+		inches        float64
+		expectedLong  string
+		expectedShort string
+	}{
+		{name: "Imperial English", locale: "en-US", system: units.Imperial, inches: 8.5, expectedLong: "8.5 inches", expectedShort: "8.5 in"},
+		{name: "Metric English", locale: "en-CA", system: units.Metric, inches: 8.5, expectedLong: "22 cm", expectedShort: "22 cm"},
+		{name: "Imperial French", locale: "fr-CA", system: units.Imperial, inches: 8.5, expectedLong: "8,5 pouces", expectedShort: "8,5 po"},
+		{name: "Metric French", locale: "fr-FR", system: units.Metric, inches: 12, expectedLong: "30 cm", expectedShort: "30 cm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, long, short := localeFor(tt.locale).snow(tt.inches, tt.system)
+			assert.Equal(t, tt.expectedLong, long)
+			assert.Equal(t, tt.expectedShort, short)
+		})
+	}
+}
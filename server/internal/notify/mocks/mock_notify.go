@@ -40,11 +40,12 @@ func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder
 }
 
 // SendSMS mocks base method.
-func (m *MockNotificationService) SendSMS(to, message string) error {
+func (m *MockNotificationService) SendSMS(to, message string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendSMS", to, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendSMS indicates an expected call of SendSMS.
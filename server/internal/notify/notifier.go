@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/google/uuid"
+)
+
+// Notification is one message to send to a recipient on a channel. It may cover several alerts merged
+// into a summary.
+type Notification struct {
+	// ID identifies the notification across retries: the UUID of the first outbox message it covers.
+	ID        uuid.UUID
+	Channel   string
+	Recipient string
+	// Message is the rendered message, or empty for channels without templates.
+	Message Message
+	Alerts  []db.AlertToSend
+}
+
+// Receipt describes a notification a provider accepted.
+type Receipt struct {
+	MessageID string
+	// Status is the delivery status when the provider confirms it synchronously. Empty means queued.
+	Status string
+}
+
+// Notifier sends notifications on one channel.
+type Notifier interface {
+	// Provider identifies the notifier in delivery records
+	Provider() string
+
+	// Send delivers a notification to its recipient
+	Send(ctx context.Context, notification Notification) (Receipt, error)
+}
+
+// smsNotifier sends notifications as text messages through a NotificationService.
+type smsNotifier struct {
+	sms NotificationService
+}
+
+func (n smsNotifier) Provider() string {
+	return ProviderTwilio
+}
+
+func (n smsNotifier) Send(_ context.Context, notification Notification) (Receipt, error) {
+	sid, err := n.sms.SendSMS(notification.Recipient, notification.Message.Text)
+	return Receipt{MessageID: sid}, err
+}
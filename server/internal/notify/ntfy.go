@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+// ProviderNtfy identifies ntfy servers in delivery records.
+const ProviderNtfy = "ntfy"
+
+// ntfy message priorities, from 1 (min) to 5 (max).
+const (
+	ntfyPriorityDefault = 3
+	ntfyPriorityHigh    = 4
+	ntfyPriorityMax     = 5
+)
+
+// ntfyDeepSnowInches is the forecast at which a push is sent at max priority, which breaks through
+// do-not-disturb on most phones.
+const ntfyDeepSnowInches = 12
+
+// ntfyTopicPattern matches the topic names ntfy accepts.
+var ntfyTopicPattern = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+// NtfyNotifier publishes push alerts to an ntfy topic, on ntfy.sh or a self-hosted server. The notification's
+// recipient is the topic URL, e.g. https://ntfy.sh/my-powder-alerts, and its title and text come from the
+// push templates.
+type NtfyNotifier struct {
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+// ntfyMessage is the JSON body ntfy accepts when publishing to the server's root URL.
+type ntfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title,omitempty"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority"`
+	Tags     []string     `json:"tags,omitempty"`
+	Click    string       `json:"click,omitempty"`
+	Actions  []ntfyAction `json:"actions,omitempty"`
+}
+
+type ntfyAction struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+	Clear  bool   `json:"clear,omitempty"`
+}
+
+// NewNtfyNotifier returns a notifier publishing to ntfy topics. allowPrivateNetworks is as for
+// NewWebhookNotifier, and is needed to reach a server on the local network.
+func NewNtfyNotifier(allowPrivateNetworks bool) *NtfyNotifier {
+	return &NtfyNotifier{
+		client:     newWebhookClient(allowPrivateNetworks),
+		retryDelay: defaultWebhookRetryDelay,
+	}
+}
+
+// NtfyNotifierFromEnv returns an ntfy notifier honouring WEBHOOK_ALLOW_PRIVATE_NETWORKS.
+func NtfyNotifierFromEnv() *NtfyNotifier {
+	return NewNtfyNotifier(privateNetworksAllowed())
+}
+
+func (n *NtfyNotifier) Provider() string {
+	return ProviderNtfy
+}
+
+// Send publishes the notification to the topic named by its recipient.
+func (n *NtfyNotifier) Send(ctx context.Context, notification Notification) (Receipt, error) {
+	serverURL, topic, err := splitNtfyTopicURL(notification.Recipient)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	body, err := json.Marshal(ntfyMessageFor(topic, notification))
+	if err != nil {
+		return Receipt{}, fmt.Errorf("error encoding ntfy message: %w", err)
+	}
+
+	err = postWithRetries(ctx, n.client, n.retryDelay, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error creating ntfy request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	// The server has accepted the message; whether a phone is subscribed to the topic isn't known.
+	return Receipt{Status: db.DeliveryStatusSent}, nil
+}
+
+// ntfyMessageFor builds the message for a notification. Clicking it opens the snow report of the resort with
+// the largest forecast.
+func ntfyMessageFor(topic string, notification Notification) ntfyMessage {
+	alerts := notification.Alerts
+	message := ntfyMessage{
+		Topic:    topic,
+		Title:    notification.Message.Subject,
+		Message:  notification.Message.Text,
+		Priority: ntfyPriorityHigh,
+		Tags:     []string{"snowflake"},
+	}
+
+	if len(alerts) > 0 {
+		message.Click = latestAlert(alerts).ResortURL
+	}
+
+	for _, alert := range alerts {
+		if alert.SnowAmount >= ntfyDeepSnowInches {
+			message.Priority = ntfyPriorityMax
+			message.Tags = append(message.Tags, "rotating_light")
+			break
+		}
+	}
+	if AlertTypeFor(alerts) == AlertUpdate && message.Priority != ntfyPriorityMax {
+		message.Priority = ntfyPriorityDefault
+		message.Tags = append(message.Tags, "arrows_counterclockwise")
+	}
+
+	if notification.Message.UnsubscribeURL != "" {
+		message.Actions = []ntfyAction{{
+			Action: "view",
+			Label:  "Unsubscribe",
+			URL:    notification.Message.UnsubscribeURL,
+			Clear:  true,
+		}}
+	}
+
+	return message
+}
+
+// ValidateNtfyTopicURL checks that rawURL is a topic URL on an ntfy server: an http or https URL whose last
+// path segment is a valid topic name. With requireHTTPS, plain http is rejected.
+func ValidateNtfyTopicURL(rawURL string, requireHTTPS bool) error {
+	if err := ValidateWebhookURL(rawURL, requireHTTPS); err != nil {
+		return errors.New(strings.Replace(err.Error(), "webhook URL", "topic URL", 1))
+	}
+	_, _, err := splitNtfyTopicURL(rawURL)
+	return err
+}
+
+// splitNtfyTopicURL splits a topic URL into the URL of its server, which messages are published to, and the
+// topic name. Servers may be hosted under a path, e.g. https://example.com/ntfy/alerts.
+func splitNtfyTopicURL(rawURL string) (string, string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid topic URL: %w", err)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", "", errors.New("topic URL must not include a query or fragment")
+	}
+
+	slash := strings.LastIndex(parsed.Path, "/")
+	topic := parsed.Path[slash+1:]
+	if slash < 0 || !ntfyTopicPattern.MatchString(topic) {
+		return "", "", errors.New("topic URL must end in a topic name of up to 64 letters, digits, - or _")
+	}
+
+	parsed.Path = parsed.Path[:slash+1]
+	parsed.RawPath = ""
+	return parsed.String(), topic, nil
+}
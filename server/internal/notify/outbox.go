@@ -0,0 +1,329 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/logging"
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultOutboxBatchSize is how many messages a worker claims at a time.
+	DefaultOutboxBatchSize = 50
+
+	// DefaultOutboxLease is how long a claimed message is reserved for a worker before another
+	// worker may claim it, e.g. after a crash mid-send.
+	DefaultOutboxLease = 5 * time.Minute
+
+	// DefaultRetryBaseDelay is the delay before the first retry; each later retry doubles it.
+	DefaultRetryBaseDelay = time.Minute
+
+	// DefaultRetryMaxDelay caps the delay between retries.
+	DefaultRetryMaxDelay = 6 * time.Hour
+)
+
+// OutboxWorker sends notifications queued in the outbox. Failed sends are retried with exponential
+// backoff until the message runs out of attempts and is dead-lettered. Messages for a user who has hit
+// their notification limits are held back and later merged into one summary.
+type OutboxWorker struct {
+	store          db.StoreService
+	notifiers      map[string]Notifier
+	limits         Limits
+	templates      *Templates
+	unsubscribe    *unsubscribe.Signer
+	baseURL        string
+	batchSize      int32
+	lease          time.Duration
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	now            func() time.Time
+}
+
+// NewOutboxWorker creates a worker that sends SMS messages through sms, within the given per-user limits
+// and rendered with templates. Other channels are added with WithNotifier.
+func NewOutboxWorker(store db.StoreService, sms NotificationService, limits Limits, templates *Templates) *OutboxWorker {
+	return &OutboxWorker{
+		store:          store,
+		notifiers:      map[string]Notifier{db.ChannelSMS: smsNotifier{sms: sms}},
+		limits:         limits,
+		templates:      templates,
+		batchSize:      DefaultOutboxBatchSize,
+		lease:          DefaultOutboxLease,
+		retryBaseDelay: DefaultRetryBaseDelay,
+		retryMaxDelay:  DefaultRetryMaxDelay,
+		now:            time.Now,
+	}
+}
+
+// WithUnsubscribeLinks adds signed unsubscribe links for the API at baseURL to every message. Without it
+// messages are sent without links.
+func (w *OutboxWorker) WithUnsubscribeLinks(signer *unsubscribe.Signer, baseURL string) *OutboxWorker {
+	w.unsubscribe = signer
+	w.baseURL = baseURL
+	return w
+}
+
+// WithNotifier sends messages queued for channel through notifier.
+func (w *OutboxWorker) WithNotifier(channel string, notifier Notifier) *OutboxWorker {
+	w.notifiers[channel] = notifier
+	return w
+}
+
+// RetryDelay returns how long to wait before retrying a message that has failed attempt times.
+func RetryDelay(attempt int32, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := int32(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return min(delay, maxDelay)
+}
+
+// Run drains the outbox every pollInterval until ctx is cancelled.
+func (w *OutboxWorker) Run(ctx context.Context, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Drain(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error draining outbox", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain sends batches of due messages until none are left. Messages that fail are rescheduled in the
+// future, so they are not retried within the same drain.
+func (w *OutboxWorker) Drain(ctx context.Context) error {
+	for {
+		sent, err := w.ProcessBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if sent == 0 {
+			return nil
+		}
+	}
+}
+
+// ProcessBatch claims one batch of due messages and attempts to send them, one message per user and
+// channel. It returns the number of messages claimed.
+func (w *OutboxWorker) ProcessBatch(ctx context.Context) (count int, err error) {
+	ctx, span := tracing.Start(ctx, "OutboxWorker.ProcessBatch")
+	defer func() {
+		span.SetAttributes(attribute.Int("outbox.messages", count))
+		tracing.End(span, err)
+	}()
+
+	messages, err := w.store.ClaimOutboxMessages(ctx, w.batchSize, w.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, group := range groupByRecipient(messages) {
+		w.send(ctx, group)
+	}
+
+	return len(messages), nil
+}
+
+// groupByRecipient groups powder alerts for the same user, channel and recipient, in the order they were
+// claimed. Downgrades and bluebird alerts are each sent on their own, since a digest lists fresh snow.
+func groupByRecipient(messages []db.OutboxMessage) [][]db.OutboxMessage {
+	type recipientKey struct {
+		user      uuid.UUID
+		channel   string
+		recipient string
+	}
+
+	var groups [][]db.OutboxMessage
+	index := make(map[recipientKey]int)
+	for _, message := range messages {
+		if kind := message.Alert.Kind; kind != "" && kind != db.AlertKindPowder {
+			groups = append(groups, []db.OutboxMessage{message})
+			continue
+		}
+
+		key := recipientKey{user: message.Alert.UserUuid, channel: message.Channel, recipient: message.Recipient}
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], message)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, []db.OutboxMessage{message})
+	}
+	return groups
+}
+
+// send delivers a group of messages for one recipient as a single message, unless the user's notification
+// limits require holding them back.
+func (w *OutboxWorker) send(ctx context.Context, messages []db.OutboxMessage) {
+	first := messages[0]
+	now := w.now()
+	ctx = logging.WithAttrs(ctx,
+		slog.String("channel", first.Channel),
+		slog.String("user_uuid", first.Alert.UserUuid.String()),
+	)
+
+	messageUUIDs := make([]uuid.UUID, 0, len(messages))
+	alerts := make([]db.AlertToSend, 0, len(messages))
+	for _, message := range messages {
+		messageUUIDs = append(messageUUIDs, message.UUID)
+		alerts = append(alerts, message.Alert)
+	}
+
+	if !limitExempt(first.Channel) {
+		budget, err := w.store.GetNotificationBudget(ctx, first.Alert.UserUuid, first.Channel, now.Add(-capWindow))
+		if err != nil {
+			slog.ErrorContext(ctx, "Error checking notification limits", "error", err)
+			w.deferMessages(ctx, messageUUIDs, now.Add(w.retryBaseDelay))
+			return
+		}
+
+		if next := w.limits.NextAllowedSend(budget, now); next.After(now) {
+			slog.InfoContext(ctx, "Holding alerts: notification limit reached",
+				"alerts", len(messages), "until", next.Format(time.RFC3339))
+			w.deferMessages(ctx, messageUUIDs, next)
+			return
+		}
+	}
+
+	delivery := db.Delivery{
+		UserUUID:  first.Alert.UserUuid,
+		Channel:   first.Channel,
+		Recipient: first.Recipient,
+		Status:    db.DeliveryStatusQueued,
+	}
+	if len(messages) == 1 {
+		delivery.ResortUUID = first.Alert.ResortUUID
+	}
+
+	notification := Notification{
+		ID:        first.UUID,
+		Channel:   first.Channel,
+		Recipient: first.Recipient,
+		Alerts:    alerts,
+	}
+
+	var sendErr error
+	notifier, ok := w.notifiers[first.Channel]
+	if !ok {
+		sendErr = fmt.Errorf("unsupported notification channel %q", first.Channel)
+	} else if HasTemplates(first.Channel) {
+		notification.Message, sendErr = w.templates.RenderAlerts(
+			first.Channel, alerts, now, w.unsubscribeURL(first.Channel, alerts))
+	}
+
+	if sendErr == nil {
+		var receipt Receipt
+		delivery.Provider = notifier.Provider()
+		// The recipient is left out of the span, like the logs, since it's personal data.
+		sendCtx, span := tracing.Start(ctx, "Notifier.Send", trace.WithAttributes(
+			attribute.String("notification.channel", first.Channel),
+			attribute.String("notification.provider", delivery.Provider),
+			attribute.Int("notification.alerts", len(alerts)),
+		))
+		start := time.Now()
+		receipt, sendErr = notifier.Send(sendCtx, notification)
+		metrics.ObserveProviderCall(delivery.Provider, start, sendErr)
+		tracing.End(span, sendErr)
+		delivery.ProviderMessageID = receipt.MessageID
+		if receipt.Status != "" {
+			delivery.Status = receipt.Status
+		}
+	}
+
+	if sendErr != nil {
+		delivery.Status = db.DeliveryStatusFailed
+		delivery.ErrorMessage = sendErr.Error()
+		metrics.Notifications.WithLabelValues(first.Channel, metrics.ResultFailed).Inc()
+	} else {
+		metrics.Notifications.WithLabelValues(first.Channel, metrics.ResultSent).Inc()
+	}
+
+	if delivery.Provider != "" {
+		if err := w.store.RecordDelivery(ctx, delivery); err != nil {
+			slog.ErrorContext(ctx, "Error recording delivery", "error", err)
+		}
+	}
+
+	if sendErr != nil {
+		for _, message := range messages {
+			w.fail(ctx, message, sendErr)
+		}
+		return
+	}
+
+	if err := w.store.MarkOutboxMessagesSent(ctx, messageUUIDs); err != nil {
+		slog.ErrorContext(ctx, "Error marking outbox messages sent", "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "Sent alert", "recipient", first.Recipient, "forecasts", len(messages))
+}
+
+// fail schedules a retry of a message that could not be sent, or dead-letters it.
+func (w *OutboxWorker) fail(ctx context.Context, message db.OutboxMessage, sendErr error) {
+	retryAt := w.now().Add(RetryDelay(message.Attempts, w.retryBaseDelay, w.retryMaxDelay))
+	dead, err := w.store.FailOutboxMessage(ctx, message.UUID, sendErr.Error(), retryAt)
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "Error recording failed send of outbox message",
+			"message_uuid", message.UUID, "error", err)
+	case dead:
+		slog.ErrorContext(ctx, "Outbox message dead-lettered",
+			"message_uuid", message.UUID, "attempts", message.Attempts, "error", sendErr)
+	default:
+		slog.WarnContext(ctx, "Error sending outbox message, retrying",
+			"message_uuid", message.UUID,
+			"attempt", message.Attempts,
+			"max_attempts", message.MaxAttempts,
+			"retry_at", retryAt.Format(time.RFC3339),
+			"error", sendErr,
+		)
+	}
+}
+
+func (w *OutboxWorker) deferMessages(ctx context.Context, messageUUIDs []uuid.UUID, until time.Time) {
+	if err := w.store.DeferOutboxMessages(ctx, messageUUIDs, until); err != nil {
+		slog.ErrorContext(ctx, "Error deferring outbox messages", "error", err)
+	}
+}
+
+// unsubscribeURL returns the unsubscribe link for alerts sent together: one removing the resort's alert when
+// they are all for one resort, or all of the user's alerts for a summary across resorts.
+func (w *OutboxWorker) unsubscribeURL(channel string, alerts []db.AlertToSend) string {
+	if w.unsubscribe == nil {
+		return ""
+	}
+
+	claims := unsubscribe.Claims{
+		UserUUID:   alerts[0].UserUuid,
+		ResortUUID: alerts[0].ResortUUID,
+		Channel:    channel,
+	}
+	for _, alert := range alerts[1:] {
+		if alert.ResortUUID != claims.ResortUUID {
+			claims.ResortUUID = uuid.Nil
+			break
+		}
+	}
+
+	return w.unsubscribe.URL(w.baseURL, claims)
+}
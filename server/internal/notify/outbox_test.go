@@ -0,0 +1,380 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/testutil"
+	"github.com/MattSilvaa/powhunter/internal/unsubscribe"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSMSSender struct {
+	sent []string
+	sid  string
+	err  error
+}
+
+func (f *fakeSMSSender) SendSMS(to, message string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.sent = append(f.sent, message)
+	return f.sid, nil
+}
+
+var testLimits = Limits{MaxPerDay: 3, MinSpacing: time.Hour}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt  int32
+		expected time.Duration
+	}{
+		{attempt: 1, expected: time.Minute},
+		{attempt: 2, expected: 2 * time.Minute},
+		{attempt: 3, expected: 4 * time.Minute},
+		{attempt: 5, expected: 16 * time.Minute},
+		{attempt: 10, expected: time.Hour},
+		{attempt: 100, expected: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if result := RetryDelay(tt.attempt, time.Minute, time.Hour); result != tt.expected {
+			t.Errorf("RetryDelay(%d) = %s, want %s", tt.attempt, result, tt.expected)
+		}
+	}
+}
+
+func testOutboxMessage(attempts int32) db.OutboxMessage {
+	return db.OutboxMessage{
+		UUID:        uuid.New(),
+		Channel:     db.ChannelSMS,
+		Recipient:   "+12065550100",
+		Attempts:    attempts,
+		MaxAttempts: 5,
+		Alert: db.AlertToSend{
+			UserUuid:     uuid.New(),
+			UserPhone:    "+12065550100",
+			ResortName:   "Crystal Mountain",
+			ResortUUID:   uuid.New(),
+			SnowAmount:   8,
+			ForecastDate: time.Now(),
+		},
+	}
+}
+
+func TestOutboxWorker_ProcessBatch(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		message   db.OutboxMessage
+		sender    *fakeSMSSender
+		setupMock func(*mocks.MockStoreService, db.OutboxMessage)
+	}{
+		{
+			name:    "Sent message is marked sent",
+			message: testOutboxMessage(1),
+			sender:  &fakeSMSSender{sid: "SM123"},
+			setupMock: func(m *mocks.MockStoreService, message db.OutboxMessage) {
+				m.EXPECT().RecordDelivery(gomock.Any(), db.Delivery{
+					UserUUID:          message.Alert.UserUuid,
+					ResortUUID:        message.Alert.ResortUUID,
+					Channel:           db.ChannelSMS,
+					Provider:          ProviderTwilio,
+					ProviderMessageID: "SM123",
+					Recipient:         "+12065550100",
+					Status:            db.DeliveryStatusQueued,
+				}).Return(nil)
+				m.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{message.UUID}).Return(nil)
+			},
+		},
+		{
+			name:    "Failed send is retried with backoff and not marked sent",
+			message: testOutboxMessage(3),
+			sender:  &fakeSMSSender{err: errors.New("twilio unavailable")},
+			setupMock: func(m *mocks.MockStoreService, message db.OutboxMessage) {
+				m.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, delivery db.Delivery) error {
+						assert.Equal(t, db.DeliveryStatusFailed, delivery.Status)
+						assert.Equal(t, "twilio unavailable", delivery.ErrorMessage)
+						return nil
+					})
+				m.EXPECT().
+					FailOutboxMessage(gomock.Any(), message.UUID, "twilio unavailable", now.Add(4*time.Minute)).
+					Return(false, nil)
+			},
+		},
+		{
+			name:    "Exhausted message is dead-lettered",
+			message: testOutboxMessage(5),
+			sender:  &fakeSMSSender{err: errors.New("twilio unavailable")},
+			setupMock: func(m *mocks.MockStoreService, message db.OutboxMessage) {
+				m.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().
+					FailOutboxMessage(gomock.Any(), message.UUID, "twilio unavailable", now.Add(16*time.Minute)).
+					Return(true, nil)
+			},
+		},
+		{
+			name: "Unsupported channel fails without a delivery record",
+			message: func() db.OutboxMessage {
+				message := testOutboxMessage(1)
+				message.Channel = "pigeon"
+				return message
+			}(),
+			sender: &fakeSMSSender{},
+			setupMock: func(m *mocks.MockStoreService, message db.OutboxMessage) {
+				m.EXPECT().
+					FailOutboxMessage(gomock.Any(), message.UUID, `unsupported notification channel "pigeon"`, now.Add(time.Minute)).
+					Return(false, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+
+			mockStore.EXPECT().
+				ClaimOutboxMessages(gomock.Any(), int32(DefaultOutboxBatchSize), DefaultOutboxLease).
+				Return([]db.OutboxMessage{tt.message}, nil)
+			mockStore.EXPECT().
+				GetNotificationBudget(gomock.Any(), tt.message.Alert.UserUuid, tt.message.Channel, now.Add(-24*time.Hour)).
+				Return(db.NotificationBudget{}, nil)
+			tt.setupMock(mockStore, tt.message)
+
+			worker := NewOutboxWorker(mockStore, tt.sender, testLimits, DefaultTemplates())
+			worker.now = func() time.Time { return now }
+
+			claimed, err := worker.ProcessBatch(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+		})
+	}
+}
+
+func TestOutboxWorker_Drain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	sender := &fakeSMSSender{sid: "SM123"}
+
+	first, second := testOutboxMessage(1), testOutboxMessage(1)
+	gomock.InOrder(
+		mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]db.OutboxMessage{first}, nil),
+		mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]db.OutboxMessage{second}, nil),
+		mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+	)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(db.NotificationBudget{}, nil).Times(2)
+	mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{first.UUID}).Return(nil)
+	mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{second.UUID}).Return(nil)
+
+	worker := NewOutboxWorker(mockStore, sender, testLimits, DefaultTemplates())
+	require.NoError(t, worker.Drain(context.Background()))
+	assert.Len(t, sender.sent, 2)
+}
+
+func TestOutboxWorker_ClaimError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("database error"))
+
+	worker := NewOutboxWorker(mockStore, &fakeSMSSender{}, testLimits, DefaultTemplates())
+	err := worker.Drain(context.Background())
+	assert.EqualError(t, err, "database error")
+}
+
+func TestOutboxWorker_TracesSends(t *testing.T) {
+	recorder := testutil.RecordSpans(t)
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	message := testOutboxMessage(1)
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]db.OutboxMessage{message}, nil)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(db.NotificationBudget{}, nil)
+	mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).Return(nil)
+	mockStore.EXPECT().FailOutboxMessage(gomock.Any(), message.UUID, "twilio unavailable", gomock.Any()).
+		Return(false, nil)
+
+	worker := NewOutboxWorker(mockStore, &fakeSMSSender{err: errors.New("twilio unavailable")}, testLimits,
+		DefaultTemplates())
+	_, err := worker.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	send, batch := spans[0], spans[1]
+	assert.Equal(t, "Notifier.Send", send.Name())
+	assert.Equal(t, "OutboxWorker.ProcessBatch", batch.Name())
+	assert.Equal(t, batch.SpanContext().SpanID(), send.Parent().SpanID())
+	assert.Equal(t, codes.Error, send.Status().Code)
+	assert.Contains(t, send.Attributes(), attribute.String("notification.provider", ProviderTwilio))
+	assert.Contains(t, batch.Attributes(), attribute.Int("outbox.messages", 1))
+	for _, kv := range send.Attributes() {
+		assert.NotEqual(t, message.Recipient, kv.Value.Emit(), "recipients are left out of spans")
+	}
+}
+
+func TestOutboxWorker_MergesAlertsForOneUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	sender := &fakeSMSSender{sid: "SM123"}
+
+	first, second, other := testOutboxMessage(1), testOutboxMessage(1), testOutboxMessage(1)
+	second.Alert.UserUuid = first.Alert.UserUuid
+	second.Alert.ResortName = "Stevens Pass"
+
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]db.OutboxMessage{first, other, second}, nil)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(db.NotificationBudget{}, nil).Times(2)
+	mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, delivery db.Delivery) error {
+			if delivery.UserUUID == first.Alert.UserUuid {
+				assert.Equal(t, uuid.Nil, delivery.ResortUUID, "summary deliveries are not tied to one resort")
+			}
+			return nil
+		}).Times(2)
+	mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{first.UUID, second.UUID}).Return(nil)
+	mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{other.UUID}).Return(nil)
+
+	worker := NewOutboxWorker(mockStore, sender, testLimits, DefaultTemplates())
+	_, err := worker.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, sender.sent, 2)
+	assert.Contains(t, sender.sent[0], "Powder Alert Summary!")
+	assert.Contains(t, sender.sent[0], "Crystal Mountain")
+	assert.Contains(t, sender.sent[0], "Stevens Pass")
+}
+
+func TestOutboxWorker_SendsDowngradesAndBluebirdAlertsAlone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	sender := &fakeSMSSender{sid: "SM123"}
+
+	powder, downgrade, bluebird := testOutboxMessage(1), testOutboxMessage(1), testOutboxMessage(1)
+	downgrade.Alert.UserUuid = powder.Alert.UserUuid
+	downgrade.Alert.ResortName = "Stevens Pass"
+	downgrade.Alert.Kind = db.AlertKindDowngrade
+	bluebird.Alert.UserUuid = powder.Alert.UserUuid
+	bluebird.Alert.ResortName = "Mt. Baker"
+	bluebird.Alert.Kind = db.AlertKindBluebird
+
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]db.OutboxMessage{powder, downgrade, bluebird}, nil)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(db.NotificationBudget{}, nil).Times(3)
+	mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	for _, message := range []db.OutboxMessage{powder, downgrade, bluebird} {
+		mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{message.UUID}).Return(nil)
+	}
+
+	worker := NewOutboxWorker(mockStore, sender, testLimits, DefaultTemplates())
+	_, err := worker.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, sender.sent, 3, "downgrades and bluebird alerts aren't merged into a summary")
+	assert.Contains(t, sender.sent[0], "Powder Alert! Crystal Mountain")
+	assert.Contains(t, sender.sent[1], "Forecast Update: Stevens Pass")
+	assert.Contains(t, sender.sent[2], "Bluebird Alert! Clear skies at Mt. Baker")
+}
+
+func TestOutboxWorker_HoldsAlertsOverTheLimit(t *testing.T) {
+	now := time.Date(2025, 12, 18, 12, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	sender := &fakeSMSSender{sid: "SM123"}
+
+	message := testOutboxMessage(1)
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]db.OutboxMessage{message}, nil)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), message.Alert.UserUuid, db.ChannelSMS, now.Add(-24*time.Hour)).
+		Return(db.NotificationBudget{
+			SentCount:    3,
+			OldestSentAt: now.Add(-22 * time.Hour),
+			LastSentAt:   now.Add(-3 * time.Hour),
+		}, nil)
+	mockStore.EXPECT().DeferOutboxMessages(gomock.Any(), []uuid.UUID{message.UUID}, now.Add(2*time.Hour)).Return(nil)
+
+	worker := NewOutboxWorker(mockStore, sender, testLimits, DefaultTemplates())
+	worker.now = func() time.Time { return now }
+
+	_, err := worker.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, sender.sent)
+}
+
+func TestOutboxWorker_IncludesUnsubscribeLink(t *testing.T) {
+	signer, err := unsubscribe.NewSigner([]byte("0123456789abcdef0123456789abcdef"), time.Hour)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		sameResort bool
+		expectAll  bool
+	}{
+		{name: "Single resort links to the resort's alert", sameResort: true},
+		{name: "Summary across resorts links to all alerts", expectAll: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			sender := &fakeSMSSender{sid: "SM123"}
+
+			first, second := testOutboxMessage(1), testOutboxMessage(1)
+			second.Alert.UserUuid = first.Alert.UserUuid
+			second.Alert.ForecastDate = first.Alert.ForecastDate.Add(24 * time.Hour)
+			if tt.sameResort {
+				second.Alert.ResortUUID = first.Alert.ResortUUID
+			}
+
+			mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]db.OutboxMessage{first, second}, nil)
+			mockStore.EXPECT().GetNotificationBudget(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(db.NotificationBudget{}, nil)
+			mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).Return(nil)
+			mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), gomock.Any()).Return(nil)
+
+			worker := NewOutboxWorker(mockStore, sender, testLimits, DefaultTemplates()).
+				WithUnsubscribeLinks(signer, "https://powhunter.app")
+			_, err := worker.ProcessBatch(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, sender.sent, 1)
+			_, token, found := strings.Cut(sender.sent[0], "Unsubscribe: https://powhunter.app"+unsubscribe.PathPrefix)
+			require.True(t, found, "message has an unsubscribe link: %s", sender.sent[0])
+
+			claims, err := signer.Verify(token)
+			require.NoError(t, err)
+			assert.Equal(t, first.Alert.UserUuid, claims.UserUUID)
+			assert.Equal(t, db.ChannelSMS, claims.Channel)
+			assert.Equal(t, tt.expectAll, claims.All())
+			if !tt.expectAll {
+				assert.Equal(t, first.Alert.ResortUUID, claims.ResortUUID)
+			}
+		})
+	}
+}
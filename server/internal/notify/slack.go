@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// slackMessage is a Slack incoming webhook payload. Text is the fallback shown in notifications, and like
+// mrkdwn blocks it must have &, < and > escaped.
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessageFor formats alerts as a header followed by one section per forecast.
+func slackMessageFor(alerts []chatAlert, more int) any {
+	title := "❄️ Powder alert: " + alerts[0].ResortName
+	if len(alerts) > 1 {
+		title = fmt.Sprintf("❄️ Powder alerts: %d forecasts", len(alerts)+more)
+	}
+
+	message := slackMessage{
+		Text: slackEscaper.Replace(
+			fmt.Sprintf("Powder alert: %s at %s %s", alerts[0].Snow, alerts[0].ResortName, alerts[0].When)),
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: title}}},
+	}
+	if len(alerts) > 1 {
+		message.Text = title
+	}
+
+	for _, alert := range alerts {
+		resort := "*" + slackEscaper.Replace(alert.ResortName) + "*"
+		if alert.ResortURL != "" {
+			resort = fmt.Sprintf("*<%s|%s>*", slackEscaper.Replace(alert.ResortURL), slackEscaper.Replace(alert.ResortName))
+		}
+		if alert.IsUpdate {
+			resort += "  `UPDATED`"
+		}
+
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: resort},
+			Fields: []slackText{
+				{Type: "mrkdwn", Text: "*Snow*\n" + slackEscaper.Replace(alert.Snow)},
+				{Type: "mrkdwn", Text: "*When*\n" + slackEscaper.Replace(alert.When)},
+			},
+		})
+	}
+
+	footer := "Sent by Pow Hunter"
+	if more > 0 {
+		footer = fmt.Sprintf("+%d more forecasts · %s", more, footer)
+	}
+	message.Blocks = append(message.Blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: footer}},
+	})
+
+	return message
+}
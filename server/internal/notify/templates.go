@@ -0,0 +1,439 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/units"
+)
+
+// AlertType selects which message is sent for an alert.
+type AlertType string
+
+const (
+	// AlertNew is the first alert for a resort and forecast date.
+	AlertNew AlertType = "new"
+	// AlertUpdate follows an earlier alert when the forecast has grown.
+	AlertUpdate AlertType = "update"
+	// AlertDowngrade follows an earlier alert when the forecast has shrunk.
+	AlertDowngrade AlertType = "downgrade"
+	// AlertDigest merges several alerts for one user into a single message.
+	AlertDigest AlertType = "digest"
+	// AlertBluebird announces clear skies after a snowfall.
+	AlertBluebird AlertType = "bluebird"
+)
+
+var alertTypes = []AlertType{AlertNew, AlertUpdate, AlertDowngrade, AlertDigest, AlertBluebird}
+
+// Template parts. html parts are rendered with html/template, the others with text/template.
+const (
+	partSubject = "subject"
+	partTitle   = "title"
+	partText    = "txt"
+	partHTML    = "html"
+)
+
+// channelParts lists the templates each channel needs for every alert type.
+var channelParts = map[string][]string{
+	db.ChannelSMS:   {partText},
+	db.ChannelPush:  {partTitle, partText},
+	db.ChannelEmail: {partSubject, partText, partHTML},
+}
+
+// sharedTemplates names channels rendered with another channel's templates. Web Push and ntfy are both push
+// notifications, so they share the push templates.
+var sharedTemplates = map[string]string{
+	db.ChannelWebPush: db.ChannelPush,
+}
+
+// templateChannel returns the channel whose templates messages on channel are rendered from.
+func templateChannel(channel string) string {
+	if shared, ok := sharedTemplates[channel]; ok {
+		return shared
+	}
+	return channel
+}
+
+// maxDigestAlerts is how many forecasts a digest lists before the rest are counted.
+const maxDigestAlerts = 5
+
+//go:embed templates
+var defaultTemplateFS embed.FS
+
+// Message is a rendered notification. Fields a channel doesn't use are empty.
+type Message struct {
+	// Subject is the email subject or push notification title.
+	Subject string
+	Text    string
+	HTML    string
+	// UnsubscribeURL is the one-click unsubscribe link included in the message, if any.
+	UnsubscribeURL string
+}
+
+// EmailHeaders returns the RFC 2369 and RFC 8058 headers that let mail clients offer a one-click
+// unsubscribe button for the message. It is empty when the message has no unsubscribe link.
+func (m Message) EmailHeaders() map[string]string {
+	if m.UnsubscribeURL == "" {
+		return map[string]string{}
+	}
+	return map[string]string{
+		"List-Unsubscribe":      "<" + m.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+// TemplateAlert is a single forecast as seen by templates.
+type TemplateAlert struct {
+	ResortName string
+	// SnowAmount is in the user's units: inches, or centimeters for metric users.
+	SnowAmount float64
+	// Snow is the formatted snow amount with its unit, e.g. "8.5 inches" or "22 cm".
+	Snow string
+	// SnowShort is Snow with an abbreviated unit, e.g. "8.5 in" or "22 cm".
+	SnowShort    string
+	ForecastDate time.Time
+	// Day describes the forecast date relative to now, e.g. "today", "tomorrow" or "on Monday, Jan 2".
+	Day string
+}
+
+// TemplateData is passed to every template. The first alert is embedded, so single-alert templates can use
+// {{.ResortName}}; digest templates range over Alerts.
+type TemplateData struct {
+	TemplateAlert
+	Alerts []TemplateAlert
+	// Count is the number of distinct forecasts, including those not listed in Alerts.
+	Count int
+	// More is the number of forecasts left out of Alerts.
+	More int
+	// Units and Locale are the user's preferences the data was formatted for.
+	Units  units.System
+	Locale string
+	// UnsubscribeURL is a signed link that removes the alert, or all alerts for a digest. Empty when
+	// unsubscribe links aren't configured.
+	UnsubscribeURL string
+}
+
+// Templates renders notifications from named templates, one per channel, alert type and part.
+// Templates are named after their path, e.g. "sms/new.txt.tmpl" or "email/digest.html.tmpl". English
+// templates are required; translations live under a language directory, e.g. "fr/sms/new.txt.tmpl", and
+// fall back to English when missing.
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+var templateFuncs = map[string]any{
+	"snow": func(amount float64) string {
+		return fmt.Sprintf("%.1f", amount)
+	},
+}
+
+// DefaultTemplates returns the templates built into the binary.
+func DefaultTemplates() *Templates {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in notification templates: %v", err))
+	}
+	return templates
+}
+
+// TemplatesFromEnv loads templates with overrides from NOTIFY_TEMPLATE_DIR, if it is set.
+func TemplatesFromEnv() (*Templates, error) {
+	return LoadTemplates(os.Getenv("NOTIFY_TEMPLATE_DIR"))
+}
+
+// LoadTemplates loads the built-in templates and replaces any that have a file with the same name in
+// overrideDir, so copy can be changed without a release. overrideDir may be empty.
+func LoadTemplates(overrideDir string) (*Templates, error) {
+	templates := &Templates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	defaults, err := fs.Sub(defaultTemplateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := templates.parseFS(defaults); err != nil {
+		return nil, err
+	}
+
+	if overrideDir != "" {
+		if err := templates.parseFS(os.DirFS(overrideDir)); err != nil {
+			return nil, fmt.Errorf("error loading templates from %s: %w", overrideDir, err)
+		}
+	}
+
+	for _, name := range requiredTemplateNames() {
+		_, isText := templates.text[name]
+		_, isHTML := templates.html[name]
+		if !isText && !isHTML {
+			return nil, fmt.Errorf("missing template %s", name)
+		}
+	}
+
+	return templates, nil
+}
+
+// requiredTemplateNames returns the name of every English template the channels need.
+func requiredTemplateNames() []string {
+	var names []string
+	for channel, parts := range channelParts {
+		for _, alertType := range alertTypes {
+			for _, part := range parts {
+				names = append(names, templateName("", channel, alertType, part))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knownTemplateNames returns the required templates and their translations.
+func knownTemplateNames() map[string]bool {
+	known := make(map[string]bool)
+	for _, name := range requiredTemplateNames() {
+		known[name] = true
+		for _, lang := range templateLanguages {
+			known[path.Join(lang, name)] = true
+		}
+	}
+	return known
+}
+
+func templateName(lang, channel string, alertType AlertType, part string) string {
+	return path.Join(lang, fmt.Sprintf("%s/%s.%s.tmpl", channel, alertType, part))
+}
+
+// parseFS parses every template in fsys, replacing templates with the same name.
+func (t *Templates) parseFS(fsys fs.FS) error {
+	known := knownTemplateNames()
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !known[name] {
+			return fmt.Errorf("unknown template %s", name)
+		}
+
+		source, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(name, "."+partHTML+".tmpl") {
+			tmpl, err := htmltemplate.New(path.Base(name)).Funcs(templateFuncs).Parse(string(source))
+			if err != nil {
+				return fmt.Errorf("error parsing template %s: %w", name, err)
+			}
+			t.html[name] = tmpl
+			return nil
+		}
+
+		tmpl, err := texttemplate.New(path.Base(name)).Funcs(templateFuncs).Parse(string(source))
+		if err != nil {
+			return fmt.Errorf("error parsing template %s: %w", name, err)
+		}
+		t.text[name] = tmpl
+		return nil
+	})
+}
+
+// AlertTypeFor returns the alert type for a group of alerts sent together. Only powder alerts are sent
+// together, so downgrades and bluebird alerts are always alone.
+func AlertTypeFor(alerts []db.AlertToSend) AlertType {
+	switch {
+	case len(alerts) > 1:
+		return AlertDigest
+	case len(alerts) == 1 && alerts[0].Kind == db.AlertKindDowngrade:
+		return AlertDowngrade
+	case len(alerts) == 1 && alerts[0].Kind == db.AlertKindBluebird:
+		return AlertBluebird
+	case len(alerts) == 1 && alerts[0].IsUpdate:
+		return AlertUpdate
+	default:
+		return AlertNew
+	}
+}
+
+// mergeAlerts keeps only the largest forecast for each resort and day among alerts sent together, which
+// can repeat a forecast when a held alert is merged with a later update of it, and sorts them by date.
+func mergeAlerts(alerts []db.AlertToSend) []db.AlertToSend {
+	type forecastKey struct {
+		resort uuid.UUID
+		date   string
+	}
+
+	var merged []db.AlertToSend
+	index := make(map[forecastKey]int)
+	for _, alert := range alerts {
+		key := forecastKey{resort: alert.ResortUUID, date: alert.ForecastDate.Format("2006-01-02")}
+		if i, ok := index[key]; ok {
+			if alert.SnowAmount > merged[i].SnowAmount {
+				merged[i] = alert
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, alert)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].ForecastDate.Before(merged[j].ForecastDate)
+	})
+	return merged
+}
+
+// NewTemplateData builds template data for alerts, formatted for the units and locale of the first alert's
+// user. Only the largest forecast for each resort and day is kept, in date order, and at most
+// maxDigestAlerts are listed.
+func NewTemplateData(alerts []db.AlertToSend, now time.Time) TemplateData {
+	merged := mergeAlerts(alerts)
+
+	data := TemplateData{Count: len(merged), Units: units.DefaultSystem, Locale: DefaultLocale}
+	if len(alerts) > 0 {
+		if alerts[0].Units != "" {
+			data.Units = alerts[0].Units
+		}
+		if alerts[0].Locale != "" {
+			data.Locale = alerts[0].Locale
+		}
+	}
+	loc := localeFor(data.Locale)
+
+	for i, alert := range merged {
+		if i == maxDigestAlerts {
+			data.More = len(merged) - maxDigestAlerts
+			break
+		}
+		amount, snow, snowShort := loc.snow(alert.SnowAmount, data.Units)
+		data.Alerts = append(data.Alerts, TemplateAlert{
+			ResortName:   alert.ResortName,
+			SnowAmount:   amount,
+			Snow:         snow,
+			SnowShort:    snowShort,
+			ForecastDate: alert.ForecastDate,
+			Day:          loc.dayLabel(alert.ForecastDate, now),
+		})
+	}
+	if len(data.Alerts) > 0 {
+		data.TemplateAlert = data.Alerts[0]
+	}
+
+	return data
+}
+
+// HasTemplates reports whether messages on a channel are rendered from templates. Channels without templates,
+// such as webhooks, format the alerts themselves.
+func HasTemplates(channel string) bool {
+	_, ok := channelParts[templateChannel(channel)]
+	return ok
+}
+
+// Render renders the message for an alert type on a channel, in the language of the data's locale.
+func (t *Templates) Render(channel string, alertType AlertType, data TemplateData) (Message, error) {
+	channel = templateChannel(channel)
+	parts, ok := channelParts[channel]
+	if !ok {
+		return Message{}, fmt.Errorf("unsupported notification channel %q", channel)
+	}
+
+	lang := localeFor(data.Locale).language
+
+	var message Message
+	for _, part := range parts {
+		rendered, err := t.renderPart(t.lookupName(lang, channel, alertType, part), data)
+		if err != nil {
+			return Message{}, err
+		}
+
+		switch part {
+		case partSubject, partTitle:
+			message.Subject = rendered
+		case partText:
+			message.Text = rendered
+		case partHTML:
+			message.HTML = rendered
+		}
+	}
+
+	message.UnsubscribeURL = data.UnsubscribeURL
+
+	return message, nil
+}
+
+// RenderAlerts renders the message for alerts sent together on a channel, choosing the alert type from
+// the alerts. Alerts that merge into a single forecast are rendered as that forecast's alert.
+// unsubscribeURL may be empty.
+func (t *Templates) RenderAlerts(channel string, alerts []db.AlertToSend, now time.Time, unsubscribeURL string) (Message, error) {
+	if len(alerts) == 0 {
+		return Message{}, errors.New("no alerts to render")
+	}
+
+	data := NewTemplateData(alerts, now)
+	data.UnsubscribeURL = unsubscribeURL
+	alertType := AlertTypeFor(alerts)
+	if alertType == AlertDigest && data.Count == 1 {
+		alertType = AlertTypeFor([]db.AlertToSend{latestAlert(alerts)})
+	}
+
+	return t.Render(channel, alertType, data)
+}
+
+// latestAlert returns the alert with the largest forecast.
+func latestAlert(alerts []db.AlertToSend) db.AlertToSend {
+	latest := alerts[0]
+	for _, alert := range alerts[1:] {
+		if alert.SnowAmount > latest.SnowAmount {
+			latest = alert
+		}
+	}
+	return latest
+}
+
+// lookupName returns the name of the template to render, preferring a translation into lang.
+func (t *Templates) lookupName(lang, channel string, alertType AlertType, part string) string {
+	if lang != "" {
+		name := templateName(lang, channel, alertType, part)
+		if _, ok := t.text[name]; ok {
+			return name
+		}
+		if _, ok := t.html[name]; ok {
+			return name
+		}
+	}
+	return templateName("", channel, alertType, part)
+}
+
+func (t *Templates) renderPart(name string, data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if tmpl, ok := t.html[name]; ok {
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("error rendering template %s: %w", name, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	tmpl, ok := t.text[name]
+	if !ok {
+		return "", fmt.Errorf("missing template %s", name)
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error rendering template %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
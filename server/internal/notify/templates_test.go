@@ -0,0 +1,264 @@
+package notify
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/units"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/golden")
+
+var goldenNow = time.Date(2025, 12, 18, 9, 0, 0, 0, time.UTC)
+
+const goldenUnsubscribeURL = "https://powhunter.app/u/AQEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+
+func goldenAlerts() []db.AlertToSend {
+	resorts := []string{"Crystal Mountain", "Stevens Pass", "Mt. Baker", "Whistler Blackcomb", "Alta", "Snowbird", "Vail"}
+
+	alerts := make([]db.AlertToSend, 0, len(resorts))
+	for i, resort := range resorts {
+		alerts = append(alerts, db.AlertToSend{
+			UserUuid:     uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+			ResortName:   resort,
+			ResortUUID:   uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-0000000001%02d", i)),
+			SnowAmount:   6 + float64(i)*1.5,
+			ForecastDate: goldenNow.AddDate(0, 0, i),
+		})
+	}
+	return alerts
+}
+
+func formatGolden(message Message) string {
+	var b strings.Builder
+	if message.Subject != "" {
+		fmt.Fprintf(&b, "-- subject --\n%s\n", message.Subject)
+	}
+	fmt.Fprintf(&b, "-- text --\n%s\n", message.Text)
+	if message.HTML != "" {
+		fmt.Fprintf(&b, "-- html --\n%s\n", message.HTML)
+	}
+	return b.String()
+}
+
+// goldenVariants are the user preferences golden files are rendered for. Files for the default preferences
+// have no prefix.
+var goldenVariants = []struct {
+	prefix string
+	units  units.System
+	locale string
+}{
+	{prefix: "", units: units.Imperial, locale: "en-US"},
+	{prefix: "fr-CA_metric_", units: units.Metric, locale: "fr-CA"},
+}
+
+func TestTemplates_Golden(t *testing.T) {
+	templates := DefaultTemplates()
+
+	for _, variant := range goldenVariants {
+		alerts := goldenAlerts()
+		for i := range alerts {
+			alerts[i].Units = variant.units
+			alerts[i].Locale = variant.locale
+		}
+
+		for _, channel := range []string{db.ChannelSMS, db.ChannelPush, db.ChannelEmail} {
+			for _, alertType := range alertTypes {
+				name := fmt.Sprintf("%s%s_%s", variant.prefix, channel, alertType)
+				t.Run(name, func(t *testing.T) {
+					data := NewTemplateData(alerts[:1], goldenNow)
+					if alertType == AlertDigest {
+						data = NewTemplateData(alerts, goldenNow)
+					}
+					data.UnsubscribeURL = goldenUnsubscribeURL
+
+					message, err := templates.Render(channel, alertType, data)
+					require.NoError(t, err)
+
+					got := formatGolden(message)
+					path := filepath.Join("testdata", "golden", name+".golden")
+					if *updateGolden {
+						require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+					}
+
+					want, err := os.ReadFile(path)
+					require.NoError(t, err, "missing golden file, run go test ./internal/notify -update")
+					assert.Equal(t, string(want), got)
+				})
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderAlerts(t *testing.T) {
+	templates := DefaultTemplates()
+	crystal, stevens := uuid.New(), uuid.New()
+
+	t.Run("Merges forecasts for the same resort and day", func(t *testing.T) {
+		alerts := []db.AlertToSend{
+			{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 6, ForecastDate: goldenNow.Add(24 * time.Hour)},
+			{ResortName: "Crystal Mountain", ResortUUID: crystal, SnowAmount: 8.5, ForecastDate: goldenNow},
+			{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 9, ForecastDate: goldenNow.Add(24 * time.Hour), IsUpdate: true},
+		}
+
+		message, err := templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Powder Alert Summary! 2 forecasts with fresh snow: Crystal Mountain 8.5 in today; "+
+			"Stevens Pass 9.0 in tomorrow. Time to hit the slopes!", message.Text)
+	})
+
+	t.Run("Alerts for one forecast render as a single alert", func(t *testing.T) {
+		alerts := []db.AlertToSend{
+			{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 6, ForecastDate: goldenNow},
+			{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 9, ForecastDate: goldenNow, IsUpdate: true},
+		}
+
+		message, err := templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Powder Alert Update! Stevens Pass is now expecting 9.0 inches of snow today - "+
+			"even more powder than before! Time to hit the slopes!", message.Text)
+	})
+
+	t.Run("Downgrades and bluebird alerts render with their own templates", func(t *testing.T) {
+		tests := []struct {
+			kind string
+			want string
+		}{
+			{
+				kind: db.AlertKindDowngrade,
+				want: "Forecast Update: Stevens Pass is now only expecting 4.0 inches of snow today. " +
+					"We will let you know if it picks back up.",
+			},
+			{
+				kind: db.AlertKindBluebird,
+				want: "Bluebird Alert! Clear skies at Stevens Pass today after 4.0 inches of fresh snow. " +
+					"Time to hit the slopes!",
+			},
+		}
+
+		for _, tt := range tests {
+			alerts := []db.AlertToSend{
+				{ResortName: "Stevens Pass", ResortUUID: stevens, SnowAmount: 4, ForecastDate: goldenNow, IsUpdate: true, Kind: tt.kind},
+			}
+
+			message, err := templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, message.Text, tt.kind)
+		}
+	})
+
+	t.Run("Unsupported channel", func(t *testing.T) {
+		_, err := templates.RenderAlerts("pigeon", goldenAlerts()[:1], goldenNow, "")
+		assert.EqualError(t, err, `unsupported notification channel "pigeon"`)
+	})
+
+	t.Run("No alerts", func(t *testing.T) {
+		_, err := templates.RenderAlerts(db.ChannelSMS, nil, goldenNow, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplates_HTMLIsEscaped(t *testing.T) {
+	alert := db.AlertToSend{ResortName: `<script>alert("pow")</script>`, ResortUUID: uuid.New(), SnowAmount: 8, ForecastDate: goldenNow}
+
+	message, err := DefaultTemplates().RenderAlerts(db.ChannelEmail, []db.AlertToSend{alert}, goldenNow, "")
+	require.NoError(t, err)
+	assert.NotContains(t, message.HTML, "<script>")
+	assert.Contains(t, message.HTML, "&lt;script&gt;")
+	assert.Contains(t, message.Text, "<script>", "plain text parts are not escaped")
+}
+
+func TestMessage_EmailHeaders(t *testing.T) {
+	assert.Empty(t, Message{}.EmailHeaders())
+	assert.Equal(t, map[string]string{
+		"List-Unsubscribe":      "<" + goldenUnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}, Message{UnsubscribeURL: goldenUnsubscribeURL}.EmailHeaders())
+}
+
+func writeTemplate(t *testing.T, dir, name, source string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o644))
+}
+
+func TestLoadTemplates_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "sms/new.txt.tmpl", "Snow day! {{snow .SnowAmount}} at {{.ResortName}} {{.Day}}.\n")
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	alerts := goldenAlerts()[:1]
+	message, err := templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Snow day! 6.0 at Crystal Mountain today.", message.Text)
+
+	alerts[0].IsUpdate = true
+	message, err = templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(message.Text, "Powder Alert Update!"), "templates without an override use the default")
+}
+
+func TestLoadTemplates_TranslationFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "fr/sms/new.txt.tmpl", "Neige! {{.Snow}} à {{.ResortName}} {{.Day}}.\n")
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	alerts := goldenAlerts()[:1]
+	alerts[0].Locale = "fr-FR"
+	message, err := templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Neige! 6,0 pouces à Crystal Mountain aujourd'hui.", message.Text)
+
+	alerts[0].Locale = "en-GB"
+	alerts[0].ForecastDate = goldenNow.AddDate(0, 0, 3)
+	message, err = templates.RenderAlerts(db.ChannelSMS, alerts, goldenNow, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Powder Alert! Crystal Mountain is expecting 6.0 inches of snow on Sunday 21 Dec. Time to hit the slopes!", message.Text)
+}
+
+func TestLoadTemplates_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		source        string
+		expectedError string
+	}{
+		{
+			name:          "Unknown template",
+			file:          "sms/neww.txt.tmpl",
+			source:        "typo",
+			expectedError: "unknown template sms/neww.txt.tmpl",
+		},
+		{
+			name:          "Parse error",
+			file:          "email/new.html.tmpl",
+			source:        "{{.ResortName",
+			expectedError: "error parsing template email/new.html.tmpl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, tt.file, tt.source)
+
+			_, err := LoadTemplates(dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
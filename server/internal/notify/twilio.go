@@ -1,12 +1,13 @@
 package notify
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/phone"
 	"github.com/twilio/twilio-go"
 	twilioAPI "github.com/twilio/twilio-go/rest/api/v2010"
 )
@@ -15,72 +16,87 @@ import (
 
 // NotificationService defines the interface for notification services.
 type NotificationService interface {
-	// SendSMS sends an SMS message
-	SendSMS(to, message string) error
+	// SendSMS sends an SMS message and returns the provider's message ID
+	SendSMS(to, message string) (string, error)
 }
 
+// ProviderTwilio identifies Twilio in delivery records.
+const ProviderTwilio = "twilio"
+
 // TwilioClient handles SMS notifications via Twilio.
 type TwilioClient struct {
-	fromNumber string
+	fromNumber        string
+	statusCallbackURL string
 }
 
-// NewTwilioClient creates a new Twilio client.
-func NewTwilioClient(fromNumber string) *TwilioClient {
+// NewTwilioClient creates a new Twilio client. When statusCallbackURL is set, Twilio reports
+// delivery status changes for each message to it.
+func NewTwilioClient(fromNumber, statusCallbackURL string) *TwilioClient {
 	return &TwilioClient{
-		fromNumber: fromNumber,
+		fromNumber:        fromNumber,
+		statusCallbackURL: statusCallbackURL,
 	}
 }
 
-// SendSMS sends an SMS message using Twilio.
-func (t *TwilioClient) SendSMS(to, message string) error {
+// SendSMS sends an SMS message using Twilio and returns the message SID.
+func (t *TwilioClient) SendSMS(to, message string) (string, error) {
 	if to == "" || message == "" {
-		return errors.New("phone number and message are required")
+		return "", errors.New("phone number and message are required")
+	}
+
+	toNumber, err := phone.Normalize(to, phone.Region())
+	if err != nil {
+		return "", fmt.Errorf("error normalizing phone number: %w", err)
 	}
 
 	// This will look for `TWILIO_ACCOUNT_SID` and `TWILIO_AUTH_TOKEN` variables inside the current environment to initialize the constructor
 	client := twilio.NewRestClient()
 	params := &twilioAPI.CreateMessageParams{}
-	params.SetTo("6195733405")
+	params.SetTo(toNumber)
 	params.SetFrom(t.fromNumber)
 	params.SetBody(message)
+	if t.statusCallbackURL != "" {
+		params.SetStatusCallback(t.statusCallbackURL)
+	}
 
 	resp, err := client.Api.CreateMessage(params)
 	if err != nil {
-		return fmt.Errorf("error sending SMS: %w", err)
+		return "", fmt.Errorf("error sending SMS: %w", err)
 	}
 
-	response, _ := json.Marshal(*resp)
-	fmt.Println("Response: " + string(response))
-
-	return nil
+	if resp.Sid == nil {
+		return "", nil
+	}
+	return *resp.Sid, nil
 }
 
-// FormatSnowAlertMessage formats a snow alert SMS message.
-func FormatSnowAlertMessage(alert db.AlertToSend) string {
-	forecastDate := alert.ForecastDate
-	resortName := alert.ResortName
-	snowAmount := alert.SnowAmount
-
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	tomorrow := today.Add(24 * time.Hour)
-	forecastDay := time.Date(forecastDate.Year(), forecastDate.Month(), forecastDate.Day(), 0, 0, 0, 0, forecastDate.Location())
-
-	var timeStr string
-	switch {
-	case forecastDay.Equal(today):
-		timeStr = "today"
-	case forecastDay.Equal(tomorrow):
-		timeStr = "tomorrow"
+// DeliveryStatusFromTwilio maps a Twilio MessageStatus to a delivery status. Statuses that
+// don't affect delivery tracking, such as "receiving" on inbound messages, are not recognised.
+func DeliveryStatusFromTwilio(status string) (string, bool) {
+	switch status {
+	case "accepted", "scheduled", "queued", "sending":
+		return db.DeliveryStatusQueued, true
+	case "sent":
+		return db.DeliveryStatusSent, true
+	case "delivered":
+		return db.DeliveryStatusDelivered, true
+	case "failed", "canceled":
+		return db.DeliveryStatusFailed, true
+	case "undelivered":
+		return db.DeliveryStatusUndelivered, true
 	default:
-		timeStr = "on " + forecastDate.Format("Monday, Jan 2")
+		return "", false
 	}
+}
 
-	if alert.IsUpdate {
-		return fmt.Sprintf("Powder Alert Update! %s is now expecting %.1f inches of snow %s - even more powder than before! Time to hit the slopes!",
-			resortName, snowAmount, timeStr)
+// FormatSnowAlertMessage formats a snow alert SMS message with the built-in templates.
+func FormatSnowAlertMessage(alert db.AlertToSend) string {
+	message, err := builtinTemplates().RenderAlerts(db.ChannelSMS, []db.AlertToSend{alert}, time.Now(), "")
+	if err != nil {
+		// The built-in templates are checked when they are loaded, so this only fails on a bug.
+		panic(err)
 	}
-
-	return fmt.Sprintf("Powder Alert! %s is expecting %.1f inches of snow %s. Time to hit the slopes!",
-		resortName, snowAmount, timeStr)
+	return message.Text
 }
+
+var builtinTemplates = sync.OnceValue(DefaultTemplates)
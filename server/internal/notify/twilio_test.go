@@ -109,3 +109,31 @@ func TestFormatSnowAlertMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestDeliveryStatusFromTwilio(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+		ok       bool
+	}{
+		{status: "accepted", expected: db.DeliveryStatusQueued, ok: true},
+		{status: "queued", expected: db.DeliveryStatusQueued, ok: true},
+		{status: "sending", expected: db.DeliveryStatusQueued, ok: true},
+		{status: "sent", expected: db.DeliveryStatusSent, ok: true},
+		{status: "delivered", expected: db.DeliveryStatusDelivered, ok: true},
+		{status: "failed", expected: db.DeliveryStatusFailed, ok: true},
+		{status: "canceled", expected: db.DeliveryStatusFailed, ok: true},
+		{status: "undelivered", expected: db.DeliveryStatusUndelivered, ok: true},
+		{status: "receiving", ok: false},
+		{status: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			result, ok := DeliveryStatusFromTwilio(tt.status)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("DeliveryStatusFromTwilio(%q) = (%q, %v), want (%q, %v)", tt.status, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+)
+
+// ProviderWebhook identifies webhooks in delivery records.
+const ProviderWebhook = "webhook"
+
+// WebhookPayloadVersion is the version of the JSON payload. Fields may be added within a version; removing
+// or changing one needs a new version.
+const WebhookPayloadVersion = "1"
+
+// Webhook events, sent in the payload and the X-PowHunter-Event header.
+const (
+	WebhookEventSnowAlert = "snow_alert"
+	WebhookEventTest      = "test"
+)
+
+// Webhook request headers.
+const (
+	WebhookEventHeader     = "X-PowHunter-Event"
+	WebhookDeliveryHeader  = "X-PowHunter-Delivery"
+	WebhookTimestampHeader = "X-PowHunter-Timestamp"
+	WebhookSignatureHeader = "X-PowHunter-Signature"
+)
+
+const (
+	// webhookTimeout bounds each request to a webhook, including reading the response.
+	webhookTimeout = 10 * time.Second
+
+	// webhookAttempts is how many times a send is tried before the message goes back to the outbox to be
+	// retried with backoff.
+	webhookAttempts = 3
+
+	// defaultWebhookRetryDelay is the pause after the first failed attempt; each later pause is longer.
+	defaultWebhookRetryDelay = time.Second
+
+	// maxWebhookURLLength is the longest webhook URL accepted.
+	maxWebhookURLLength = 2048
+
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 24
+)
+
+var errPrivateNetwork = errors.New("webhook address is not on a public network")
+
+// WebhookStatusError is returned when a webhook responds with a status other than 2xx.
+type WebhookStatusError struct {
+	StatusCode int
+}
+
+func (e *WebhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.StatusCode)
+}
+
+// retryable reports whether the webhook may accept the same request later.
+func (e *WebhookStatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks.
+type WebhookPayload struct {
+	Version string `json:"version"`
+	Event   string `json:"event"`
+	// ID identifies the notification and is the same on every retry, so receivers can drop duplicates.
+	ID     string         `json:"id"`
+	SentAt time.Time      `json:"sent_at"`
+	Alerts []WebhookAlert `json:"alerts"`
+}
+
+// WebhookAlert is one forecast in a webhook payload. Snow amounts are always in inches.
+type WebhookAlert struct {
+	Resort       WebhookResort `json:"resort"`
+	ForecastDate string        `json:"forecast_date"`
+	SnowAmount   float64       `json:"snow_amount"`
+	SnowUnit     string        `json:"snow_unit"`
+	// IsUpdate is true when an alert for the same resort and date was sent before.
+	IsUpdate bool `json:"is_update"`
+}
+
+// WebhookResort identifies the resort an alert is for.
+type WebhookResort struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// WebhookNotifier POSTs alerts as signed JSON to the webhooks users register. Each request carries an
+// HMAC-SHA256 signature of the timestamp and body, keyed by the webhook's secret.
+type WebhookNotifier struct {
+	store      db.StoreService
+	client     *http.Client
+	retryDelay time.Duration
+	now        func() time.Time
+}
+
+// NewWebhookNotifier returns a notifier looking webhooks up in store. Unless allowPrivateNetworks is set,
+// webhooks resolving to loopback, private or link-local addresses are refused, so users can't use them to
+// reach internal services.
+func NewWebhookNotifier(store db.StoreService, allowPrivateNetworks bool) *WebhookNotifier {
+	return &WebhookNotifier{
+		store:      store,
+		client:     newWebhookClient(allowPrivateNetworks),
+		retryDelay: defaultWebhookRetryDelay,
+		now:        time.Now,
+	}
+}
+
+// WebhookNotifierFromEnv returns a notifier that refuses private addresses unless
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS is true, which is only meant for local development.
+func WebhookNotifierFromEnv(store db.StoreService) *WebhookNotifier {
+	return NewWebhookNotifier(store, privateNetworksAllowed())
+}
+
+// newWebhookClient returns the client used to call URLs users give us: it times out, doesn't follow
+// redirects and, unless allowPrivateNetworks is set, only connects to public addresses.
+func newWebhookClient(allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivateNetworks {
+		dialer.Control = denyPrivateNetworks
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   webhookTimeout,
+		// A redirect could point anywhere, so it is reported as a failure instead of followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// privateNetworksAllowed reports whether WEBHOOK_ALLOW_PRIVATE_NETWORKS is set to true.
+func privateNetworksAllowed() bool {
+	value := os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS")
+	if value == "" {
+		return false
+	}
+
+	allowed, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid WEBHOOK_ALLOW_PRIVATE_NETWORKS", "value", value)
+		return false
+	}
+	return allowed
+}
+
+func (n *WebhookNotifier) Provider() string {
+	return ProviderWebhook
+}
+
+// Send POSTs a snow alert to the webhook whose UUID is the notification's recipient.
+func (n *WebhookNotifier) Send(ctx context.Context, notification Notification) (Receipt, error) {
+	webhookUUID, err := uuid.Parse(notification.Recipient)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("invalid webhook recipient %q", notification.Recipient)
+	}
+
+	webhook, err := n.store.GetWebhook(ctx, webhookUUID)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	alerts := make([]WebhookAlert, 0, len(notification.Alerts))
+	for _, alert := range notification.Alerts {
+		alerts = append(alerts, WebhookAlert{
+			Resort: WebhookResort{
+				UUID: alert.ResortUUID.String(),
+				Name: alert.ResortName,
+			},
+			ForecastDate: alert.ForecastDate.Format(time.DateOnly),
+			SnowAmount:   alert.SnowAmount,
+			SnowUnit:     "in",
+			IsUpdate:     alert.IsUpdate,
+		})
+	}
+
+	return n.post(ctx, webhook, WebhookPayload{
+		Version: WebhookPayloadVersion,
+		Event:   WebhookEventSnowAlert,
+		ID:      notification.ID.String(),
+		Alerts:  alerts,
+	})
+}
+
+// SendTest POSTs a test event with no alerts, so users can check their receiver before real alerts arrive.
+func (n *WebhookNotifier) SendTest(ctx context.Context, webhook dbgen.Webhook) (Receipt, error) {
+	return n.post(ctx, webhook, WebhookPayload{
+		Version: WebhookPayloadVersion,
+		Event:   WebhookEventTest,
+		ID:      uuid.NewString(),
+		Alerts:  []WebhookAlert{},
+	})
+}
+
+// post sends a payload, retrying a few times on network errors and retryable statuses.
+func (n *WebhookNotifier) post(ctx context.Context, webhook dbgen.Webhook, payload WebhookPayload) (Receipt, error) {
+	payload.SentAt = n.now().UTC().Truncate(time.Second)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	deliveryID := uuid.NewString()
+
+	err = postWithRetries(ctx, n.client, n.retryDelay, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error creating webhook request: %w", err)
+		}
+
+		timestamp := strconv.FormatInt(n.now().Unix(), 10)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "PowHunter-Webhook/"+WebhookPayloadVersion)
+		req.Header.Set(WebhookEventHeader, payload.Event)
+		req.Header.Set(WebhookDeliveryHeader, deliveryID)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, body))
+		return req, nil
+	})
+	if err != nil {
+		return Receipt{MessageID: deliveryID}, err
+	}
+
+	return Receipt{MessageID: deliveryID, Status: db.DeliveryStatusDelivered}, nil
+}
+
+// postWithRetries sends the request newRequest builds, trying again a few times on network errors and
+// retryable statuses. newRequest is called for every attempt.
+func postWithRetries(
+	ctx context.Context,
+	client *http.Client,
+	retryDelay time.Duration,
+	newRequest func() (*http.Request, error),
+) error {
+	var sendErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return sendErr
+			case <-time.After(time.Duration(attempt-1) * retryDelay):
+			}
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+
+		sendErr = do(client, req)
+		if sendErr == nil {
+			return nil
+		}
+
+		var statusErr *WebhookStatusError
+		if errors.As(sendErr, &statusErr) && !statusErr.retryable() {
+			return sendErr
+		}
+		if errors.Is(sendErr, errPrivateNetwork) {
+			return sendErr
+		}
+	}
+
+	return sendErr
+}
+
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &WebhookStatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// SignWebhook returns the X-PowHunter-Signature value for a request body sent at timestamp (Unix seconds):
+// "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a period and the body.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is valid for a request body sent at timestamp. Receivers
+// should also reject timestamps too far from their own clock, to stop requests being replayed.
+func VerifyWebhookSignature(secret, timestamp, signature string, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body)))
+}
+
+// NewWebhookSecret returns a random secret for signing a new webhook's payloads.
+func NewWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute http or https URL without credentials. With
+// requireHTTPS, plain http is rejected.
+func ValidateWebhookURL(rawURL string, requireHTTPS bool) error {
+	if len(rawURL) > maxWebhookURLLength {
+		return fmt.Errorf("webhook URL is longer than %d characters", maxWebhookURLLength)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	switch {
+	case parsed.Scheme != "https" && parsed.Scheme != "http":
+		return errors.New("webhook URL must use http or https")
+	case requireHTTPS && parsed.Scheme != "https":
+		return errors.New("webhook URL must use https")
+	case parsed.Host == "" || parsed.Hostname() == "":
+		return errors.New("webhook URL must include a host")
+	case parsed.User != nil:
+		return errors.New("webhook URL must not include credentials")
+	}
+
+	return nil
+}
+
+// denyPrivateNetworks is a net.Dialer Control refusing connections to addresses that aren't publicly
+// routable. It runs after DNS resolution, so hostnames pointing at internal addresses are caught too.
+func denyPrivateNetworks(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errPrivateNetwork
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return errPrivateNetwork
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	"github.com/MattSilvaa/powhunter/internal/webpush"
+)
+
+// ProviderWebPush identifies browser push services in delivery records.
+const ProviderWebPush = "webpush"
+
+const (
+	// webPushTTL is how long a push service keeps a message for a browser that is offline. A forecast alert
+	// is stale after a day.
+	webPushTTL = 24 * time.Hour
+
+	// defaultVAPIDSubject is the contact push services are given for the sender when VAPID_SUBJECT is unset.
+	defaultVAPIDSubject = "mailto:support@powhunter.app"
+)
+
+// WebPushPayload is the JSON the service worker receives and shows as a notification.
+type WebPushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// URL is the snow report page opened when the notification is clicked.
+	URL string `json:"url,omitempty"`
+	// Tag groups notifications, so an update replaces the alert for the same resort.
+	Tag            string `json:"tag"`
+	UnsubscribeURL string `json:"unsubscribe_url,omitempty"`
+}
+
+// WebPushNotifier sends alerts to browsers with Web Push. The notification's recipient is the UUID of the
+// push subscription, and its title and text come from the push templates. Subscriptions the push service
+// reports as gone are removed.
+type WebPushNotifier struct {
+	store      db.StoreService
+	client     *http.Client
+	keys       *webpush.VAPIDKeys
+	subject    string
+	retryDelay time.Duration
+	now        func() time.Time
+}
+
+// NewWebPushNotifier returns a notifier signing pushes with keys. subject is a mailto: or https: URL push
+// services can contact the sender at. allowPrivateNetworks is as for NewWebhookNotifier.
+func NewWebPushNotifier(
+	store db.StoreService,
+	keys *webpush.VAPIDKeys,
+	subject string,
+	allowPrivateNetworks bool,
+) *WebPushNotifier {
+	return &WebPushNotifier{
+		store:      store,
+		client:     newWebhookClient(allowPrivateNetworks),
+		keys:       keys,
+		subject:    subject,
+		retryDelay: defaultWebhookRetryDelay,
+		now:        time.Now,
+	}
+}
+
+// WebPushNotifierFromEnv returns a notifier using the keys in VAPID_PRIVATE_KEY and the contact in
+// VAPID_SUBJECT. It returns nil when no keys are configured, in which case Web Push is disabled.
+func WebPushNotifierFromEnv(store db.StoreService) (*WebPushNotifier, error) {
+	keys, err := webpush.VAPIDKeysFromEnv()
+	if err != nil || keys == nil {
+		return nil, err
+	}
+
+	subject := os.Getenv("VAPID_SUBJECT")
+	if subject == "" {
+		subject = defaultVAPIDSubject
+	}
+
+	return NewWebPushNotifier(store, keys, subject, privateNetworksAllowed()), nil
+}
+
+func (n *WebPushNotifier) Provider() string {
+	return ProviderWebPush
+}
+
+// Send pushes the notification to the subscription named by its recipient.
+func (n *WebPushNotifier) Send(ctx context.Context, notification Notification) (Receipt, error) {
+	subscriptionUUID, err := uuid.Parse(notification.Recipient)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("invalid push subscription recipient %q", notification.Recipient)
+	}
+
+	subscription, err := n.store.GetPushSubscription(ctx, subscriptionUUID)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	payload, err := json.Marshal(webPushPayloadFor(notification))
+	if err != nil {
+		return Receipt{}, fmt.Errorf("error encoding push payload: %w", err)
+	}
+	body, err := webpush.Encrypt(webpush.Subscription{
+		Endpoint: subscription.Endpoint,
+		P256DH:   subscription.P256dh,
+		Auth:     subscription.Auth,
+	}, payload)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	urgency := "high"
+	if AlertTypeFor(notification.Alerts) == AlertUpdate {
+		urgency = "normal"
+	}
+
+	err = postWithRetries(ctx, n.client, n.retryDelay, func() (*http.Request, error) {
+		authorization, err := n.keys.Authorization(subscription.Endpoint, n.subject, n.now())
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error creating push request: %w", err)
+		}
+		req.Header.Set("Authorization", authorization)
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("Content-Encoding", "aes128gcm")
+		req.Header.Set("TTL", strconv.Itoa(int(webPushTTL/time.Second)))
+		req.Header.Set("Urgency", urgency)
+		return req, nil
+	})
+
+	var statusErr *WebhookStatusError
+	if errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone) {
+		// The browser unsubscribed or the subscription expired; it will never accept a message again.
+		slog.InfoContext(ctx, "Removing expired push subscription",
+			"subscription_uuid", subscriptionUUID, "status", statusErr.StatusCode)
+		if err := n.store.ExpirePushSubscription(ctx, subscriptionUUID); err != nil {
+			return Receipt{}, err
+		}
+		return Receipt{Status: db.DeliveryStatusUndelivered}, nil
+	}
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	// The push service has accepted the message and delivers it when the browser is next online.
+	return Receipt{Status: db.DeliveryStatusSent}, nil
+}
+
+// webPushPayloadFor builds the payload for a notification. Clicking it opens the snow report of the resort
+// with the largest forecast.
+func webPushPayloadFor(notification Notification) WebPushPayload {
+	payload := WebPushPayload{
+		Title:          notification.Message.Subject,
+		Body:           notification.Message.Text,
+		Tag:            "powhunter-summary",
+		UnsubscribeURL: notification.Message.UnsubscribeURL,
+	}
+
+	if len(notification.Alerts) > 0 {
+		latest := latestAlert(notification.Alerts)
+		payload.URL = latest.ResortURL
+
+		single := true
+		for _, alert := range notification.Alerts {
+			if alert.ResortUUID != latest.ResortUUID {
+				single = false
+				break
+			}
+		}
+		if single {
+			payload.Tag = "powhunter-" + latest.ResortUUID.String()
+		}
+	}
+
+	return payload
+}
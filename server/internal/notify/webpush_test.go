@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+	dbgen "github.com/MattSilvaa/powhunter/internal/db/generated"
+	"github.com/MattSilvaa/powhunter/internal/db/mocks"
+	"github.com/MattSilvaa/powhunter/internal/webpush"
+)
+
+const testPushAuth = "BTBZMqHH6r4Tts7J_aSIgg"
+
+// testBrowser is a browser subscribed to a local push service stand-in, holding the private key payloads are
+// encrypted to.
+type testBrowser struct {
+	privateKey   string
+	subscription dbgen.PushSubscription
+}
+
+func newTestBrowser(t *testing.T, endpoint string) testBrowser {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testBrowser{
+		privateKey: base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		subscription: dbgen.PushSubscription{
+			Uuid:     uuid.New(),
+			Endpoint: endpoint,
+			P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:     testPushAuth,
+		},
+	}
+}
+
+func newTestWebPushNotifier(t *testing.T, store db.StoreService) (*WebPushNotifier, *webpush.VAPIDKeys) {
+	t.Helper()
+
+	keys, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	notifier := NewWebPushNotifier(store, keys, "mailto:support@powhunter.app", true)
+	notifier.retryDelay = time.Millisecond
+	return notifier, keys
+}
+
+func TestOutboxWorker_SendsWebPush(t *testing.T) {
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	pushService := newWebhookReceiver(t, http.StatusTooManyRequests, http.StatusCreated)
+	browser := newTestBrowser(t, pushService.URL+"/push/abc123")
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+
+	message := testOutboxMessage(1)
+	message.Channel = db.ChannelWebPush
+	message.Recipient = browser.subscription.Uuid.String()
+	message.Alert.ResortURL = "https://www.crystalmountainresort.com/snow-report"
+	message.Alert.ForecastDate = now.Add(24 * time.Hour)
+
+	mockStore.EXPECT().ClaimOutboxMessages(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]db.OutboxMessage{message}, nil)
+	mockStore.EXPECT().GetNotificationBudget(gomock.Any(), message.Alert.UserUuid, db.ChannelWebPush, gomock.Any()).
+		Return(db.NotificationBudget{}, nil)
+	mockStore.EXPECT().GetPushSubscription(gomock.Any(), browser.subscription.Uuid).Return(browser.subscription, nil)
+	mockStore.EXPECT().RecordDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, delivery db.Delivery) error {
+			assert.Equal(t, db.ChannelWebPush, delivery.Channel)
+			assert.Equal(t, ProviderWebPush, delivery.Provider)
+			assert.Equal(t, db.DeliveryStatusSent, delivery.Status)
+			return nil
+		})
+	mockStore.EXPECT().MarkOutboxMessagesSent(gomock.Any(), []uuid.UUID{message.UUID}).Return(nil)
+
+	notifier, keys := newTestWebPushNotifier(t, mockStore)
+	notifier.now = func() time.Time { return now }
+	worker := NewOutboxWorker(mockStore, &fakeSMSSender{}, testLimits, DefaultTemplates()).
+		WithNotifier(db.ChannelWebPush, notifier)
+	worker.now = func() time.Time { return now }
+	_, err := worker.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, pushService.requests, 2, "the throttled first attempt is retried")
+	req := pushService.requests[1]
+	assert.Equal(t, "/push/abc123", req.URL.Path)
+	assert.Equal(t, "aes128gcm", req.Header.Get("Content-Encoding"))
+	assert.Equal(t, "86400", req.Header.Get("TTL"))
+	assert.Equal(t, "high", req.Header.Get("Urgency"))
+
+	claims, publicKey, err := webpush.VerifyAuthorization(req.Header.Get("Authorization"))
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey(), publicKey)
+	assert.Equal(t, pushService.URL, claims["aud"])
+	assert.Equal(t, "mailto:support@powhunter.app", claims["sub"])
+
+	plaintext, err := webpush.Decrypt(browser.privateKey, testPushAuth, pushService.bodies[1])
+	require.NoError(t, err)
+	var payload WebPushPayload
+	require.NoError(t, json.Unmarshal(plaintext, &payload))
+	assert.Equal(t, WebPushPayload{
+		Title: "Powder Alert: Crystal Mountain",
+		Body:  "8.0 inches of snow expected tomorrow.",
+		URL:   "https://www.crystalmountainresort.com/snow-report",
+		Tag:   "powhunter-" + message.Alert.ResortUUID.String(),
+	}, payload)
+}
+
+func TestWebPushNotifier_RemovesExpiredSubscriptions(t *testing.T) {
+	for _, status := range []int{http.StatusGone, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			pushService := newWebhookReceiver(t, status)
+			browser := newTestBrowser(t, pushService.URL+"/push/expired")
+
+			ctrl := gomock.NewController(t)
+			mockStore := mocks.NewMockStoreService(ctrl)
+			mockStore.EXPECT().GetPushSubscription(gomock.Any(), browser.subscription.Uuid).
+				Return(browser.subscription, nil)
+			mockStore.EXPECT().ExpirePushSubscription(gomock.Any(), browser.subscription.Uuid).Return(nil)
+
+			notifier, _ := newTestWebPushNotifier(t, mockStore)
+			receipt, err := notifier.Send(context.Background(), Notification{
+				Channel:   db.ChannelWebPush,
+				Recipient: browser.subscription.Uuid.String(),
+				Alerts:    []db.AlertToSend{testOutboxMessage(1).Alert},
+				Message:   Message{Subject: "Powder Alert: Crystal Mountain", Text: "8.0 inches of snow expected today."},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, db.DeliveryStatusUndelivered, receipt.Status)
+			assert.Len(t, pushService.requests, 1, "gone subscriptions aren't retried")
+		})
+	}
+}
+
+func TestWebPushNotifier_ServerErrorsAreRetriedByTheOutbox(t *testing.T) {
+	pushService := newWebhookReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	browser := newTestBrowser(t, pushService.URL+"/push/abc")
+
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStoreService(ctrl)
+	mockStore.EXPECT().GetPushSubscription(gomock.Any(), browser.subscription.Uuid).Return(browser.subscription, nil)
+
+	notifier, _ := newTestWebPushNotifier(t, mockStore)
+	_, err := notifier.Send(context.Background(), Notification{
+		Channel:   db.ChannelWebPush,
+		Recipient: browser.subscription.Uuid.String(),
+		Alerts:    []db.AlertToSend{testOutboxMessage(1).Alert},
+	})
+	assert.ErrorContains(t, err, "502")
+	assert.Len(t, pushService.requests, 3)
+}
+
+func TestWebPushPayloadFor_Summary(t *testing.T) {
+	first, second := testOutboxMessage(1).Alert, testOutboxMessage(1).Alert
+	second.ResortUUID = uuid.New()
+	second.SnowAmount = 14
+	second.ResortURL = "https://www.stevenspass.com"
+
+	payload := webPushPayloadFor(Notification{
+		Alerts:  []db.AlertToSend{first, second},
+		Message: Message{Subject: "Powder Alert: 2 forecasts", UnsubscribeURL: "https://powhunter.app/u/abc"},
+	})
+	assert.Equal(t, "powhunter-summary", payload.Tag)
+	assert.Equal(t, "https://www.stevenspass.com", payload.URL, "links to the largest forecast")
+	assert.Equal(t, "https://powhunter.app/u/abc", payload.UnsubscribeURL)
+}
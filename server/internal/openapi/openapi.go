@@ -0,0 +1,211 @@
+// Package openapi builds OpenAPI 3.1 documents describing the API, with JSON schemas reflected from the Go
+// types handlers decode and encode, and validates responses against them.
+//
+// Schemas are reflected rather than written by hand so the document can't describe fields a type doesn't
+// have. What reflection can't see, such as which error codes an operation returns, is checked by validating
+// the responses handlers actually write.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version documents are written in.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on a path, keyed by lowercase method.
+type PathItem map[string]*Operation
+
+// Operation is a method on a path.
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Parameters  []Parameter  `json:"parameters,omitempty"`
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	// Responses are keyed by status code.
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body of a request, keyed by media type.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response to an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a header of a response.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in one media type. Bodies that aren't JSON have no schema.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the schemas of named types, which other schemas refer to.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathParam returns a required path parameter.
+func PathParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: schema}
+}
+
+// QueryParam returns a query parameter.
+func QueryParam(name, description string, required bool, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Required: required, Schema: schema}
+}
+
+// Builder builds a document, adding the schemas of the types its operations use to its components.
+type Builder struct {
+	doc *Document
+	// types are the Go types whose schemas are in the components, by schema name.
+	types map[string]reflect.Type
+	// request is set while reflecting a request body's schema.
+	request bool
+}
+
+// NewBuilder returns a builder for a document describing info.
+func NewBuilder(info Info) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      map[string]PathItem{},
+			Components: Components{Schemas: map[string]*Schema{}},
+		},
+		types: map[string]reflect.Type{},
+	}
+}
+
+// Add adds an operation on a route pattern such as "GET /api/v1/resorts/{id}".
+func (b *Builder) Add(pattern string, op *Operation) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		panic(fmt.Sprintf("openapi: route pattern %q has no method", pattern))
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Document returns the document built.
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+// JSONBody returns a JSON request body of v's type. Handlers decide which fields they need, so the schema
+// only requires those with a required validate rule.
+func (b *Builder) JSONBody(v any) *RequestBody {
+	b.request = true
+	defer func() { b.request = false }()
+
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: b.Schema(v)}},
+	}
+}
+
+// JSONResponse returns a response with a JSON body of v's type.
+func (b *Builder) JSONResponse(description string, v any) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: b.Schema(v)}},
+	}
+}
+
+// Find returns the operation serving a route pattern, or nil.
+func (d *Document) Find(pattern string) *Operation {
+	method, path, _ := strings.Cut(pattern, " ")
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Patterns returns the route pattern of every operation, sorted.
+func (d *Document) Patterns() []string {
+	var patterns []string
+	for path, item := range d.Paths {
+		for method := range item {
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// ValidateResponse checks that a response to an operation is documented: its status is one of the
+// operation's responses, it has the headers the response requires, its media type is one the response has,
+// and a JSON body matches the schema.
+func (d *Document) ValidateResponse(op *Operation, status int, header http.Header, body []byte) error {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented for %s", status, op.OperationID)
+	}
+	for name, documented := range response.Headers {
+		if documented.Required && header.Get(name) == "" {
+			return fmt.Errorf("%s responded %d without the %s header", op.OperationID, status, name)
+		}
+	}
+
+	contentType := header.Get("Content-Type")
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s responded %d with a body, which is documented as empty", op.OperationID, status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	content, ok := response.Content[strings.TrimSpace(mediaType)]
+	if !ok {
+		return fmt.Errorf("%s responded %d with %q, which is not documented", op.OperationID, status, contentType)
+	}
+	if content.Schema == nil {
+		return nil
+	}
+	return d.ValidateJSON(content.Schema, body)
+}
@@ -0,0 +1,204 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type testBase struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type testResort struct {
+	testBase
+	Name      string     `json:"name"`
+	Location  *testPoint `json:"location"`
+	Tags      []string   `json:"tags,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	internal  string
+}
+
+type testRequest struct {
+	Name    string   `json:"name" validate:"required,max=100"`
+	Email   string   `json:"email" validate:"email"`
+	Days    *int32   `json:"days" validate:"min=1,max=10"`
+	Resorts []string `json:"resorts" validate:"max=2,dive,uuid"`
+}
+
+func TestBuilder_Schema(t *testing.T) {
+	b := NewBuilder(Info{Title: "Test", Version: "1"})
+	assert.Equal(t, Ref("testResort"), b.Schema(testResort{}))
+
+	schemas := b.Document().Components.Schemas
+	resort := schemas["testResort"]
+	require.NotNil(t, resort)
+	assert.ElementsMatch(t, []string{"id", "name", "location", "updated_at"}, resort.Required,
+		"embedded fields are flattened, and omitempty fields are optional")
+	assert.Equal(t, String("uuid"), resort.Properties["id"])
+	assert.Equal(t, &Schema{AnyOf: []*Schema{Ref("testPoint"), {Type: "null"}}}, resort.Properties["location"])
+	assert.Equal(t, &Schema{Type: []string{"string", "null"}, Format: "date-time"}, resort.Properties["updated_at"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, resort.Properties["tags"])
+	assert.NotContains(t, resort.Properties, "internal")
+	assert.True(t, resort.Closed)
+	assert.Contains(t, schemas, "testPoint")
+
+	encoded, err := json.Marshal(resort)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"additionalProperties":false`)
+}
+
+func TestBuilder_JSONBody(t *testing.T) {
+	b := NewBuilder(Info{Title: "Test", Version: "1"})
+	body := b.JSONBody(testRequest{})
+	assert.Equal(t, Ref("testRequest"), body.Content["application/json"].Schema)
+
+	doc := b.Document()
+	request := doc.Components.Schemas["testRequest"]
+	assert.Equal(t, []string{"name"}, request.Required, "only fields with a required rule are required")
+	assert.True(t, request.Closed, "handlers reject fields they don't know")
+
+	one, ten, hundred, two := 1.0, 10.0, 100, 2
+	assert.Equal(t, &Schema{Type: "string", MaxLength: &hundred}, request.Properties["name"])
+	assert.Equal(t, String("email"), request.Properties["email"])
+	assert.Equal(t, &Schema{Type: []string{"integer", "null"}, Minimum: &one, Maximum: &ten},
+		request.Properties["days"])
+	assert.Equal(t, &Schema{Type: "array", Items: String("uuid"), MaxItems: &two}, request.Properties["resorts"])
+
+	schema := body.Content["application/json"].Schema
+	assert.NoError(t, doc.ValidateJSON(schema, []byte(`{"name":"Crystal","days":3}`)))
+	assert.EqualError(t, doc.ValidateJSON(schema, []byte(`{"name":"Crystal","days":0}`)), "$.days: 0 is less than 1")
+	assert.EqualError(t, doc.ValidateJSON(schema, []byte(`{"name":"Crystal","resorts":["a","b","c"]}`)),
+		"$.resorts: array has more than 2 items")
+}
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	b := NewBuilder(Info{Title: "Test", Version: "1"})
+	op := &Operation{
+		OperationID: "getResort",
+		Responses: map[string]*Response{
+			"200": b.JSONResponse("The resort", testResort{}),
+			"204": {Description: "Nothing"},
+			"429": {
+				Description: "Too many requests",
+				Headers:     map[string]Header{"Retry-After": {Required: true, Schema: Integer()}},
+			},
+			"404": {
+				Description: "Not found",
+				Content: map[string]MediaType{"application/json": {Schema: &Schema{AllOf: []*Schema{
+					b.Schema(struct {
+						Error string `json:"error"`
+					}{}),
+					{Properties: map[string]*Schema{"error": Enum("RESORT_NOT_FOUND")}},
+				}}}},
+			},
+		},
+	}
+	b.Add("GET /resorts/{id}", op)
+	doc := b.Document()
+	require.Same(t, op, doc.Find("GET /resorts/{id}"))
+	assert.Equal(t, []string{"GET /resorts/{id}"}, doc.Patterns())
+
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		body          string
+		expectedError string
+	}{
+		{
+			name:   "Matches",
+			status: http.StatusOK,
+			header: jsonHeader,
+			body: `{"id":"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d","name":"Crystal","location":{"lat":46.9,"lon":-121.5},` +
+				`"updated_at":null}`,
+		},
+		{
+			name:          "Missing property",
+			status:        http.StatusOK,
+			header:        jsonHeader,
+			body:          `{"id":"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d","location":null,"updated_at":null}`,
+			expectedError: `$: missing required property "name"`,
+		},
+		{
+			name:   "Undocumented property",
+			status: http.StatusOK,
+			header: jsonHeader,
+			body: `{"id":"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d","name":"Crystal","location":null,"updated_at":null,` +
+				`"elevation":1000}`,
+			expectedError: `$: property "elevation" is not documented`,
+		},
+		{
+			name:   "Wrong type",
+			status: http.StatusOK,
+			header: jsonHeader,
+			body: `{"id":"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d","name":"Crystal","location":{"lat":"46.9","lon":0},` +
+				`"updated_at":null}`,
+			expectedError: `$.location: matches no schema: $.location.lat: string is not of type number; ` +
+				`$.location: object is not of type null`,
+		},
+		{
+			name:          "Undocumented error code",
+			status:        http.StatusNotFound,
+			header:        jsonHeader,
+			body:          `{"error":"FEED_NOT_FOUND"}`,
+			expectedError: "$.error: FEED_NOT_FOUND is not one of [RESORT_NOT_FOUND]",
+		},
+		{
+			name:          "Undocumented status",
+			status:        http.StatusConflict,
+			header:        jsonHeader,
+			body:          `{"error":"DUPLICATE"}`,
+			expectedError: "status 409 is not documented for getResort",
+		},
+		{
+			name:          "Undocumented media type",
+			status:        http.StatusOK,
+			header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			body:          "Crystal",
+			expectedError: `getResort responded 200 with "text/plain; charset=utf-8", which is not documented`,
+		},
+		{
+			name:   "Empty response",
+			status: http.StatusNoContent,
+		},
+		{
+			name:          "Body on an empty response",
+			status:        http.StatusNoContent,
+			body:          "{}",
+			expectedError: "getResort responded 204 with a body, which is documented as empty",
+		},
+		{
+			name:   "Required header",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"30"}},
+		},
+		{
+			name:          "Missing required header",
+			status:        http.StatusTooManyRequests,
+			expectedError: "getResort responded 429 without the Retry-After header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateResponse(op, tt.status, tt.header, []byte(tt.body))
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
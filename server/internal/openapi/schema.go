@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/validate"
+)
+
+// Schema is a JSON Schema (draft 2020-12), the dialect of OpenAPI 3.1.
+type Schema struct {
+	Ref string `json:"$ref,omitempty"`
+	// Type is a JSON type, or a list of them.
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	// Closed disallows properties that aren't listed.
+	Closed bool `json:"-"`
+}
+
+// MarshalJSON writes a closed schema's additionalProperties as false.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	if !s.Closed {
+		return json.Marshal((*schema)(s))
+	}
+	return json.Marshal(struct {
+		*schema
+		AdditionalProperties bool `json:"additionalProperties"`
+	}{schema: (*schema)(s)})
+}
+
+// String returns a string schema.
+func String(format string) *Schema {
+	return &Schema{Type: "string", Format: format}
+}
+
+// Integer returns an integer schema.
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+// Enum returns a string schema allowing only values.
+func Enum(values ...string) *Schema {
+	schema := &Schema{Type: "string"}
+	for _, value := range values {
+		schema.Enum = append(schema.Enum, value)
+	}
+	return schema
+}
+
+// Ref returns a schema referring to a component.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Schema returns the schema of v's type as encoding/json encodes it. Named struct types are added to the
+// components and referred to. Fields without omitempty are required, since they're always encoded, and
+// the rules in fields' validate tags are added to their schemas.
+func (b *Builder) Schema(v any) *Schema {
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *Builder) schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return String("date-time")
+	case t == uuidType:
+		return String("uuid")
+	case t.Kind() != reflect.Pointer &&
+		(t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return String("byte")
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Pointer:
+		return nullable(b.schemaOf(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return b.component(t)
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// component adds a named struct type to the components, and returns a reference to it.
+func (b *Builder) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := b.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: schemas %s and %s have the same name", existing, t))
+		}
+		return Ref(name)
+	}
+
+	// Registered before reflecting the fields, so recursive types refer to themselves.
+	b.types[name] = t
+	b.doc.Components.Schemas[name] = b.structSchema(t)
+	return Ref(name)
+}
+
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, Closed: true}
+	b.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the fields of a struct to schema, including those of embedded structs as encoding/json
+// does.
+func (b *Builder) addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.schemaOf(field.Type)
+		rules := validate.Rules(field)
+		addRules(property, rules)
+		schema.Properties[name] = property
+
+		required := !b.request && !hasOption(options, "omitempty") && !hasOption(options, "omitzero")
+		if required || slices.Contains(rules, validate.Rule{Name: validate.Required}) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// addRules adds the keywords matching a field's validate rules to its schema.
+func addRules(schema *Schema, rules []validate.Rule) {
+	for i, rule := range rules {
+		switch rule.Name {
+		case validate.Email:
+			schema.Format = "email"
+		case validate.UUID:
+			schema.Format = "uuid"
+		case validate.OneOf:
+			for _, value := range strings.Fields(rule.Param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case validate.Min, validate.Max:
+			addBound(schema, rule)
+		case validate.Dive:
+			if schema.Items != nil {
+				addRules(schema.Items, rules[i+1:])
+			}
+			return
+		}
+	}
+}
+
+// addBound adds a min or max rule as the keyword for the schema's type.
+func addBound(schema *Schema, rule validate.Rule) {
+	bound, _ := strconv.ParseFloat(rule.Param, 64)
+	count := int(bound)
+
+	typ := types(schema.Type)
+	isMin := rule.Name == validate.Min
+	switch {
+	case slices.Contains(typ, "string") && isMin:
+		schema.MinLength = &count
+	case slices.Contains(typ, "string"):
+		schema.MaxLength = &count
+	case slices.Contains(typ, "array") && isMin:
+		schema.MinItems = &count
+	case slices.Contains(typ, "array"):
+		schema.MaxItems = &count
+	case isMin:
+		schema.Minimum = &bound
+	default:
+		schema.Maximum = &bound
+	}
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable allows null as well as what schema allows.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	}
+	if typ, ok := schema.Type.(string); ok {
+		schema.Type = []string{typ, "null"}
+		return schema
+	}
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
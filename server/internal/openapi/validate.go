@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateJSON checks that a JSON document matches schema. It supports the keywords this package writes.
+func (d *Document) ValidateJSON(schema *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("body is not JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("body has more than one JSON value")
+	}
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value any, path string) error {
+	if schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		component, found := d.Components.Schemas[name]
+		if !ok || !found {
+			return fmt.Errorf("%s: unknown schema %s", path, schema.Ref)
+		}
+		return d.validate(component, value, path)
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := d.validate(sub, value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.AnyOf) > 0 {
+		var errs []string
+		for _, sub := range schema.AnyOf {
+			err := d.validate(sub, value, path)
+			if err == nil {
+				errs = nil
+				break
+			}
+			errs = append(errs, err.Error())
+		}
+		if errs != nil {
+			return fmt.Errorf("%s: matches no schema: %s", path, strings.Join(errs, "; "))
+		}
+	}
+
+	if schema.Type != nil && !slices.Contains(types(schema.Type), jsonType(value)) {
+		// Integers are numbers too.
+		if jsonType(value) != "integer" || !slices.Contains(types(schema.Type), "number") {
+			return fmt.Errorf("%s: %s is not of type %v", path, jsonType(value), schema.Type)
+		}
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, schema.Enum)
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, property := range value {
+			sub, ok := schema.Properties[name]
+			switch {
+			case ok:
+			case schema.AdditionalProperties != nil:
+				sub = schema.AdditionalProperties
+			case schema.Closed:
+				return fmt.Errorf("%s: property %q is not documented", path, name)
+			default:
+				continue
+			}
+			if err := d.validate(sub, property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && value < *schema.Minimum {
+			return fmt.Errorf("%s: %v is less than %v", path, value, *schema.Minimum)
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			return fmt.Errorf("%s: %v is more than %v", path, value, *schema.Maximum)
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return fmt.Errorf("%s: string is shorter than %d characters", path, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return fmt.Errorf("%s: string is longer than %d characters", path, *schema.MaxLength)
+		}
+	case []any:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			return fmt.Errorf("%s: array has fewer than %d items", path, *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			return fmt.Errorf("%s: array has more than %d items", path, *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range value {
+				if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func types(t any) []string {
+	if list, ok := t.([]string); ok {
+		return list
+	}
+	return []string{t.(string)}
+}
+
+// jsonType returns the JSON Schema type of a decoded value, "integer" for whole numbers.
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
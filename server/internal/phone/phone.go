@@ -0,0 +1,80 @@
+package phone
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+const (
+	// DefaultRegion is the region used to parse numbers entered without a
+	// country calling code when PHONE_DEFAULT_REGION is not set.
+	DefaultRegion = "US"
+)
+
+var (
+	ErrMissing     = errors.New("phone number is required")
+	ErrInvalid     = errors.New("invalid phone number")
+	ErrPremiumRate = errors.New("premium-rate phone numbers are not supported")
+)
+
+// Region returns the default region used when parsing user input.
+func Region() string {
+	region := strings.ToUpper(strings.TrimSpace(os.Getenv("PHONE_DEFAULT_REGION")))
+	if region == "" {
+		return DefaultRegion
+	}
+	return region
+}
+
+// Normalize parses a user-entered phone number and returns it in E.164 format
+// (e.g. +12065550100). Numbers without a country calling code are parsed using
+// the given region, falling back to Region() when region is empty.
+func Normalize(input, region string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", ErrMissing
+	}
+
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region == "" {
+		region = Region()
+	}
+
+	num, err := phonenumbers.Parse(input, region)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	if !phonenumbers.IsValidNumber(num) {
+		return "", ErrInvalid
+	}
+
+	if phonenumbers.GetNumberType(num) == phonenumbers.PREMIUM_RATE {
+		return "", ErrPremiumRate
+	}
+
+	return phonenumbers.Format(num, phonenumbers.E164), nil
+}
+
+// Mask hides all but the country calling code and last four digits of an E.164 number, e.g.
+// +12065550100 becomes +1••••••0100, so a number can be recognised without being disclosed. Anything that
+// doesn't parse as a number is masked up to its last four characters.
+func Mask(number string) string {
+	num, err := phonenumbers.Parse(number, "")
+	if err != nil {
+		return maskTail("", number)
+	}
+	prefix := "+" + strconv.Itoa(int(num.GetCountryCode()))
+	return maskTail(prefix, phonenumbers.GetNationalSignificantNumber(num))
+}
+
+// maskTail returns prefix followed by s with all but its last four characters replaced by dots.
+func maskTail(prefix, s string) string {
+	runes := []rune(s)
+	shown := min(4, len(runes))
+	return prefix + strings.Repeat("•", len(runes)-shown) + string(runes[len(runes)-shown:])
+}
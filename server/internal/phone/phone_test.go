@@ -0,0 +1,126 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		region      string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "US number with formatting",
+			input:    "(206) 555-0100",
+			region:   "US",
+			expected: "+12065550100",
+		},
+		{
+			name:     "US number with dashes",
+			input:    "619-573-3405",
+			region:   "US",
+			expected: "+16195733405",
+		},
+		{
+			name:     "Already E.164",
+			input:    "+14155552671",
+			region:   "US",
+			expected: "+14155552671",
+		},
+		{
+			name:     "Empty region falls back to default",
+			input:    "415 555 2671",
+			region:   "",
+			expected: "+14155552671",
+		},
+		{
+			name:     "International number ignores default region",
+			input:    "+33 6 12 34 56 78",
+			region:   "US",
+			expected: "+33612345678",
+		},
+		{
+			name:     "National number parsed with another region",
+			input:    "06 12 34 56 78",
+			region:   "fr",
+			expected: "+33612345678",
+		},
+		{
+			name:        "Empty input",
+			input:       "   ",
+			region:      "US",
+			expectedErr: ErrMissing,
+		},
+		{
+			name:        "Not a number",
+			input:       "call me maybe",
+			region:      "US",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "Invalid area code",
+			input:       "1234567890",
+			region:      "US",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "Too short",
+			input:       "555-0100",
+			region:      "US",
+			expectedErr: ErrInvalid,
+		},
+		{
+			name:        "Premium rate number",
+			input:       "+1 900 555 0123",
+			region:      "US",
+			expectedErr: ErrPremiumRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Normalize(tt.input, tt.region)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	t.Setenv("PHONE_DEFAULT_REGION", "")
+	assert.Equal(t, DefaultRegion, Region())
+
+	t.Setenv("PHONE_DEFAULT_REGION", " ca ")
+	assert.Equal(t, "CA", Region())
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   string
+		expected string
+	}{
+		{name: "US number", number: "+12065550100", expected: "+1••••••0100"},
+		{name: "UK number", number: "+447911123456", expected: "+44••••••3456"},
+		{name: "Not a number", number: "0100", expected: "0100"},
+		{name: "Unparseable", number: "garbage-number", expected: "••••••••••mber"},
+		{name: "Empty", number: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Mask(tt.number))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// maxPeekedBody is how much of a JSON body Account reads looking for an email. Bodies are small, so one
+// longer than this is only limited by IP.
+const maxPeekedBody = 64 << 10
+
+// ParsePrefixes parses a comma separated list of addresses and CIDR ranges, such as
+// "10.0.0.0/8, 2001:db8::/32, 192.0.2.1".
+func ParsePrefixes(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		if prefix.Addr().Is4In6() {
+			return nil, fmt.Errorf("%s: write IPv4 ranges in IPv4 form", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the address of the client r is from. That's the address it came from, unless that's a
+// trusted proxy: then it's the last address in X-Forwarded-For that isn't a trusted proxy too, since clients
+// can put anything before the addresses the proxies added. It returns the zero Addr if there's no address.
+func (l *Limiter) ClientIP(r *http.Request) netip.Addr {
+	addr := remoteAddr(r.RemoteAddr)
+	if !l.trusted(addr) {
+		return addr
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			// The proxies write addresses, so the client wrote this one.
+			break
+		}
+		addr = hop.Unmap()
+		if !l.trusted(addr) {
+			break
+		}
+	}
+	return addr
+}
+
+func (l *Limiter) trusted(addr netip.Addr) bool {
+	for _, prefix := range l.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteAddr parses a request's RemoteAddr, which is an address and port.
+func remoteAddr(s string) netip.Addr {
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap()
+	}
+	addr, _ := netip.ParseAddr(s)
+	return addr.Unmap()
+}
+
+// clientNetwork returns the network a client's requests are counted for: its address for IPv4, and its /64
+// for IPv6, since one host is usually given a whole /64.
+func clientNetwork(addr netip.Addr) string {
+	if addr.Is6() {
+		prefix, _ := addr.Prefix(64)
+		return prefix.String()
+	}
+	return addr.String()
+}
+
+// Account returns the email r is for, lowercased, from its email query parameter or the email member of its
+// JSON body, or "" if it has neither. The body is left for the handler to read.
+func Account(r *http.Request) string {
+	if email := r.URL.Query().Get("email"); email != "" {
+		return normalizeEmail(email)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Body == nil || r.Body == http.NoBody || (mediaType != "" && mediaType != "application/json") {
+		return ""
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, maxPeekedBody))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(peeked, &body) != nil {
+		return ""
+	}
+	return normalizeEmail(body.Email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// readCloser reads from a Reader and closes a Closer, so a request body can be put back after reading the
+// start of it.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in memory. Each instance of the API using one limits clients on its own, so it's
+// for running a single instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	sweptAt time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	fullAt time.Time
+}
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]memoryBucket{}}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, wait := limit.take(s.buckets[key].bucket, now)
+	s.buckets[key] = memoryBucket{bucket: b, fullAt: limit.fullAt(b)}
+	return wait, nil
+}
+
+// sweep deletes the buckets that have refilled, which are no different from having none.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	s.sweptAt = now
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+// PostgresStore keeps buckets in the database, so every instance of the API using it shares them.
+type PostgresStore struct {
+	store *db.Store
+
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+// NewPostgresStore returns a store keeping buckets through store.
+func NewPostgresStore(store *db.Store) *PostgresStore {
+	return &PostgresStore{store: store}
+}
+
+// Take implements Store.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error) {
+	full := db.RateLimitBucket{Tokens: float64(limit.Requests), UpdatedAt: now, ExpiresAt: now}
+
+	var wait time.Duration
+	err := s.store.UpdateRateLimitBucket(ctx, key, full, func(b db.RateLimitBucket) db.RateLimitBucket {
+		var taken bucket
+		taken, wait = limit.take(bucket{Tokens: b.Tokens, UpdatedAt: b.UpdatedAt}, now)
+		return db.RateLimitBucket{Tokens: taken.Tokens, UpdatedAt: taken.UpdatedAt, ExpiresAt: limit.fullAt(taken)}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if s.sweepDue(now) {
+		if _, err := s.store.DeleteExpiredRateLimitBuckets(ctx, now); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete expired rate limit buckets", "error", err)
+		}
+	}
+	return wait, nil
+}
+
+// sweepDue reports whether it's time to delete the buckets that have refilled, and if it is, counts them as
+// deleted so other requests don't delete them too.
+func (s *PostgresStore) sweepDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return false
+	}
+	s.sweptAt = now
+	return true
+}
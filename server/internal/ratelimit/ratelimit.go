@@ -0,0 +1,158 @@
+// Package ratelimit limits how often clients can call the API, with a token bucket for each client address
+// and account a route is limited by. Buckets are kept in memory for a single instance, or in Postgres so
+// every instance of the API shares them.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+const (
+	// storeTimeout bounds how long a request waits for its buckets, so a slow store doesn't hold up the API.
+	storeTimeout = 2 * time.Second
+	// sweepInterval is how often stores delete the buckets that have refilled.
+	sweepInterval = time.Minute
+)
+
+// Limit allows Requests every Per on average, in bursts of up to Requests. The zero Limit allows everything.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// IsZero reports whether the limit allows everything.
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// bucket is a token bucket's state.
+type bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// interval is how long the bucket takes to refill by one token.
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// take refills b for the time since it was updated, and takes a token from it if it has one. It returns the
+// bucket afterwards, and how long until it has a token if it had none. The zero bucket is full.
+func (l Limit) take(b bucket, now time.Time) (bucket, time.Duration) {
+	tokens := float64(l.Requests)
+	if !b.UpdatedAt.IsZero() {
+		// Instances sharing buckets can disagree about the time, so a bucket may have been updated in what
+		// looks like the future. It refills from then.
+		if now.Before(b.UpdatedAt) {
+			now = b.UpdatedAt
+		}
+		tokens = min(tokens, b.Tokens+float64(now.Sub(b.UpdatedAt))/float64(l.interval()))
+	}
+
+	if tokens >= 1 {
+		return bucket{Tokens: tokens - 1, UpdatedAt: now}, 0
+	}
+	return bucket{Tokens: tokens, UpdatedAt: now}, time.Duration((1 - tokens) * float64(l.interval()))
+}
+
+// fullAt returns when b will have refilled.
+func (l Limit) fullAt(b bucket) time.Time {
+	return b.UpdatedAt.Add(time.Duration((float64(l.Requests) - b.Tokens) * float64(l.interval())))
+}
+
+// Store keeps token buckets by key.
+type Store interface {
+	// Take takes a token from the bucket with the given key, which refills at limit. It returns 0 if it took
+	// one, and how long until the bucket has one if it's empty.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error)
+}
+
+// Policy is how a route is limited.
+type Policy struct {
+	// Name identifies the policy in bucket keys. Routes with the same name share buckets, so a route and its
+	// deprecated alias count against the same limits.
+	Name string
+	// IP limits requests from each client address. IPv6 clients are limited by their /64 network.
+	IP Limit
+	// Account limits requests for each account, identified by the email in the request's query or JSON
+	// body. Requests without one are only limited by IP.
+	Account Limit
+}
+
+// IsZero reports whether the policy allows everything.
+func (p Policy) IsZero() bool {
+	return p.IP.IsZero() && p.Account.IsZero()
+}
+
+// Limiter limits requests by policy.
+type Limiter struct {
+	store          Store
+	trustedProxies []netip.Prefix
+	now            func() time.Time
+}
+
+// New returns a limiter keeping buckets in store. Requests from trustedProxies are limited by the client
+// address in their X-Forwarded-For header.
+func New(store Store, trustedProxies []netip.Prefix) *Limiter {
+	return &Limiter{
+		store:          store,
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+	}
+}
+
+// FromEnv returns a limiter keeping buckets where RATE_LIMIT_STORE says: memory (the default) for a single
+// instance, or postgres to share them through store between instances. It returns nil when RATE_LIMIT_STORE
+// is off, in which case requests aren't limited. TRUSTED_PROXIES lists the addresses and CIDR ranges of the
+// proxies whose X-Forwarded-For header is believed.
+func FromEnv(store *db.Store) (*Limiter, error) {
+	trustedProxies, err := ParsePrefixes(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	switch value := os.Getenv("RATE_LIMIT_STORE"); value {
+	case "", "memory":
+		return New(NewMemoryStore(), trustedProxies), nil
+	case "postgres":
+		return New(NewPostgresStore(store), trustedProxies), nil
+	case "off":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid RATE_LIMIT_STORE %q: must be memory, postgres or off", value)
+	}
+}
+
+// Allow takes a token for r from each bucket policy limits it by. It returns 0 if r is allowed, and how long
+// until it would be if a bucket is empty. Buckets are only taken from until one is empty.
+func (l *Limiter) Allow(r *http.Request, policy Policy) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), storeTimeout)
+	defer cancel()
+
+	now := l.now()
+	if !policy.IP.IsZero() {
+		key := "ip:" + policy.Name + ":" + clientNetwork(l.ClientIP(r))
+		wait, err := l.store.Take(ctx, key, policy.IP, now)
+		if err != nil || wait > 0 {
+			return wait, err
+		}
+	}
+	if !policy.Account.IsZero() {
+		if account := Account(r); account != "" {
+			// Emails are hashed so the store doesn't keep who has been using the API.
+			sum := sha256.Sum256([]byte(account))
+			key := "account:" + policy.Name + ":" + hex.EncodeToString(sum[:16])
+			return l.store.Take(ctx, key, policy.Account, now)
+		}
+	}
+	return 0, nil
+}
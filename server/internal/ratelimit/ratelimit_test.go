@@ -0,0 +1,271 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimit_take(t *testing.T) {
+	limit := Limit{Requests: 4, Per: time.Minute}
+	start := time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		bucket         bucket
+		now            time.Time
+		expectedBucket bucket
+		expectedWait   time.Duration
+	}{
+		{
+			name:           "New bucket",
+			now:            start,
+			expectedBucket: bucket{Tokens: 3, UpdatedAt: start},
+		},
+		{
+			name:           "Refilled",
+			bucket:         bucket{Tokens: 0, UpdatedAt: start},
+			now:            start.Add(30 * time.Second),
+			expectedBucket: bucket{Tokens: 1, UpdatedAt: start.Add(30 * time.Second)},
+		},
+		{
+			name:           "Refills up to the limit",
+			bucket:         bucket{Tokens: 1, UpdatedAt: start},
+			now:            start.Add(time.Hour),
+			expectedBucket: bucket{Tokens: 3, UpdatedAt: start.Add(time.Hour)},
+		},
+		{
+			name:           "Empty",
+			bucket:         bucket{Tokens: 0.5, UpdatedAt: start},
+			now:            start,
+			expectedBucket: bucket{Tokens: 0.5, UpdatedAt: start},
+			expectedWait:   7500 * time.Millisecond,
+		},
+		{
+			name:           "Updated in the future",
+			bucket:         bucket{Tokens: 0, UpdatedAt: start.Add(time.Second)},
+			now:            start,
+			expectedBucket: bucket{Tokens: 0, UpdatedAt: start.Add(time.Second)},
+			expectedWait:   15 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken, wait := limit.take(tt.bucket, tt.now)
+			assert.Equal(t, tt.expectedBucket, taken)
+			assert.Equal(t, tt.expectedWait, wait)
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Per: time.Minute}
+	start := time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	take := func(key string, now time.Time) time.Duration {
+		wait, err := store.Take(ctx, key, limit, now)
+		require.NoError(t, err)
+		return wait
+	}
+
+	assert.Zero(t, take("a", start))
+	assert.Zero(t, take("a", start))
+	assert.Equal(t, 30*time.Second, take("a", start), "the burst is used up")
+	assert.Zero(t, take("b", start), "buckets are kept by key")
+	assert.Zero(t, take("a", start.Add(30*time.Second)))
+
+	take("c", start.Add(5*time.Minute))
+	assert.Len(t, store.buckets, 1, "buckets that have refilled are deleted")
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes(" 10.0.0.0/8, 2001:db8::/32,192.0.2.1,, 192.0.2.77/24")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("192.0.2.0/24"),
+	}, prefixes)
+
+	_, err = ParsePrefixes("10.0.0.0/8, proxy")
+	assert.Error(t, err)
+}
+
+func TestLimiter_ClientIP(t *testing.T) {
+	limiter := New(NewMemoryStore(), []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	})
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  []string
+		expectedIP    string
+		expectedLimit string
+	}{
+		{
+			name:          "Direct",
+			remoteAddr:    "198.51.100.7:51234",
+			expectedIP:    "198.51.100.7",
+			expectedLimit: "198.51.100.7",
+		},
+		{
+			name:          "Forwarded by an untrusted client",
+			remoteAddr:    "198.51.100.7:51234",
+			forwardedFor:  []string{"203.0.113.9"},
+			expectedIP:    "198.51.100.7",
+			expectedLimit: "198.51.100.7",
+		},
+		{
+			name:          "Trusted proxy",
+			remoteAddr:    "10.1.2.3:51234",
+			forwardedFor:  []string{"203.0.113.9"},
+			expectedIP:    "203.0.113.9",
+			expectedLimit: "203.0.113.9",
+		},
+		{
+			name:          "Chain of trusted proxies",
+			remoteAddr:    "10.1.2.3:51234",
+			forwardedFor:  []string{"192.0.2.66, 203.0.113.9", "10.4.5.6"},
+			expectedIP:    "203.0.113.9",
+			expectedLimit: "203.0.113.9",
+		},
+		{
+			name:          "Garbage from the client",
+			remoteAddr:    "10.1.2.3:51234",
+			forwardedFor:  []string{"not an address, 203.0.113.9"},
+			expectedIP:    "203.0.113.9",
+			expectedLimit: "203.0.113.9",
+		},
+		{
+			name:          "IPv6",
+			remoteAddr:    "[2001:db8::1]:51234",
+			forwardedFor:  []string{"2600:1700:abcd:1234:5678::9"},
+			expectedIP:    "2600:1700:abcd:1234:5678::9",
+			expectedLimit: "2600:1700:abcd:1234::/64",
+		},
+		{
+			name:          "IPv4 mapped to IPv6",
+			remoteAddr:    "[::ffff:198.51.100.7]:51234",
+			expectedIP:    "198.51.100.7",
+			expectedLimit: "198.51.100.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/resorts", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			ip := limiter.ClientIP(req)
+			assert.Equal(t, tt.expectedIP, ip.String())
+			assert.Equal(t, tt.expectedLimit, clientNetwork(ip))
+		})
+	}
+}
+
+func TestAccount(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		contentType     string
+		body            string
+		expectedAccount string
+	}{
+		{
+			name:            "Query",
+			target:          "/api/v1/me/alerts?email=Skier@Example.com",
+			expectedAccount: "skier@example.com",
+		},
+		{
+			name:            "JSON body",
+			target:          "/api/v1/alerts",
+			contentType:     "application/json; charset=utf-8",
+			body:            `{"email":" skier@example.com ","phone":"+12065550100"}`,
+			expectedAccount: "skier@example.com",
+		},
+		{
+			name:   "Body without a content type",
+			target: "/api/v1/alerts",
+			body:   `{"email":"skier@example.com"}`,
+			// Clients sending JSON don't always say so.
+			expectedAccount: "skier@example.com",
+		},
+		{
+			name:        "Form",
+			target:      "/api/v1/sms/inbound",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "From=%2B12065550100&Body=STOP",
+		},
+		{
+			name:   "Malformed JSON",
+			target: "/api/v1/alerts",
+			body:   `{"email":`,
+		},
+		{
+			name:   "No body",
+			target: "/api/v1/me/alerts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			assert.Equal(t, tt.expectedAccount, Account(req))
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body), "the body is left for the handler")
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := New(NewMemoryStore(), nil)
+	now := time.Date(2026, 1, 10, 8, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	policy := Policy{
+		Name:    "alerts",
+		IP:      Limit{Requests: 3, Per: time.Hour},
+		Account: Limit{Requests: 1, Per: time.Hour},
+	}
+	allow := func(policy Policy, remoteAddr, email string) time.Duration {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/alerts?email="+email, nil)
+		req.RemoteAddr = remoteAddr
+		wait, err := limiter.Allow(req, policy)
+		require.NoError(t, err)
+		return wait
+	}
+
+	assert.Zero(t, allow(policy, "198.51.100.7:1234", "a@example.com"))
+	assert.Equal(t, time.Hour, allow(policy, "198.51.100.8:1234", "a@example.com"), "the account is limited")
+	assert.Zero(t, allow(policy, "198.51.100.7:1234", "b@example.com"))
+	assert.Zero(t, allow(policy, "198.51.100.7:1234", "c@example.com"))
+	assert.Equal(t, 20*time.Minute, allow(policy, "198.51.100.7:1234", "d@example.com"), "the address is limited")
+
+	other := policy
+	other.Name = "contact"
+	assert.Zero(t, allow(other, "198.51.100.7:1234", "d@example.com"), "policies have their own buckets")
+	assert.Zero(t, allow(Policy{}, "198.51.100.7:1234", "a@example.com"), "the zero policy allows everything")
+}
@@ -0,0 +1,122 @@
+// Package tracing configures the OpenTelemetry traces of the API and the workers. Spans are started around
+// each API request, store call, forecast fetch and notification send, so that a request or a forecaster pass
+// can be followed end to end, and are exported to an OTLP collector, to stdout or nowhere.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of every span the service starts.
+const instrumentationName = "github.com/MattSilvaa/powhunter"
+
+// shutdownTimeout bounds flushing the spans not exported yet when the service exits.
+const shutdownTimeout = 5 * time.Second
+
+// Exporters that traces can be sent to.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Propagator carries trace context and baggage in W3C headers, between clients and the API and from the
+// services to the providers they call.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Tracer returns the tracer spans are started with. It uses the global tracer provider, so spans are
+// dropped until Setup is called, and tests can record them by setting a provider of their own.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span named name as a child of the span in ctx, if there is one.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End ends span, marking it failed with err if err isn't nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Setup makes a tracer provider exporting to the exporter named by OTEL_TRACES_EXPORTER (otlp, stdout or
+// none, the default) the global one, and propagates trace context in W3C headers. The OTLP exporter sends
+// traces over HTTP and is configured by the standard OTEL_EXPORTER_OTLP_* variables. The returned function
+// flushes the spans not exported yet and must be called before the service exits.
+func Setup(ctx context.Context, service string) (func(), error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	exporter, err := newExporter(ctx, os.Getenv("OTEL_TRACES_EXPORTER"))
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func() {}, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(service),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	slog.InfoContext(ctx, "Exporting traces", "exporter", strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")))
+
+	return func() {
+		// The context the service ran in is usually done by now, so flushing gets one of its own.
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}, nil
+}
+
+// newExporter returns the exporter named name, or nil if traces aren't exported.
+func newExporter(ctx context.Context, name string) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(name) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout, "console":
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q: use %s, %s or %s", name, ExporterOTLP, ExporterStdout,
+			ExporterNone)
+	}
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name         string
+		exporter     string
+		wantExporter bool
+		wantErr      bool
+	}{
+		{name: "Unset", exporter: ""},
+		{name: "None", exporter: "none"},
+		{name: "Stdout", exporter: "stdout", wantExporter: true},
+		{name: "Console alias", exporter: "console", wantExporter: true},
+		{name: "OTLP", exporter: "OTLP", wantExporter: true},
+		{name: "Unknown", exporter: "zipkin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newExporter(context.Background(), tt.exporter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.wantExporter {
+				assert.Nil(t, exporter)
+				return
+			}
+			require.NotNil(t, exporter)
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "ok")
+	End(ok, nil)
+	_, failed := tracer.Start(context.Background(), "failed")
+	End(failed, errors.New("connection refused"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "connection refused", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,69 @@
+// Package units converts forecast measurements between the canonical units they are stored in and the
+// measurement system a user prefers.
+//
+// Snow amounts are fetched, stored and compared in inches, and temperatures in degrees Fahrenheit. Only
+// convert when presenting a value to a user.
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// System is a measurement system users can receive alerts in.
+type System string
+
+const (
+	Imperial System = "imperial"
+	Metric   System = "metric"
+)
+
+// DefaultSystem is used for users who haven't chosen a system.
+const DefaultSystem = Imperial
+
+const centimetersPerInch = 2.54
+
+// ParseSystem parses a measurement system name. An empty name is the default system.
+func ParseSystem(s string) (System, error) {
+	switch System(strings.ToLower(strings.TrimSpace(s))) {
+	case "":
+		return DefaultSystem, nil
+	case Imperial:
+		return Imperial, nil
+	case Metric:
+		return Metric, nil
+	default:
+		return "", fmt.Errorf("unknown measurement system %q", s)
+	}
+}
+
+// Snow converts a snow amount in inches to the system's unit.
+func (s System) Snow(inches float64) float64 {
+	if s == Metric {
+		return InchesToCentimeters(inches)
+	}
+	return inches
+}
+
+// Temperature converts a temperature in degrees Fahrenheit to the system's unit.
+func (s System) Temperature(fahrenheit float64) float64 {
+	if s == Metric {
+		return FahrenheitToCelsius(fahrenheit)
+	}
+	return fahrenheit
+}
+
+// InchesToCentimeters converts inches to centimeters.
+func InchesToCentimeters(inches float64) float64 {
+	return inches * centimetersPerInch
+}
+
+// CentimetersToInches converts centimeters to inches.
+func CentimetersToInches(centimeters float64) float64 {
+	return centimeters / centimetersPerInch
+}
+
+// FahrenheitToCelsius converts degrees Fahrenheit to degrees Celsius.
+func FahrenheitToCelsius(fahrenheit float64) float64 {
+	return (fahrenheit - 32) * 5 / 9
+}
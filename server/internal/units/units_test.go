@@ -0,0 +1,44 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSystem(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    System
+		expectError bool
+	}{
+		{input: "", expected: Imperial},
+		{input: "imperial", expected: Imperial},
+		{input: " Metric ", expected: Metric},
+		{input: "nautical", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSystem(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestSystem_Snow(t *testing.T) {
+	assert.Equal(t, 10.0, Imperial.Snow(10))
+	assert.InDelta(t, 25.4, Metric.Snow(10), 1e-9)
+	assert.InDelta(t, 10.0, CentimetersToInches(Metric.Snow(10)), 1e-9)
+}
+
+func TestSystem_Temperature(t *testing.T) {
+	assert.Equal(t, 32.0, Imperial.Temperature(32))
+	assert.InDelta(t, 0.0, Metric.Temperature(32), 1e-9)
+	assert.InDelta(t, -10.0, Metric.Temperature(14), 1e-9)
+}
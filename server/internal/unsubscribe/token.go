@@ -0,0 +1,180 @@
+// Package unsubscribe signs and verifies the tokens in one-click unsubscribe links.
+//
+// A token names a user and optionally one resort, the channel the link was sent on and when the link expires.
+// Tokens are HMAC-SHA256 signed, so links work without logging in but can't be forged or edited, and they are
+// compact enough for SMS: 46 characters for every alert, 67 for a single resort.
+package unsubscribe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+const (
+	tokenVersion = 1
+	// macSize is the length of the truncated HMAC-SHA256 in a token.
+	macSize = 12
+	// headerSize is the version, channel and expiry before the user UUID.
+	headerSize = 1 + 1 + 4
+	// minKeySize is the shortest signing key accepted, the size of the HMAC-SHA256 output.
+	minKeySize = 32
+
+	// DefaultTTL is how long links stay valid. Alerts can sit in an inbox for a while before someone acts
+	// on them, so this is generous.
+	DefaultTTL = 60 * 24 * time.Hour
+
+	// PathPrefix is the path unsubscribe links are served under.
+	PathPrefix = "/u/"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed or have a bad signature.
+	ErrInvalidToken = errors.New("invalid unsubscribe token")
+	// ErrExpiredToken is returned for correctly signed tokens past their expiry.
+	ErrExpiredToken = errors.New("unsubscribe token expired")
+)
+
+// channelCodes encodes the channel a link was sent on in a single byte. Zero is an unknown channel.
+var channelCodes = map[string]byte{
+	db.ChannelSMS:     1,
+	db.ChannelEmail:   2,
+	db.ChannelPush:    3,
+	db.ChannelWebPush: 4,
+}
+
+// Claims are the contents of a token.
+type Claims struct {
+	UserUUID uuid.UUID
+	// ResortUUID is the resort whose alert the link removes, or uuid.Nil to remove every alert.
+	ResortUUID uuid.UUID
+	Channel    string
+	ExpiresAt  time.Time
+}
+
+// All reports whether the token removes all of the user's alerts.
+func (c Claims) All() bool {
+	return c.ResortUUID == uuid.Nil
+}
+
+// Signer signs and verifies tokens.
+type Signer struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewSigner returns a signer using key, which must be at least 32 bytes, for links valid for ttl.
+func NewSigner(key []byte, ttl time.Duration) (*Signer, error) {
+	if len(key) < minKeySize {
+		return nil, fmt.Errorf("unsubscribe signing key must be at least %d bytes", minKeySize)
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Signer{
+		key: key,
+		ttl: ttl,
+		now: time.Now,
+	}, nil
+}
+
+// SignerFromEnv returns a signer keyed by UNSUBSCRIBE_SECRET, with links valid for UNSUBSCRIBE_LINK_TTL
+// (default 60 days). It returns nil when no secret is configured, in which case messages are sent without
+// unsubscribe links.
+func SignerFromEnv() (*Signer, error) {
+	secret := os.Getenv("UNSUBSCRIBE_SECRET")
+	if secret == "" {
+		return nil, nil
+	}
+
+	ttl := DefaultTTL
+	if value := os.Getenv("UNSUBSCRIBE_LINK_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			slog.Warn("Ignoring invalid UNSUBSCRIBE_LINK_TTL", "value", value)
+		} else {
+			ttl = parsed
+		}
+	}
+
+	return NewSigner([]byte(secret), ttl)
+}
+
+// Sign returns a token for the claims. A zero ExpiresAt expires after the signer's TTL.
+func (s *Signer) Sign(claims Claims) string {
+	expiresAt := claims.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = s.now().Add(s.ttl)
+	}
+
+	payload := make([]byte, headerSize, headerSize+2*16+macSize)
+	payload[0] = tokenVersion
+	payload[1] = channelCodes[claims.Channel]
+	binary.BigEndian.PutUint32(payload[2:headerSize], uint32(expiresAt.Unix()))
+	payload = append(payload, claims.UserUUID[:]...)
+	if !claims.All() {
+		payload = append(payload, claims.ResortUUID[:]...)
+	}
+	payload = append(payload, s.mac(payload)...)
+
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// Verify checks a token's signature and expiry and returns its claims.
+func (s *Signer) Verify(token string) (Claims, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	bodySize := len(raw) - macSize
+	if bodySize != headerSize+16 && bodySize != headerSize+2*16 {
+		return Claims{}, ErrInvalidToken
+	}
+	body, mac := raw[:bodySize], raw[bodySize:]
+	if !hmac.Equal(mac, s.mac(body)) || body[0] != tokenVersion {
+		return Claims{}, ErrInvalidToken
+	}
+
+	claims := Claims{
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint32(body[2:headerSize])), 0).UTC(),
+	}
+	for channel, code := range channelCodes {
+		if code == body[1] {
+			claims.Channel = channel
+		}
+	}
+	copy(claims.UserUUID[:], body[headerSize:headerSize+16])
+	if bodySize > headerSize+16 {
+		copy(claims.ResortUUID[:], body[headerSize+16:])
+	}
+
+	if !s.now().Before(claims.ExpiresAt) {
+		return claims, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+// URL returns the unsubscribe link for the claims, served by the API at baseURL.
+func (s *Signer) URL(baseURL string, claims Claims) string {
+	return baseURL + PathPrefix + s.Sign(claims)
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
@@ -0,0 +1,119 @@
+package unsubscribe
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MattSilvaa/powhunter/internal/db"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestSigner(t *testing.T, now time.Time) *Signer {
+	t.Helper()
+	signer, err := NewSigner(testKey, 24*time.Hour)
+	require.NoError(t, err)
+	signer.now = func() time.Time { return now }
+	return signer
+}
+
+func TestSigner_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 12, 18, 9, 0, 0, 0, time.UTC)
+	signer := newTestSigner(t, now)
+	userUUID := uuid.MustParse("6f1c2b0e-8a0a-4a57-9f0a-3c4d5e6f7a8b")
+	resortUUID := uuid.MustParse("1b2c3d4e-5f60-4172-8394-a5b6c7d8e9f0")
+
+	tests := []struct {
+		name           string
+		claims         Claims
+		expectedLength int
+	}{
+		{
+			name:           "Single resort",
+			claims:         Claims{UserUUID: userUUID, ResortUUID: resortUUID, Channel: db.ChannelSMS},
+			expectedLength: 67,
+		},
+		{
+			name:           "All alerts",
+			claims:         Claims{UserUUID: userUUID, Channel: db.ChannelEmail},
+			expectedLength: 46,
+		},
+		{
+			name:           "Unknown channel",
+			claims:         Claims{UserUUID: userUUID},
+			expectedLength: 46,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signer.Sign(tt.claims)
+			assert.Len(t, token, tt.expectedLength)
+
+			claims, err := signer.Verify(token)
+			require.NoError(t, err)
+
+			expected := tt.claims
+			expected.ExpiresAt = now.Add(24 * time.Hour)
+			assert.Equal(t, expected, claims)
+		})
+	}
+}
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Date(2025, 12, 18, 9, 0, 0, 0, time.UTC)
+	signer := newTestSigner(t, now)
+	claims := Claims{UserUUID: uuid.New(), ResortUUID: uuid.New(), Channel: db.ChannelSMS}
+	token := signer.Sign(claims)
+
+	otherSigner, err := NewSigner([]byte(strings.Repeat("x", 32)), time.Hour)
+	require.NoError(t, err)
+
+	tampered := []byte(token)
+	if tampered[10] == 'A' {
+		tampered[10] = 'B'
+	} else {
+		tampered[10] = 'A'
+	}
+
+	tests := []struct {
+		name          string
+		signer        *Signer
+		token         string
+		expectedError error
+	}{
+		{name: "Valid", signer: signer, token: token},
+		{name: "Tampered", signer: signer, token: string(tampered), expectedError: ErrInvalidToken},
+		{name: "Truncated", signer: signer, token: token[:40], expectedError: ErrInvalidToken},
+		{name: "Not base64", signer: signer, token: "not a token!", expectedError: ErrInvalidToken},
+		{name: "Other key", signer: otherSigner, token: token, expectedError: ErrInvalidToken},
+		{name: "Expired", signer: newTestSigner(t, now.Add(25*time.Hour)), token: token, expectedError: ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.Verify(tt.token)
+			if tt.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestNewSigner_ShortKey(t *testing.T) {
+	_, err := NewSigner([]byte("too short"), time.Hour)
+	assert.Error(t, err)
+}
+
+func TestSigner_URL(t *testing.T) {
+	signer := newTestSigner(t, time.Now())
+	url := signer.URL("https://powhunter.app", Claims{UserUUID: uuid.New()})
+	assert.True(t, strings.HasPrefix(url, "https://powhunter.app/u/"))
+}
@@ -0,0 +1,62 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ErrTrailingData is returned by DecodeJSON when the body has more after the first JSON value.
+var ErrTrailingData = errors.New("body has data after the JSON value")
+
+// DecodeJSON decodes a JSON body into the struct v points to, which callers then check with Struct. Fields
+// v doesn't have and values that don't fit their field are reported as Errors with the Unknown and Type
+// rules. Other errors, such as malformed JSON, are returned as they are.
+func DecodeJSON(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return ErrTrailingData
+	}
+	return nil
+}
+
+// decodeError reports the field a decoding error is about, if it's about one.
+func decodeError(err error) error {
+	// The decoder doesn't have a type for unknown fields, only its message.
+	if field, ok := strings.CutPrefix(err.Error(), `json: unknown field "`); ok {
+		return Errors{{Field: strings.TrimSuffix(field, `"`), Rule: Unknown, Message: "is not a known field"}}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Errors{{Field: typeErr.Field, Rule: Type, Message: "must be " + jsonType(typeErr.Type)}}
+	}
+	return err
+}
+
+// jsonType describes the JSON values a Go type decodes from.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem()) + " or null"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,262 @@
+// Package validate checks request structs against rules declared in their fields' validate tags, such as
+//
+//	Email        string   `json:"email" validate:"email,max=254"`
+//	Days         int      `json:"days" validate:"min=1,max=10"`
+//	ResortsUuids []string `json:"resorts" validate:"max=50,dive,uuid"`
+//
+// and reports every field that breaks one, named as in the JSON the struct was decoded from.
+//
+// The rules are:
+//
+//   - required: the field is set. Strings must have something other than whitespace, and slices an element.
+//   - email: the field is a bare email address, such as "name@example.com".
+//   - uuid: the field is a UUID in its canonical form.
+//   - min=N, max=N: numbers are at least or at most N. Strings have at least or at most N characters, and
+//     slices N elements.
+//   - oneof=A B: the field is one of the values listed.
+//   - dive: the rules after it apply to each element of a slice.
+//
+// Rules other than required don't apply to empty strings or nil pointers, so optional fields are only
+// checked when they're given. Structs in fields are checked too. Embedded structs are named like other
+// fields, so request structs shouldn't embed them.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Rule names.
+const (
+	Required = "required"
+	Email    = "email"
+	UUID     = "uuid"
+	Min      = "min"
+	Max      = "max"
+	OneOf    = "oneof"
+	Dive     = "dive"
+
+	// Unknown is reported for fields the JSON has but the struct doesn't, by DecodeJSON.
+	Unknown = "unknown"
+	// Type is reported for fields whose JSON value doesn't fit the field, by DecodeJSON.
+	Type = "type"
+)
+
+// FieldError is a field that breaks a rule.
+type FieldError struct {
+	// Field is the field's path in the JSON, such as "email", "resortsUuids[2]" or "subscription.endpoint".
+	Field string `json:"field"`
+	// Rule is the name of the rule broken, which clients can branch on.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors are the fields of a struct that break its rules.
+type Errors []FieldError
+
+// Error implements error.
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+" "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Rule is a rule in a validate tag, with its parameter if it has one.
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Rules parses a field's validate tag. It panics on rules it doesn't know, since tags are fixed when the
+// program is built.
+func Rules(field reflect.StructField) []Rule {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return nil
+	}
+
+	var rules []Rule
+	for _, spec := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(spec, "=")
+		switch name {
+		case Required, Email, UUID, Dive:
+		case Min, Max:
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				panic(fmt.Sprintf("validate: %s of %s is not a number: %q", name, field.Name, param))
+			}
+		case OneOf:
+			if param == "" {
+				panic(fmt.Sprintf("validate: oneof of %s lists no values", field.Name))
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q on %s", name, field.Name))
+		}
+		rules = append(rules, Rule{Name: name, Param: param})
+	}
+	return rules
+}
+
+// Struct checks the struct v points to, returning Errors listing every field that breaks a rule, or nil.
+func Struct(v any) error {
+	var errs Errors
+	checkStruct(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func checkStruct(v reflect.Value, prefix string, errs *Errors) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		checkValue(v.Field(i), prefix+name, Rules(field), errs)
+	}
+}
+
+func checkValue(v reflect.Value, path string, rules []Rule, errs *Errors) {
+	for i, rule := range rules {
+		if rule.Name == Dive {
+			if v.Kind() == reflect.Slice {
+				for j := range v.Len() {
+					checkValue(v.Index(j), fmt.Sprintf("%s[%d]", path, j), rules[i+1:], errs)
+				}
+			}
+			return
+		}
+
+		if message := check(v, rule); message != "" {
+			*errs = append(*errs, FieldError{Field: path, Rule: rule.Name, Message: message})
+			// Rules after a broken one would mostly repeat it, such as max after email.
+			return
+		}
+	}
+
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		checkStruct(v, path+".", errs)
+	}
+}
+
+// check returns a message saying how v breaks rule, or "" if it doesn't.
+func check(v reflect.Value, rule Rule) string {
+	if rule.Name == Required {
+		if isEmpty(v) {
+			return "is required"
+		}
+		return ""
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String && v.String() == "" {
+		return ""
+	}
+
+	switch rule.Name {
+	case Email:
+		if !isEmail(v.String()) {
+			return "must be an email address"
+		}
+	case UUID:
+		if _, err := uuid.Parse(v.String()); err != nil || len(v.String()) != 36 {
+			return "must be a UUID"
+		}
+	case Min, Max:
+		return checkBound(v, rule)
+	case OneOf:
+		values := strings.Fields(rule.Param)
+		for _, value := range values {
+			if fmt.Sprint(v.Interface()) == value {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(values, ", ")
+	}
+	return ""
+}
+
+// checkBound checks v against a min or max rule.
+func checkBound(v reflect.Value, rule Rule) string {
+	bound, _ := strconv.ParseFloat(rule.Param, 64)
+
+	var value float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(v.String()))
+		unit = " characters"
+	case reflect.Slice:
+		value = float64(v.Len())
+		unit = " items"
+	default:
+		return ""
+	}
+
+	switch {
+	case rule.Name == Min && value < bound && unit == "":
+		return "must be at least " + rule.Param
+	case rule.Name == Min && value < bound:
+		return "must have at least " + rule.Param + unit
+	case rule.Name == Max && value > bound && unit == "":
+		return "must be at most " + rule.Param
+	case rule.Name == Max && value > bound:
+		return "must have at most " + rule.Param + unit
+	}
+	return ""
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+// isEmail reports whether s is a bare address, without a display name or angle brackets, whose domain
+// has a dot.
+func isEmail(s string) bool {
+	address, err := mail.ParseAddress(s)
+	if err != nil || address.Address != s {
+		return false
+	}
+	_, domain, _ := strings.Cut(address.Address, "@")
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
@@ -0,0 +1,203 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	Auth string `json:"auth" validate:"required"`
+}
+
+type testRequest struct {
+	Name    string   `json:"name" validate:"required,max=5"`
+	Email   string   `json:"email,omitempty" validate:"email,max=254"`
+	Days    int      `json:"days" validate:"min=1,max=10"`
+	Snow    *float64 `json:"snow" validate:"min=0"`
+	Units   string   `json:"units" validate:"oneof=imperial metric"`
+	Resorts []string `json:"resorts" validate:"max=2,dive,uuid"`
+	Keys    testKeys `json:"keys"`
+	Ignored string   `json:"-" validate:"required"`
+}
+
+func validRequest() testRequest {
+	return testRequest{
+		Name:    "Anna",
+		Email:   "anna@example.com",
+		Days:    3,
+		Resorts: []string{"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d"},
+		Keys:    testKeys{Auth: "secret"},
+	}
+}
+
+func TestStruct(t *testing.T) {
+	negative := -1.0
+
+	tests := []struct {
+		name           string
+		modify         func(*testRequest)
+		expectedErrors Errors
+	}{
+		{
+			name:   "Valid",
+			modify: func(*testRequest) {},
+		},
+		{
+			name: "Optional fields left out",
+			modify: func(r *testRequest) {
+				r.Email = ""
+				r.Units = ""
+				r.Resorts = nil
+			},
+		},
+		{
+			name:   "Required",
+			modify: func(r *testRequest) { r.Name = "  " },
+			expectedErrors: Errors{
+				{Field: "name", Rule: Required, Message: "is required"},
+			},
+		},
+		{
+			name:   "Email",
+			modify: func(r *testRequest) { r.Email = "Anna <anna@example.com>" },
+			expectedErrors: Errors{
+				{Field: "email", Rule: Email, Message: "must be an email address"},
+			},
+		},
+		{
+			name:   "Email without a domain",
+			modify: func(r *testRequest) { r.Email = "anna@localhost" },
+			expectedErrors: Errors{
+				{Field: "email", Rule: Email, Message: "must be an email address"},
+			},
+		},
+		{
+			name: "Ranges",
+			modify: func(r *testRequest) {
+				r.Days = 10000
+				r.Snow = &negative
+			},
+			expectedErrors: Errors{
+				{Field: "days", Rule: Max, Message: "must be at most 10"},
+				{Field: "snow", Rule: Min, Message: "must be at least 0"},
+			},
+		},
+		{
+			name:   "Length",
+			modify: func(r *testRequest) { r.Name = "Annabelle" },
+			expectedErrors: Errors{
+				{Field: "name", Rule: Max, Message: "must have at most 5 characters"},
+			},
+		},
+		{
+			name:   "One of",
+			modify: func(r *testRequest) { r.Units = "kelvin" },
+			expectedErrors: Errors{
+				{Field: "units", Rule: OneOf, Message: "must be one of imperial, metric"},
+			},
+		},
+		{
+			name:   "Elements",
+			modify: func(r *testRequest) { r.Resorts = []string{"9a8b7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d", "resort1"} },
+			expectedErrors: Errors{
+				{Field: "resorts[1]", Rule: UUID, Message: "must be a UUID"},
+			},
+		},
+		{
+			name: "List length",
+			modify: func(r *testRequest) {
+				r.Resorts = []string{"a", "b", "c"}
+			},
+			expectedErrors: Errors{
+				{Field: "resorts", Rule: Max, Message: "must have at most 2 items"},
+			},
+		},
+		{
+			name:   "Nested struct",
+			modify: func(r *testRequest) { r.Keys.Auth = "" },
+			expectedErrors: Errors{
+				{Field: "keys.auth", Rule: Required, Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := validRequest()
+			tt.modify(&request)
+
+			err := Struct(&request)
+			if tt.expectedErrors == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedErrors, err)
+		})
+	}
+}
+
+func TestRules_PanicsOnUnknownRule(t *testing.T) {
+	type badRequest struct {
+		Name string `validate:"requird"`
+	}
+	assert.PanicsWithValue(t, `validate: unknown rule "requird" on Name`, func() {
+		_ = Struct(&badRequest{})
+	})
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedError error
+	}{
+		{
+			name: "Valid",
+			body: `{"name":"Anna","days":3,"keys":{"auth":"secret"}}`,
+		},
+		{
+			name: "Unknown field",
+			body: `{"name":"Anna","nickname":"Annie"}`,
+			expectedError: Errors{
+				{Field: "nickname", Rule: Unknown, Message: "is not a known field"},
+			},
+		},
+		{
+			name: "Wrong type",
+			body: `{"name":"Anna","days":"three"}`,
+			expectedError: Errors{
+				{Field: "days", Rule: Type, Message: "must be an integer"},
+			},
+		},
+		{
+			name: "Wrong type in a nested struct",
+			body: `{"keys":{"auth":7}}`,
+			expectedError: Errors{
+				{Field: "keys.auth", Rule: Type, Message: "must be a string"},
+			},
+		},
+		{
+			name:          "Trailing data",
+			body:          `{"name":"Anna"}{"name":"Bob"}`,
+			expectedError: ErrTrailingData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request testRequest
+			err := DecodeJSON(strings.NewReader(tt.body), &request)
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+
+	t.Run("Malformed JSON", func(t *testing.T) {
+		var request testRequest
+		err := DecodeJSON(strings.NewReader(`{"name":`), &request)
+		require.Error(t, err)
+		assert.NotErrorAs(t, err, new(Errors))
+	})
+}
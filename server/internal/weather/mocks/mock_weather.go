@@ -41,6 +41,21 @@ func (m *MockWeatherService) EXPECT() *MockWeatherServiceMockRecorder {
 	return m.recorder
 }
 
+// GetDailyForecast mocks base method.
+func (m *MockWeatherService) GetDailyForecast(ctx context.Context, lat, lon float64) ([]weather.WeatherPrediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyForecast", ctx, lat, lon)
+	ret0, _ := ret[0].([]weather.WeatherPrediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyForecast indicates an expected call of GetDailyForecast.
+func (mr *MockWeatherServiceMockRecorder) GetDailyForecast(ctx, lat, lon any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyForecast", reflect.TypeOf((*MockWeatherService)(nil).GetDailyForecast), ctx, lat, lon)
+}
+
 // GetSnowForecast mocks base method.
 func (m *MockWeatherService) GetSnowForecast(ctx context.Context, lat, lon float64) ([]weather.WeatherPrediction, error) {
 	m.ctrl.T.Helper()
@@ -5,16 +5,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MattSilvaa/powhunter/internal/metrics"
+	"github.com/MattSilvaa/powhunter/internal/tracing"
 )
 
+// ProviderOpenMeteo identifies Open-Meteo in metrics.
+const ProviderOpenMeteo = "open-meteo"
+
 //go:generate mockgen -destination=mocks/mock_weather.go -package=mocks github.com/MattSilvaa/powhunter/internal/weather WeatherService
 
 // WeatherService defines the interface for weather service operations.
 type WeatherService interface {
 	// GetSnowForecast gets the snow forecast for a location
 	GetSnowForecast(ctx context.Context, lat, lon float64) ([]WeatherPrediction, error)
+
+	// GetDailyForecast gets the forecast for every day at a location, including days without snow
+	GetDailyForecast(ctx context.Context, lat, lon float64) ([]WeatherPrediction, error)
 }
 
 // OpenMeteoClient provides access to the Open-Meteo API.
@@ -23,11 +37,13 @@ type OpenMeteoClient struct {
 	baseURL string
 }
 
-// NewOpenMeteoClient creates a new Open-Meteo API client.
+// NewOpenMeteoClient creates a new Open-Meteo API client. Its requests are traced as children of the span
+// of the forecast they fetch.
 func NewOpenMeteoClient() *OpenMeteoClient {
 	return &OpenMeteoClient{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		},
 		baseURL: "https://api.open-meteo.com/v1",
 	}
@@ -78,24 +94,62 @@ type OpenMeteoResponse struct {
 		Time        []OpenMeteoTime `json:"time"`
 		Temperature []float64       `json:"temperature_2m"`
 		Snowfall    []float64       `json:"snowfall"`
+		CloudCover  []float64       `json:"cloud_cover"`
 	} `json:"hourly"`
 }
 
 // WeatherPrediction represents a predicted snowfall and temperature for a specific date.
 type WeatherPrediction struct {
 	Date           time.Time
-	SnowAmount     float64 // in inches
-	AvgTemperature float64 // in fahrenheit
+	SnowAmount     float64 // in inches, the canonical snow unit (see package units)
+	AvgTemperature float64 // in fahrenheit, the canonical temperature unit
 	MinTemperature float64 // in fahrenheit
 	MaxTemperature float64 // in fahrenheit
+	// CloudCover is the average percentage of the sky covered by cloud. Days missing cloud cover for any
+	// hour count as overcast, so they're never taken for clear days.
+	CloudCover float64
+}
+
+// A bluebird day is a clear day after a day of snow.
+const (
+	// bluebirdMaxCloudCover is the most cloud cover, in percent, a clear day averages.
+	bluebirdMaxCloudCover = 25
+	// bluebirdMaxSnow is the most snow, in inches, a clear day has.
+	bluebirdMaxSnow = 0.1
+)
+
+// Bluebird is a clear day following a day of snow.
+type Bluebird struct {
+	Date time.Time
+	// SnowAmount is the snow forecast for the day before, in inches.
+	SnowAmount float64
 }
 
-func (c *OpenMeteoClient) GetForecast(ctx context.Context, lat, lon float64) (*OpenMeteoResponse, error) {
+// Open-Meteo unit parameters matching the canonical units predictions are stored in. Conversion to a
+// user's preferred system happens when alerts are rendered, not here.
+const (
+	openMeteoTemperatureUnit   = "fahrenheit"
+	openMeteoPrecipitationUnit = "inch"
+)
+
+func (c *OpenMeteoClient) GetForecast(ctx context.Context, lat, lon float64) (forecast *OpenMeteoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "OpenMeteoClient.GetForecast", trace.WithAttributes(
+		attribute.Float64("geo.latitude", lat),
+		attribute.Float64("geo.longitude", lon),
+	))
+	start := time.Now()
+	defer func() {
+		metrics.ObserveProviderCall(ProviderOpenMeteo, start, err)
+		tracing.End(span, err)
+	}()
+
 	url := fmt.Sprintf(
-		"%s/forecast?latitude=%.6f&longitude=%.6f&current=temperature_2m,snowfall&hourly=snowfall,temperature_2m&temperature_unit=fahrenheit&precipitation_unit=inch&temporal_resolution=hourly_6&timezone=Etc/UTC",
+		"%s/forecast?latitude=%.6f&longitude=%.6f&current=temperature_2m,snowfall&hourly=snowfall,temperature_2m,cloud_cover&temperature_unit=%s&precipitation_unit=%s&temporal_resolution=hourly_6&timezone=Etc/UTC",
 		c.baseURL,
 		lat,
 		lon,
+		openMeteoTemperatureUnit,
+		openMeteoPrecipitationUnit,
 	)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -124,6 +178,27 @@ func (c *OpenMeteoClient) GetForecast(ctx context.Context, lat, lon float64) (*O
 	return &forecastResp, nil
 }
 
+// Ping checks Open-Meteo can be reached and answers, with the smallest forecast request it serves.
+func (c *OpenMeteoClient) Ping(ctx context.Context) error {
+	url := c.baseURL + "/forecast?latitude=0&longitude=0&current=temperature_2m"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Powhunter/1.0 (Language=Go 1.24)")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reaching Open-Meteo API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error from Open-Meteo API: %s", resp.Status)
+	}
+	return nil
+}
+
 func (c *OpenMeteoClient) GetSnowForecast(ctx context.Context, lat, lon float64) ([]WeatherPrediction, error) {
 	forecast, err := c.GetForecast(ctx, lat, lon)
 	if err != nil {
@@ -133,12 +208,56 @@ func (c *OpenMeteoClient) GetSnowForecast(ctx context.Context, lat, lon float64)
 	return ParseWeatherData(forecast), nil
 }
 
-// ParseWeatherData parses the snowfall and temperature data from the response.
+// GetDailyForecast gets the forecast for every day at a location, sorted by date.
+func (c *OpenMeteoClient) GetDailyForecast(ctx context.Context, lat, lon float64) ([]WeatherPrediction, error) {
+	forecast, err := c.GetForecast(ctx, lat, lon)
+	if err != nil {
+		return nil, fmt.Errorf("error getting forecast: %w", err)
+	}
+
+	return ParseDailyForecast(forecast), nil
+}
+
+// ParseWeatherData parses the snowfall and temperature data from the response, keeping the days with snow.
 func ParseWeatherData(forecast *OpenMeteoResponse) []WeatherPrediction {
+	return SnowDays(ParseDailyForecast(forecast))
+}
+
+// SnowDays returns the predictions with snow.
+func SnowDays(predictions []WeatherPrediction) []WeatherPrediction {
+	var snowDays []WeatherPrediction
+	for _, prediction := range predictions {
+		if prediction.SnowAmount > 0 {
+			snowDays = append(snowDays, prediction)
+		}
+	}
+	return snowDays
+}
+
+// BluebirdDays returns the clear days in predictions that follow a day with snow. Predictions must be sorted
+// by date, as ParseDailyForecast returns them.
+func BluebirdDays(predictions []WeatherPrediction) []Bluebird {
+	var bluebirds []Bluebird
+	for i := 1; i < len(predictions); i++ {
+		day, before := predictions[i], predictions[i-1]
+		if !day.Date.Equal(before.Date.AddDate(0, 0, 1)) || before.SnowAmount <= bluebirdMaxSnow {
+			continue
+		}
+		if day.SnowAmount <= bluebirdMaxSnow && day.CloudCover <= bluebirdMaxCloudCover {
+			bluebirds = append(bluebirds, Bluebird{Date: day.Date, SnowAmount: before.SnowAmount})
+		}
+	}
+	return bluebirds
+}
+
+// ParseDailyForecast aggregates the hourly data in the response into one prediction per day, sorted by date.
+func ParseDailyForecast(forecast *OpenMeteoResponse) []WeatherPrediction {
 	snowByDate := make(map[string]float64)
 	tempSumByDate := make(map[string]float64)
 	tempMinByDate := make(map[string]float64)
 	tempMaxByDate := make(map[string]float64)
+	cloudSumByDate := make(map[string]float64)
+	cloudCountByDate := make(map[string]int)
 	countByDate := make(map[string]int)
 
 	for i, timestamp := range forecast.Hourly.Time {
@@ -165,13 +284,14 @@ func ParseWeatherData(forecast *OpenMeteoResponse) []WeatherPrediction {
 
 		tempSumByDate[dateStr] += temp
 		countByDate[dateStr]++
+
+		if i < len(forecast.Hourly.CloudCover) {
+			cloudSumByDate[dateStr] += forecast.Hourly.CloudCover[i]
+			cloudCountByDate[dateStr]++
+		}
 	}
 	var predictions []WeatherPrediction
 	for dateStr, snowAmount := range snowByDate {
-		if snowAmount <= 0 {
-			continue
-		}
-
 		date, err := time.Parse("2006-01-02", dateStr)
 		if err != nil {
 			continue
@@ -182,14 +302,24 @@ func ParseWeatherData(forecast *OpenMeteoResponse) []WeatherPrediction {
 			avgTemp = tempSumByDate[dateStr] / float64(count)
 		}
 
+		cloudCover := 100.0
+		if count := cloudCountByDate[dateStr]; count > 0 && count == countByDate[dateStr] {
+			cloudCover = cloudSumByDate[dateStr] / float64(count)
+		}
+
 		predictions = append(predictions, WeatherPrediction{
 			Date:           date,
 			SnowAmount:     snowAmount,
 			AvgTemperature: avgTemp,
 			MinTemperature: tempMinByDate[dateStr],
 			MaxTemperature: tempMaxByDate[dateStr],
+			CloudCover:     cloudCover,
 		})
 	}
 
+	sort.Slice(predictions, func(i, j int) bool {
+		return predictions[i].Date.Before(predictions[j].Date)
+	})
+
 	return predictions
 }
@@ -0,0 +1,82 @@
+package weather
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBluebirdDays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name        string
+		predictions []WeatherPrediction
+		expected    []Bluebird
+	}{
+		{
+			name: "Clear day after snow",
+			predictions: []WeatherPrediction{
+				{Date: day(20), SnowAmount: 9, CloudCover: 100},
+				{Date: day(21), SnowAmount: 0, CloudCover: 10},
+			},
+			expected: []Bluebird{{Date: day(21), SnowAmount: 9}},
+		},
+		{
+			name: "Cloudy day after snow",
+			predictions: []WeatherPrediction{
+				{Date: day(20), SnowAmount: 9, CloudCover: 100},
+				{Date: day(21), SnowAmount: 0, CloudCover: 60},
+			},
+		},
+		{
+			name: "Clear day without snow before",
+			predictions: []WeatherPrediction{
+				{Date: day(20), SnowAmount: 0, CloudCover: 10},
+				{Date: day(21), SnowAmount: 0, CloudCover: 10},
+			},
+		},
+		{
+			name: "Snowy day after snow",
+			predictions: []WeatherPrediction{
+				{Date: day(20), SnowAmount: 9, CloudCover: 100},
+				{Date: day(21), SnowAmount: 2, CloudCover: 20},
+			},
+		},
+		{
+			name: "Clear day after a gap in the forecast",
+			predictions: []WeatherPrediction{
+				{Date: day(20), SnowAmount: 9, CloudCover: 100},
+				{Date: day(22), SnowAmount: 0, CloudCover: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, BluebirdDays(tt.predictions))
+		})
+	}
+}
+
+func TestParseDailyForecast_CloudCover(t *testing.T) {
+	var forecast OpenMeteoResponse
+	for i, hour := range []string{"2025-12-20T00:00", "2025-12-20T12:00", "2025-12-21T00:00", "2025-12-21T12:00"} {
+		parsed, err := time.Parse("2006-01-02T15:04", hour)
+		require.NoError(t, err)
+		forecast.Hourly.Time = append(forecast.Hourly.Time, OpenMeteoTime{parsed})
+		forecast.Hourly.Temperature = append(forecast.Hourly.Temperature, 20)
+		forecast.Hourly.Snowfall = append(forecast.Hourly.Snowfall, 0)
+		if i < 3 {
+			forecast.Hourly.CloudCover = append(forecast.Hourly.CloudCover, float64(10*(i+1)))
+		}
+	}
+
+	predictions := ParseDailyForecast(&forecast)
+
+	require.Len(t, predictions, 2)
+	assert.Equal(t, 15.0, predictions[0].CloudCover)
+	assert.Equal(t, 100.0, predictions[1].CloudCover, "days missing cloud cover count as overcast")
+}
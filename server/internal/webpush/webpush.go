@@ -0,0 +1,385 @@
+// Package webpush implements the sending side of the Web Push protocol: message encryption (RFC 8291) and
+// VAPID authentication of the application server (RFC 8292).
+//
+// Browsers give the client a PushSubscription: an endpoint URL on their push service and the keys a payload
+// is encrypted to. Payloads are encrypted with aes128gcm (RFC 8188) as a single record, so the push service
+// only relays ciphertext, and each request carries a short-lived JWT signed by the VAPID key the subscription
+// was created with.
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// recordSize is the aes128gcm record size advertised in the header. Payloads are sent as one record.
+	recordSize = 4096
+
+	// headerSize is the aes128gcm header: salt, record size, key ID length and the sender's public key.
+	headerSize = saltSize + 4 + 1 + publicKeySize
+
+	saltSize      = 16
+	authSize      = 16
+	publicKeySize = 65
+	tagSize       = 16
+
+	// MaxPayloadSize is the largest plaintext that fits the 4096 bytes push services must accept.
+	MaxPayloadSize = recordSize - headerSize - tagSize - 1
+
+	// tokenTTL is how long VAPID tokens are valid. Push services reject tokens valid for over 24 hours.
+	tokenTTL = 12 * time.Hour
+)
+
+// ErrPayloadTooLarge is returned by Encrypt for payloads over MaxPayloadSize.
+var ErrPayloadTooLarge = fmt.Errorf("web push payload is larger than %d bytes", MaxPayloadSize)
+
+// encoding is the unpadded base64url used for keys in subscriptions and VAPID headers.
+var encoding = base64.RawURLEncoding
+
+// Subscription is a browser's PushSubscription.
+type Subscription struct {
+	Endpoint string
+	// P256DH is the browser's P-256 public key, base64url encoded.
+	P256DH string
+	// Auth is the browser's 16-byte authentication secret, base64url encoded.
+	Auth string
+}
+
+// Validate checks that the subscription's keys are well formed.
+func (s Subscription) Validate() error {
+	_, _, err := s.keys()
+	return err
+}
+
+func (s Subscription) keys() (*ecdh.PublicKey, []byte, error) {
+	rawKey, err := decode(s.P256DH)
+	if err != nil {
+		return nil, nil, errors.New("subscription p256dh key is not base64url")
+	}
+	publicKey, err := ecdh.P256().NewPublicKey(rawKey)
+	if err != nil {
+		return nil, nil, errors.New("subscription p256dh key is not a P-256 public key")
+	}
+
+	auth, err := decode(s.Auth)
+	if err != nil || len(auth) != authSize {
+		return nil, nil, fmt.Errorf("subscription auth secret must be %d base64url bytes", authSize)
+	}
+
+	return publicKey, auth, nil
+}
+
+// Encrypt encrypts a payload to a subscription, returning the aes128gcm request body.
+func Encrypt(subscription Subscription, payload []byte) ([]byte, error) {
+	senderKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating web push key: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error generating web push salt: %w", err)
+	}
+
+	return encrypt(subscription, payload, senderKey, salt)
+}
+
+// encrypt implements RFC 8291 with the sender's key pair and salt given.
+func encrypt(subscription Subscription, payload []byte, senderKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	receiverKey, auth, err := subscription.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := senderKey.ECDH(receiverKey)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving web push secret: %w", err)
+	}
+
+	senderPublic := senderKey.PublicKey().Bytes()
+	gcm, nonce, err := contentCipher(sharedSecret, auth, receiverKey.Bytes(), senderPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, headerSize, headerSize+len(payload)+1+tagSize)
+	copy(body, salt)
+	binary.BigEndian.PutUint32(body[saltSize:], recordSize)
+	body[saltSize+4] = publicKeySize
+	copy(body[saltSize+5:], senderPublic)
+
+	// 0x02 marks the last (and only) record, with no padding after it.
+	record := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
+
+// Decrypt decrypts a single-record message with the receiver's base64url encoded private key and auth
+// secret. Browsers do this; it is exported for tests standing in for one.
+func Decrypt(privateKey, auth string, body []byte) ([]byte, error) {
+	if len(body) < headerSize || body[saltSize+4] != publicKeySize {
+		return nil, errors.New("malformed web push message")
+	}
+
+	rawPrivate, err := decode(privateKey)
+	if err != nil {
+		return nil, errors.New("private key is not base64url")
+	}
+	receiverKey, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	authSecret, err := decode(auth)
+	if err != nil {
+		return nil, errors.New("auth secret is not base64url")
+	}
+	senderPublic := body[saltSize+5 : headerSize]
+	senderKey, err := ecdh.P256().NewPublicKey(senderPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender key: %w", err)
+	}
+
+	sharedSecret, err := receiverKey.ECDH(senderKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, nonce, err := contentCipher(
+		sharedSecret, authSecret, receiverKey.PublicKey().Bytes(), senderPublic, body[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := gcm.Open(nil, nonce, body[headerSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting web push message: %w", err)
+	}
+	end := len(record) - 1
+	for end >= 0 && record[end] == 0 {
+		end--
+	}
+	if end < 0 || record[end] != 0x02 {
+		return nil, errors.New("web push message is not a single record")
+	}
+	return record[:end], nil
+}
+
+// contentCipher derives the content encryption key and nonce for a message. The input keying material mixes
+// the ECDH secret with the subscription's auth secret and both public keys.
+func contentCipher(sharedSecret, auth, receiverPublic, senderPublic, salt []byte) (cipher.AEAD, []byte, error) {
+	keyInfo := "WebPush: info\x00" + string(receiverPublic) + string(senderPublic)
+	prkKey, err := hkdf.Extract(sha256.New, sharedSecret, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, nonce, nil
+}
+
+// VAPIDKeys is the application server's P-256 key pair. Browsers are given the public key when they
+// subscribe and only accept pushes signed with the matching private key.
+type VAPIDKeys struct {
+	key    *ecdh.PrivateKey
+	signer *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys returns a new key pair.
+func GenerateVAPIDKeys() (*VAPIDKeys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating VAPID keys: %w", err)
+	}
+	return newVAPIDKeys(key), nil
+}
+
+// ParseVAPIDKeys parses a base64url encoded private key, as printed by PrivateKey.
+func ParseVAPIDKeys(privateKey string) (*VAPIDKeys, error) {
+	raw, err := decode(privateKey)
+	if err != nil {
+		return nil, errors.New("VAPID private key is not base64url")
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	return newVAPIDKeys(key), nil
+}
+
+func newVAPIDKeys(key *ecdh.PrivateKey) *VAPIDKeys {
+	return &VAPIDKeys{
+		key: key,
+		signer: &ecdsa.PrivateKey{
+			PublicKey: ecdsaPublicKey(key.PublicKey()),
+			D:         new(big.Int).SetBytes(key.Bytes()),
+		},
+	}
+}
+
+// ecdsaPublicKey converts a validated P-256 public key for signature checks.
+func ecdsaPublicKey(key *ecdh.PublicKey) ecdsa.PublicKey {
+	raw := key.Bytes()
+	return ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(raw[1:33]),
+		Y:     new(big.Int).SetBytes(raw[33:]),
+	}
+}
+
+// VAPIDKeysFromEnv returns the key pair in VAPID_PRIVATE_KEY. It returns nil when no key is configured, in
+// which case Web Push is disabled.
+func VAPIDKeysFromEnv() (*VAPIDKeys, error) {
+	privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+	if privateKey == "" {
+		return nil, nil
+	}
+	return ParseVAPIDKeys(privateKey)
+}
+
+// PublicKey returns the uncompressed public key, base64url encoded. This is the applicationServerKey
+// browsers subscribe with.
+func (k *VAPIDKeys) PublicKey() string {
+	return encoding.EncodeToString(k.key.PublicKey().Bytes())
+}
+
+// PrivateKey returns the private key, base64url encoded.
+func (k *VAPIDKeys) PrivateKey() string {
+	return encoding.EncodeToString(k.key.Bytes())
+}
+
+// Authorization returns the Authorization header for a push to endpoint: a VAPID token for the endpoint's
+// push service, signed at now, and the public key to check it with. subject is a mailto: or https: URL the
+// push service can contact the sender at.
+func (k *VAPIDKeys) Authorization(endpoint, subject string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, err := json.Marshal(map[string]any{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(tokenTTL).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, k.signer, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing VAPID token: %w", err)
+	}
+
+	// JWS ES256 signatures are r and s as fixed-size big-endian integers, not ASN.1.
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := signingInput + "." + encoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + k.PublicKey(), nil
+}
+
+// VerifyAuthorization checks a VAPID Authorization header against the public key it names and returns the
+// token's claims. Push services do this; it is exported for tests standing in for one.
+func VerifyAuthorization(header string) (map[string]any, string, error) {
+	params, ok := strings.CutPrefix(header, "vapid ")
+	if !ok {
+		return nil, "", errors.New("authorization is not vapid")
+	}
+
+	var token, publicKey string
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "t":
+			token = value
+		case "k":
+			publicKey = value
+		}
+	}
+
+	rawKey, err := decode(publicKey)
+	if err != nil {
+		return nil, "", errors.New("invalid vapid public key")
+	}
+	ecdhKey, err := ecdh.P256().NewPublicKey(rawKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid vapid public key: %w", err)
+	}
+	key := ecdsaPublicKey(ecdhKey)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, "", errors.New("malformed vapid token")
+	}
+	signature, err := decode(parts[2])
+	if err != nil || len(signature) != 64 {
+		return nil, "", errors.New("malformed vapid signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(&key, digest[:], r, s) {
+		return nil, "", errors.New("bad vapid signature")
+	}
+
+	rawClaims, err := decode(parts[1])
+	if err != nil {
+		return nil, "", errors.New("malformed vapid claims")
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return nil, "", errors.New("malformed vapid claims")
+	}
+	return claims, publicKey, nil
+}
+
+// decode accepts base64url with or without padding, as browsers differ.
+func decode(value string) ([]byte, error) {
+	return encoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,143 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The example from RFC 8291, Appendix A.
+const (
+	rfcPlaintext      = "When I grow up, I want to be a watermelon"
+	rfcSenderPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcReceiverPublic = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcReceiverKey    = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfcAuth           = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcSalt           = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcMessage        = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3v" +
+		"CYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func rfcSubscription() Subscription {
+	return Subscription{
+		Endpoint: "https://push.example.net/push/JzLQ3raZJfFBR0aqvOMsLrt54w4rJUsV",
+		P256DH:   rfcReceiverPublic,
+		Auth:     rfcAuth,
+	}
+}
+
+func TestEncrypt_RFC8291Example(t *testing.T) {
+	rawSender, err := decode(rfcSenderPrivate)
+	require.NoError(t, err)
+	senderKey, err := ecdh.P256().NewPrivateKey(rawSender)
+	require.NoError(t, err)
+	salt, err := decode(rfcSalt)
+	require.NoError(t, err)
+
+	body, err := encrypt(rfcSubscription(), []byte(rfcPlaintext), senderKey, salt)
+	require.NoError(t, err)
+	assert.Equal(t, rfcMessage, encoding.EncodeToString(body))
+
+	plaintext, err := Decrypt(rfcReceiverKey, rfcAuth, body)
+	require.NoError(t, err)
+	assert.Equal(t, rfcPlaintext, string(plaintext))
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	receiver, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	subscription := Subscription{
+		Endpoint: "https://push.example.net/push/abc",
+		P256DH:   encoding.EncodeToString(receiver.PublicKey().Bytes()) + "=",
+		Auth:     rfcAuth,
+	}
+
+	first, err := Encrypt(subscription, []byte(`{"title":"Powder Alert"}`))
+	require.NoError(t, err)
+	second, err := Encrypt(subscription, []byte(`{"title":"Powder Alert"}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "every message has a fresh key and salt")
+
+	plaintext, err := Decrypt(encoding.EncodeToString(receiver.Bytes()), rfcAuth, first)
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"Powder Alert"}`, string(plaintext))
+
+	first[len(first)-1] ^= 1
+	_, err = Decrypt(encoding.EncodeToString(receiver.Bytes()), rfcAuth, first)
+	assert.Error(t, err, "tampered messages don't decrypt")
+
+	_, err = Encrypt(subscription, make([]byte, MaxPayloadSize+1))
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+	_, err = Encrypt(subscription, make([]byte, MaxPayloadSize))
+	assert.NoError(t, err)
+}
+
+func TestSubscription_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription Subscription
+		err          string
+	}{
+		{name: "Valid", subscription: rfcSubscription()},
+		{
+			name:         "Key not on the curve",
+			subscription: Subscription{P256DH: "BAAA" + rfcReceiverPublic[4:], Auth: rfcAuth},
+			err:          "subscription p256dh key is not a P-256 public key",
+		},
+		{
+			name:         "Key not base64url",
+			subscription: Subscription{P256DH: "not a key!", Auth: rfcAuth},
+			err:          "subscription p256dh key is not base64url",
+		},
+		{
+			name:         "Short auth secret",
+			subscription: Subscription{P256DH: rfcReceiverPublic, Auth: "BTBZMqHH"},
+			err:          "subscription auth secret must be 16 base64url bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.subscription.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVAPIDKeys_Authorization(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	parsed, err := ParseVAPIDKeys(keys.PrivateKey())
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey(), parsed.PublicKey())
+	assert.Len(t, keys.PublicKey(), 87, "65 byte uncompressed point")
+
+	now := time.Date(2025, 12, 18, 6, 0, 0, 0, time.UTC)
+	header, err := parsed.Authorization("https://fcm.googleapis.com/fcm/send/abc:def", "mailto:support@powhunter.app", now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(header, "vapid t="))
+
+	claims, publicKey, err := VerifyAuthorization(header)
+	require.NoError(t, err)
+	assert.Equal(t, keys.PublicKey(), publicKey)
+	assert.Equal(t, "https://fcm.googleapis.com", claims["aud"])
+	assert.Equal(t, "mailto:support@powhunter.app", claims["sub"])
+	assert.Equal(t, float64(now.Add(12*time.Hour).Unix()), claims["exp"])
+
+	tampered := strings.Replace(header, "k=", "k=B"+keys.PublicKey()[1:2], 1)
+	_, _, err = VerifyAuthorization(tampered)
+	assert.Error(t, err)
+
+	_, err = ParseVAPIDKeys("not a key")
+	assert.Error(t, err)
+}